@@ -0,0 +1,166 @@
+// Collaborative plain-text editor backed by the same RGA document model as
+// internal/collab. The server streams the document snapshot and later
+// operations through Datastar (load/receive are called from patched scripts);
+// local edits are diffed into operations, applied immediately and POSTed to
+// data-ops-url one batch at a time, so the server sees them in causal order.
+const key = (id) => `${id.c}:${id.s}`;
+
+// Same order as ID.after in Go: higher counters first, then higher sites.
+const isAfter = (a, b) => (a.c !== b.c ? a.c > b.c : a.s > b.s);
+
+class CollabEditor extends HTMLElement {
+  #site = crypto.randomUUID();
+  #elems = [];
+  #ids = new Set();
+  #clock = 0;
+  #seq = 0;
+  #text = [];
+  #queue = [];
+  #sending = false;
+
+  connectedCallback() {
+    this.textarea = this.querySelector('textarea');
+    this.textarea.addEventListener('input', this.#onInput);
+  }
+
+  disconnectedCallback() {
+    this.textarea?.removeEventListener('input', this.#onInput);
+  }
+
+  // load replaces the document with a server snapshot.
+  load(snapshot) {
+    this.#elems = snapshot.elements.map((e) => ({ id: e.id, v: e.v, d: !!e.d }));
+    this.#ids = new Set(this.#elems.map((e) => key(e.id)));
+    this.#clock = Math.max(0, ...this.#elems.map((e) => e.id.c));
+    this.#seq = snapshot.seq;
+    this.#render(null);
+    this.textarea.disabled = false;
+  }
+
+  // receive applies operations from the server's op log.
+  receive(batch) {
+    if (batch.seq <= this.#seq) return;
+    this.#seq = batch.seq;
+
+    const anchors = this.#anchors();
+    for (const op of batch.ops) {
+      // Our own operations come back too and are already applied
+      if (op.t === 'ins' && this.#ids.has(key(op.id))) continue;
+      this.#apply(op);
+    }
+    this.#render(anchors);
+  }
+
+  #apply(op) {
+    if (op.t === 'del') {
+      const elem = this.#elems.find((e) => key(e.id) === key(op.id));
+      if (elem) elem.d = true;
+      return;
+    }
+
+    let pos = 0;
+    if (op.after.c !== 0 || op.after.s !== '') {
+      pos = this.#elems.findIndex((e) => key(e.id) === key(op.after)) + 1;
+    }
+    while (pos < this.#elems.length && isAfter(this.#elems[pos].id, op.id)) pos++;
+
+    this.#elems.splice(pos, 0, { id: op.id, v: op.v, d: false });
+    this.#ids.add(key(op.id));
+    this.#clock = Math.max(this.#clock, op.id.c);
+  }
+
+  #visible() {
+    return this.#elems.filter((e) => !e.d);
+  }
+
+  #onInput = () => {
+    const before = this.#text;
+    const after = Array.from(this.textarea.value);
+
+    let prefix = 0;
+    while (prefix < before.length && prefix < after.length && before[prefix] === after[prefix]) prefix++;
+    let suffix = 0;
+    while (
+      suffix < before.length - prefix &&
+      suffix < after.length - prefix &&
+      before[before.length - 1 - suffix] === after[after.length - 1 - suffix]
+    ) suffix++;
+
+    const visible = this.#visible();
+    const ops = [];
+
+    for (const elem of visible.slice(prefix, before.length - suffix)) {
+      ops.push({ t: 'del', id: elem.id, after: { c: 0, s: '' } });
+    }
+
+    let previous = prefix > 0 ? visible[prefix - 1].id : { c: 0, s: '' };
+    for (const ch of after.slice(prefix, after.length - suffix)) {
+      const id = { c: ++this.#clock, s: this.#site };
+      ops.push({ t: 'ins', id, after: previous, v: ch });
+      previous = id;
+    }
+
+    ops.forEach((op) => this.#apply(op));
+    this.#text = after;
+    this.#queue.push(...ops);
+    this.#flush();
+  };
+
+  async #flush() {
+    if (this.#sending || this.#queue.length === 0) return;
+    this.#sending = true;
+
+    const ops = this.#queue.splice(0);
+    try {
+      const res = await fetch(this.dataset.opsUrl, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ ops }),
+      });
+      if (!res.ok) throw new Error(`ops rejected: ${res.status}`);
+    } catch (err) {
+      // Our copy can no longer be trusted; start over from the server state
+      console.error(err);
+      window.location.reload();
+      return;
+    } finally {
+      this.#sending = false;
+    }
+    this.#flush();
+  }
+
+  // #anchors remembers the characters before the selection ends, so the
+  // caret stays in place when remote edits shift the text.
+  #anchors() {
+    const visible = this.#visible();
+    const at = (offset) => (offset > 0 ? visible[offset - 1]?.id : null);
+    const text = this.textarea.value;
+    const toPoints = (offset) => Array.from(text.slice(0, offset)).length;
+    return {
+      start: at(toPoints(this.textarea.selectionStart)),
+      end: at(toPoints(this.textarea.selectionEnd)),
+      focused: document.activeElement === this.textarea,
+    };
+  }
+
+  #render(anchors) {
+    const visible = this.#visible();
+    this.#text = visible.map((e) => e.v);
+    this.textarea.value = this.#text.join('');
+    if (!anchors || !anchors.focused) return;
+
+    const offset = (id) => {
+      if (!id) return 0;
+      const i = visible.findIndex((e) => key(e.id) === key(id));
+      // A deleted anchor falls back to its nearest visible predecessor
+      if (i < 0) {
+        const all = this.#elems.findIndex((e) => key(e.id) === key(id));
+        return this.#elems.slice(0, all).filter((e) => !e.d).map((e) => e.v).join('').length;
+      }
+      return visible.slice(0, i + 1).map((e) => e.v).join('').length;
+    };
+    this.textarea.setSelectionRange(offset(anchors.start), offset(anchors.end));
+  }
+}
+
+customElements.define('collab-editor', CollabEditor);
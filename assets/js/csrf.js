@@ -0,0 +1,17 @@
+// Send the CSRF token with every state-changing same-origin request, so
+// Datastar actions and editor saves pass the server's CSRF check.
+const token = document.querySelector('meta[name="csrf-token"]')?.content;
+const safeMethods = ['GET', 'HEAD', 'OPTIONS', 'TRACE'];
+const originalFetch = window.fetch;
+
+window.fetch = (input, init = {}) => {
+  const request = new Request(input, init);
+  const url = new URL(request.url, window.location.href);
+  if (!token || safeMethods.includes(request.method) || url.origin !== window.location.origin) {
+    return originalFetch(input, init);
+  }
+
+  const headers = new Headers(init.headers ?? (input instanceof Request ? input.headers : undefined));
+  headers.set('X-CSRF-Token', token);
+  return originalFetch(input, { ...init, headers });
+};
@@ -1,3 +1,6 @@
+// CSRF token for state-changing requests; must load before Datastar
+import './csrf.js';
+
 // Datastar - Hypermedia Framework
 import 'https://cdn.jsdelivr.net/gh/starfederation/datastar@1.0.0-RC.6/bundles/datastar.js'
 
@@ -20,6 +23,14 @@ import '@shoelace-style/shoelace/dist/components/select/select.js';
 import '@shoelace-style/shoelace/dist/components/option/option.js';
 import '@shoelace-style/shoelace/dist/components/textarea/textarea.js';
 import '@shoelace-style/shoelace/dist/components/checkbox/checkbox.js';
+import '@shoelace-style/shoelace/dist/components/tag/tag.js';
+import '@shoelace-style/shoelace/dist/components/tooltip/tooltip.js';
+import '@shoelace-style/shoelace/dist/components/format-date/format-date.js';
+import '@shoelace-style/shoelace/dist/components/copy-button/copy-button.js';
+
+// Custom elements
+import './nav-editor.js';
+import './collab-editor.js';
 
 // Set the base path for Shoelace assets (icons, etc.)
 import { setBasePath } from '@shoelace-style/shoelace/dist/utilities/base-path.js';
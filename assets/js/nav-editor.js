@@ -0,0 +1,85 @@
+// Drag-and-drop editor for the Starlight sidebar.
+// Items are <li data-nav-id> inside <ul data-nav-list>; groups contain a nested list.
+// After every drop the new order is serialized as JSON into the bound
+// <input data-nav-order> so Datastar can post it.
+class NavEditor extends HTMLElement {
+  #dragged = null;
+
+  connectedCallback() {
+    if (this.hasAttribute('readonly')) return;
+
+    this.querySelectorAll('[data-nav-id]').forEach((item) => {
+      item.setAttribute('draggable', 'true');
+    });
+
+    this.addEventListener('dragstart', this.#onDragStart);
+    this.addEventListener('dragover', this.#onDragOver);
+    this.addEventListener('drop', this.#onDrop);
+    this.addEventListener('dragend', this.#onDragEnd);
+  }
+
+  disconnectedCallback() {
+    this.removeEventListener('dragstart', this.#onDragStart);
+    this.removeEventListener('dragover', this.#onDragOver);
+    this.removeEventListener('drop', this.#onDrop);
+    this.removeEventListener('dragend', this.#onDragEnd);
+  }
+
+  #onDragStart = (e) => {
+    this.#dragged = e.target.closest('[data-nav-id]');
+    if (!this.#dragged) return;
+    e.stopPropagation();
+    e.dataTransfer.effectAllowed = 'move';
+    e.dataTransfer.setData('text/plain', this.#dragged.dataset.navId);
+    this.#dragged.classList.add('is-dragging');
+  };
+
+  #onDragOver = (e) => {
+    if (!this.#dragged) return;
+    const list = e.target.closest('[data-nav-list]');
+    // Never drop a group into itself
+    if (!list || this.#dragged.contains(list)) return;
+    e.preventDefault();
+
+    const after = [...list.children].find((child) => {
+      if (child === this.#dragged || !child.matches('[data-nav-id]')) return false;
+      const box = child.getBoundingClientRect();
+      return e.clientY < box.top + box.height / 2;
+    });
+    list.insertBefore(this.#dragged, after || null);
+  };
+
+  #onDrop = (e) => {
+    if (!this.#dragged) return;
+    e.preventDefault();
+    this.#sync();
+  };
+
+  #onDragEnd = () => {
+    this.#dragged?.classList.remove('is-dragging');
+    this.#dragged = null;
+    this.#sync();
+  };
+
+  #serialize(list) {
+    return [...list.children]
+      .filter((child) => child.matches('[data-nav-id]'))
+      .map((child) => {
+        const nested = child.querySelector(':scope > [data-nav-list]');
+        const entry = { id: child.dataset.navId };
+        if (nested) entry.items = this.#serialize(nested);
+        return entry;
+      });
+  }
+
+  #sync() {
+    const root = this.querySelector('[data-nav-list]');
+    const input = this.querySelector('input[data-nav-order]');
+    if (!root || !input) return;
+
+    input.value = JSON.stringify(this.#serialize(root));
+    input.dispatchEvent(new Event('input', { bubbles: true }));
+  }
+}
+
+customElements.define('nav-editor', NavEditor);
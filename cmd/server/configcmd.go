@@ -0,0 +1,50 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/gracchi-stdio/goaat/internal/config"
+)
+
+// runConfig prints the effective configuration and any problems with it.
+// Usage: server config print [--redacted]
+func runConfig(cfg *config.Config, loadErr error, args []string) int {
+	if len(args) == 0 || args[0] != "print" {
+		fmt.Fprintln(os.Stderr, "usage: server config print [--redacted]")
+		return 2
+	}
+	fs := flag.NewFlagSet("config print", flag.ContinueOnError)
+	redact := fs.Bool("redacted", false, "replace secrets with a placeholder")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	if cfg.File != "" {
+		fmt.Printf("# Read from %s and the environment\n", cfg.File)
+	}
+	if err := cfg.Print(os.Stdout, *redact); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to print configuration: %v\n", err)
+		return 1
+	}
+	if loadErr != nil {
+		printConfigErrors(loadErr)
+		return 1
+	}
+	return 0
+}
+
+// printConfigErrors lists every configuration problem on its own line.
+func printConfigErrors(err error) {
+	fmt.Fprintln(os.Stderr, "invalid configuration:")
+	var joined interface{ Unwrap() []error }
+	if errors.As(err, &joined) {
+		for _, e := range joined.Unwrap() {
+			fmt.Fprintf(os.Stderr, "  - %v\n", e)
+		}
+		return
+	}
+	fmt.Fprintf(os.Stderr, "  - %v\n", err)
+}
@@ -2,50 +2,112 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
-	"github.com/gorilla/sessions"
+	"github.com/gracchi-stdio/goaat/db/migrations"
+	"github.com/gracchi-stdio/goaat/internal/apitoken"
 	"github.com/gracchi-stdio/goaat/internal/auth"
+	"github.com/gracchi-stdio/goaat/internal/author"
+	"github.com/gracchi-stdio/goaat/internal/authorsync"
+	"github.com/gracchi-stdio/goaat/internal/collab"
 	"github.com/gracchi-stdio/goaat/internal/config"
+	"github.com/gracchi-stdio/goaat/internal/files"
+	"github.com/gracchi-stdio/goaat/internal/graph"
+	"github.com/gracchi-stdio/goaat/internal/health"
 	"github.com/gracchi-stdio/goaat/internal/middleware"
+	"github.com/gracchi-stdio/goaat/internal/notification"
 	"github.com/gracchi-stdio/goaat/internal/platform/db"
 	"github.com/gracchi-stdio/goaat/internal/platform/logger"
+	"github.com/gracchi-stdio/goaat/internal/platform/metrics"
+	"github.com/gracchi-stdio/goaat/internal/platform/tracing"
+	"github.com/gracchi-stdio/goaat/internal/presence"
+	"github.com/gracchi-stdio/goaat/internal/preview"
+	"github.com/gracchi-stdio/goaat/internal/ratelimit"
+	"github.com/gracchi-stdio/goaat/internal/review"
+	"github.com/gracchi-stdio/goaat/internal/revision"
+	"github.com/gracchi-stdio/goaat/internal/search"
+	"github.com/gracchi-stdio/goaat/internal/starlight"
+	"github.com/gracchi-stdio/goaat/internal/translation"
 	"github.com/gracchi-stdio/goaat/internal/web"
+	"github.com/gracchi-stdio/goaat/internal/web/handlers"
+	"github.com/gracchi-stdio/goaat/internal/web/templates/pages"
+	"github.com/gracchi-stdio/goaat/internal/webhook"
+	"github.com/gracchi-stdio/goaat/internal/workflow"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/labstack/echo-contrib/session"
 	"github.com/labstack/echo/v4"
 	echomiddleware "github.com/labstack/echo/v4/middleware"
+	"github.com/labstack/gommon/log"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/labstack/echo/otelecho"
 )
 
 func main() {
-	// Load configuration
-	cfg := config.Load()
+	// Load configuration; config print shows it even when invalid
+	cfg, err := config.Load()
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		os.Exit(runConfig(cfg, err, os.Args[2:]))
+	}
+	if err != nil {
+		printConfigErrors(err)
+		os.Exit(1)
+	}
 
-	// Initialize Auth
-	if err := auth.Init(cfg); err != nil {
-		// Log warning but don't fail if auth is not configured in dev
-		if cfg.Environment == "production" {
-			panic(err)
+	// Subcommands
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "reindex":
+			os.Exit(runReindex(cfg, os.Args[2:]))
+		case "migrate":
+			os.Exit(runMigrate(cfg, os.Args[2:]))
+		case "openapi":
+			os.Exit(runOpenAPI(os.Args[2:]))
+		default:
+			fmt.Fprintf(os.Stderr, "unknown command %q\n", os.Args[1])
+			os.Exit(2)
 		}
 	}
 
+	// Structured logging: JSON in production, colored lines in development
+	appLogger := logger.New(cfg.Environment, os.Stdout)
+	slog.SetDefault(appLogger)
+
+	// Initialize Auth; validation already requires it in production
+	if err := auth.Init(cfg); err != nil {
+		appLogger.Warn("GitHub sign-in is disabled", "error", err)
+	}
+
+	// Tracing: exported over OTLP when an endpoint is configured
+	tracerProvider, err := tracing.New(context.Background(), cfg)
+	if err != nil {
+		appLogger.Error("Failed to set up tracing", "error", err)
+		os.Exit(1)
+	}
+
 	// Initialize Echo
 	e := echo.New()
-	e.Logger = logger.NewColorful()
-	e.Logger.SetOutput(logger.NewColorWriter(os.Stdout))
+	e.HideBanner = true
+	e.Logger = logger.NewEchoLogger(appLogger)
+	e.StdLogger = slog.NewLogLogger(appLogger.Handler(), slog.LevelError)
+	e.IPExtractor = ipExtractor(cfg.TrustedProxies)
 
 	// Middleware
-	e.Use(middleware.ColorfulLogger())
-	e.Use(echomiddleware.Recover())
+	e.Use(otelecho.Middleware(tracing.ServiceName))
+	e.Use(middleware.RequestID)
+	e.Use(middleware.RequestLogger(appLogger))
+	e.Use(metrics.HTTP())
+	e.Use(echomiddleware.RecoverWithConfig(echomiddleware.RecoverConfig{LogLevel: log.ERROR}))
+	e.Use(middleware.Security(securityConfig(cfg))...)
 	e.Use(echomiddleware.Static("public"))
 
 	// Session Middleware
 	if cfg.SessionSecret != "" {
-		e.Use(session.Middleware(sessions.NewCookieStore([]byte(cfg.SessionSecret))))
+		e.Use(session.Middleware(auth.NewCookieStore(cfg.SessionSecret, cfg.UsesHTTPS())))
 	} else {
 		e.Logger.Warn("SESSION_SECRET not set, session middleware disabled")
 	}
@@ -53,16 +115,94 @@ func main() {
 	// Database connection
 	pool, queries := initDatabase(e, cfg.DatabaseURL)
 
+	// A schema this binary was not built for is fatal in production; in
+	// development the server starts and /readyz reports it
+	if pool != nil {
+		if err := checkSchema(pool, cfg.AutoMigrate); err != nil {
+			if cfg.IsProduction() {
+				e.Logger.Fatal("Refusing to start: ", err)
+			}
+			e.Logger.Warn("Database schema check failed:", err)
+		}
+	}
+
 	// Initialize Auth Service
 	authService := auth.NewService()
 
+	// Initialize content services
+	services := handlers.Services{Auth: authService}
+	var presenceHub *presence.Hub
+	var scheduler *workflow.Scheduler
+	var dispatcher *webhook.Dispatcher
+	if queries != nil {
+		services.Authors = author.NewService(queries)
+		services.AuthorSync = authorsync.NewService(queries, services.Authors, cfg.ReposDir)
+		services.Search = search.NewService(queries)
+		services.Revisions = revision.NewService(queries, cfg.ReposDir, services.Search)
+		presenceHub = presence.NewHub(pool, e.Logger)
+		services.Presence = presence.NewService(queries, presenceHub)
+		services.Collab = collab.NewService(queries, cfg.ReposDir, services.Search)
+		services.Notifications = notification.NewService(queries)
+		services.Review = review.NewService(queries, cfg.ReposDir, services.Notifications, pages.ReviewURL)
+		services.Workflow = workflow.NewService(queries, pool, cfg.ReposDir)
+		services.Tokens = apitoken.NewService(queries)
+		services.Graph = graph.NewService(queries, cfg.ReposDir)
+		services.Webhooks = webhook.NewService(queries)
+		scheduler = workflow.NewScheduler(pool, services.Workflow, services.Notifications, pages.ChangeSetURL, services.Webhooks.Published, e.Logger)
+		dispatcher = webhook.NewDispatcher(pool, e.Logger)
+		if cfg.PreviewDir != "" {
+			services.Previews = preview.NewService(cfg.PreviewDir, cfg.ReposDir, preview.Options{
+				Timeout:    cfg.PreviewTimeout,
+				CPUSeconds: cfg.PreviewCPUSeconds,
+			})
+		}
+	}
+	services.Files = files.NewService(cfg.ReposDir, services.Search)
+	services.Translations = translation.NewService(cfg.ReposDir, services.Files)
+	services.Starlight = starlight.NewService(cfg.ReposDir)
+	services.Readiness = readinessChecks(cfg, pool, scheduler, dispatcher, presenceHub)
+
 	// Routes
-	web.RegisterRoutes(e, queries, authService)
+	if err := web.RegisterRoutes(e, queries, services, rateLimitStore(e, cfg, queries)); err != nil {
+		e.Logger.Fatal("Refusing to start: ", err)
+	}
 
 	// Graceful shutdown
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
+	// File events from every replica, via Postgres LISTEN/NOTIFY
+	if presenceHub != nil {
+		go presenceHub.Listen(ctx)
+	}
+
+	// Scheduled publishes, run by one replica at a time
+	if scheduler != nil {
+		go scheduler.Run(ctx)
+	}
+
+	// Webhook deliveries, sent by one replica at a time
+	if dispatcher != nil {
+		go dispatcher.Run(ctx)
+	}
+
+	// Metrics are served on their own listener, kept off the public port
+	var metricsServer *http.Server
+	if cfg.MetricsAddr != "" {
+		metricsServer = &http.Server{
+			Addr:              cfg.MetricsAddr,
+			Handler:           metricsMux(),
+			ReadHeaderTimeout: 5 * time.Second,
+			ErrorLog:          e.StdLogger,
+		}
+		go func() {
+			e.Logger.Infof("Serving metrics on %s", cfg.MetricsAddr)
+			if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				e.Logger.Errorf("Metrics server failed: %v", err)
+			}
+		}()
+	}
+
 	// Start server
 	go func() {
 		if err := e.Start(":" + cfg.Port); err != nil && err != http.ErrServerClosed {
@@ -81,6 +221,9 @@ func main() {
 	if err := e.Shutdown(shutdownCtx); err != nil {
 		e.Logger.Fatal(err)
 	}
+	if metricsServer != nil {
+		metricsServer.Shutdown(shutdownCtx)
+	}
 
 	// Close database pool
 	if pool != nil {
@@ -88,6 +231,11 @@ func main() {
 		e.Logger.Info("Database connection closed")
 	}
 
+	// Flush pending spans
+	if err := tracerProvider.Shutdown(shutdownCtx); err != nil {
+		e.Logger.Warn("Failed to flush traces:", err)
+	}
+
 	e.Logger.Info("Server stopped")
 }
 
@@ -101,7 +249,14 @@ func initDatabase(e *echo.Echo, dbURL string) (*pgxpool.Pool, *db.Queries) {
 	defer cancel()
 
 	e.Logger.Info("Connecting to database...")
-	pool, err := pgxpool.New(ctx, dbURL)
+	poolConfig, err := pgxpool.ParseConfig(dbURL)
+	if err != nil {
+		e.Logger.Warn("Invalid DATABASE_URL:", err)
+		return nil, nil
+	}
+	poolConfig.ConnConfig.Tracer = tracing.QueryTracer{}
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
 		e.Logger.Warn("Database connection failed:", err)
 		e.Logger.Info("Starting server without database connection")
@@ -114,6 +269,75 @@ func initDatabase(e *echo.Echo, dbURL string) (*pgxpool.Pool, *db.Queries) {
 		return nil, nil
 	}
 
+	if err := metrics.RegisterPool(pool); err != nil {
+		e.Logger.Warn("Failed to export database pool metrics:", err)
+	}
+
 	e.Logger.Info("Successfully connected to database")
 	return pool, db.New(pool)
 }
+
+// ipExtractor reads the client address from X-Forwarded-For only when the
+// request came through one of the trusted proxies; otherwise any client
+// could claim any address and escape its rate limits.
+func ipExtractor(proxies config.Networks) echo.IPExtractor {
+	if len(proxies) == 0 {
+		return echo.ExtractIPDirect()
+	}
+	options := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+	for _, network := range proxies {
+		options = append(options, echo.TrustIPRange(network))
+	}
+	return echo.ExtractIPFromXFFHeader(options...)
+}
+
+// rateLimitStore keeps rate limits in Postgres when configured to share
+// them between replicas, falling back to memory without a database.
+func rateLimitStore(e *echo.Echo, cfg *config.Config, queries *db.Queries) ratelimit.Store {
+	if cfg.RateLimitStore == config.RateLimitPostgres {
+		if queries != nil {
+			return ratelimit.NewPostgresStore(queries)
+		}
+		e.Logger.Warn("Database unavailable, keeping rate limits in memory")
+	}
+	return ratelimit.NewMemoryStore()
+}
+
+// securityConfig allows the Vite dev server in development, where the
+// layout loads assets from it.
+func securityConfig(cfg *config.Config) middleware.SecurityConfig {
+	security := middleware.SecurityConfig{HTTPS: cfg.UsesHTTPS()}
+	if cfg.Environment == config.Development {
+		security.DevServer = cfg.DevServer
+	}
+	return security
+}
+
+// readinessChecks lists what this replica needs to serve traffic.
+func readinessChecks(cfg *config.Config, pool *pgxpool.Pool, scheduler *workflow.Scheduler, dispatcher *webhook.Dispatcher, hub *presence.Hub) *health.Checker {
+	checks := &health.Checker{}
+	checks.Add("database", health.Database(pool))
+	checks.Add("migrations", health.Migrations(pool, migrations.Latest()))
+	checks.Add("storage", health.Storage(cfg.ReposDir, uint64(cfg.MinFreeSpace)))
+	if scheduler != nil {
+		checks.Add("scheduler", health.Worker(scheduler.Healthy))
+	}
+	if dispatcher != nil {
+		checks.Add("webhooks", health.Worker(dispatcher.Healthy))
+	}
+	if hub != nil {
+		checks.Add("file_events", health.Worker(hub.Healthy))
+	}
+	return checks
+}
+
+// metricsMux serves the admin listener's endpoints.
+func metricsMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", metrics.Handler())
+	return mux
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/gracchi-stdio/goaat/db/migrations"
+	"github.com/gracchi-stdio/goaat/internal/config"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// migrateTimeout bounds applying migrations at startup, including waiting
+// for another replica that holds the migration lock.
+const migrateTimeout = 5 * time.Minute
+
+// runMigrate applies or reverts the embedded migrations, or shows where the
+// schema stands. Usage: server migrate up|down|status
+func runMigrate(cfg *config.Config, args []string) int {
+	if len(args) != 1 || (args[0] != "up" && args[0] != "down" && args[0] != "status") {
+		fmt.Fprintln(os.Stderr, "usage: server migrate up|down|status")
+		return 2
+	}
+
+	if cfg.DatabaseURL == "" {
+		fmt.Fprintln(os.Stderr, "DATABASE_URL must be set")
+		return 1
+	}
+
+	ctx := context.Background()
+	conn, err := pgx.Connect(ctx, cfg.DatabaseURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to connect to database: %v\n", err)
+		return 1
+	}
+	defer conn.Close(ctx)
+
+	m, err := migrations.New(ctx, conn)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	m.OnStart = func(_ int32, name, direction, _ string) {
+		fmt.Printf("%s %s\n", direction, name)
+	}
+
+	switch args[0] {
+	case "up":
+		err = m.Up(ctx)
+	case "down":
+		err = m.Down(ctx)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "migration failed: %v\n", err)
+		return 1
+	}
+
+	current, err := m.GetCurrentVersion(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read schema version: %v\n", err)
+		return 1
+	}
+	fmt.Printf("schema version %d of %d\n", current, migrations.Latest())
+	if args[0] == "status" {
+		for _, migration := range m.Migrations {
+			applied := " "
+			if migration.Sequence <= current {
+				applied = "x"
+			}
+			fmt.Printf("[%s] %s\n", applied, migration.Name)
+		}
+	}
+	return 0
+}
+
+// checkSchema applies pending migrations when auto-migrate is on, then
+// compares the schema with the version this binary expects.
+func checkSchema(pool *pgxpool.Pool, autoMigrate bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), migrateTimeout)
+	defer cancel()
+
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	m, err := migrations.New(ctx, conn.Conn())
+	if err != nil {
+		return err
+	}
+	if autoMigrate {
+		if err := m.Up(ctx); err != nil {
+			return fmt.Errorf("failed to apply migrations: %w", err)
+		}
+	}
+	return m.Check(ctx)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/gracchi-stdio/goaat/internal/ratelimit"
+	"github.com/gracchi-stdio/goaat/internal/web"
+	"github.com/gracchi-stdio/goaat/internal/web/handlers"
+	"github.com/labstack/echo/v4"
+)
+
+// runOpenAPI prints the API's OpenAPI document, failing if it does not
+// describe exactly the registered API routes; -check only runs the check,
+// which the web package's tests also run.
+// Usage: server openapi [-check]
+func runOpenAPI(args []string) int {
+	fs := flag.NewFlagSet("openapi", flag.ContinueOnError)
+	check := fs.Bool("check", false, "only check that every API route is described")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	// Routes are registered without services; nothing is served
+	e := echo.New()
+	if err := web.RegisterRoutes(e, nil, handlers.Services{}, ratelimit.NewMemoryStore()); err != nil {
+		fmt.Fprintf(os.Stderr, "the API specification does not match the routes:\n%v\n", err)
+		return 1
+	}
+	if *check {
+		return 0
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(web.APISpec().Document()); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write the document: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gracchi-stdio/goaat/internal/config"
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+	"github.com/gracchi-stdio/goaat/internal/repository"
+	"github.com/gracchi-stdio/goaat/internal/search"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// runReindex rebuilds the full-text search index from the repository clones
+// on disk. Usage: server reindex [-repo ID]
+func runReindex(cfg *config.Config, args []string) int {
+	fs := flag.NewFlagSet("reindex", flag.ContinueOnError)
+	repoID := fs.Int64("repo", 0, "only rebuild the index for this repository id")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if cfg.DatabaseURL == "" {
+		fmt.Fprintln(os.Stderr, "DATABASE_URL must be set")
+		return 1
+	}
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, cfg.DatabaseURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to connect to database: %v\n", err)
+		return 1
+	}
+	defer pool.Close()
+
+	queries := db.New(pool)
+	searchService := search.NewService(queries)
+
+	var repos []db.Repository
+	if *repoID != 0 {
+		repo, err := queries.GetRepository(ctx, *repoID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to load repository %d: %v\n", *repoID, err)
+			return 1
+		}
+		repos = append(repos, repo)
+	} else {
+		repos, err = queries.ListRepositories(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to list repositories: %v\n", err)
+			return 1
+		}
+	}
+
+	failed := 0
+	for _, repo := range repos {
+		dir := filepath.Join(repository.Dir(cfg.ReposDir, repo.ID), repo.ContentPath)
+		count, err := searchService.Rebuild(ctx, repo, dir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", repo.FullName, err)
+			failed++
+			continue
+		}
+		fmt.Printf("%s: indexed %d documents\n", repo.FullName, count)
+	}
+
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
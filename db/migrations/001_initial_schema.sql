@@ -11,3 +11,7 @@ CREATE TABLE authors (
 );
 
 CREATE INDEX idx_authors_email ON authors(email);
+
+---- create above / drop below ----
+
+DROP TABLE authors;
@@ -14,3 +14,7 @@ CREATE TABLE users (
 
 CREATE INDEX idx_users_github_id ON users(github_id);
 CREATE INDEX idx_users_email ON users(email);
+
+---- create above / drop below ----
+
+DROP TABLE users;
@@ -0,0 +1,21 @@
+-- Migration: Create repositories table
+-- Created: 2026-10-19
+-- Description: Track Starlight repositories cloned under REPOS_DIR/{id}
+
+CREATE TABLE repositories (
+    id BIGSERIAL PRIMARY KEY,
+    owner_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    full_name TEXT NOT NULL,
+    default_branch TEXT NOT NULL DEFAULT 'main',
+    content_path TEXT NOT NULL DEFAULT 'src/content/docs',
+    search_language TEXT NOT NULL DEFAULT 'english',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (owner_id, full_name)
+);
+
+CREATE INDEX idx_repositories_owner_id ON repositories(owner_id);
+
+---- create above / drop below ----
+
+DROP TABLE repositories;
@@ -0,0 +1,26 @@
+-- Migration: Create search documents table
+-- Created: 2026-10-19
+-- Description: Full-text index of repository content (title, description, headings, body)
+
+CREATE TABLE search_documents (
+    id BIGSERIAL PRIMARY KEY,
+    repository_id BIGINT NOT NULL REFERENCES repositories(id) ON DELETE CASCADE,
+    path TEXT NOT NULL,
+    language TEXT NOT NULL DEFAULT 'english',
+    title TEXT NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    headings TEXT NOT NULL DEFAULT '',
+    body TEXT NOT NULL DEFAULT '',
+    -- Weighted on write because the text search config is stored per row
+    -- and to_tsvector(text::regconfig, ...) is not allowed in a generated column.
+    search_vector TSVECTOR NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (repository_id, path)
+);
+
+CREATE INDEX idx_search_documents_repository_id ON search_documents(repository_id);
+CREATE INDEX idx_search_documents_search_vector ON search_documents USING GIN (search_vector);
+
+---- create above / drop below ----
+
+DROP TABLE search_documents;
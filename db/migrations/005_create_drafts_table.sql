@@ -0,0 +1,23 @@
+-- Migration: Create drafts table
+-- Created: 2026-10-19
+-- Description: Autosaved in-progress edits per user, repository, file and base version
+
+CREATE TABLE drafts (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    repository_id BIGINT NOT NULL REFERENCES repositories(id) ON DELETE CASCADE,
+    path TEXT NOT NULL,
+    -- Hash of the file the draft was started from; a saved file gets a new
+    -- hash, so older drafts stay behind as revision history.
+    base_hash TEXT NOT NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, repository_id, path, base_hash)
+);
+
+CREATE INDEX idx_drafts_repository_path ON drafts(repository_id, path);
+
+---- create above / drop below ----
+
+DROP TABLE drafts;
@@ -0,0 +1,28 @@
+-- Migration: Create presence tables
+-- Created: 2026-10-19
+-- Description: Who has which file open (heartbeats) and advisory soft locks on files
+
+CREATE TABLE file_presence (
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    repository_id BIGINT NOT NULL REFERENCES repositories(id) ON DELETE CASCADE,
+    path TEXT NOT NULL,
+    last_seen_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, repository_id, path)
+);
+
+CREATE INDEX idx_file_presence_repository_id ON file_presence(repository_id, last_seen_at);
+
+-- Soft locks are advisory: they are shown to other editors but never block a save.
+CREATE TABLE file_locks (
+    repository_id BIGINT NOT NULL REFERENCES repositories(id) ON DELETE CASCADE,
+    path TEXT NOT NULL,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    acquired_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (repository_id, path)
+);
+
+---- create above / drop below ----
+
+DROP TABLE file_locks;
+DROP TABLE file_presence;
@@ -0,0 +1,35 @@
+-- Migration: Create collaborative editing tables
+-- Created: 2026-10-19
+-- Description: Co-editing sessions per file version and their ordered operation logs
+
+CREATE TABLE collab_documents (
+    id BIGSERIAL PRIMARY KEY,
+    repository_id BIGINT NOT NULL REFERENCES repositories(id) ON DELETE CASCADE,
+    path TEXT NOT NULL,
+    -- The file the session started from; replaying the op log over it
+    -- rebuilds the document.
+    base_hash TEXT NOT NULL,
+    base_body TEXT NOT NULL,
+    -- Hash of the file as last written by this session. Opening a file joins
+    -- the session whose file_hash matches it.
+    file_hash TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (repository_id, path, file_hash)
+);
+
+CREATE TABLE collab_ops (
+    document_id BIGINT NOT NULL REFERENCES collab_documents(id) ON DELETE CASCADE,
+    -- Gapless per document; the primary key makes concurrent appends from
+    -- several replicas fail instead of interleaving.
+    seq BIGINT NOT NULL,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    op JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (document_id, seq)
+);
+
+---- create above / drop below ----
+
+DROP TABLE collab_ops;
+DROP TABLE collab_documents;
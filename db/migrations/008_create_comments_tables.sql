@@ -0,0 +1,49 @@
+-- Migration: Create review comments tables
+-- Created: 2026-10-19
+-- Description: Comment threads anchored to line ranges or frontmatter fields of content files
+
+CREATE TABLE comment_threads (
+    id BIGSERIAL PRIMARY KEY,
+    repository_id BIGINT NOT NULL REFERENCES repositories(id) ON DELETE CASCADE,
+    path TEXT NOT NULL,
+    line_start INTEGER NOT NULL,
+    line_end INTEGER NOT NULL,
+    -- Frontmatter key when the thread is about a field rather than lines
+    field TEXT NOT NULL DEFAULT '',
+    -- The anchored lines and the file version they were taken from; when the
+    -- file changes the thread is moved to wherever the lines went.
+    anchor_text TEXT NOT NULL,
+    base_hash TEXT NOT NULL,
+    outdated BOOLEAN NOT NULL DEFAULT FALSE,
+    created_by BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    resolved_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    resolved_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_comment_threads_repository_path ON comment_threads(repository_id, path);
+
+CREATE TABLE comments (
+    id BIGSERIAL PRIMARY KEY,
+    thread_id BIGINT NOT NULL REFERENCES comment_threads(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_comments_thread_id ON comments(thread_id);
+
+CREATE TABLE comment_mentions (
+    comment_id BIGINT NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    PRIMARY KEY (comment_id, user_id)
+);
+
+CREATE INDEX idx_comment_mentions_user_id ON comment_mentions(user_id);
+
+---- create above / drop below ----
+
+DROP TABLE comment_mentions;
+DROP TABLE comments;
+DROP TABLE comment_threads;
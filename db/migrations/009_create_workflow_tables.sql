@@ -0,0 +1,71 @@
+-- Migration: Create editorial workflow tables
+-- Created: 2026-10-19
+-- Description: Repository roles, approval settings and change sets moving through draft, in review, approved and published
+
+CREATE TABLE repository_members (
+    repository_id BIGINT NOT NULL REFERENCES repositories(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- The owner is not listed here; it implicitly holds every role
+    role TEXT NOT NULL CHECK (role IN ('editor', 'reviewer')),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (repository_id, user_id)
+);
+
+CREATE INDEX idx_repository_members_user_id ON repository_members(user_id);
+
+CREATE TABLE workflow_settings (
+    repository_id BIGINT PRIMARY KEY REFERENCES repositories(id) ON DELETE CASCADE,
+    required_approvals INTEGER NOT NULL DEFAULT 1 CHECK (required_approvals >= 0),
+    approver_role TEXT NOT NULL DEFAULT 'reviewer' CHECK (approver_role IN ('editor', 'reviewer')),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE change_sets (
+    id BIGSERIAL PRIMARY KEY,
+    repository_id BIGINT NOT NULL REFERENCES repositories(id) ON DELETE CASCADE,
+    title TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    author_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    state TEXT NOT NULL DEFAULT 'draft' CHECK (state IN ('draft', 'in_review', 'approved', 'published')),
+    -- The commit a published change set was written to
+    published_commit TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_change_sets_repository_state ON change_sets(repository_id, state);
+
+CREATE TABLE change_set_files (
+    change_set_id BIGINT NOT NULL REFERENCES change_sets(id) ON DELETE CASCADE,
+    path TEXT NOT NULL,
+    PRIMARY KEY (change_set_id, path)
+);
+
+CREATE TABLE change_set_approvals (
+    change_set_id BIGINT NOT NULL REFERENCES change_sets(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (change_set_id, user_id)
+);
+
+CREATE TABLE change_set_transitions (
+    id BIGSERIAL PRIMARY KEY,
+    change_set_id BIGINT NOT NULL REFERENCES change_sets(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    action TEXT NOT NULL,
+    from_state TEXT NOT NULL,
+    to_state TEXT NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_change_set_transitions_change_set_id ON change_set_transitions(change_set_id);
+
+---- create above / drop below ----
+
+DROP TABLE change_set_transitions;
+DROP TABLE change_set_approvals;
+DROP TABLE change_set_files;
+DROP TABLE change_sets;
+DROP TABLE workflow_settings;
+DROP TABLE repository_members;
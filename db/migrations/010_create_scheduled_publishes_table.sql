@@ -0,0 +1,39 @@
+-- Migration: Create scheduled publishes and notifications tables
+-- Created: 2026-10-19
+-- Description: Change sets scheduled to publish at a future time, and in-app notifications of the outcome
+
+CREATE TABLE scheduled_publishes (
+    id BIGSERIAL PRIMARY KEY,
+    change_set_id BIGINT NOT NULL UNIQUE REFERENCES change_sets(id) ON DELETE CASCADE,
+    repository_id BIGINT NOT NULL REFERENCES repositories(id) ON DELETE CASCADE,
+    scheduled_by BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    publish_at TIMESTAMPTZ NOT NULL,
+    -- IANA zone the time was entered in, for display
+    timezone TEXT NOT NULL,
+    -- Published schedules are deleted; failed ones stay until rescheduled
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_scheduled_publishes_due ON scheduled_publishes(next_attempt_at) WHERE status = 'pending';
+
+CREATE TABLE notifications (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    level TEXT NOT NULL DEFAULT 'primary',
+    message TEXT NOT NULL,
+    link TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    read_at TIMESTAMP
+);
+
+CREATE INDEX idx_notifications_unread ON notifications(user_id) WHERE read_at IS NULL;
+
+---- create above / drop below ----
+
+DROP TABLE notifications;
+DROP TABLE scheduled_publishes;
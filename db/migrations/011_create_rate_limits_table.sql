@@ -0,0 +1,16 @@
+-- Migration: Create rate limits table
+-- Created: 2026-10-19
+-- Description: Token buckets shared by every replica when rate limits are kept in Postgres
+
+CREATE UNLOGGED TABLE rate_limits (
+    -- Budget name and the IP or user it applies to, e.g. "auth:ip:192.0.2.1"
+    key TEXT PRIMARY KEY,
+    tokens DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_rate_limits_updated_at ON rate_limits(updated_at);
+
+---- create above / drop below ----
+
+DROP TABLE rate_limits;
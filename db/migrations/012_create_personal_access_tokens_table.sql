@@ -0,0 +1,24 @@
+-- Migration: Create personal access tokens table
+-- Created: 2026-10-19
+-- Description: Tokens authenticating scripts against the /api/v1 API on a user's behalf
+
+CREATE TABLE personal_access_tokens (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    -- SHA-256 of the token, which is only shown when it is created
+    token_hash TEXT NOT NULL UNIQUE,
+    -- The one repository the token can reach, or NULL for all of the user's
+    repository_id BIGINT REFERENCES repositories(id) ON DELETE CASCADE,
+    permission TEXT NOT NULL CHECK (permission IN ('read', 'write', 'publish')),
+    expires_at TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_personal_access_tokens_user_id ON personal_access_tokens(user_id) WHERE revoked_at IS NULL;
+
+---- create above / drop below ----
+
+DROP TABLE personal_access_tokens;
@@ -0,0 +1,14 @@
+-- Migration: Link authors to users
+-- Created: 2026-10-19
+-- Description: Let an author be a signed-in user, and page authors by name
+
+ALTER TABLE authors
+    ADD COLUMN user_id BIGINT UNIQUE REFERENCES users(id) ON DELETE SET NULL;
+
+-- Keyset pagination orders by (name, id)
+CREATE INDEX idx_authors_name_id ON authors(name, id);
+
+---- create above / drop below ----
+
+DROP INDEX idx_authors_name_id;
+ALTER TABLE authors DROP COLUMN user_id;
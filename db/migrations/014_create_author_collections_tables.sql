@@ -0,0 +1,31 @@
+-- Migration: Create author collections tables
+-- Created: 2026-10-19
+-- Description: Sync the authors table with a Starlight authors content collection
+
+-- A repository whose authors collection mirrors the authors table
+CREATE TABLE author_collections (
+    repository_id BIGINT PRIMARY KEY REFERENCES repositories(id) ON DELETE CASCADE,
+    -- Directory of the collection's YAML files, relative to the repository root
+    path TEXT NOT NULL,
+    -- Frontmatter field whose values reference collection entries
+    frontmatter_key TEXT NOT NULL,
+    synced_at TIMESTAMPTZ,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Each collection file as of the last sync, the base both sides are
+-- compared against to tell which one changed
+CREATE TABLE author_collection_entries (
+    repository_id BIGINT NOT NULL REFERENCES author_collections(repository_id) ON DELETE CASCADE,
+    slug TEXT NOT NULL,
+    -- NULL once the author is deleted
+    author_id BIGINT REFERENCES authors(id) ON DELETE SET NULL,
+    file_hash TEXT NOT NULL,
+    author_updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (repository_id, slug)
+);
+
+---- create above / drop below ----
+
+DROP TABLE author_collection_entries;
+DROP TABLE author_collections;
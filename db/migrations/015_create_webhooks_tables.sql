@@ -0,0 +1,42 @@
+-- Migration: Create webhooks tables
+-- Created: 2026-10-19
+-- Description: Outbound webhooks per repository and the log of their deliveries
+
+CREATE TABLE webhook_endpoints (
+    id BIGSERIAL PRIMARY KEY,
+    repository_id BIGINT NOT NULL REFERENCES repositories(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    -- Signs each delivery; the receiver checks the signature with it
+    secret TEXT NOT NULL,
+    -- Event types the endpoint subscribes to, such as page.saved
+    events TEXT[] NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_webhook_endpoints_repository_id ON webhook_endpoints(repository_id);
+
+-- One event sent to one endpoint, retried until it is delivered or given up on
+CREATE TABLE webhook_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    endpoint_id BIGINT NOT NULL REFERENCES webhook_endpoints(id) ON DELETE CASCADE,
+    event TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    -- HTTP status of the last attempt; 0 if it got no response
+    response_status INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    delivered_at TIMESTAMPTZ,
+    -- The delivery this one sends again, for manual redeliveries
+    redelivery_of BIGINT REFERENCES webhook_deliveries(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_webhook_deliveries_endpoint_id ON webhook_deliveries(endpoint_id, created_at DESC);
+
+---- create above / drop below ----
+
+DROP TABLE webhook_deliveries;
+DROP TABLE webhook_endpoints;
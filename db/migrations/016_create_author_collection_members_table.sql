@@ -0,0 +1,23 @@
+-- Migration: Create author collection members table
+-- Created: 2026-10-19
+-- Description: Keep the authors each repository's collection lists, so syncs
+-- only write and remove that repository's authors
+
+-- An author the repository's collection lists, with or without a file yet
+CREATE TABLE author_collection_members (
+    repository_id BIGINT NOT NULL REFERENCES author_collections(repository_id) ON DELETE CASCADE,
+    author_id BIGINT NOT NULL REFERENCES authors(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (repository_id, author_id)
+);
+
+CREATE INDEX idx_author_collection_members_author_id ON author_collection_members(author_id);
+
+-- Authors already synced to a file are members of that collection
+INSERT INTO author_collection_members (repository_id, author_id)
+SELECT DISTINCT repository_id, author_id FROM author_collection_entries
+WHERE author_id IS NOT NULL;
+
+---- create above / drop below ----
+
+DROP TABLE author_collection_members;
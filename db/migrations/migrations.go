@@ -0,0 +1,103 @@
+// Package migrations embeds the database migrations and applies them with
+// tern, so a binary knows and can reach the schema version it was built for.
+package migrations
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/tern/v2/migrate"
+)
+
+// VersionTable is where tern records the schema version, as set in tern.conf.
+const VersionTable = "schema_version"
+
+var (
+	// ErrSchemaOutdated is returned when migrations are pending.
+	ErrSchemaOutdated = errors.New("database schema is older than this binary")
+
+	// ErrSchemaNewer is returned when the database was migrated by a newer
+	// binary, whose schema this one may not understand.
+	ErrSchemaNewer = errors.New("database schema is newer than this binary")
+)
+
+// FS holds the migration files, named like tern's "001_description.sql".
+//
+//go:embed *.sql
+var FS embed.FS
+
+var filename = regexp.MustCompile(`^(\d+)_.+\.sql$`)
+
+// Latest returns the version of the newest migration: the schema version
+// this binary expects.
+func Latest() int32 {
+	entries, _ := fs.ReadDir(FS, ".")
+	var latest int32
+	for _, entry := range entries {
+		m := filename.FindStringSubmatch(entry.Name())
+		if m == nil {
+			continue
+		}
+		if v, err := strconv.ParseInt(m[1], 10, 32); err == nil && int32(v) > latest {
+			latest = int32(v)
+		}
+	}
+	return latest
+}
+
+// Migrator is a tern migrator loaded with the embedded migrations. It takes
+// the same advisory lock as the tern CLI while migrating, so replicas
+// starting together apply each migration once.
+type Migrator struct {
+	*migrate.Migrator
+}
+
+// New creates a migrator on conn, creating the version table if needed.
+func New(ctx context.Context, conn *pgx.Conn) (*Migrator, error) {
+	m, err := migrate.NewMigrator(ctx, conn, VersionTable)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migrator: %w", err)
+	}
+	if err := m.LoadMigrations(FS); err != nil {
+		return nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+	return &Migrator{m}, nil
+}
+
+// Up applies every pending migration.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.MigrateTo(ctx, Latest())
+}
+
+// Down reverts the newest applied migration.
+func (m *Migrator) Down(ctx context.Context) error {
+	current, err := m.GetCurrentVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if current == 0 {
+		return errors.New("no migrations to revert")
+	}
+	return m.MigrateTo(ctx, current-1)
+}
+
+// Check compares the schema version with the one this binary expects.
+func (m *Migrator) Check(ctx context.Context) error {
+	current, err := m.GetCurrentVersion(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+	switch expected := Latest(); {
+	case current < expected:
+		return fmt.Errorf("%w: at version %d, expected %d", ErrSchemaOutdated, current, expected)
+	case current > expected:
+		return fmt.Errorf("%w: at version %d, expected %d", ErrSchemaNewer, current, expected)
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+// Every migration can be reverted, so `server migrate down` works from any
+// version.
+func TestMigrationsHaveDownSections(t *testing.T) {
+	entries, err := fs.ReadDir(FS, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		src, err := fs.ReadFile(FS, entry.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, down, ok := strings.Cut(string(src), "---- create above / drop below ----")
+		if !ok || strings.TrimSpace(down) == "" {
+			t.Errorf("%s has no down section", entry.Name())
+		}
+	}
+}
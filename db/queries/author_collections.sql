@@ -0,0 +1,50 @@
+-- name: GetAuthorCollection :one
+SELECT * FROM author_collections
+WHERE repository_id = $1;
+
+-- name: UpsertAuthorCollection :one
+INSERT INTO author_collections (repository_id, path, frontmatter_key)
+VALUES ($1, $2, $3)
+ON CONFLICT (repository_id) DO UPDATE
+SET path = EXCLUDED.path, frontmatter_key = EXCLUDED.frontmatter_key
+RETURNING *;
+
+-- name: DeleteAuthorCollection :exec
+DELETE FROM author_collections
+WHERE repository_id = $1;
+
+-- name: MarkAuthorCollectionSynced :exec
+UPDATE author_collections
+SET synced_at = NOW()
+WHERE repository_id = $1;
+
+-- name: ListAuthorCollectionEntries :many
+SELECT * FROM author_collection_entries
+WHERE repository_id = $1
+ORDER BY slug;
+
+-- name: UpsertAuthorCollectionEntry :exec
+INSERT INTO author_collection_entries (repository_id, slug, author_id, file_hash, author_updated_at)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (repository_id, slug) DO UPDATE
+SET author_id = EXCLUDED.author_id,
+    file_hash = EXCLUDED.file_hash,
+    author_updated_at = EXCLUDED.author_updated_at;
+
+-- name: DeleteAuthorCollectionEntry :exec
+DELETE FROM author_collection_entries
+WHERE repository_id = $1 AND slug = $2;
+
+-- name: ListAuthorCollectionMembers :many
+SELECT author_id FROM author_collection_members
+WHERE repository_id = $1
+ORDER BY author_id;
+
+-- name: AddAuthorCollectionMember :exec
+INSERT INTO author_collection_members (repository_id, author_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteAuthorCollectionMember :exec
+DELETE FROM author_collection_members
+WHERE repository_id = $1 AND author_id = $2;
@@ -5,17 +5,24 @@ SELECT * FROM authors WHERE id = $1 LIMIT 1;
 SELECT * FROM authors WHERE email = $1 LIMIT 1;
 
 -- name: ListAuthors :many
-SELECT * FROM authors ORDER BY name;
+-- A page of authors whose names contain search, ordered by name after the
+-- (name, id) keyset cursor; the first page starts after an empty name.
+SELECT * FROM authors
+WHERE name ILIKE '%' || sqlc.arg(search)::text || '%'
+  AND (name, id) > (sqlc.arg(after_name)::text, sqlc.arg(after_id)::bigint)
+ORDER BY name, id
+LIMIT sqlc.arg(page_size);
 
 -- name: CreateAuthor :one
-INSERT INTO authors (name, email) 
-VALUES ($1, $2) 
+INSERT INTO authors (name, email, user_id) 
+VALUES ($1, $2, $3) 
 RETURNING *;
 
--- name: UpdateAuthor :exec
+-- name: UpdateAuthor :one
 UPDATE authors 
-SET name = $2, email = $3, updated_at = NOW() 
-WHERE id = $1;
+SET name = $2, email = $3, user_id = $4, updated_at = NOW() 
+WHERE id = $1
+RETURNING *;
 
--- name: DeleteAuthor :exec
+-- name: DeleteAuthor :execrows
 DELETE FROM authors WHERE id = $1;
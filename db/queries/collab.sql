@@ -0,0 +1,39 @@
+-- name: OpenCollabDocument :one
+INSERT INTO collab_documents (
+    repository_id,
+    path,
+    base_hash,
+    base_body,
+    file_hash
+) VALUES (
+    @repository_id, @path, @file_hash, @base_body, @file_hash
+)
+ON CONFLICT (repository_id, path, file_hash) DO UPDATE
+SET updated_at = NOW()
+RETURNING *;
+
+-- name: GetCollabDocument :one
+SELECT * FROM collab_documents
+WHERE id = $1 AND repository_id = $2
+LIMIT 1;
+
+-- name: RetireCollabDocuments :exec
+-- Frees a file hash for the session that just wrote it; retired sessions
+-- can no longer be joined.
+UPDATE collab_documents
+SET file_hash = 'retired:' || id::text, updated_at = NOW()
+WHERE repository_id = $1 AND path = $2 AND file_hash = $3 AND id <> $4;
+
+-- name: UpdateCollabDocumentFileHash :exec
+UPDATE collab_documents
+SET file_hash = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: InsertCollabOp :exec
+INSERT INTO collab_ops (document_id, seq, user_id, op)
+VALUES ($1, $2, $3, $4);
+
+-- name: ListCollabOps :many
+SELECT seq, op FROM collab_ops
+WHERE document_id = $1 AND seq > $2
+ORDER BY seq;
@@ -0,0 +1,93 @@
+-- name: CreateCommentThread :one
+INSERT INTO comment_threads (
+    repository_id,
+    path,
+    line_start,
+    line_end,
+    field,
+    anchor_text,
+    base_hash,
+    created_by
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+)
+RETURNING *;
+
+-- name: GetCommentThread :one
+SELECT * FROM comment_threads
+WHERE id = $1 AND repository_id = $2
+LIMIT 1;
+
+-- name: ListCommentThreads :many
+SELECT
+    t.id,
+    t.path,
+    t.line_start,
+    t.line_end,
+    t.field,
+    t.anchor_text,
+    t.base_hash,
+    t.outdated,
+    t.resolved_at,
+    r.name AS resolved_by_name
+FROM comment_threads t
+LEFT JOIN users r ON r.id = t.resolved_by
+WHERE t.repository_id = $1 AND t.path = $2
+ORDER BY t.line_start, t.id;
+
+-- name: UpdateCommentThreadAnchor :exec
+UPDATE comment_threads
+SET
+    line_start = $2,
+    line_end = $3,
+    base_hash = $4,
+    outdated = $5,
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: ResolveCommentThread :exec
+UPDATE comment_threads
+SET resolved_by = $2, resolved_at = NOW(), updated_at = NOW()
+WHERE id = $1;
+
+-- name: ReopenCommentThread :exec
+UPDATE comment_threads
+SET resolved_by = NULL, resolved_at = NULL, updated_at = NOW()
+WHERE id = $1;
+
+-- name: CreateComment :one
+INSERT INTO comments (thread_id, user_id, body)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: ListCommentsByPath :many
+SELECT
+    c.id,
+    c.thread_id,
+    c.user_id,
+    u.name AS author_name,
+    u.avatar_url AS author_avatar_url,
+    c.body,
+    c.created_at
+FROM comments c
+JOIN comment_threads t ON t.id = c.thread_id
+JOIN users u ON u.id = c.user_id
+WHERE t.repository_id = $1 AND t.path = $2
+ORDER BY c.created_at, c.id;
+
+-- name: CreateCommentMention :exec
+INSERT INTO comment_mentions (comment_id, user_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: ListRepositoryParticipants :many
+-- The owner, members and everyone who has commented: the users that can be @mentioned.
+SELECT * FROM users
+WHERE id = (SELECT owner_id FROM repositories WHERE repositories.id = @repository_id)
+   OR id IN (SELECT user_id FROM repository_members WHERE repository_id = @repository_id)
+   OR id IN (
+       SELECT c.user_id FROM comments c
+       JOIN comment_threads t ON t.id = c.thread_id
+       WHERE t.repository_id = @repository_id
+   )
+ORDER BY name;
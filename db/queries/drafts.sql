@@ -0,0 +1,41 @@
+-- name: UpsertDraft :one
+INSERT INTO drafts (
+    user_id,
+    repository_id,
+    path,
+    base_hash,
+    body
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+ON CONFLICT (user_id, repository_id, path, base_hash) DO UPDATE
+SET
+    body = EXCLUDED.body,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: GetDraft :one
+SELECT * FROM drafts
+WHERE user_id = $1 AND repository_id = $2 AND path = $3 AND base_hash = $4
+LIMIT 1;
+
+-- name: GetDraftByID :one
+SELECT * FROM drafts
+WHERE id = $1 AND repository_id = $2
+LIMIT 1;
+
+-- name: ListDraftsByPath :many
+SELECT
+    d.id,
+    d.user_id,
+    u.name AS user_name,
+    d.base_hash,
+    d.updated_at
+FROM drafts d
+JOIN users u ON u.id = d.user_id
+WHERE d.repository_id = $1 AND d.path = $2
+ORDER BY d.updated_at DESC;
+
+-- name: DeleteDraft :exec
+DELETE FROM drafts
+WHERE user_id = $1 AND repository_id = $2 AND path = $3 AND base_hash = $4;
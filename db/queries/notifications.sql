@@ -0,0 +1,14 @@
+-- name: CreateNotification :exec
+INSERT INTO notifications (user_id, level, message, link)
+VALUES ($1, $2, $3, $4);
+
+-- name: ListUnreadNotifications :many
+SELECT * FROM notifications
+WHERE user_id = $1 AND read_at IS NULL
+ORDER BY created_at DESC
+LIMIT $2;
+
+-- name: MarkNotificationRead :exec
+UPDATE notifications
+SET read_at = NOW()
+WHERE id = $1 AND user_id = $2;
@@ -0,0 +1,51 @@
+-- name: UpsertFilePresence :exec
+INSERT INTO file_presence (user_id, repository_id, path)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, repository_id, path) DO UPDATE
+SET last_seen_at = NOW();
+
+-- name: DeleteFilePresence :exec
+DELETE FROM file_presence
+WHERE user_id = $1 AND repository_id = $2 AND path = $3;
+
+-- name: ListFilePresence :many
+SELECT
+    p.user_id,
+    p.path,
+    u.name,
+    u.avatar_url
+FROM file_presence p
+JOIN users u ON u.id = p.user_id
+WHERE p.repository_id = @repository_id
+  AND p.last_seen_at > NOW() - make_interval(secs => @ttl_seconds::int)
+ORDER BY p.path, u.name;
+
+-- name: AcquireFileLock :one
+INSERT INTO file_locks (repository_id, path, user_id, expires_at)
+VALUES (@repository_id, @path, @user_id, NOW() + make_interval(secs => @ttl_seconds::int))
+ON CONFLICT (repository_id, path) DO UPDATE
+SET
+    user_id = EXCLUDED.user_id,
+    acquired_at = NOW(),
+    expires_at = EXCLUDED.expires_at
+WHERE file_locks.user_id = EXCLUDED.user_id OR file_locks.expires_at < NOW()
+RETURNING *;
+
+-- name: GetFileLock :one
+SELECT
+    l.repository_id,
+    l.path,
+    l.user_id,
+    u.name AS user_name,
+    l.expires_at
+FROM file_locks l
+JOIN users u ON u.id = l.user_id
+WHERE l.repository_id = $1 AND l.path = $2 AND l.expires_at > NOW()
+LIMIT 1;
+
+-- name: ReleaseFileLock :exec
+DELETE FROM file_locks
+WHERE repository_id = $1 AND path = $2 AND user_id = $3;
+
+-- name: NotifyFileEvent :exec
+SELECT pg_notify('file_events', @payload::text);
@@ -0,0 +1,27 @@
+-- name: TakeRateLimitToken :one
+-- Refills the bucket for the time since it was last used and takes a token
+-- if one is available. available is the balance before taking: the request
+-- is allowed when it is at least 1.
+WITH bucket AS (
+    SELECT LEAST(
+        @burst::float8,
+        COALESCE(
+            (SELECT tokens + EXTRACT(EPOCH FROM NOW() - updated_at)::float8 * @rate::float8
+             FROM rate_limits WHERE key = @key FOR UPDATE),
+            @burst::float8
+        )
+    ) AS tokens
+)
+INSERT INTO rate_limits (key, tokens, updated_at)
+SELECT @key, CASE WHEN tokens >= 1 THEN tokens - 1 ELSE tokens END, NOW()
+FROM bucket
+ON CONFLICT (key) DO UPDATE
+SET
+    tokens = EXCLUDED.tokens,
+    updated_at = EXCLUDED.updated_at
+RETURNING (SELECT tokens FROM bucket)::float8 AS available;
+
+-- name: DeleteIdleRateLimits :exec
+-- Buckets idle this long have refilled, so dropping them changes nothing.
+DELETE FROM rate_limits
+WHERE updated_at < @idle_since;
@@ -0,0 +1,25 @@
+-- name: GetRepository :one
+SELECT * FROM repositories
+WHERE id = $1 LIMIT 1;
+
+-- name: ListRepositories :many
+SELECT * FROM repositories
+ORDER BY id;
+
+-- name: ListRepositoriesByAuthor :many
+-- Repositories whose authors collection lists the author.
+SELECT * FROM repositories
+WHERE id IN (SELECT repository_id FROM author_collection_members WHERE author_id = $1)
+ORDER BY full_name;
+
+-- name: ListRepositoriesByOwner :many
+SELECT * FROM repositories
+WHERE owner_id = $1
+ORDER BY full_name;
+
+-- name: ListRepositoriesForUser :many
+-- Repositories the user owns or is a member of.
+SELECT * FROM repositories
+WHERE owner_id = @user_id
+   OR id IN (SELECT repository_id FROM repository_members WHERE user_id = @user_id)
+ORDER BY full_name;
@@ -0,0 +1,81 @@
+-- name: UpsertScheduledPublish :one
+INSERT INTO scheduled_publishes (
+    change_set_id,
+    repository_id,
+    scheduled_by,
+    publish_at,
+    timezone,
+    next_attempt_at
+) VALUES (
+    @change_set_id, @repository_id, @scheduled_by, @publish_at, @timezone, @publish_at
+)
+ON CONFLICT (change_set_id) DO UPDATE
+SET
+    scheduled_by = EXCLUDED.scheduled_by,
+    publish_at = EXCLUDED.publish_at,
+    timezone = EXCLUDED.timezone,
+    status = 'pending',
+    attempts = 0,
+    next_attempt_at = EXCLUDED.publish_at,
+    last_error = '',
+    updated_at = NOW()
+RETURNING *;
+
+-- name: GetScheduledPublish :one
+SELECT * FROM scheduled_publishes
+WHERE change_set_id = $1;
+
+-- name: DeleteScheduledPublish :exec
+DELETE FROM scheduled_publishes
+WHERE change_set_id = $1;
+
+-- name: ListUpcomingScheduledPublishes :many
+-- Pending publishes in every repository the user can access, soonest first.
+SELECT
+    s.change_set_id,
+    s.repository_id,
+    r.full_name AS repository_name,
+    c.title,
+    u.name AS scheduled_by_name,
+    s.publish_at,
+    s.timezone,
+    s.status,
+    s.attempts,
+    s.last_error
+FROM scheduled_publishes s
+JOIN change_sets c ON c.id = s.change_set_id
+JOIN repositories r ON r.id = s.repository_id
+JOIN users u ON u.id = s.scheduled_by
+WHERE s.status = 'pending'
+  AND (
+      r.owner_id = @user_id
+      OR r.id IN (SELECT repository_id FROM repository_members WHERE user_id = @user_id)
+  )
+ORDER BY s.publish_at;
+
+-- name: ListDueScheduledPublishes :many
+SELECT * FROM scheduled_publishes
+WHERE status = 'pending' AND next_attempt_at <= NOW()
+ORDER BY next_attempt_at
+LIMIT $1;
+
+-- name: CountDueScheduledPublishes :one
+SELECT COUNT(*) FROM scheduled_publishes
+WHERE status = 'pending' AND next_attempt_at <= NOW();
+
+-- name: UpdateScheduledPublishAttempt :exec
+UPDATE scheduled_publishes
+SET
+    status = $2,
+    attempts = $3,
+    next_attempt_at = $4,
+    last_error = $5,
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: TryAdvisoryLock :one
+-- Session-level lock; must be released on the same connection.
+SELECT pg_try_advisory_lock(@key::bigint) AS locked;
+
+-- name: AdvisoryUnlock :exec
+SELECT pg_advisory_unlock(@key::bigint);
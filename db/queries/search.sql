@@ -0,0 +1,56 @@
+-- name: UpsertSearchDocument :exec
+INSERT INTO search_documents (
+    repository_id,
+    path,
+    language,
+    title,
+    description,
+    headings,
+    body,
+    search_vector
+) VALUES (
+    @repository_id, @path, @language, @title, @description, @headings, @body,
+    setweight(to_tsvector(@language::regconfig, @title), 'A') ||
+    setweight(to_tsvector(@language::regconfig, @description), 'B') ||
+    setweight(to_tsvector(@language::regconfig, @headings), 'B') ||
+    setweight(to_tsvector(@language::regconfig, @body), 'D')
+)
+ON CONFLICT (repository_id, path) DO UPDATE
+SET
+    language = EXCLUDED.language,
+    title = EXCLUDED.title,
+    description = EXCLUDED.description,
+    headings = EXCLUDED.headings,
+    body = EXCLUDED.body,
+    search_vector = EXCLUDED.search_vector,
+    updated_at = NOW();
+
+-- name: DeleteSearchDocument :exec
+DELETE FROM search_documents
+WHERE repository_id = $1 AND path = $2;
+
+-- name: DeleteStaleSearchDocuments :exec
+DELETE FROM search_documents
+WHERE repository_id = @repository_id AND NOT (path = ANY(@paths::text[]));
+
+-- name: SearchDocuments :many
+SELECT
+    sd.repository_id,
+    r.full_name AS repository_name,
+    sd.path,
+    sd.title,
+    ts_headline(
+        sd.language::regconfig,
+        sd.body,
+        q.query,
+        @headline_options::text
+    ) AS snippet,
+    ts_rank_cd(sd.search_vector, q.query)::real AS rank
+FROM search_documents sd
+JOIN repositories r ON r.id = sd.repository_id
+CROSS JOIN LATERAL websearch_to_tsquery(sd.language::regconfig, @query::text) AS q(query)
+WHERE (r.owner_id = @user_id
+       OR r.id IN (SELECT repository_id FROM repository_members WHERE user_id = @user_id))
+  AND sd.search_vector @@ q.query
+ORDER BY rank DESC, sd.path
+LIMIT @result_limit;
@@ -0,0 +1,47 @@
+-- name: CreatePersonalAccessToken :one
+INSERT INTO personal_access_tokens (
+    user_id,
+    name,
+    token_hash,
+    repository_id,
+    permission,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+)
+RETURNING *;
+
+-- name: GetActivePersonalAccessToken :one
+-- An unrevoked, unexpired token by the hash of its secret, with its user.
+SELECT t.id, t.user_id, t.repository_id, t.permission, u.name AS user_name, u.email AS user_email
+FROM personal_access_tokens t
+JOIN users u ON u.id = t.user_id
+WHERE t.token_hash = $1
+  AND t.revoked_at IS NULL
+  AND (t.expires_at IS NULL OR t.expires_at > NOW());
+
+-- name: ListPersonalAccessTokens :many
+-- The user's unrevoked tokens, expired ones included, newest first.
+SELECT
+    t.id,
+    t.name,
+    t.repository_id,
+    COALESCE(r.full_name, '')::text AS repository_name,
+    t.permission,
+    t.expires_at,
+    t.last_used_at,
+    t.created_at
+FROM personal_access_tokens t
+LEFT JOIN repositories r ON r.id = t.repository_id
+WHERE t.user_id = $1 AND t.revoked_at IS NULL
+ORDER BY t.created_at DESC, t.id DESC;
+
+-- name: RevokePersonalAccessToken :execrows
+UPDATE personal_access_tokens
+SET revoked_at = NOW()
+WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL;
+
+-- name: TouchPersonalAccessToken :exec
+UPDATE personal_access_tokens
+SET last_used_at = NOW()
+WHERE id = $1;
@@ -6,6 +6,12 @@ WHERE id = $1 LIMIT 1;
 SELECT * FROM users
 WHERE github_id = $1 LIMIT 1;
 
+-- name: GetUserByEmail :one
+SELECT * FROM users
+WHERE lower(email) = lower(sqlc.arg(email))
+ORDER BY id
+LIMIT 1;
+
 -- name: UpsertUser :one
 INSERT INTO users (
     github_id,
@@ -22,3 +28,11 @@ SET
     avatar_url = EXCLUDED.avatar_url,
     updated_at = NOW()
 RETURNING *;
+
+-- name: ListUsers :many
+SELECT * FROM users
+ORDER BY name, id;
+
+-- name: ListUsersByIDs :many
+SELECT * FROM users
+WHERE id = ANY(@ids::bigint[]);
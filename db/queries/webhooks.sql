@@ -0,0 +1,70 @@
+-- name: CreateWebhookEndpoint :one
+INSERT INTO webhook_endpoints (
+    repository_id,
+    url,
+    secret,
+    events
+) VALUES (
+    $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: ListWebhookEndpoints :many
+SELECT * FROM webhook_endpoints
+WHERE repository_id = $1
+ORDER BY created_at, id;
+
+-- name: GetWebhookEndpoint :one
+SELECT * FROM webhook_endpoints
+WHERE id = $1 AND repository_id = $2;
+
+-- name: DeleteWebhookEndpoint :execrows
+DELETE FROM webhook_endpoints
+WHERE id = $1 AND repository_id = $2;
+
+-- name: EnqueueWebhookDeliveries :execrows
+-- Queues an event for every endpoint of the repository subscribed to it.
+INSERT INTO webhook_deliveries (endpoint_id, event, payload)
+SELECT id, @event::text, @payload::jsonb
+FROM webhook_endpoints
+WHERE repository_id = @repository_id AND @event::text = ANY(events);
+
+-- name: ListWebhookDeliveries :many
+-- The endpoint's most recent deliveries, newest first.
+SELECT * FROM webhook_deliveries
+WHERE endpoint_id = $1
+ORDER BY created_at DESC, id DESC
+LIMIT $2;
+
+-- name: RedeliverWebhookDelivery :one
+-- Queues a delivery of the same event to an endpoint of the repository again.
+INSERT INTO webhook_deliveries (endpoint_id, event, payload, redelivery_of)
+SELECT d.endpoint_id, d.event, d.payload, d.id
+FROM webhook_deliveries d
+JOIN webhook_endpoints e ON e.id = d.endpoint_id
+WHERE d.id = @id AND e.repository_id = @repository_id
+RETURNING *;
+
+-- name: ListDueWebhookDeliveries :many
+-- Pending deliveries whose next attempt is due, with where to send them.
+SELECT d.id, d.event, d.payload, d.attempts, e.url, e.secret
+FROM webhook_deliveries d
+JOIN webhook_endpoints e ON e.id = d.endpoint_id
+WHERE d.status = 'pending' AND d.next_attempt_at <= NOW()
+ORDER BY d.next_attempt_at
+LIMIT $1;
+
+-- name: CountDueWebhookDeliveries :one
+SELECT COUNT(*) FROM webhook_deliveries
+WHERE status = 'pending' AND next_attempt_at <= NOW();
+
+-- name: UpdateWebhookDeliveryAttempt :exec
+UPDATE webhook_deliveries
+SET
+    status = @status,
+    attempts = @attempts,
+    next_attempt_at = @next_attempt_at,
+    response_status = @response_status,
+    last_error = @last_error,
+    delivered_at = CASE WHEN @status::text = 'delivered' THEN NOW() ELSE delivered_at END
+WHERE id = @id;
@@ -0,0 +1,215 @@
+-- name: GetRepositoryMemberRole :one
+SELECT role FROM repository_members
+WHERE repository_id = $1 AND user_id = $2;
+
+-- name: ListRepositoryMembers :many
+SELECT
+    m.user_id,
+    m.role,
+    u.name,
+    u.email,
+    u.avatar_url
+FROM repository_members m
+JOIN users u ON u.id = m.user_id
+WHERE m.repository_id = $1
+ORDER BY u.name;
+
+-- name: UpsertRepositoryMember :exec
+INSERT INTO repository_members (repository_id, user_id, role)
+VALUES ($1, $2, $3)
+ON CONFLICT (repository_id, user_id) DO UPDATE
+SET role = EXCLUDED.role;
+
+-- name: DeleteRepositoryMember :exec
+DELETE FROM repository_members
+WHERE repository_id = $1 AND user_id = $2;
+
+-- name: GetWorkflowSettings :one
+SELECT * FROM workflow_settings
+WHERE repository_id = $1;
+
+-- name: UpsertWorkflowSettings :exec
+INSERT INTO workflow_settings (repository_id, required_approvals, approver_role)
+VALUES ($1, $2, $3)
+ON CONFLICT (repository_id) DO UPDATE
+SET
+    required_approvals = EXCLUDED.required_approvals,
+    approver_role = EXCLUDED.approver_role,
+    updated_at = NOW();
+
+-- name: CreateChangeSet :one
+INSERT INTO change_sets (repository_id, title, description, author_id)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: AddChangeSetFile :exec
+INSERT INTO change_set_files (change_set_id, path)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: GetChangeSet :one
+SELECT
+    c.id,
+    c.repository_id,
+    c.title,
+    c.description,
+    c.author_id,
+    u.name AS author_name,
+    c.state,
+    c.published_commit,
+    c.created_at,
+    c.updated_at
+FROM change_sets c
+JOIN users u ON u.id = c.author_id
+WHERE c.id = $1 AND c.repository_id = $2;
+
+-- name: LockChangeSet :one
+-- Holds the change set until the transaction ends, so transitions on it
+-- run one at a time.
+SELECT state FROM change_sets
+WHERE id = $1 AND repository_id = $2
+FOR UPDATE;
+
+-- name: GetChangeSetRepositoryID :one
+SELECT repository_id FROM change_sets
+WHERE id = $1;
+
+-- name: ListChangeSets :many
+SELECT
+    c.id,
+    c.repository_id,
+    c.title,
+    c.description,
+    c.author_id,
+    u.name AS author_name,
+    c.state,
+    c.published_commit,
+    c.created_at,
+    c.updated_at
+FROM change_sets c
+JOIN users u ON u.id = c.author_id
+WHERE c.repository_id = $1
+ORDER BY c.updated_at DESC;
+
+-- name: ListChangeSetsInReview :many
+-- Change sets awaiting review in every repository the user can access.
+SELECT
+    c.id,
+    c.repository_id,
+    r.full_name AS repository_name,
+    c.title,
+    c.description,
+    c.author_id,
+    u.name AS author_name,
+    c.state,
+    c.published_commit,
+    c.created_at,
+    c.updated_at
+FROM change_sets c
+JOIN repositories r ON r.id = c.repository_id
+JOIN users u ON u.id = c.author_id
+WHERE c.state = 'in_review'
+  AND (
+      r.owner_id = @user_id
+      OR r.id IN (SELECT repository_id FROM repository_members WHERE user_id = @user_id)
+  )
+ORDER BY c.updated_at;
+
+-- name: ListChangeSetFiles :many
+SELECT path FROM change_set_files
+WHERE change_set_id = $1
+ORDER BY path;
+
+-- name: ListUnpublishedChangeSetFiles :many
+SELECT f.change_set_id, f.path
+FROM change_set_files f
+JOIN change_sets c ON c.id = f.change_set_id
+WHERE c.repository_id = $1 AND c.state <> 'published';
+
+-- name: UpdateChangeSetState :execrows
+-- Moves a change set on only if it is still in from_state, so concurrent
+-- transitions cannot both apply.
+UPDATE change_sets
+SET state = @to_state, published_commit = @published_commit, updated_at = NOW()
+WHERE id = @id AND state = @from_state;
+
+-- name: SetChangeSetPublishedCommit :exec
+UPDATE change_sets SET published_commit = $1
+WHERE id = $2;
+
+-- name: CreateChangeSetTransition :exec
+INSERT INTO change_set_transitions (change_set_id, user_id, action, from_state, to_state, note)
+VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: ListChangeSetTransitions :many
+SELECT
+    t.id,
+    t.action,
+    t.from_state,
+    t.to_state,
+    t.note,
+    t.created_at,
+    u.name AS user_name
+FROM change_set_transitions t
+JOIN users u ON u.id = t.user_id
+WHERE t.change_set_id = $1
+ORDER BY t.created_at, t.id;
+
+-- name: CreateChangeSetApproval :exec
+INSERT INTO change_set_approvals (change_set_id, user_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: ListChangeSetApprovals :many
+SELECT
+    a.user_id,
+    u.name AS user_name,
+    a.created_at
+FROM change_set_approvals a
+JOIN users u ON u.id = a.user_id
+WHERE a.change_set_id = $1
+ORDER BY a.created_at;
+
+-- name: DeleteChangeSetApprovals :exec
+DELETE FROM change_set_approvals
+WHERE change_set_id = $1;
+
+-- name: ListRepositoryMembersByRepositories :many
+SELECT * FROM repository_members
+WHERE repository_id = ANY(@repository_ids::bigint[])
+ORDER BY repository_id, created_at, user_id;
+
+-- name: ListChangeSetsByRepositories :many
+SELECT * FROM change_sets
+WHERE repository_id = ANY(@repository_ids::bigint[])
+ORDER BY repository_id, updated_at DESC, id DESC;
+
+-- name: ListChangeSetsByIDs :many
+SELECT * FROM change_sets
+WHERE id = ANY(@ids::bigint[]);
+
+-- name: ListChangeSetFilesByChangeSets :many
+SELECT * FROM change_set_files
+WHERE change_set_id = ANY(@change_set_ids::bigint[])
+ORDER BY change_set_id, path;
+
+-- name: ListChangeSetTransitionsByChangeSets :many
+SELECT * FROM change_set_transitions
+WHERE change_set_id = ANY(@change_set_ids::bigint[])
+ORDER BY change_set_id, created_at DESC, id DESC;
+
+-- name: ListActivityByRepositories :many
+-- The newest change set transitions of each repository, at most
+-- per_repository of them.
+SELECT id, repository_id, change_set_id, user_id, action, from_state, to_state, note, created_at
+FROM (
+    SELECT
+        t.*,
+        c.repository_id,
+        ROW_NUMBER() OVER (PARTITION BY c.repository_id ORDER BY t.created_at DESC, t.id DESC) AS position
+    FROM change_set_transitions t
+    JOIN change_sets c ON c.id = t.change_set_id
+    WHERE c.repository_id = ANY(@repository_ids::bigint[])
+) ranked
+WHERE position <= @per_repository::int
+ORDER BY repository_id, created_at DESC, id DESC;
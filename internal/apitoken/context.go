@@ -0,0 +1,16 @@
+package apitoken
+
+import "context"
+
+type grantKey struct{}
+
+// WithGrant records the grant of the token a request was authenticated with.
+func WithGrant(ctx context.Context, grant Grant) context.Context {
+	return context.WithValue(ctx, grantKey{}, grant)
+}
+
+// GrantFromContext returns the grant of the request's token, if it has one.
+func GrantFromContext(ctx context.Context) (Grant, bool) {
+	grant, ok := ctx.Value(grantKey{}).(Grant)
+	return grant, ok
+}
@@ -0,0 +1,140 @@
+package apitoken
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+	"github.com/jackc/pgx/v5"
+)
+
+// fakeQueries stores tokens by hash, returning the ones the real query
+// would: unexpired as of now.
+type fakeQueries struct {
+	db.Querier
+	tokens map[string]db.CreatePersonalAccessTokenParams
+}
+
+func (f *fakeQueries) CreatePersonalAccessToken(ctx context.Context, arg db.CreatePersonalAccessTokenParams) (db.PersonalAccessToken, error) {
+	f.tokens[arg.TokenHash] = arg
+	return db.PersonalAccessToken{}, nil
+}
+
+func (f *fakeQueries) GetActivePersonalAccessToken(ctx context.Context, tokenHash string) (db.GetActivePersonalAccessTokenRow, error) {
+	t, ok := f.tokens[tokenHash]
+	if !ok || (t.ExpiresAt.Valid && !t.ExpiresAt.Time.After(time.Now())) {
+		return db.GetActivePersonalAccessTokenRow{}, pgx.ErrNoRows
+	}
+	return db.GetActivePersonalAccessTokenRow{UserID: t.UserID, RepositoryID: t.RepositoryID, Permission: t.Permission}, nil
+}
+
+func (f *fakeQueries) TouchPersonalAccessToken(ctx context.Context, id int64) error {
+	return nil
+}
+
+func TestHash(t *testing.T) {
+	sum := sha256.Sum256([]byte("goaat_secret"))
+	if got := hash("goaat_secret"); got != hex.EncodeToString(sum[:]) {
+		t.Fatalf("hash = %s, want the hex SHA-256 of the secret", got)
+	}
+	if hash("goaat_a") == hash("goaat_b") {
+		t.Fatal("different secrets share a hash")
+	}
+}
+
+func TestCreateAndAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	queries := &fakeQueries{tokens: map[string]db.CreatePersonalAccessTokenParams{}}
+	s := NewService(queries)
+
+	tests := []struct {
+		name       string
+		repository int64
+		permission Permission
+		expiresAt  time.Time
+		createErr  error
+		authErr    error
+	}{
+		{"never expires", 0, PermissionRead, time.Time{}, nil, nil},
+		{"expires later", 7, PermissionWrite, time.Now().Add(time.Hour), nil, nil},
+		{"expires soon", 0, PermissionRead, time.Now().Add(50 * time.Millisecond), nil, ErrInvalidToken},
+		{"already expired", 0, PermissionRead, time.Now().Add(-time.Hour), ErrPastExpiry, nil},
+		{"", 0, PermissionRead, time.Time{}, ErrNoName, nil},
+		{"admin", 0, Permission("admin"), time.Time{}, ErrInvalidPermission, nil},
+	}
+	for _, tt := range tests {
+		secret, err := s.Create(ctx, 1, tt.name, tt.repository, tt.permission, tt.expiresAt)
+		if !errors.Is(err, tt.createErr) {
+			t.Errorf("%q: Create = %v, want %v", tt.name, err, tt.createErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if !strings.HasPrefix(secret, Prefix) {
+			t.Errorf("%q: secret %q lacks the %q prefix", tt.name, secret, Prefix)
+		}
+		if _, stored := queries.tokens[secret]; stored {
+			t.Errorf("%q: the secret itself was stored", tt.name)
+		}
+
+		if tt.authErr != nil {
+			time.Sleep(time.Until(tt.expiresAt))
+		}
+		grant, err := s.Authenticate(ctx, secret)
+		if !errors.Is(err, tt.authErr) {
+			t.Errorf("%q: Authenticate = %v, want %v", tt.name, err, tt.authErr)
+			continue
+		}
+		if err == nil && (grant.RepositoryID != tt.repository || grant.Permission != tt.permission) {
+			t.Errorf("%q: grant = %+v", tt.name, grant)
+		}
+	}
+
+	for _, secret := range []string{"", "goaat_unknown", "unprefixed"} {
+		if _, err := s.Authenticate(ctx, secret); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Authenticate(%q) = %v, want ErrInvalidToken", secret, err)
+		}
+	}
+}
+
+func TestExpired(t *testing.T) {
+	tests := []struct {
+		expiresAt time.Time
+		want      bool
+	}{
+		{time.Time{}, false},
+		{time.Now().Add(time.Hour), false},
+		{time.Now().Add(-time.Second), true},
+	}
+	for _, tt := range tests {
+		if got := (Token{ExpiresAt: tt.expiresAt}).Expired(); got != tt.want {
+			t.Errorf("Expired at %s = %v, want %v", tt.expiresAt, got, tt.want)
+		}
+	}
+}
+
+func TestPermissionAllows(t *testing.T) {
+	tests := []struct {
+		have, need Permission
+		want       bool
+	}{
+		{PermissionRead, PermissionRead, true},
+		{PermissionRead, PermissionWrite, false},
+		{PermissionWrite, PermissionRead, true},
+		{PermissionPublish, PermissionWrite, true},
+		{PermissionWrite, PermissionPublish, false},
+		{Permission("admin"), PermissionRead, false},
+		{PermissionPublish, Permission("admin"), false},
+	}
+	for _, tt := range tests {
+		if got := tt.have.Allows(tt.need); got != tt.want {
+			t.Errorf("%s.Allows(%s) = %v, want %v", tt.have, tt.need, got, tt.want)
+		}
+	}
+}
@@ -1,13 +1,16 @@
 package auth
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
 	"fmt"
+	"net/http"
 
-	"github.com/gorilla/sessions"
 	"github.com/gracchi-stdio/goaat/internal/config"
 	"github.com/markbates/goth"
 	"github.com/markbates/goth/gothic"
 	"github.com/markbates/goth/providers/github"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 // Init initializes the authentication providers.
@@ -22,14 +25,26 @@ func Init(cfg *config.Config) error {
 	}
 
 	// Set up the store for gothic (handles OAuth state)
-	store := sessions.NewCookieStore([]byte(cfg.SessionSecret))
-	gothic.Store = store
+	gothic.Store = NewCookieStore(cfg.SessionSecret, cfg.UsesHTTPS())
+
+	// The state carries the page to return to, signed with a key of its own
+	mac := hmac.New(sha256.New, []byte(cfg.SessionSecret))
+	mac.Write([]byte("oauth state"))
+	stateKey = mac.Sum(nil)
+	gothic.SetState = newState
 
 	callbackURL := cfg.BaseURL + "/auth/github/callback"
 
-	goth.UseProviders(
-		github.New(cfg.GithubClientID, cfg.GithubClientSecret, callbackURL),
-	)
+	provider := github.New(cfg.GithubClientID, cfg.GithubClientSecret, callbackURL)
+	// goth does not pass request contexts on, so these are spans of their own
+	provider.HTTPClient = &http.Client{
+		Transport: otelhttp.NewTransport(http.DefaultTransport,
+			otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+				return "GitHub " + r.Method + " " + r.URL.Path
+			}),
+		),
+	}
+	goth.UseProviders(provider)
 
 	return nil
 }
@@ -0,0 +1,25 @@
+package auth
+
+import "context"
+
+const (
+	// CSRFField is the form field carrying the CSRF token in classic forms.
+	CSRFField = "_csrf"
+
+	// CSRFHeader is the header carrying the CSRF token in fetch requests,
+	// including Datastar actions.
+	CSRFHeader = "X-CSRF-Token"
+)
+
+type csrfContextKey struct{}
+
+// WithCSRFToken stores the request's CSRF token for templates.
+func WithCSRFToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, csrfContextKey{}, token)
+}
+
+// CSRFToken returns the token pages embed so their requests pass the CSRF check.
+func CSRFToken(ctx context.Context) string {
+	token, _ := ctx.Value(csrfContextKey{}).(string)
+	return token
+}
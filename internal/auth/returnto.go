@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ReturnToParam is the query parameter carrying the page to return to
+// after signing in.
+const ReturnToParam = "return_to"
+
+// stateKey signs the OAuth state; it is derived from the session secret.
+var stateKey []byte
+
+type returnToKey struct{}
+
+// SafeReturnTo returns target if it is a path on this site, or "" for
+// anything a browser could resolve to another origin: absolute and
+// scheme-relative URLs, backslashes, which browsers read as slashes, and
+// control characters.
+func SafeReturnTo(target string) string {
+	if !strings.HasPrefix(target, "/") || strings.HasPrefix(target, "//") {
+		return ""
+	}
+	if strings.ContainsAny(target, "\\") || strings.ContainsFunc(target, func(r rune) bool { return r < 0x20 || r == 0x7f }) {
+		return ""
+	}
+	u, err := url.Parse(target)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.User != nil {
+		return ""
+	}
+	return u.RequestURI()
+}
+
+// LoginURL is the login page, returning to target afterwards when it is
+// safe to.
+func LoginURL(target string) string {
+	if target = SafeReturnTo(target); target == "" || target == "/" {
+		return "/login"
+	}
+	return "/login?" + url.Values{ReturnToParam: {target}}.Encode()
+}
+
+// WithReturnTo passes the page to return to on to the OAuth state, which
+// carries it through the provider's round trip.
+func WithReturnTo(ctx context.Context, target string) context.Context {
+	return context.WithValue(ctx, returnToKey{}, SafeReturnTo(target))
+}
+
+// newState is the OAuth state for req: a random nonce and the page to
+// return to, signed so the callback can trust it. gothic checks the state
+// the provider sends back against the one it stored for the sign-in, so a
+// target cannot be swapped in either.
+func newState(req *http.Request) string {
+	nonce := make([]byte, 32)
+	rand.Read(nonce)
+	target, _ := req.Context().Value(returnToKey{}).(string)
+
+	payload := base64.RawURLEncoding.EncodeToString(nonce) + "." + base64.RawURLEncoding.EncodeToString([]byte(target))
+	return payload + "." + base64.RawURLEncoding.EncodeToString(signState(payload))
+}
+
+// ReturnTo reads the page to return to from a verified OAuth state,
+// defaulting to the home page.
+func ReturnTo(state string) string {
+	payload, signature, ok := cutLast(state, ".")
+	if !ok {
+		return "/"
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, signState(payload)) {
+		return "/"
+	}
+	_, encoded, _ := cutLast(payload, ".")
+	target, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "/"
+	}
+	if safe := SafeReturnTo(string(target)); safe != "" {
+		return safe
+	}
+	return "/"
+}
+
+func signState(payload string) []byte {
+	mac := hmac.New(sha256.New, stateKey)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
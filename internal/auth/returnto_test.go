@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSafeReturnTo(t *testing.T) {
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{"/admin/dashboard", "/admin/dashboard"},
+		{"/admin/search?q=intro", "/admin/search?q=intro"},
+		{"/", "/"},
+		{"", ""},
+		{"admin/dashboard", ""},
+		{"https://evil.example/", ""},
+		{"//evil.example/", ""},
+		{"/\\evil.example/", ""},
+		{"\\\\evil.example/", ""},
+		{"/\tevil", ""},
+		{"/admin\r\nSet-Cookie: x=y", ""},
+		{"/%2F%2Fevil.example", "/%2F%2Fevil.example"},
+		{"javascript:alert(1)", ""},
+	}
+	for _, tt := range tests {
+		if got := SafeReturnTo(tt.target); got != tt.want {
+			t.Errorf("SafeReturnTo(%q) = %q, want %q", tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestLoginURL(t *testing.T) {
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{"", "/login"},
+		{"/", "/login"},
+		{"//evil.example/", "/login"},
+		{"/admin/settings", "/login?return_to=%2Fadmin%2Fsettings"},
+	}
+	for _, tt := range tests {
+		if got := LoginURL(tt.target); got != tt.want {
+			t.Errorf("LoginURL(%q) = %q, want %q", tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestReturnToState(t *testing.T) {
+	stateKey = []byte("test key")
+	req := httptest.NewRequest(http.MethodGet, "/auth/github", nil)
+	req = req.WithContext(WithReturnTo(req.Context(), "/admin/settings"))
+	state := newState(req)
+
+	if got := ReturnTo(state); got != "/admin/settings" {
+		t.Fatalf("ReturnTo = %q, want /admin/settings", got)
+	}
+
+	payload, _, _ := strings.Cut(state, ".")
+	forged := payload + ".L2FkbWlu." + state[strings.LastIndex(state, ".")+1:]
+	tests := []string{"", "garbage", state + "x", forged}
+	for _, state := range tests {
+		if got := ReturnTo(state); got != "/" {
+			t.Errorf("ReturnTo(%q) = %q, want /", state, got)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package auth
 import (
 	"context"
 	"encoding/gob"
+	"net/http"
 
 	"github.com/gorilla/sessions"
 	"github.com/labstack/echo-contrib/session"
@@ -29,7 +30,6 @@ type UserSession struct {
 	Email     string
 	Name      string
 	AvatarURL string
-	ReturnTo  string
 }
 
 // IsAuthenticated checks if the user is logged in
@@ -45,6 +45,23 @@ func GetUserFromContext(ctx context.Context) UserSession {
 	return UserSession{}
 }
 
+// sessionMaxAge is how long a sign-in lasts, in seconds.
+const sessionMaxAge = 86400 * 7 // 7 days
+
+// NewCookieStore creates the session cookie store. Cookies are sent only
+// over HTTPS when secure is set, and not on cross-site subrequests.
+func NewCookieStore(secret string, secure bool) *sessions.CookieStore {
+	store := sessions.NewCookieStore([]byte(secret))
+	store.Options = &sessions.Options{
+		Path:     "/",
+		MaxAge:   sessionMaxAge,
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteLaxMode,
+	}
+	return store
+}
+
 // GetSession retrieves the current user session
 func GetSession(c echo.Context) UserSession {
 	sess, _ := session.Get(SessionName, c)
@@ -58,14 +75,9 @@ func GetSession(c echo.Context) UserSession {
 	return UserSession{}
 }
 
-// SaveSession saves the user session
+// SaveSession saves the user session, with the cookie options of the store
 func SaveSession(c echo.Context, s UserSession) error {
 	sess, _ := session.Get(SessionName, c)
-	sess.Options = &sessions.Options{
-		Path:     "/",
-		MaxAge:   86400 * 7, // 7 days
-		HttpOnly: true,
-	}
 
 	// Store the entire struct
 	sess.Values[UserKey] = s
@@ -79,10 +91,3 @@ func ClearSession(c echo.Context) error {
 	sess.Options.MaxAge = -1
 	return sess.Save(c.Request(), c.Response())
 }
-
-// SetReturnTo saves the return URL in the session
-func SetReturnTo(c echo.Context, url string) error {
-	s := GetSession(c)
-	s.ReturnTo = url
-	return SaveSession(c, s)
-}
@@ -0,0 +1,231 @@
+// Package author manages the authors content is credited to, optionally
+// linked to the users who sign in.
+package author
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strconv"
+	"strings"
+
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	// DefaultPageSize is how many authors a page lists unless asked otherwise.
+	DefaultPageSize = 50
+
+	// MaxPageSize caps the page size a client may ask for.
+	MaxPageSize = 100
+)
+
+var (
+	// ErrUnknownAuthor is returned for authors that do not exist.
+	ErrUnknownAuthor = errors.New("unknown author")
+
+	// ErrNoName is returned for authors without a name.
+	ErrNoName = errors.New("author needs a name")
+
+	// ErrInvalidEmail is returned for malformed email addresses.
+	ErrInvalidEmail = errors.New("invalid email address")
+
+	// ErrEmailTaken is returned when another author has the email address.
+	ErrEmailTaken = errors.New("email address belongs to another author")
+
+	// ErrUnknownUser is returned when linking an author to a missing user.
+	ErrUnknownUser = errors.New("unknown user")
+
+	// ErrUserTaken is returned when the user is linked to another author.
+	ErrUserTaken = errors.New("user is linked to another author")
+
+	// ErrInvalidCursor is returned for page cursors this service did not issue.
+	ErrInvalidCursor = errors.New("invalid page cursor")
+)
+
+// Query selects a page of authors.
+type Query struct {
+	Search string // part of the name, matched case-insensitively
+	After  string // cursor of the previous page's last author, or empty
+	Limit  int    // DefaultPageSize if zero
+}
+
+// Page is one page of authors, in name order.
+type Page struct {
+	Authors []db.Author
+	Next    string // cursor for the following page, empty on the last
+}
+
+// Input is an author's editable fields.
+type Input struct {
+	Name   string
+	Email  string
+	UserID int64 // 0 for an author who is not a user
+}
+
+// Service manages authors.
+type Service interface {
+	List(ctx context.Context, q Query) (Page, error)
+	Get(ctx context.Context, id int64) (db.Author, error)
+	Create(ctx context.Context, in Input) (db.Author, error)
+	Update(ctx context.Context, id int64, in Input) (db.Author, error)
+	Delete(ctx context.Context, id int64) error
+
+	// Repositories lists the repositories whose authors collections list the author
+	Repositories(ctx context.Context, id int64) ([]db.Repository, error)
+}
+
+type service struct {
+	queries db.Querier
+}
+
+// NewService creates an author service.
+func NewService(queries db.Querier) Service {
+	return &service{queries: queries}
+}
+
+func (s *service) List(ctx context.Context, q Query) (Page, error) {
+	limit := q.Limit
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	limit = min(limit, MaxPageSize)
+
+	params := db.ListAuthorsParams{Search: escapeLike(q.Search), PageSize: int32(limit + 1)}
+	if q.After != "" {
+		var err error
+		if params.AfterName, params.AfterID, err = decodeCursor(q.After); err != nil {
+			return Page{}, err
+		}
+	}
+
+	// One extra row tells whether there is a next page
+	authors, err := s.queries.ListAuthors(ctx, params)
+	if err != nil {
+		return Page{}, fmt.Errorf("failed to list authors: %w", err)
+	}
+	page := Page{Authors: authors}
+	if len(authors) > limit {
+		page.Authors = authors[:limit]
+		last := page.Authors[limit-1]
+		page.Next = encodeCursor(last.Name, last.ID)
+	}
+	return page, nil
+}
+
+func (s *service) Get(ctx context.Context, id int64) (db.Author, error) {
+	a, err := s.queries.GetAuthorByID(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return db.Author{}, ErrUnknownAuthor
+	}
+	if err != nil {
+		return db.Author{}, fmt.Errorf("failed to load author: %w", err)
+	}
+	return a, nil
+}
+
+func (s *service) Create(ctx context.Context, in Input) (db.Author, error) {
+	if err := in.normalize(); err != nil {
+		return db.Author{}, err
+	}
+	a, err := s.queries.CreateAuthor(ctx, db.CreateAuthorParams{Name: in.Name, Email: in.Email, UserID: userID(in.UserID)})
+	if err != nil {
+		return db.Author{}, writeError("create", err)
+	}
+	return a, nil
+}
+
+func (s *service) Update(ctx context.Context, id int64, in Input) (db.Author, error) {
+	if err := in.normalize(); err != nil {
+		return db.Author{}, err
+	}
+	a, err := s.queries.UpdateAuthor(ctx, db.UpdateAuthorParams{ID: id, Name: in.Name, Email: in.Email, UserID: userID(in.UserID)})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return db.Author{}, ErrUnknownAuthor
+	}
+	if err != nil {
+		return db.Author{}, writeError("update", err)
+	}
+	return a, nil
+}
+
+func (s *service) Delete(ctx context.Context, id int64) error {
+	deleted, err := s.queries.DeleteAuthor(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete author: %w", err)
+	}
+	if deleted == 0 {
+		return ErrUnknownAuthor
+	}
+	return nil
+}
+
+func (s *service) Repositories(ctx context.Context, id int64) ([]db.Repository, error) {
+	repos, err := s.queries.ListRepositoriesByAuthor(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the author's repositories: %w", err)
+	}
+	return repos, nil
+}
+
+// normalize trims the input and checks it is complete.
+func (in *Input) normalize() error {
+	in.Name = strings.TrimSpace(in.Name)
+	in.Email = strings.TrimSpace(in.Email)
+	if in.Name == "" {
+		return ErrNoName
+	}
+	if addr, err := mail.ParseAddress(in.Email); err != nil || addr.Address != in.Email {
+		return ErrInvalidEmail
+	}
+	return nil
+}
+
+// writeError maps constraint violations to the errors they stand for.
+func writeError(action string, err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch {
+		case pgErr.Code == "23505" && pgErr.ConstraintName == "authors_email_key":
+			return ErrEmailTaken
+		case pgErr.Code == "23505" && pgErr.ConstraintName == "authors_user_id_key":
+			return ErrUserTaken
+		case pgErr.Code == "23503":
+			return ErrUnknownUser
+		}
+	}
+	return fmt.Errorf("failed to %s author: %w", action, err)
+}
+
+// userID converts an optional user id to its column value.
+func userID(id int64) pgtype.Int8 {
+	return pgtype.Int8{Int64: id, Valid: id > 0}
+}
+
+// escapeLike makes search match literally within an ILIKE pattern.
+func escapeLike(search string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(strings.TrimSpace(search))
+}
+
+// encodeCursor identifies a position in name order.
+func encodeCursor(name string, id int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(id, 10) + ":" + name))
+}
+
+func decodeCursor(cursor string) (string, int64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", 0, ErrInvalidCursor
+	}
+	idText, name, ok := strings.Cut(string(raw), ":")
+	id, err := strconv.ParseInt(idText, 10, 64)
+	if !ok || err != nil {
+		return "", 0, ErrInvalidCursor
+	}
+	return name, id, nil
+}
@@ -0,0 +1,75 @@
+package author
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestCursor(t *testing.T) {
+	tests := []struct {
+		name string
+		id   int64
+	}{
+		{"Ada Lovelace", 1},
+		{"", 7},
+		{"Name: with a colon", 42},
+		{"Zoë Ünicode", 1 << 40},
+	}
+	for _, tt := range tests {
+		name, id, err := decodeCursor(encodeCursor(tt.name, tt.id))
+		if err != nil || name != tt.name || id != tt.id {
+			t.Errorf("decodeCursor(encodeCursor(%q, %d)) = %q, %d, %v", tt.name, tt.id, name, id, err)
+		}
+	}
+
+	for _, cursor := range []string{"not base64!", "bm8tY29sb24", "YWJjOm5hbWU"} {
+		if _, _, err := decodeCursor(cursor); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("decodeCursor(%q) = %v, want ErrInvalidCursor", cursor, err)
+		}
+	}
+}
+
+func TestEscapeLike(t *testing.T) {
+	tests := []struct {
+		search string
+		want   string
+	}{
+		{"ada", "ada"},
+		{"  ada  ", "ada"},
+		{"100%", `100\%`},
+		{"snake_case", `snake\_case`},
+		{`back\slash`, `back\\slash`},
+		{`%_\`, `\%\_\\`},
+	}
+	for _, tt := range tests {
+		if got := escapeLike(tt.search); got != tt.want {
+			t.Errorf("escapeLike(%q) = %q, want %q", tt.search, got, tt.want)
+		}
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	other := errors.New("connection reset")
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"duplicate email", &pgconn.PgError{Code: "23505", ConstraintName: "authors_email_key"}, ErrEmailTaken},
+		{"duplicate user", &pgconn.PgError{Code: "23505", ConstraintName: "authors_user_id_key"}, ErrUserTaken},
+		{"missing user", &pgconn.PgError{Code: "23503", ConstraintName: "authors_user_id_fkey"}, ErrUnknownUser},
+		{"other constraint", &pgconn.PgError{Code: "23505", ConstraintName: "authors_pkey"}, nil},
+		{"not a constraint", other, other},
+	}
+	for _, tt := range tests {
+		err := writeError("create", tt.err)
+		switch {
+		case tt.want != nil && !errors.Is(err, tt.want):
+			t.Errorf("%s: writeError = %v, want %v", tt.name, err, tt.want)
+		case tt.want == nil && (errors.Is(err, ErrEmailTaken) || errors.Is(err, ErrUserTaken) || errors.Is(err, ErrUnknownUser)):
+			t.Errorf("%s: writeError = %v, want the error passed through", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package authorsync
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gracchi-stdio/goaat/internal/content"
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+	"gopkg.in/yaml.v3"
+)
+
+// fileExtensions are the data file extensions an entry may use; new files
+// get the first.
+var fileExtensions = []string{".yml", ".yaml"}
+
+// slugUnsafe matches runs of characters that do not belong in a slug.
+var slugUnsafe = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Entry is an author as a collection file describes it. Its slug is the file
+// name, the id pages reference it by.
+type Entry struct {
+	Slug  string
+	Name  string
+	Email string
+}
+
+// file is a collection file as read from the working copy.
+type file struct {
+	Entry
+	Path string // relative to the repository root
+	Hash string
+	Src  []byte
+}
+
+// entry returns the file's entry, or nil for no file.
+func (f *file) entry() *Entry {
+	if f == nil {
+		return nil
+	}
+	return &f.Entry
+}
+
+// matches reports whether the file already says what the author does.
+func (f *file) matches(a *db.Author) bool {
+	return f.Name == a.Name && f.Email == a.Email
+}
+
+// readFiles reads the collection's entries. Files without a name and an
+// email address are reported as problems rather than entries.
+func readFiles(root, dir string) (map[string]*file, map[string]bool, []Problem, error) {
+	abs, err := content.SafeJoin(root, dir)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	list, err := os.ReadDir(abs)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, nil, nil, fmt.Errorf("failed to list %s: %w", dir, err)
+	}
+
+	files := make(map[string]*file)
+	invalid := make(map[string]bool)
+	var problems []Problem
+	for _, item := range list {
+		ext := path.Ext(item.Name())
+		if item.IsDir() || !isDataFile(ext) {
+			continue
+		}
+		slug := strings.TrimSuffix(item.Name(), ext)
+		if _, dup := files[slug]; dup || invalid[slug] {
+			delete(files, slug)
+			invalid[slug] = true
+			problems = append(problems, Problem{Slug: slug, Message: "More than one file has this name."})
+			continue
+		}
+
+		rel := path.Join(dir, item.Name())
+		src, hash, err := content.ReadFile(root, rel)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to read %s: %w", rel, err)
+		}
+		f := &file{Entry: Entry{Slug: slug}, Path: rel, Hash: hash, Src: src}
+		if message := f.parse(); message != "" {
+			invalid[slug] = true
+			problems = append(problems, Problem{Slug: slug, Message: message})
+			continue
+		}
+		files[slug] = f
+	}
+	return files, invalid, problems, nil
+}
+
+// parse reads the name and email address of a file, returning what is
+// wrong with it if they cannot be read.
+func (f *file) parse() string {
+	var fields struct {
+		Name  string `yaml:"name"`
+		Email string `yaml:"email"`
+	}
+	if err := yaml.Unmarshal(f.Src, &fields); err != nil {
+		return "The file is not a valid YAML mapping."
+	}
+	f.Name, f.Email = strings.TrimSpace(fields.Name), strings.TrimSpace(fields.Email)
+	switch {
+	case f.Name == "":
+		return "The file has no name."
+	case f.Email == "":
+		return "The file has no email address."
+	}
+	return ""
+}
+
+// render writes an author into the YAML of its file, keeping the file's
+// other fields and comments. src is empty for a new file.
+func render(src []byte, a *db.Author) ([]byte, error) {
+	var doc yaml.Node
+	if len(bytes.TrimSpace(src)) > 0 {
+		if err := yaml.Unmarshal(src, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse author file: %w", err)
+		}
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	fields := doc.Content[0]
+	if fields.Kind != yaml.MappingNode {
+		return nil, errors.New("author file is not a YAML mapping")
+	}
+
+	setField(fields, "name", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: a.Name})
+	setField(fields, "email", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: a.Email})
+	return encode(&doc)
+}
+
+// setField sets a key of a YAML mapping, adding it at the end if missing.
+func setField(mapping *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content[i+1] = value
+			return
+		}
+	}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
+
+// field returns the value of a key of a YAML mapping, or nil.
+func field(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// encode writes YAML with the two-space indent Starlight projects use.
+func encode(doc *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return nil, fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// removeFile deletes a collection file if it is unchanged since it was read.
+func removeFile(root string, f *file) error {
+	_, hash, err := content.ReadFile(root, f.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if hash != f.Hash {
+		return content.ErrConflict
+	}
+	abs, err := content.SafeJoin(root, f.Path)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(abs); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", f.Path, err)
+	}
+	return nil
+}
+
+// newSlug names the file of an author from their name, numbering it when
+// the name is taken.
+func newSlug(name string, taken map[string]bool) string {
+	base := strings.Trim(slugUnsafe.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if base == "" {
+		base = "author"
+	}
+	slug := base
+	for n := 2; taken[slug]; n++ {
+		slug = base + "-" + strconv.Itoa(n)
+	}
+	return slug
+}
+
+// isDataFile reports whether a file extension is one entries may use.
+func isDataFile(ext string) bool {
+	for _, e := range fileExtensions {
+		if strings.EqualFold(ext, e) {
+			return true
+		}
+	}
+	return false
+}
+
+// entryPath is where a new entry's file is written.
+func entryPath(dir, slug string) string {
+	return path.Join(dir, slug+fileExtensions[0])
+}
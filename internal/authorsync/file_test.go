@@ -0,0 +1,89 @@
+package authorsync
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestReadFiles(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "authors")
+	if err := os.MkdirAll(filepath.Join(dir, "nested.yml"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range map[string]string{
+		"ada.yml":      "name: Ada Lovelace\nemail: ' ada@example.com '\n",
+		"grace.YAML":   "name: Grace Hopper\nemail: grace@example.com\n",
+		"twice.yml":    "name: Twice\nemail: twice@example.com\n",
+		"twice.yaml":   "name: Twice\nemail: twice@example.com\n",
+		"nameless.yml": "email: nobody@example.com\n",
+		"mailless.yml": "name: No Mail\n",
+		"list.yml":     "- ada\n",
+		"notes.md":     "# Not an author\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, invalid, problems, err := readFiles(root, "authors")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ada := files["ada"]; ada == nil || ada.Name != "Ada Lovelace" || ada.Email != "ada@example.com" || ada.Path != "authors/ada.yml" {
+		t.Errorf("ada = %+v, want a trimmed entry at authors/ada.yml", ada)
+	}
+	if files["grace"] == nil {
+		t.Error("grace.YAML was not read")
+	}
+	if len(files) != 2 {
+		t.Errorf("read %d files, want 2", len(files))
+	}
+
+	wantProblems := map[string]string{
+		"twice":    "More than one file has this name.",
+		"nameless": "The file has no name.",
+		"mailless": "The file has no email address.",
+		"list":     "The file is not a valid YAML mapping.",
+	}
+	for _, p := range problems {
+		if want := wantProblems[p.Slug]; p.Message != want {
+			t.Errorf("%s: problem %q, want %q", p.Slug, p.Message, want)
+		}
+		if !invalid[p.Slug] {
+			t.Errorf("%s has a problem but is not marked invalid", p.Slug)
+		}
+	}
+	var slugs []string
+	for _, p := range problems {
+		slugs = append(slugs, p.Slug)
+	}
+	slices.Sort(slugs)
+	if want := []string{"list", "mailless", "nameless", "twice"}; !slices.Equal(slugs, want) {
+		t.Errorf("problems for %q, want %q", slugs, want)
+	}
+
+	if files, _, _, err := readFiles(root, "missing"); err != nil || len(files) != 0 {
+		t.Errorf("readFiles of a missing directory = %d files, %v; want none", len(files), err)
+	}
+}
+
+func TestNewSlug(t *testing.T) {
+	taken := map[string]bool{"ada-lovelace": true, "ada-lovelace-2": true}
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Grace Hopper", "grace-hopper"},
+		{"  Zoë O'Brien! ", "zo-o-brien"},
+		{"Ada Lovelace", "ada-lovelace-3"},
+		{"李", "author"},
+	}
+	for _, tt := range tests {
+		if got := newSlug(tt.name, taken); got != tt.want {
+			t.Errorf("newSlug(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
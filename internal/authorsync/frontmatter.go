@@ -0,0 +1,79 @@
+package authorsync
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+
+	"github.com/gracchi-stdio/goaat/internal/content"
+	"gopkg.in/yaml.v3"
+)
+
+// References returns the entries a page's frontmatter field names, either a
+// single slug or a list of them.
+func References(frontmatter map[string]any, key string) []string {
+	switch value := frontmatter[key].(type) {
+	case string:
+		return []string{value}
+	case []any:
+		var slugs []string
+		for _, item := range value {
+			if slug, ok := item.(string); ok {
+				slugs = append(slugs, slug)
+			}
+		}
+		return slugs
+	}
+	return nil
+}
+
+// AddReference adds an entry to the frontmatter field key of a content file,
+// turning a single reference into a list. The body is left as it is.
+func AddReference(src []byte, key, slug string) ([]byte, error) {
+	front, body, err := content.SplitFrontmatter(src)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc yaml.Node
+	if len(bytes.TrimSpace(front)) > 0 {
+		if err := yaml.Unmarshal(front, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse frontmatter: %w", err)
+		}
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	fields := doc.Content[0]
+	if fields.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("frontmatter is not a YAML mapping")
+	}
+
+	ref := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: slug}
+	switch value := field(fields, key); {
+	case value == nil:
+		setField(fields, key, &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: []*yaml.Node{ref}})
+	case value.Kind == yaml.ScalarNode && value.Value == slug:
+		return src, nil
+	case value.Kind == yaml.ScalarNode:
+		setField(fields, key, &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: []*yaml.Node{value, ref}})
+	case value.Kind == yaml.SequenceNode:
+		if slices.ContainsFunc(value.Content, func(n *yaml.Node) bool { return n.Value == slug }) {
+			return src, nil
+		}
+		value.Content = append(value.Content, ref)
+	default:
+		return nil, fmt.Errorf("frontmatter field %s is not a list of authors", key)
+	}
+
+	out, err := encode(&doc)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	buf.Write(out)
+	buf.WriteString("---\n")
+	buf.Write(body)
+	return buf.Bytes(), nil
+}
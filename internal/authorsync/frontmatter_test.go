@@ -0,0 +1,54 @@
+package authorsync
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestReferences(t *testing.T) {
+	tests := []struct {
+		name        string
+		frontmatter map[string]any
+		want        []string
+	}{
+		{"single slug", map[string]any{"authors": "ada"}, []string{"ada"}},
+		{"list", map[string]any{"authors": []any{"ada", "grace"}}, []string{"ada", "grace"}},
+		{"list with other values", map[string]any{"authors": []any{"ada", 7, map[string]any{"name": "x"}}}, []string{"ada"}},
+		{"other key", map[string]any{"writers": "ada"}, nil},
+		{"not a slug", map[string]any{"authors": 7}, nil},
+	}
+	for _, tt := range tests {
+		if got := References(tt.frontmatter, "authors"); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: References = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAddReference(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		want    string
+		wantErr bool
+	}{
+		{"no frontmatter", "# Intro\n", "---\nauthors:\n  - ada\n---\n# Intro\n", false},
+		{"no field", "---\ntitle: Intro\n---\nBody\n", "---\ntitle: Intro\nauthors:\n  - ada\n---\nBody\n", false},
+		{"single reference", "---\nauthors: grace\n---\n", "---\nauthors:\n  - grace\n  - ada\n---\n", false},
+		{"list", "---\nauthors: [grace]\n---\n", "---\nauthors: [grace, ada]\n---\n", false},
+		{"already credited", "---\nauthors: [ada]\n---\nBody\n", "---\nauthors: [ada]\n---\nBody\n", false},
+		{"already the single reference", "---\nauthors: ada\n---\n", "---\nauthors: ada\n---\n", false},
+		{"field is a mapping", "---\nauthors:\n  name: ada\n---\n", "", true},
+		{"frontmatter is a list", "---\n- ada\n---\n", "", true},
+		{"invalid YAML", "---\nauthors: [ada\n---\n", "", true},
+	}
+	for _, tt := range tests {
+		got, err := AddReference([]byte(tt.src), "authors", "ada")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: AddReference error = %v, want error %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("%s: AddReference =\n%s\nwant\n%s", tt.name, got, tt.want)
+		}
+	}
+}
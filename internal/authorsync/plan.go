@@ -0,0 +1,208 @@
+package authorsync
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+)
+
+// ChangeKind is what a sync does to bring one entry back in step.
+type ChangeKind string
+
+const (
+	// CreateAuthor adds an author for a new collection file.
+	CreateAuthor ChangeKind = "create_author"
+
+	// UpdateAuthor copies an edited file into its author.
+	UpdateAuthor ChangeKind = "update_author"
+
+	// RemoveAuthor takes the author of a deleted file out of the collection.
+	// The author is kept, as other repositories may list them.
+	RemoveAuthor ChangeKind = "remove_author"
+
+	// WriteFile writes a new or edited author to its file.
+	WriteFile ChangeKind = "write_file"
+
+	// DeleteFile removes the file of a deleted author.
+	DeleteFile ChangeKind = "delete_file"
+
+	// Link records that a file and an author already agree.
+	Link ChangeKind = "link"
+
+	// Forget drops the record of an entry deleted on both sides.
+	Forget ChangeKind = "forget"
+)
+
+// Change is one step of a sync.
+type Change struct {
+	Kind   ChangeKind
+	Slug   string
+	Author *db.Author // nil while the file has no author
+	File   *Entry     // nil while the author has no file
+
+	file *file
+}
+
+// Conflict is an entry changed on both sides since the last sync. It is
+// left alone until someone chooses the side to keep.
+type Conflict struct {
+	Slug   string
+	Reason string
+	Author *db.Author // nil if the author was deleted
+	File   *Entry     // nil if the file was deleted
+
+	file *file
+}
+
+// Problem is a collection file that cannot be synced as it is.
+type Problem struct {
+	Slug    string
+	Message string
+}
+
+// Plan is what a sync would do.
+type Plan struct {
+	Changes   []Change
+	Conflicts []Conflict
+	Problems  []Problem
+	Available []db.Author // authors the collection could add, in name order
+}
+
+// state is both sides of a collection and the entries of the last sync.
+type state struct {
+	collection db.AuthorCollection
+	root       string // working copy of the repository
+	files      map[string]*file
+	invalid    map[string]bool // slugs of files that could not be read
+	problems   []Problem
+	entries    map[string]db.AuthorCollectionEntry
+	members    map[int64]bool // ids of the authors the collection lists
+	authors    []db.Author    // every author, in name order
+}
+
+// plan compares each side with the last sync. A side changed when its file
+// hash or author update time differs from the entry; an entry changed on
+// one side is copied to the other, one changed on both is a conflict.
+func (st *state) plan() *Plan {
+	p := &Plan{Problems: slices.Clone(st.problems)}
+
+	byID := make(map[int64]*db.Author, len(st.authors))
+	byEmail := make(map[string]*db.Author, len(st.authors))
+	for i := range st.authors {
+		a := &st.authors[i]
+		byID[a.ID] = a
+		byEmail[strings.ToLower(a.Email)] = a
+	}
+
+	claimed := make(map[int64]bool)
+	for _, e := range st.entries {
+		if e.AuthorID.Valid {
+			claimed[e.AuthorID.Int64] = true
+		}
+	}
+
+	taken := make(map[string]bool)
+	for slug := range st.files {
+		taken[slug] = true
+	}
+	for slug := range st.entries {
+		taken[slug] = true
+	}
+	for slug := range st.invalid {
+		taken[slug] = true
+	}
+
+	for _, slug := range slices.Sorted(maps.Keys(taken)) {
+		if st.invalid[slug] {
+			// An unreadable file is not a deleted one; wait until it is fixed
+			continue
+		}
+		f := st.files[slug]
+		e, synced := st.entries[slug]
+
+		if !synced {
+			// A new file: link it to the author with its email, if there is one
+			a := byEmail[strings.ToLower(f.Email)]
+			switch {
+			case a == nil:
+				p.change(CreateAuthor, slug, nil, f)
+			case claimed[a.ID]:
+				p.Problems = append(p.Problems, Problem{Slug: slug, Message: "Its email address belongs to the author of another file."})
+			case f.matches(a):
+				p.change(Link, slug, a, f)
+			default:
+				p.conflict(slug, "An author with this email address has different details.", a, f)
+			}
+			if a != nil {
+				claimed[a.ID] = true
+			}
+			continue
+		}
+
+		var a *db.Author
+		if e.AuthorID.Valid {
+			a = byID[e.AuthorID.Int64]
+		}
+		fileChanged := f == nil || f.Hash != e.FileHash
+		authorChanged := a == nil || !a.UpdatedAt.Time.Equal(e.AuthorUpdatedAt.Time)
+
+		switch {
+		case !fileChanged && !authorChanged:
+		case f != nil && a != nil && f.matches(a):
+			p.change(Link, slug, a, f)
+		case !authorChanged && f == nil:
+			p.change(RemoveAuthor, slug, a, nil)
+		case !authorChanged:
+			p.change(UpdateAuthor, slug, a, f)
+		case !fileChanged && a == nil:
+			p.change(DeleteFile, slug, nil, f)
+		case !fileChanged:
+			p.change(WriteFile, slug, a, f)
+		case f == nil && a == nil:
+			p.change(Forget, slug, nil, nil)
+		case f == nil:
+			p.conflict(slug, "The file was deleted but the author was edited.", a, nil)
+		case a == nil:
+			p.conflict(slug, "The author was deleted but the file was edited.", nil, f)
+		default:
+			p.conflict(slug, "Both the file and the author were edited.", a, f)
+		}
+	}
+
+	// Authors added to the collection get a file of their own; the others
+	// belong to other repositories, or to none yet
+	for i := range st.authors {
+		a := &st.authors[i]
+		switch {
+		case claimed[a.ID]:
+		case st.members[a.ID]:
+			slug := newSlug(a.Name, taken)
+			taken[slug] = true
+			p.change(WriteFile, slug, a, nil)
+		default:
+			p.Available = append(p.Available, *a)
+		}
+	}
+
+	return p
+}
+
+func (p *Plan) change(kind ChangeKind, slug string, a *db.Author, f *file) {
+	p.Changes = append(p.Changes, Change{Kind: kind, Slug: slug, Author: a, File: f.entry(), file: f})
+}
+
+func (p *Plan) conflict(slug, reason string, a *db.Author, f *file) {
+	p.Conflicts = append(p.Conflicts, Conflict{Slug: slug, Reason: reason, Author: a, File: f.entry(), file: f})
+}
+
+// conflictOver returns the conflict over an entry, if there is one.
+func (p *Plan) conflictOver(slug string) (Conflict, bool) {
+	for _, c := range p.Conflicts {
+		if c.Slug == slug {
+			return c, true
+		}
+	}
+	return Conflict{}, false
+}
@@ -0,0 +1,223 @@
+package authorsync
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	syncedAt = time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	editedAt = syncedAt.Add(time.Hour)
+)
+
+func testAuthor(id int64, name, email string, updated time.Time) db.Author {
+	return db.Author{ID: id, Name: name, Email: email, UpdatedAt: pgtype.Timestamp{Time: updated, Valid: true}}
+}
+
+func testFile(slug, name, email, hash string) *file {
+	return &file{Entry: Entry{Slug: slug, Name: name, Email: email}, Path: entryPath(DefaultPath, slug), Hash: hash}
+}
+
+func testEntry(slug string, authorID int64, hash string) db.AuthorCollectionEntry {
+	return db.AuthorCollectionEntry{
+		Slug:            slug,
+		AuthorID:        pgtype.Int8{Int64: authorID, Valid: authorID > 0},
+		FileHash:        hash,
+		AuthorUpdatedAt: pgtype.Timestamp{Time: syncedAt, Valid: true},
+	}
+}
+
+func TestPlan(t *testing.T) {
+	ada := testAuthor(1, "Ada Lovelace", "ada@example.com", syncedAt)
+	adaEdited := testAuthor(1, "Ada King", "ada@example.com", editedAt)
+	grace := testAuthor(2, "Grace Hopper", "grace@example.com", syncedAt)
+
+	tests := []struct {
+		name      string
+		files     []*file
+		invalid   []string
+		entries   []db.AuthorCollectionEntry
+		authors   []db.Author
+		members   []int64
+		changes   []string // kind and slug
+		conflicts []string // slug and reason
+		problems  []string // slug and message
+		available []int64
+	}{
+		{
+			name:    "in step",
+			files:   []*file{testFile("ada", "Ada Lovelace", "ada@example.com", "h1")},
+			entries: []db.AuthorCollectionEntry{testEntry("ada", 1, "h1")},
+			authors: []db.Author{ada},
+			members: []int64{1},
+		},
+		{
+			name:    "new file",
+			files:   []*file{testFile("ada", "Ada Lovelace", "ada@example.com", "h1")},
+			changes: []string{"create_author ada"},
+		},
+		{
+			name:    "new file of an existing author",
+			files:   []*file{testFile("ada", "Ada Lovelace", "ada@example.com", "h1")},
+			authors: []db.Author{ada},
+			changes: []string{"link ada"},
+		},
+		{
+			name:      "new file with an existing author's email",
+			files:     []*file{testFile("ada", "Ada", "ada@example.com", "h1")},
+			authors:   []db.Author{ada},
+			conflicts: []string{"ada: An author with this email address has different details."},
+		},
+		{
+			name: "new file with the email of another file's author",
+			files: []*file{
+				testFile("ada", "Ada Lovelace", "ada@example.com", "h1"),
+				testFile("countess", "Ada Lovelace", "ADA@example.com", "h2"),
+			},
+			entries:  []db.AuthorCollectionEntry{testEntry("ada", 1, "h1")},
+			authors:  []db.Author{ada},
+			members:  []int64{1},
+			problems: []string{"countess: Its email address belongs to the author of another file."},
+		},
+		{
+			name:    "file edited",
+			files:   []*file{testFile("ada", "Ada King", "ada@example.com", "h2")},
+			entries: []db.AuthorCollectionEntry{testEntry("ada", 1, "h1")},
+			authors: []db.Author{ada},
+			members: []int64{1},
+			changes: []string{"update_author ada"},
+		},
+		{
+			name:    "file deleted",
+			entries: []db.AuthorCollectionEntry{testEntry("ada", 1, "h1")},
+			authors: []db.Author{ada},
+			members: []int64{1},
+			changes: []string{"remove_author ada"},
+		},
+		{
+			name:    "unreadable file is not a deleted one",
+			invalid: []string{"ada"},
+			entries: []db.AuthorCollectionEntry{testEntry("ada", 1, "h1")},
+			authors: []db.Author{ada},
+			members: []int64{1},
+		},
+		{
+			name:    "author edited",
+			files:   []*file{testFile("ada", "Ada Lovelace", "ada@example.com", "h1")},
+			entries: []db.AuthorCollectionEntry{testEntry("ada", 1, "h1")},
+			authors: []db.Author{adaEdited},
+			members: []int64{1},
+			changes: []string{"write_file ada"},
+		},
+		{
+			name:    "author deleted",
+			files:   []*file{testFile("ada", "Ada Lovelace", "ada@example.com", "h1")},
+			entries: []db.AuthorCollectionEntry{testEntry("ada", 0, "h1")},
+			changes: []string{"delete_file ada"},
+		},
+		{
+			name:    "deleted on both sides",
+			entries: []db.AuthorCollectionEntry{testEntry("ada", 0, "h1")},
+			changes: []string{"forget ada"},
+		},
+		{
+			name:    "edited alike on both sides",
+			files:   []*file{testFile("ada", "Ada King", "ada@example.com", "h2")},
+			entries: []db.AuthorCollectionEntry{testEntry("ada", 1, "h1")},
+			authors: []db.Author{adaEdited},
+			members: []int64{1},
+			changes: []string{"link ada"},
+		},
+		{
+			name:      "file deleted, author edited",
+			entries:   []db.AuthorCollectionEntry{testEntry("ada", 1, "h1")},
+			authors:   []db.Author{adaEdited},
+			members:   []int64{1},
+			conflicts: []string{"ada: The file was deleted but the author was edited."},
+		},
+		{
+			name:      "author deleted, file edited",
+			files:     []*file{testFile("ada", "Ada King", "ada@example.com", "h2")},
+			entries:   []db.AuthorCollectionEntry{testEntry("ada", 0, "h1")},
+			conflicts: []string{"ada: The author was deleted but the file was edited."},
+		},
+		{
+			name:      "edited differently on both sides",
+			files:     []*file{testFile("ada", "Ada L.", "ada@example.com", "h2")},
+			entries:   []db.AuthorCollectionEntry{testEntry("ada", 1, "h1")},
+			authors:   []db.Author{adaEdited},
+			members:   []int64{1},
+			conflicts: []string{"ada: Both the file and the author were edited."},
+		},
+		{
+			name:    "added author gets a file with a free slug",
+			files:   []*file{testFile("grace-hopper", "Someone Else", "someone@example.com", "h1")},
+			entries: []db.AuthorCollectionEntry{testEntry("grace-hopper", 3, "h1")},
+			authors: []db.Author{grace, testAuthor(3, "Someone Else", "someone@example.com", syncedAt)},
+			members: []int64{2, 3},
+			changes: []string{"write_file grace-hopper-2"},
+		},
+		{
+			name:      "authors of other repositories are left alone",
+			authors:   []db.Author{ada, grace},
+			members:   []int64{2},
+			changes:   []string{"write_file grace-hopper"},
+			available: []int64{1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := &state{
+				files:   make(map[string]*file),
+				invalid: make(map[string]bool),
+				entries: make(map[string]db.AuthorCollectionEntry),
+				members: make(map[int64]bool),
+				authors: tt.authors,
+			}
+			for _, f := range tt.files {
+				st.files[f.Slug] = f
+			}
+			for _, slug := range tt.invalid {
+				st.invalid[slug] = true
+			}
+			for _, e := range tt.entries {
+				st.entries[e.Slug] = e
+			}
+			for _, id := range tt.members {
+				st.members[id] = true
+			}
+
+			p := st.plan()
+			var changes, conflicts, problems []string
+			var available []int64
+			for _, c := range p.Changes {
+				changes = append(changes, string(c.Kind)+" "+c.Slug)
+			}
+			for _, c := range p.Conflicts {
+				conflicts = append(conflicts, c.Slug+": "+c.Reason)
+			}
+			for _, pr := range p.Problems {
+				problems = append(problems, pr.Slug+": "+pr.Message)
+			}
+			for _, a := range p.Available {
+				available = append(available, a.ID)
+			}
+			if !slices.Equal(changes, tt.changes) {
+				t.Errorf("changes = %q, want %q", changes, tt.changes)
+			}
+			if !slices.Equal(conflicts, tt.conflicts) {
+				t.Errorf("conflicts = %q, want %q", conflicts, tt.conflicts)
+			}
+			if !slices.Equal(problems, tt.problems) {
+				t.Errorf("problems = %q, want %q", problems, tt.problems)
+			}
+			if !slices.Equal(available, tt.available) {
+				t.Errorf("available = %v, want %v", available, tt.available)
+			}
+		})
+	}
+}
@@ -0,0 +1,449 @@
+// Package authorsync keeps the authors table in step with a Starlight
+// authors content collection: one YAML data file per author, referenced by
+// file name from page frontmatter.
+package authorsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/gracchi-stdio/goaat/internal/author"
+	"github.com/gracchi-stdio/goaat/internal/content"
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+	"github.com/gracchi-stdio/goaat/internal/repository"
+	"github.com/gracchi-stdio/goaat/internal/workflow"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	// DefaultPath is where Starlight projects keep their authors collection.
+	DefaultPath = "src/content/authors"
+
+	// DefaultKey is the frontmatter field pages list their authors in.
+	DefaultKey = "authors"
+)
+
+var (
+	// ErrNotConfigured is returned for repositories without an authors collection.
+	ErrNotConfigured = errors.New("repository has no authors collection")
+
+	// ErrInvalidKey is returned for frontmatter keys that are not plain field names.
+	ErrInvalidKey = errors.New("invalid frontmatter key")
+
+	// ErrNoConflict is returned when resolving an entry that is not in conflict.
+	ErrNoConflict = errors.New("entry is not in conflict")
+
+	// ErrInvalidSide is returned when resolving a conflict in favour of neither side.
+	ErrInvalidSide = errors.New("keep the file or the author")
+
+	// ErrUnknownReference is returned for pages naming authors the collection lacks.
+	ErrUnknownReference = errors.New("unknown author reference")
+)
+
+// frontmatterKey matches the field names a key may be.
+var frontmatterKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// Side is the side of a conflict to keep.
+type Side string
+
+const (
+	// KeepFile copies the file into the authors table.
+	KeepFile Side = "file"
+
+	// KeepAuthor copies the author into the file.
+	KeepAuthor Side = "author"
+)
+
+// ReferenceError lists the authors a page names that the collection lacks.
+type ReferenceError struct {
+	Unknown []string
+}
+
+func (e *ReferenceError) Error() string {
+	return "unknown authors: " + strings.Join(e.Unknown, ", ")
+}
+
+func (e *ReferenceError) Unwrap() error {
+	return ErrUnknownReference
+}
+
+// Service syncs authors with the collections in repository working copies.
+type Service interface {
+	// Collection returns the repository's collection settings; ErrNotConfigured if it has none
+	Collection(ctx context.Context, repo db.Repository) (db.AuthorCollection, error)
+
+	// Configure turns the collection on, or moves it; moving starts over from a first sync
+	Configure(ctx context.Context, actor workflow.Actor, repo db.Repository, path, key string) (db.AuthorCollection, error)
+
+	// Disable stops syncing, leaving authors and files as they are
+	Disable(ctx context.Context, actor workflow.Actor, repo db.Repository) error
+
+	// Plan compares the collection with its authors without changing either
+	Plan(ctx context.Context, repo db.Repository) (*Plan, error)
+
+	// Sync applies the plan's changes, leaving conflicts; changes that fail are returned as problems
+	Sync(ctx context.Context, actor workflow.Actor, repo db.Repository) (*Plan, error)
+
+	// Add lists an author in the collection; the next sync writes their file
+	Add(ctx context.Context, actor workflow.Actor, repo db.Repository, authorID int64) error
+
+	// Resolve settles a conflict by copying one side over the other
+	Resolve(ctx context.Context, actor workflow.Actor, repo db.Repository, slug string, keep Side) error
+
+	// Entries lists the collection's authors by name, for pages to reference
+	Entries(ctx context.Context, repo db.Repository) ([]Entry, error)
+
+	// CheckReferences returns a *ReferenceError if a content file names authors the collection lacks
+	CheckReferences(ctx context.Context, repo db.Repository, src []byte) error
+}
+
+type service struct {
+	queries  db.Querier
+	authors  author.Service
+	reposDir string
+}
+
+// NewService creates an author sync service over the clones in reposDir.
+// Authors are written through the author service, so its rules apply.
+func NewService(queries db.Querier, authors author.Service, reposDir string) Service {
+	return &service{queries: queries, authors: authors, reposDir: reposDir}
+}
+
+func (s *service) Collection(ctx context.Context, repo db.Repository) (db.AuthorCollection, error) {
+	collection, err := s.queries.GetAuthorCollection(ctx, repo.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return db.AuthorCollection{}, ErrNotConfigured
+	}
+	if err != nil {
+		return db.AuthorCollection{}, fmt.Errorf("failed to load authors collection: %w", err)
+	}
+	return collection, nil
+}
+
+func (s *service) Configure(ctx context.Context, actor workflow.Actor, repo db.Repository, path, key string) (db.AuthorCollection, error) {
+	if !actor.CanManage() {
+		return db.AuthorCollection{}, workflow.ErrForbidden
+	}
+	path = strings.Trim(strings.TrimSpace(path), "/")
+	key = strings.TrimSpace(key)
+	if _, err := content.SafeJoin(repository.Dir(s.reposDir, repo.ID), path); err != nil {
+		return db.AuthorCollection{}, err
+	}
+	if !frontmatterKey.MatchString(key) {
+		return db.AuthorCollection{}, ErrInvalidKey
+	}
+
+	// The last sync's entries describe files at the old path
+	current, err := s.Collection(ctx, repo)
+	if err == nil && current.Path != path {
+		if err := s.queries.DeleteAuthorCollection(ctx, repo.ID); err != nil {
+			return db.AuthorCollection{}, fmt.Errorf("failed to move authors collection: %w", err)
+		}
+	} else if err != nil && !errors.Is(err, ErrNotConfigured) {
+		return db.AuthorCollection{}, err
+	}
+
+	collection, err := s.queries.UpsertAuthorCollection(ctx, db.UpsertAuthorCollectionParams{RepositoryID: repo.ID, Path: path, FrontmatterKey: key})
+	if err != nil {
+		return db.AuthorCollection{}, fmt.Errorf("failed to save authors collection: %w", err)
+	}
+	return collection, nil
+}
+
+func (s *service) Disable(ctx context.Context, actor workflow.Actor, repo db.Repository) error {
+	if !actor.CanManage() {
+		return workflow.ErrForbidden
+	}
+	if err := s.queries.DeleteAuthorCollection(ctx, repo.ID); err != nil {
+		return fmt.Errorf("failed to disable authors collection: %w", err)
+	}
+	return nil
+}
+
+func (s *service) Plan(ctx context.Context, repo db.Repository) (*Plan, error) {
+	st, err := s.load(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	return st.plan(), nil
+}
+
+func (s *service) Sync(ctx context.Context, actor workflow.Actor, repo db.Repository) (*Plan, error) {
+	if !actor.CanEdit() {
+		return nil, workflow.ErrForbidden
+	}
+	st, err := s.load(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := st.plan()
+	done := &Plan{Conflicts: plan.Conflicts, Problems: plan.Problems}
+	for _, change := range plan.Changes {
+		err := s.apply(ctx, st, change)
+		if message, ok := problemMessage(err); ok {
+			done.Problems = append(done.Problems, Problem{Slug: change.Slug, Message: message})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		done.Changes = append(done.Changes, change)
+	}
+
+	if err := s.queries.MarkAuthorCollectionSynced(ctx, repo.ID); err != nil {
+		return nil, fmt.Errorf("failed to record sync: %w", err)
+	}
+	return done, nil
+}
+
+func (s *service) Add(ctx context.Context, actor workflow.Actor, repo db.Repository, authorID int64) error {
+	if !actor.CanEdit() {
+		return workflow.ErrForbidden
+	}
+	if _, err := s.Collection(ctx, repo); err != nil {
+		return err
+	}
+	if _, err := s.authors.Get(ctx, authorID); err != nil {
+		return err
+	}
+	if err := s.queries.AddAuthorCollectionMember(ctx, db.AddAuthorCollectionMemberParams{RepositoryID: repo.ID, AuthorID: authorID}); err != nil {
+		return fmt.Errorf("failed to add author to collection: %w", err)
+	}
+	return nil
+}
+
+func (s *service) Resolve(ctx context.Context, actor workflow.Actor, repo db.Repository, slug string, keep Side) error {
+	if !actor.CanEdit() {
+		return workflow.ErrForbidden
+	}
+	st, err := s.load(ctx, repo)
+	if err != nil {
+		return err
+	}
+	conflict, ok := st.plan().conflictOver(slug)
+	if !ok {
+		return ErrNoConflict
+	}
+
+	change := Change{Slug: slug, Author: conflict.Author, File: conflict.File, file: conflict.file}
+	switch {
+	case keep == KeepFile && conflict.file == nil:
+		change.Kind = RemoveAuthor
+	case keep == KeepFile && conflict.Author == nil:
+		change.Kind = CreateAuthor
+	case keep == KeepFile:
+		change.Kind = UpdateAuthor
+	case keep == KeepAuthor && conflict.Author == nil:
+		change.Kind = DeleteFile
+	case keep == KeepAuthor:
+		change.Kind = WriteFile
+	default:
+		return ErrInvalidSide
+	}
+	return s.apply(ctx, st, change)
+}
+
+func (s *service) Entries(ctx context.Context, repo db.Repository) ([]Entry, error) {
+	collection, err := s.Collection(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	files, _, _, err := readFiles(repository.Dir(s.reposDir, repo.ID), collection.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(files))
+	for _, f := range files {
+		entries = append(entries, f.Entry)
+	}
+	slices.SortFunc(entries, func(a, b Entry) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+	return entries, nil
+}
+
+func (s *service) CheckReferences(ctx context.Context, repo db.Repository, src []byte) error {
+	collection, err := s.Collection(ctx, repo)
+	if errors.Is(err, ErrNotConfigured) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// Unparseable frontmatter is for the build to report, not this check
+	doc, err := content.Parse("", src)
+	if err != nil {
+		return nil
+	}
+	refs := References(doc.Frontmatter, collection.FrontmatterKey)
+	if len(refs) == 0 {
+		return nil
+	}
+
+	files, invalid, _, err := readFiles(repository.Dir(s.reposDir, repo.ID), collection.Path)
+	if err != nil {
+		return err
+	}
+	var unknown []string
+	for _, slug := range refs {
+		if files[slug] == nil && !invalid[slug] && !slices.Contains(unknown, slug) {
+			unknown = append(unknown, slug)
+		}
+	}
+	if len(unknown) > 0 {
+		return &ReferenceError{Unknown: unknown}
+	}
+	return nil
+}
+
+// load reads both sides of a collection and the last sync's entries.
+func (s *service) load(ctx context.Context, repo db.Repository) (*state, error) {
+	collection, err := s.Collection(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	st := &state{collection: collection, root: repository.Dir(s.reposDir, repo.ID)}
+	if st.files, st.invalid, st.problems, err = readFiles(st.root, collection.Path); err != nil {
+		return nil, err
+	}
+
+	entries, err := s.queries.ListAuthorCollectionEntries(ctx, repo.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collection entries: %w", err)
+	}
+	st.entries = make(map[string]db.AuthorCollectionEntry, len(entries))
+	for _, e := range entries {
+		st.entries[e.Slug] = e
+	}
+
+	members, err := s.queries.ListAuthorCollectionMembers(ctx, repo.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collection members: %w", err)
+	}
+	st.members = make(map[int64]bool, len(members))
+	for _, id := range members {
+		st.members[id] = true
+	}
+
+	for after := ""; ; {
+		page, err := s.authors.List(ctx, author.Query{After: after, Limit: author.MaxPageSize})
+		if err != nil {
+			return nil, err
+		}
+		st.authors = append(st.authors, page.Authors...)
+		if page.Next == "" {
+			break
+		}
+		after = page.Next
+	}
+	return st, nil
+}
+
+// apply makes one change and records the entry as both sides now have it.
+func (s *service) apply(ctx context.Context, st *state, change Change) error {
+	switch change.Kind {
+	case CreateAuthor:
+		a, err := s.authors.Create(ctx, author.Input{Name: change.file.Name, Email: change.file.Email})
+		if err != nil {
+			return err
+		}
+		return s.record(ctx, st, change.Slug, a, change.file.Hash)
+
+	case UpdateAuthor:
+		in := author.Input{Name: change.file.Name, Email: change.file.Email, UserID: change.Author.UserID.Int64}
+		a, err := s.authors.Update(ctx, change.Author.ID, in)
+		if err != nil {
+			return err
+		}
+		return s.record(ctx, st, change.Slug, a, change.file.Hash)
+
+	case RemoveAuthor:
+		err := s.queries.DeleteAuthorCollectionMember(ctx, db.DeleteAuthorCollectionMemberParams{RepositoryID: st.collection.RepositoryID, AuthorID: change.Author.ID})
+		if err != nil {
+			return fmt.Errorf("failed to remove author from collection: %w", err)
+		}
+		return s.forget(ctx, st, change.Slug)
+
+	case WriteFile:
+		path, src, baseHash := entryPath(st.collection.Path, change.Slug), []byte(nil), ""
+		if change.file != nil {
+			path, src, baseHash = change.file.Path, change.file.Src, change.file.Hash
+		}
+		out, err := render(src, change.Author)
+		if err != nil {
+			return err
+		}
+		hash, err := content.WriteFile(st.root, path, out, baseHash)
+		if err != nil {
+			return err
+		}
+		return s.record(ctx, st, change.Slug, *change.Author, hash)
+
+	case DeleteFile:
+		if err := removeFile(st.root, change.file); err != nil {
+			return err
+		}
+		return s.forget(ctx, st, change.Slug)
+
+	case Link:
+		return s.record(ctx, st, change.Slug, *change.Author, change.file.Hash)
+
+	case Forget:
+		return s.forget(ctx, st, change.Slug)
+	}
+	return fmt.Errorf("unknown change %q", change.Kind)
+}
+
+// record stores an entry as the base the next sync compares against, and
+// lists its author in the collection.
+func (s *service) record(ctx context.Context, st *state, slug string, a db.Author, fileHash string) error {
+	err := s.queries.AddAuthorCollectionMember(ctx, db.AddAuthorCollectionMemberParams{RepositoryID: st.collection.RepositoryID, AuthorID: a.ID})
+	if err != nil {
+		return fmt.Errorf("failed to add author to collection: %w", err)
+	}
+	err = s.queries.UpsertAuthorCollectionEntry(ctx, db.UpsertAuthorCollectionEntryParams{
+		RepositoryID:    st.collection.RepositoryID,
+		Slug:            slug,
+		AuthorID:        pgtype.Int8{Int64: a.ID, Valid: true},
+		FileHash:        fileHash,
+		AuthorUpdatedAt: a.UpdatedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record collection entry: %w", err)
+	}
+	return nil
+}
+
+// forget drops an entry that is gone from both sides.
+func (s *service) forget(ctx context.Context, st *state, slug string) error {
+	err := s.queries.DeleteAuthorCollectionEntry(ctx, db.DeleteAuthorCollectionEntryParams{RepositoryID: st.collection.RepositoryID, Slug: slug})
+	if err != nil {
+		return fmt.Errorf("failed to forget collection entry: %w", err)
+	}
+	return nil
+}
+
+// problemMessage describes the errors a change can fail with that are the
+// collection's to fix, like a file with an email address already taken.
+func problemMessage(err error) (string, bool) {
+	switch {
+	case errors.Is(err, author.ErrNoName):
+		return "The file has no name.", true
+	case errors.Is(err, author.ErrInvalidEmail):
+		return "The file's email address is not valid.", true
+	case errors.Is(err, author.ErrEmailTaken):
+		return "Another author already has the file's email address.", true
+	case errors.Is(err, content.ErrConflict):
+		return "The file changed during the sync; sync again.", true
+	}
+	return "", false
+}
@@ -0,0 +1,131 @@
+package authorsync
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/gracchi-stdio/goaat/internal/author"
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+	"github.com/gracchi-stdio/goaat/internal/workflow"
+	"github.com/jackc/pgx/v5"
+)
+
+// fakeQueries has the authors collection of repository 1, its entries and
+// its members.
+type fakeQueries struct {
+	db.Querier
+	entries []db.AuthorCollectionEntry
+	members []int64
+}
+
+func (*fakeQueries) GetAuthorCollection(ctx context.Context, repositoryID int64) (db.AuthorCollection, error) {
+	if repositoryID != 1 {
+		return db.AuthorCollection{}, pgx.ErrNoRows
+	}
+	return db.AuthorCollection{RepositoryID: 1, Path: DefaultPath, FrontmatterKey: DefaultKey}, nil
+}
+
+func TestCheckReferences(t *testing.T) {
+	reposDir := t.TempDir()
+	dir := filepath.Join(reposDir, "1", filepath.FromSlash(DefaultPath))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range map[string]string{
+		"ada.yml":    "name: Ada Lovelace\nemail: ada@example.com\n",
+		"broken.yml": "name: [\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := NewService(&fakeQueries{}, nil, reposDir)
+
+	tests := []struct {
+		name    string
+		repoID  int64
+		src     string
+		unknown []string
+	}{
+		{"known author", 1, "---\nauthors: ada\n---\n", nil},
+		{"unknown authors, once each", 1, "---\nauthors: [ada, grace, alan, grace]\n---\n", []string{"grace", "alan"}},
+		{"unreadable file is not reported", 1, "---\nauthors: [broken]\n---\n", nil},
+		{"no byline", 1, "---\ntitle: Intro\n---\n", nil},
+		{"unparseable frontmatter is left to the build", 1, "---\nauthors: [grace\n---\n", nil},
+		{"repository without a collection", 2, "---\nauthors: grace\n---\n", nil},
+	}
+	for _, tt := range tests {
+		err := s.CheckReferences(context.Background(), db.Repository{ID: tt.repoID}, []byte(tt.src))
+		var refErr *ReferenceError
+		switch {
+		case tt.unknown == nil && err != nil:
+			t.Errorf("%s: CheckReferences = %v, want nil", tt.name, err)
+		case tt.unknown != nil && !errors.As(err, &refErr):
+			t.Errorf("%s: CheckReferences = %v, want a ReferenceError", tt.name, err)
+		case tt.unknown != nil && !slices.Equal(refErr.Unknown, tt.unknown):
+			t.Errorf("%s: unknown = %q, want %q", tt.name, refErr.Unknown, tt.unknown)
+		case tt.unknown != nil && !errors.Is(err, ErrUnknownReference):
+			t.Errorf("%s: CheckReferences = %v, want it to wrap ErrUnknownReference", tt.name, err)
+		}
+	}
+}
+
+func (f *fakeQueries) ListAuthorCollectionEntries(ctx context.Context, repositoryID int64) ([]db.AuthorCollectionEntry, error) {
+	return f.entries, nil
+}
+
+func (f *fakeQueries) ListAuthorCollectionMembers(ctx context.Context, repositoryID int64) ([]int64, error) {
+	return f.members, nil
+}
+
+func (f *fakeQueries) DeleteAuthorCollectionMember(ctx context.Context, arg db.DeleteAuthorCollectionMemberParams) error {
+	f.members = slices.DeleteFunc(f.members, func(id int64) bool { return id == arg.AuthorID })
+	return nil
+}
+
+func (f *fakeQueries) DeleteAuthorCollectionEntry(ctx context.Context, arg db.DeleteAuthorCollectionEntryParams) error {
+	f.entries = slices.DeleteFunc(f.entries, func(e db.AuthorCollectionEntry) bool { return e.Slug == arg.Slug })
+	return nil
+}
+
+func (f *fakeQueries) MarkAuthorCollectionSynced(ctx context.Context, repositoryID int64) error {
+	return nil
+}
+
+// fakeAuthors lists authors and fails the test if one is deleted.
+type fakeAuthors struct {
+	author.Service
+	t       *testing.T
+	authors []db.Author
+}
+
+func (f fakeAuthors) List(ctx context.Context, q author.Query) (author.Page, error) {
+	return author.Page{Authors: f.authors}, nil
+}
+
+func (f fakeAuthors) Delete(ctx context.Context, id int64) error {
+	f.t.Errorf("author %d was deleted", id)
+	return nil
+}
+
+func TestSyncKeepsAuthorsOfDeletedFiles(t *testing.T) {
+	ada := testAuthor(1, "Ada Lovelace", "ada@example.com", syncedAt)
+	queries := &fakeQueries{entries: []db.AuthorCollectionEntry{testEntry("ada", 1, "h1")}, members: []int64{1}}
+	s := NewService(queries, fakeAuthors{t: t, authors: []db.Author{ada}}, t.TempDir())
+
+	owner := workflow.Actor{UserID: 1, Role: workflow.RoleOwner}
+	done, err := s.Sync(context.Background(), owner, db.Repository{ID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(done.Changes) != 1 || done.Changes[0].Kind != RemoveAuthor {
+		t.Fatalf("changes = %+v, want the author removed from the collection", done.Changes)
+	}
+	if len(queries.members) != 0 || len(queries.entries) != 0 {
+		t.Errorf("members %v and entries %+v remain, want neither", queries.members, queries.entries)
+	}
+}
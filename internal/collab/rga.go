@@ -0,0 +1,172 @@
+package collab
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// The document model is a Replicated Growable Array (RGA): every character
+// carries a unique ID and is inserted after the character it followed when
+// typed. Concurrent inserts after the same character are ordered by ID, and
+// deletions only tombstone, so replicas that apply the same operations in
+// any causal order converge to the same text.
+
+// ErrInvalidOp is returned for operations that cannot apply to a document.
+var ErrInvalidOp = errors.New("invalid operation")
+
+// baseSite is the site of the characters a document starts with.
+const baseSite = "base"
+
+// ID identifies a character. Counter is a Lamport clock and Site the
+// client that created it.
+type ID struct {
+	Counter uint64 `json:"c"`
+	Site    string `json:"s"`
+}
+
+// IsZero reports whether id is the document head.
+func (id ID) IsZero() bool {
+	return id.Counter == 0 && id.Site == ""
+}
+
+// after orders concurrent siblings: higher counters first, then higher sites.
+func (id ID) after(other ID) bool {
+	if id.Counter != other.Counter {
+		return id.Counter > other.Counter
+	}
+	return id.Site > other.Site
+}
+
+// OpType is the kind of a document operation.
+type OpType string
+
+const (
+	OpInsert OpType = "ins"
+	OpDelete OpType = "del"
+)
+
+// Op inserts one character after another (the zero ID is the head) or
+// deletes a character.
+type Op struct {
+	Type  OpType `json:"t"`
+	ID    ID     `json:"id"`
+	After ID     `json:"after"`
+	Value string `json:"v,omitempty"`
+}
+
+// Element is a character of a document, kept after deletion as a tombstone
+// because later operations may still refer to it.
+type Element struct {
+	ID      ID     `json:"id"`
+	Value   string `json:"v"`
+	Deleted bool   `json:"d,omitempty"`
+}
+
+// Doc is an RGA text document.
+type Doc struct {
+	elems []Element
+	index map[ID]struct{}
+	clock uint64
+}
+
+// NewDoc creates a document holding text, with deterministic IDs so every
+// replica can build the same starting state.
+func NewDoc(text string) *Doc {
+	d := &Doc{index: make(map[ID]struct{})}
+	var counter uint64
+	for _, r := range text {
+		counter++
+		id := ID{Counter: counter, Site: baseSite}
+		d.elems = append(d.elems, Element{ID: id, Value: string(r)})
+		d.index[id] = struct{}{}
+	}
+	d.clock = counter
+	return d
+}
+
+// Apply integrates an operation. Operations must arrive in causal order:
+// an insert after the character it references, a delete after the insert.
+func (d *Doc) Apply(op Op) error {
+	if err := d.Validate(op); err != nil {
+		return err
+	}
+	if op.Type == OpDelete {
+		d.elems[d.position(op.ID)].Deleted = true
+		return nil
+	}
+
+	pos := 0
+	if !op.After.IsZero() {
+		pos = d.position(op.After) + 1
+	}
+	// Skip newer siblings (and their descendants, whose counters are higher still)
+	for pos < len(d.elems) && d.elems[pos].ID.after(op.ID) {
+		pos++
+	}
+
+	d.elems = append(d.elems, Element{})
+	copy(d.elems[pos+1:], d.elems[pos:])
+	d.elems[pos] = Element{ID: op.ID, Value: op.Value}
+	d.index[op.ID] = struct{}{}
+	d.clock = max(d.clock, op.ID.Counter)
+	return nil
+}
+
+// Validate reports whether op can be applied, without changing the document.
+func (d *Doc) Validate(op Op) error {
+	switch op.Type {
+	case OpInsert:
+		if op.ID.Counter == 0 || op.ID.Site == "" || op.ID.Site == baseSite {
+			return fmt.Errorf("%w: bad id %v", ErrInvalidOp, op.ID)
+		}
+		if utf8.RuneCountInString(op.Value) != 1 {
+			return fmt.Errorf("%w: value must be one character", ErrInvalidOp)
+		}
+		if _, exists := d.index[op.ID]; exists {
+			return fmt.Errorf("%w: duplicate id %v", ErrInvalidOp, op.ID)
+		}
+		if _, ok := d.index[op.After]; !ok && !op.After.IsZero() {
+			return fmt.Errorf("%w: unknown reference %v", ErrInvalidOp, op.After)
+		}
+	case OpDelete:
+		if _, ok := d.index[op.ID]; !ok {
+			return fmt.Errorf("%w: unknown id %v", ErrInvalidOp, op.ID)
+		}
+	default:
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidOp, op.Type)
+	}
+	return nil
+}
+
+// position returns the index of a known element.
+func (d *Doc) position(id ID) int {
+	for i := range d.elems {
+		if d.elems[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// Text returns the visible text.
+func (d *Doc) Text() string {
+	var b strings.Builder
+	for _, e := range d.elems {
+		if !e.Deleted {
+			b.WriteString(e.Value)
+		}
+	}
+	return b.String()
+}
+
+// Elements returns a copy of every element in document order, tombstones included.
+func (d *Doc) Elements() []Element {
+	return append([]Element(nil), d.elems...)
+}
+
+// Clock returns the highest counter seen, for Lamport timestamps of new inserts.
+func (d *Doc) Clock() uint64 {
+	return d.clock
+}
@@ -0,0 +1,216 @@
+package collab
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+// message is an operation broadcast by a simulated client, with the
+// vector clock of what its sender had seen so it is delivered causally.
+type message struct {
+	op   Op
+	site int
+	deps []int // operations seen from each site, the sender's own included
+}
+
+// replica is a simulated client: its document and what it has applied.
+type replica struct {
+	site    string
+	doc     *Doc
+	seen    []int      // operations applied from each site
+	pending []*message // received but not yet deliverable
+}
+
+// ready reports whether every operation m depends on has been applied.
+func (r *replica) ready(m *message) bool {
+	for site, n := range m.deps {
+		if site == m.site {
+			if r.seen[site] != n-1 {
+				return false
+			}
+		} else if r.seen[site] < n {
+			return false
+		}
+	}
+	return true
+}
+
+// deliver applies up to n random deliverable pending operations, in the
+// order they are picked rather than the order they were sent.
+func (r *replica) deliver(t *testing.T, rng *rand.Rand, n int) {
+	for ; n > 0; n-- {
+		var ready []int
+		for i, m := range r.pending {
+			if r.ready(m) {
+				ready = append(ready, i)
+			}
+		}
+		if len(ready) == 0 {
+			return
+		}
+		i := ready[rng.IntN(len(ready))]
+		m := r.pending[i]
+		r.pending = slices.Delete(r.pending, i, i+1)
+		if err := r.doc.Apply(m.op); err != nil {
+			t.Fatalf("%s: apply %+v: %v", r.site, m.op, err)
+		}
+		r.seen[m.site]++
+	}
+}
+
+// edit makes a random local insert or delete, the way an editor would:
+// inserting after a visible character, or the head, and deleting a visible
+// one.
+func (r *replica) edit(rng *rand.Rand) Op {
+	var visible []ID
+	for _, e := range r.doc.Elements() {
+		if !e.Deleted {
+			visible = append(visible, e.ID)
+		}
+	}
+
+	if len(visible) > 0 && rng.IntN(3) == 0 {
+		return Op{Type: OpDelete, ID: visible[rng.IntN(len(visible))]}
+	}
+	op := Op{
+		Type:  OpInsert,
+		ID:    ID{Counter: r.doc.Clock() + 1, Site: r.site},
+		Value: string(rune('a' + rng.IntN(26))),
+	}
+	if at := rng.IntN(len(visible) + 1); at > 0 {
+		op.After = visible[at-1]
+	}
+	return op
+}
+
+func TestRGAConvergence(t *testing.T) {
+	for seed := range uint64(200) {
+		t.Run(fmt.Sprint(seed), func(t *testing.T) {
+			rng := rand.New(rand.NewPCG(seed, seed*7919))
+			clients := 2 + rng.IntN(4)
+			base := []string{"", "hello", "line one\nline two"}[rng.IntN(3)]
+
+			replicas := make([]*replica, clients)
+			for i := range replicas {
+				replicas[i] = &replica{site: fmt.Sprintf("client-%d", i), doc: NewDoc(base), seen: make([]int, clients)}
+			}
+
+			for range 10 + rng.IntN(20) {
+				// Some clients type, the others read some of what arrived
+				for i, r := range replicas {
+					if rng.IntN(2) == 0 {
+						r.deliver(t, rng, rng.IntN(5))
+						continue
+					}
+					for range 1 + rng.IntN(4) {
+						op := r.edit(rng)
+						if err := r.doc.Apply(op); err != nil {
+							t.Fatalf("%s: local %+v: %v", r.site, op, err)
+						}
+						r.seen[i]++
+						m := &message{op: op, site: i, deps: slices.Clone(r.seen)}
+						for j, other := range replicas {
+							if j != i {
+								other.pending = append(other.pending, m)
+							}
+						}
+					}
+				}
+			}
+
+			// Deliver the rest; causal delivery can always make progress
+			for _, r := range replicas {
+				r.deliver(t, rng, len(r.pending))
+				if len(r.pending) != 0 {
+					t.Fatalf("%s: %d operations never became deliverable", r.site, len(r.pending))
+				}
+			}
+
+			want := replicas[0].doc.Elements()
+			for _, r := range replicas[1:] {
+				if got := r.doc.Text(); got != replicas[0].doc.Text() {
+					t.Fatalf("replicas diverged:\n%s: %q\n%s: %q", replicas[0].site, replicas[0].doc.Text(), r.site, got)
+				}
+				if !slices.Equal(r.doc.Elements(), want) {
+					t.Fatalf("%s: elements differ from %s", r.site, replicas[0].site)
+				}
+			}
+		})
+	}
+}
+
+// Concurrent inserts at the same place keep each client's run together and
+// order the runs the same way everywhere.
+func TestRGAConcurrentInsertsAtSamePlace(t *testing.T) {
+	typeRun := func(site, text string, after ID, clock uint64) []Op {
+		var ops []Op
+		for _, r := range text {
+			clock++
+			id := ID{Counter: clock, Site: site}
+			ops = append(ops, Op{Type: OpInsert, ID: id, After: after, Value: string(r)})
+			after = id
+		}
+		return ops
+	}
+	// Both start typing after "ab" having seen only the base text
+	clock := NewDoc("abc").Clock()
+	b := ID{Counter: 2, Site: baseSite}
+	alice := typeRun("alice", "XY", b, clock)
+	bob := typeRun("bob", "12", b, clock)
+
+	orders := [][]Op{
+		slices.Concat(alice, bob),
+		slices.Concat(bob, alice),
+		{alice[0], bob[0], bob[1], alice[1]},
+	}
+	var texts []string
+	for _, ops := range orders {
+		d := NewDoc("abc")
+		for _, op := range ops {
+			if err := d.Apply(op); err != nil {
+				t.Fatal(err)
+			}
+		}
+		texts = append(texts, d.Text())
+	}
+	for _, text := range texts {
+		if text != texts[0] {
+			t.Fatalf("orders produced different texts: %q", texts)
+		}
+	}
+	// Equal counters: the higher site, bob, goes first
+	if texts[0] != "ab12XYc" {
+		t.Fatalf("text = %q, want %q", texts[0], "ab12XYc")
+	}
+}
+
+func TestRGAValidate(t *testing.T) {
+	d := NewDoc("ab")
+	a := ID{Counter: 1, Site: baseSite}
+	tests := []struct {
+		name string
+		op   Op
+		ok   bool
+	}{
+		{"insert after head", Op{Type: OpInsert, ID: ID{3, "x"}, Value: "c"}, true},
+		{"insert after character", Op{Type: OpInsert, ID: ID{3, "x"}, After: a, Value: "c"}, true},
+		{"delete", Op{Type: OpDelete, ID: a}, true},
+		{"zero id", Op{Type: OpInsert, Value: "c"}, false},
+		{"base site", Op{Type: OpInsert, ID: ID{3, baseSite}, Value: "c"}, false},
+		{"duplicate id", Op{Type: OpInsert, ID: a, Value: "c"}, false},
+		{"two characters", Op{Type: OpInsert, ID: ID{3, "x"}, Value: "cd"}, false},
+		{"unknown reference", Op{Type: OpInsert, ID: ID{3, "x"}, After: ID{9, "y"}, Value: "c"}, false},
+		{"delete unknown", Op{Type: OpDelete, ID: ID{9, "y"}}, false},
+		{"unknown type", Op{Type: "move", ID: a}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := d.Validate(tt.op)
+			if (err == nil) != tt.ok {
+				t.Fatalf("Validate = %v, want ok=%v", err, tt.ok)
+			}
+		})
+	}
+}
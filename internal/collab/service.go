@@ -0,0 +1,353 @@
+package collab
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/gracchi-stdio/goaat/internal/content"
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+	"github.com/gracchi-stdio/goaat/internal/repository"
+	"github.com/gracchi-stdio/goaat/internal/search"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrUnknownDocument is returned for sessions that do not exist in the repository.
+var ErrUnknownDocument = errors.New("unknown collaborative document")
+
+// appendRetries bounds how often an append is retried after losing a
+// sequence number to another replica.
+const appendRetries = 5
+
+// Session is a co-editing session on a file.
+type Session struct {
+	ID       int64
+	Path     string
+	FileHash string
+}
+
+// Snapshot is a document's full state at a point in its op log.
+type Snapshot struct {
+	Seq      int64     `json:"seq"`
+	Elements []Element `json:"elements"`
+}
+
+// Batch is a run of consecutive operations from the op log.
+type Batch struct {
+	Seq int64 `json:"seq"` // Sequence number of the last operation
+	Ops []Op  `json:"ops"`
+}
+
+// Service defines collaborative editing sessions over content files.
+type Service interface {
+	// Open joins the session on the file's current version, starting one if needed
+	Open(ctx context.Context, repo db.Repository, path string) (*Session, error)
+
+	// Session looks up a session of the repository
+	Session(ctx context.Context, repo db.Repository, sessionID int64) (*Session, error)
+
+	// Snapshot returns the session's document with its latest sequence number
+	Snapshot(ctx context.Context, repo db.Repository, sessionID int64) (*Snapshot, error)
+
+	// Ops returns the operations logged after seq
+	Ops(ctx context.Context, repo db.Repository, sessionID, seq int64) (*Batch, error)
+
+	// Apply validates operations against the document and appends them to the log
+	Apply(ctx context.Context, userID int64, repo db.Repository, sessionID int64, ops []Op) error
+
+	// Save writes the document text to the file and returns the new file hash
+	Save(ctx context.Context, repo db.Repository, sessionID int64) (string, error)
+
+	// Join keeps the session's document in memory while a client streams
+	// it; Leave lets it go once the last client has left
+	Join(ctx context.Context, repo db.Repository, sessionID int64) error
+	Leave(sessionID int64)
+}
+
+// state is this replica's replay of a session's op log. It is only a cache:
+// every operation is in the log until the session is saved, so a state can
+// be dropped whenever nobody uses it and replayed again later.
+type state struct {
+	mu  sync.Mutex
+	doc *Doc
+	seq int64
+
+	users int // streams and requests holding the state; guarded by service.mu
+}
+
+type service struct {
+	queries  db.Querier
+	reposDir string
+	search   search.Service
+
+	mu     sync.Mutex
+	states map[int64]*state
+}
+
+// NewService creates a collaborative editing service over the clones in
+// reposDir. searchService may be nil; when set, saved files are re-indexed.
+func NewService(queries db.Querier, reposDir string, searchService search.Service) Service {
+	return &service{
+		queries:  queries,
+		reposDir: reposDir,
+		search:   searchService,
+		states:   make(map[int64]*state),
+	}
+}
+
+func (s *service) contentDir(repo db.Repository) string {
+	return filepath.Join(repository.Dir(s.reposDir, repo.ID), filepath.FromSlash(repo.ContentPath))
+}
+
+func (s *service) Open(ctx context.Context, repo db.Repository, path string) (*Session, error) {
+	body, hash, err := content.ReadFile(s.contentDir(repo), path)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := s.queries.OpenCollabDocument(ctx, db.OpenCollabDocumentParams{
+		RepositoryID: repo.ID,
+		Path:         path,
+		FileHash:     hash,
+		BaseBody:     string(body),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open collaborative session: %w", err)
+	}
+	return &Session{ID: doc.ID, Path: doc.Path, FileHash: doc.FileHash}, nil
+}
+
+func (s *service) Session(ctx context.Context, repo db.Repository, sessionID int64) (*Session, error) {
+	doc, err := s.document(ctx, repo, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return &Session{ID: doc.ID, Path: doc.Path, FileHash: doc.FileHash}, nil
+}
+
+func (s *service) Snapshot(ctx context.Context, repo db.Repository, sessionID int64) (*Snapshot, error) {
+	st, err := s.acquire(ctx, repo, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer s.release(sessionID, st)
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if err := s.catchUp(ctx, sessionID, st); err != nil {
+		return nil, err
+	}
+	return &Snapshot{Seq: st.seq, Elements: st.doc.Elements()}, nil
+}
+
+func (s *service) Ops(ctx context.Context, repo db.Repository, sessionID, seq int64) (*Batch, error) {
+	if _, err := s.document(ctx, repo, sessionID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.queries.ListCollabOps(ctx, db.ListCollabOpsParams{DocumentID: sessionID, Seq: seq})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read op log: %w", err)
+	}
+
+	batch := &Batch{Seq: seq, Ops: make([]Op, 0, len(rows))}
+	for _, row := range rows {
+		var op Op
+		if err := json.Unmarshal(row.Op, &op); err != nil {
+			return nil, fmt.Errorf("failed to decode op %d: %w", row.Seq, err)
+		}
+		batch.Ops = append(batch.Ops, op)
+		batch.Seq = row.Seq
+	}
+	return batch, nil
+}
+
+func (s *service) Apply(ctx context.Context, userID int64, repo db.Repository, sessionID int64, ops []Op) error {
+	st, err := s.acquire(ctx, repo, sessionID)
+	if err != nil {
+		return err
+	}
+	defer s.release(sessionID, st)
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	for _, op := range ops {
+		if err := s.append(ctx, userID, sessionID, st, op); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// append logs one operation under the next sequence number. Another replica
+// may take that number first; then the log is replayed and the operation
+// validated again against the newer document.
+func (s *service) append(ctx context.Context, userID, sessionID int64, st *state, op Op) error {
+	payload, err := json.Marshal(op)
+	if err != nil {
+		return fmt.Errorf("failed to encode op: %w", err)
+	}
+
+	for attempt := 0; attempt < appendRetries; attempt++ {
+		if err := s.catchUp(ctx, sessionID, st); err != nil {
+			return err
+		}
+
+		if err := st.doc.Validate(op); err != nil {
+			return err
+		}
+
+		err := s.queries.InsertCollabOp(ctx, db.InsertCollabOpParams{
+			DocumentID: sessionID,
+			Seq:        st.seq + 1,
+			UserID:     userID,
+			Op:         payload,
+		})
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to append op: %w", err)
+		}
+
+		st.seq++
+		return st.doc.Apply(op)
+	}
+	return fmt.Errorf("failed to append op: too much contention")
+}
+
+func (s *service) Save(ctx context.Context, repo db.Repository, sessionID int64) (string, error) {
+	doc, err := s.document(ctx, repo, sessionID)
+	if err != nil {
+		return "", err
+	}
+
+	snapshot, err := s.Snapshot(ctx, repo, sessionID)
+	if err != nil {
+		return "", err
+	}
+	text := textOf(snapshot.Elements)
+
+	hash, err := content.WriteFile(s.contentDir(repo), doc.Path, []byte(text), doc.FileHash)
+	if err != nil {
+		return "", err
+	}
+
+	// The session keeps going on the file it just wrote
+	err = s.queries.RetireCollabDocuments(ctx, db.RetireCollabDocumentsParams{
+		RepositoryID: repo.ID,
+		Path:         doc.Path,
+		FileHash:     hash,
+		ID:           doc.ID,
+	})
+	if err == nil {
+		err = s.queries.UpdateCollabDocumentFileHash(ctx, db.UpdateCollabDocumentFileHashParams{ID: doc.ID, FileHash: hash})
+	}
+	if err != nil {
+		return "", fmt.Errorf("saved %s but failed to update session: %w", doc.Path, err)
+	}
+
+	if s.search != nil {
+		parsed, err := content.Parse(doc.Path, []byte(text))
+		if err == nil {
+			err = s.search.Index(ctx, repo, parsed)
+		}
+		if err != nil {
+			// The file is saved; a stale index entry is fixed by the next rebuild
+			return hash, fmt.Errorf("saved %s but failed to update search index: %w", doc.Path, err)
+		}
+	}
+
+	return hash, nil
+}
+
+func (s *service) Join(ctx context.Context, repo db.Repository, sessionID int64) error {
+	_, err := s.acquire(ctx, repo, sessionID)
+	return err
+}
+
+func (s *service) Leave(sessionID int64) {
+	s.mu.Lock()
+	st, ok := s.states[sessionID]
+	s.mu.Unlock()
+	if ok {
+		s.release(sessionID, st)
+	}
+}
+
+// document loads a session row, checking it belongs to the repository.
+func (s *service) document(ctx context.Context, repo db.Repository, sessionID int64) (db.CollabDocument, error) {
+	doc, err := s.queries.GetCollabDocument(ctx, db.GetCollabDocumentParams{ID: sessionID, RepositoryID: repo.ID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return db.CollabDocument{}, ErrUnknownDocument
+	}
+	if err != nil {
+		return db.CollabDocument{}, fmt.Errorf("failed to load collaborative session: %w", err)
+	}
+	return doc, nil
+}
+
+// acquire returns this replica's replay of a session, starting it from the
+// session's base text if nobody holds it. Each acquire is paired with a
+// release.
+func (s *service) acquire(ctx context.Context, repo db.Repository, sessionID int64) (*state, error) {
+	doc, err := s.document(ctx, repo, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st, ok := s.states[sessionID]
+	if !ok {
+		st = &state{doc: NewDoc(doc.BaseBody)}
+		s.states[sessionID] = st
+	}
+	st.users++
+	return st, nil
+}
+
+// release drops a hold on a state, forgetting the state with the last one.
+func (s *service) release(sessionID int64, st *state) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if st.users--; st.users == 0 && s.states[sessionID] == st {
+		delete(s.states, sessionID)
+	}
+}
+
+// catchUp applies operations other replicas logged since st.seq.
+// The caller holds st.mu.
+func (s *service) catchUp(ctx context.Context, sessionID int64, st *state) error {
+	rows, err := s.queries.ListCollabOps(ctx, db.ListCollabOpsParams{DocumentID: sessionID, Seq: st.seq})
+	if err != nil {
+		return fmt.Errorf("failed to read op log: %w", err)
+	}
+
+	for _, row := range rows {
+		var op Op
+		if err := json.Unmarshal(row.Op, &op); err != nil {
+			return fmt.Errorf("failed to decode op %d: %w", row.Seq, err)
+		}
+		if err := st.doc.Apply(op); err != nil {
+			return fmt.Errorf("failed to replay op %d: %w", row.Seq, err)
+		}
+		st.seq = row.Seq
+	}
+	return nil
+}
+
+// textOf returns the visible text of a snapshot.
+func textOf(elems []Element) string {
+	d := &Doc{elems: elems}
+	return d.Text()
+}
@@ -0,0 +1,70 @@
+package collab
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+)
+
+// fakeQueries serves one session and its op log.
+type fakeQueries struct {
+	db.Querier
+	doc db.CollabDocument
+	ops []db.ListCollabOpsRow
+}
+
+func (f *fakeQueries) GetCollabDocument(ctx context.Context, arg db.GetCollabDocumentParams) (db.CollabDocument, error) {
+	return f.doc, nil
+}
+
+func (f *fakeQueries) ListCollabOps(ctx context.Context, arg db.ListCollabOpsParams) ([]db.ListCollabOpsRow, error) {
+	var rows []db.ListCollabOpsRow
+	for _, row := range f.ops {
+		if row.Seq > arg.Seq {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+func TestSessionStateIsDroppedWhenUnused(t *testing.T) {
+	op, _ := json.Marshal(Op{Type: OpInsert, ID: ID{Counter: 3, Site: "x"}, After: ID{Counter: 2, Site: baseSite}, Value: "!"})
+	queries := &fakeQueries{
+		doc: db.CollabDocument{ID: 1, Path: "page.md", BaseBody: "hi"},
+		ops: []db.ListCollabOpsRow{{Seq: 1, Op: op}},
+	}
+	s := NewService(queries, t.TempDir(), nil).(*service)
+	ctx := context.Background()
+	repo := db.Repository{ID: 1}
+
+	snapshot, err := s.Snapshot(ctx, repo, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := textOf(snapshot.Elements); text != "hi!" {
+		t.Fatalf("snapshot text = %q, want %q", text, "hi!")
+	}
+	if len(s.states) != 0 {
+		t.Fatal("a snapshot outside any stream kept the session in memory")
+	}
+
+	for range 2 {
+		if err := s.Join(ctx, repo, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.Snapshot(ctx, repo, 1); err != nil {
+		t.Fatal(err)
+	}
+	s.Leave(1)
+	if len(s.states) != 1 {
+		t.Fatal("the session was dropped while a stream still uses it")
+	}
+	s.Leave(1)
+	if len(s.states) != 0 {
+		t.Fatal("the session stayed in memory after the last stream left")
+	}
+	s.Leave(1) // a stray Leave is harmless
+}
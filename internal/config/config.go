@@ -1,42 +1,284 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 )
 
-// Config holds application configuration loaded from environment variables.
+// Config holds application configuration. Each setting is read, in order of
+// precedence, from its environment variable, a file named by the variable
+// with a _FILE suffix (for container secrets), the environment's profile in
+// the config file, the top level of the config file, and its default.
 type Config struct {
-	DatabaseURL        string
-	Port               string
-	Environment        string // "development" or "production"
-	BaseURL            string // Base URL for OAuth callbacks (e.g., "http://localhost:5173")
-	GithubClientID     string
-	GithubClientSecret string
-	SessionSecret      string
-}
-
-// Load reads configuration from environment variables with sensible defaults.
-func Load() *Config {
-	port := getEnvOrDefault("PORT", "8080")
-	env := getEnvOrDefault("ENV", "development")
-	baseURL := getEnvOrDefault("BASE_URL", fmt.Sprintf("http://localhost:%s", port))
-
-	return &Config{
-		DatabaseURL:        os.Getenv("DATABASE_URL"),
-		Port:               port,
-		Environment:        env,
-		BaseURL:            baseURL,
-		GithubClientID:     os.Getenv("GITHUB_CLIENT_ID"),
-		GithubClientSecret: os.Getenv("GITHUB_CLIENT_SECRET"),
-		SessionSecret:      os.Getenv("SESSION_SECRET"),
-	}
-}
-
-// getEnvOrDefault returns the environment variable value or a default.
-func getEnvOrDefault(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
+	DatabaseURL        string        `env:"DATABASE_URL" secret:"true"`
+	Port               string        `env:"PORT" default:"8080"`
+	Environment        string        `env:"ENV" default:"development"` // "development" or "production"
+	BaseURL            string        `env:"BASE_URL"`                  // Base URL for OAuth callbacks (e.g., "http://localhost:5173")
+	GithubClientID     string        `env:"GITHUB_CLIENT_ID"`
+	GithubClientSecret string        `env:"GITHUB_CLIENT_SECRET" secret:"true"`
+	SessionSecret      string        `env:"SESSION_SECRET" secret:"true"`
+	ReposDir           string        `env:"REPOS_DIR" default:"/data/repos"` // Root directory for repository clones ({ReposDir}/{repo_id})
+	PreviewDir         string        `env:"PREVIEW_DIR"`                     // Root directory for preview builds; previews are disabled when empty
+	PreviewTimeout     time.Duration `env:"PREVIEW_TIMEOUT" default:"10m"`
+	PreviewCPUSeconds  int           `env:"PREVIEW_CPU_SECONDS" default:"600"`          // CPU time each build step may use
+	MetricsAddr        string        `env:"METRICS_ADDR"`                               // Admin listener for Prometheus metrics (e.g., ":9090"); disabled when empty
+	TracingEndpoint    string        `env:"OTEL_EXPORTER_OTLP_ENDPOINT"`                // OTLP/HTTP collector URL (e.g., "http://localhost:4318"); spans are dropped when empty
+	MinFreeSpace       Size          `env:"MIN_FREE_SPACE" default:"1GiB"`              // Free space ReposDir needs for the replica to report ready
+	AutoMigrate        bool          `env:"AUTO_MIGRATE" default:"false"`               // Apply pending migrations at startup
+	TrustedProxies     Networks      `env:"TRUSTED_PROXIES"`                            // Proxies whose X-Forwarded-For is believed (e.g., "10.0.0.0/8"); none when empty
+	RateLimitStore     string        `env:"RATE_LIMIT_STORE" default:"memory"`          // "memory" for one replica, or "postgres" to share budgets between replicas
+	DevServer          string        `env:"DEV_SERVER" default:"http://localhost:5173"` // Vite dev server pages load assets from in development
+
+	// File is the config file the settings were read from, if any
+	File string
+}
+
+// Environments the application knows how to run in.
+const (
+	Development = "development"
+	Production  = "production"
+)
+
+// Rate limit stores.
+const (
+	RateLimitMemory   = "memory"
+	RateLimitPostgres = "postgres"
+)
+
+// minSessionSecret is the shortest session secret accepted in production.
+const minSessionSecret = 32
+
+// Load reads the configuration from CONFIG_FILE, if set, and the
+// environment. The returned error lists every invalid setting at once; the
+// config is returned regardless so it can still be printed.
+func Load() (*Config, error) {
+	cfg := &Config{File: os.Getenv("CONFIG_FILE")}
+
+	var file *fileSettings
+	var errs []error
+	if cfg.File != "" {
+		var err error
+		if file, err = readFile(cfg.File); err != nil {
+			return cfg, err
+		}
+	}
+
+	// The environment picks the profile, so it is resolved first; errors
+	// are reported with the other settings
+	environment, _ := lookup(setting{env: "ENV"}, file, "")
+	if environment == "" {
+		environment = Development
+	}
+
+	failed := make(map[string]bool)
+	for _, s := range settingsOf(cfg) {
+		value, err := lookup(s, file, environment)
+		if err == nil {
+			err = s.set(value)
+			if err != nil {
+				err = fmt.Errorf("%s: %w", s.env, err)
+			}
+		}
+		if err != nil {
+			errs = append(errs, err)
+			failed[s.env] = true
+		}
+	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = fmt.Sprintf("http://localhost:%s", cfg.Port)
+	}
+
+	errs = append(errs, file.unknownKeys()...)
+	errs = append(errs, cfg.validate(failed)...)
+	return cfg, errors.Join(errs...)
+}
+
+// IsProduction reports whether the application runs in production.
+func (c *Config) IsProduction() bool {
+	return c.Environment == Production
+}
+
+// UsesHTTPS reports whether the application is served over HTTPS, so
+// cookies must only be sent over it.
+func (c *Config) UsesHTTPS() bool {
+	return strings.HasPrefix(c.BaseURL, "https://")
+}
+
+// validate checks the settings against each other, returning every problem
+// except with settings that already failed to parse.
+func (c *Config) validate(failed map[string]bool) []error {
+	var errs []error
+	invalid := func(env, format string, args ...any) {
+		if !failed[env] {
+			errs = append(errs, fmt.Errorf("%s: "+format, append([]any{env}, args...)...))
+		}
+	}
+
+	if c.Environment != Development && c.Environment != Production {
+		invalid("ENV", "must be %q or %q, got %q", Development, Production, c.Environment)
+	}
+	if port, err := strconv.Atoi(c.Port); err != nil || port < 1 || port > 65535 {
+		invalid("PORT", "must be a port number, got %q", c.Port)
+	}
+	if u, err := url.Parse(c.BaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		invalid("BASE_URL", "must be an http or https URL, got %q", c.BaseURL)
+	}
+	if c.ReposDir == "" {
+		invalid("REPOS_DIR", "must be set")
+	}
+	if c.PreviewTimeout <= 0 {
+		invalid("PREVIEW_TIMEOUT", "must be positive")
+	}
+	if c.PreviewCPUSeconds <= 0 {
+		invalid("PREVIEW_CPU_SECONDS", "must be positive")
+	}
+	if c.MetricsAddr != "" {
+		if _, _, err := net.SplitHostPort(c.MetricsAddr); err != nil {
+			invalid("METRICS_ADDR", "must be host:port, got %q", c.MetricsAddr)
+		}
+	}
+	if c.TracingEndpoint != "" {
+		if u, err := url.Parse(c.TracingEndpoint); err != nil || u.Host == "" {
+			invalid("OTEL_EXPORTER_OTLP_ENDPOINT", "must be a URL, got %q", c.TracingEndpoint)
+		}
+	}
+	if c.DevServer != "" {
+		if u, err := url.Parse(c.DevServer); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.Path != "" {
+			invalid("DEV_SERVER", "must be an http or https origin, got %q", c.DevServer)
+		}
+	}
+	if c.RateLimitStore != RateLimitMemory && c.RateLimitStore != RateLimitPostgres {
+		invalid("RATE_LIMIT_STORE", "must be %q or %q, got %q", RateLimitMemory, RateLimitPostgres, c.RateLimitStore)
+	}
+
+	// Production must be able to sign users in and keep sessions safe
+	if c.IsProduction() {
+		for env, value := range map[string]string{
+			"DATABASE_URL":         c.DatabaseURL,
+			"GITHUB_CLIENT_ID":     c.GithubClientID,
+			"GITHUB_CLIENT_SECRET": c.GithubClientSecret,
+			"SESSION_SECRET":       c.SessionSecret,
+		} {
+			if value == "" {
+				invalid(env, "must be set in production")
+			}
+		}
+		if c.SessionSecret != "" && len(c.SessionSecret) < minSessionSecret {
+			invalid("SESSION_SECRET", "must be at least %d characters in production", minSessionSecret)
+		}
+	}
+
+	slices.SortFunc(errs, func(a, b error) int { return strings.Compare(a.Error(), b.Error()) })
+	return errs
+}
+
+// setting is one configurable field of Config.
+type setting struct {
+	env      string
+	fallback string
+	secret   bool
+	field    reflect.Value
+}
+
+// key is the setting's name in the config file.
+func (s setting) key() string {
+	return strings.ToLower(s.env)
+}
+
+// settingsOf lists the settings of cfg in field order.
+func settingsOf(cfg *Config) []setting {
+	v := reflect.ValueOf(cfg).Elem()
+	t := v.Type()
+	var settings []setting
+	for i := range t.NumField() {
+		f := t.Field(i)
+		env, ok := f.Tag.Lookup("env")
+		if !ok {
+			continue
+		}
+		settings = append(settings, setting{
+			env:      env,
+			fallback: f.Tag.Get("default"),
+			secret:   f.Tag.Get("secret") == "true",
+			field:    v.Field(i),
+		})
+	}
+	return settings
+}
+
+// lookup finds a setting's raw value by precedence. Empty variables count
+// as unset, as compose passes them for unset host variables.
+func lookup(s setting, file *fileSettings, environment string) (string, error) {
+	value, path := os.Getenv(s.env), os.Getenv(s.env+"_FILE")
+	hasValue, hasPath := value != "", path != ""
+	switch {
+	case hasValue && hasPath:
+		return "", fmt.Errorf("%s: set either %s or %s_FILE, not both", s.env, s.env, s.env)
+	case hasValue:
+		return value, nil
+	case hasPath:
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("%s_FILE: %w", s.env, err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+	if value, ok := file.get(s.key(), environment); ok {
+		return value, nil
+	}
+	return s.fallback, nil
+}
+
+// set parses value into the setting's field.
+func (s setting) set(value string) error {
+	switch p := s.field.Addr().Interface().(type) {
+	case *string:
+		*p = value
+	case *int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("must be a whole number, got %q", value)
+		}
+		*p = n
+	case *bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("must be true or false, got %q", value)
+		}
+		*p = b
+	case *time.Duration:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("must be a duration such as 10m, got %q", value)
+		}
+		*p = d
+	case *Size:
+		size, err := ParseSize(value)
+		if err != nil {
+			return err
+		}
+		*p = size
+	case *Networks:
+		networks, err := ParseNetworks(value)
+		if err != nil {
+			return err
+		}
+		*p = networks
+	default:
+		panic("config: unsupported setting type " + s.field.Type().String())
+	}
+	return nil
+}
+
+// format renders the setting's current value as it would be configured.
+func (s setting) format() string {
+	return fmt.Sprint(s.field.Interface())
 }
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clearEnv unsets every setting for the test, so only what it sets is read.
+func clearEnv(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	for _, s := range settingsOf(&Config{}) {
+		t.Setenv(s.env, "")
+		t.Setenv(s.env+"_FILE", "")
+	}
+}
+
+// writeFile writes a test file and returns its path.
+func writeFile(t *testing.T, name, data string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("CONFIG_FILE", writeFile(t, "config.yaml", `
+port: 9000
+repos_dir: /srv/repos
+preview_timeout: 15m
+environments:
+  development:
+    repos_dir: /tmp/repos
+`))
+	t.Setenv("PORT", "7000")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		setting string
+		got     any
+		want    any
+	}{
+		{"environment over file", cfg.Port, "7000"},
+		{"profile over top level", cfg.ReposDir, "/tmp/repos"},
+		{"top level over default", cfg.PreviewTimeout, 15 * time.Minute},
+		{"default", cfg.RateLimitStore, RateLimitMemory},
+		{"derived", cfg.BaseURL, "http://localhost:7000"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.setting, tt.got, tt.want)
+		}
+	}
+}
+
+func TestLoadFromFileVariable(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("SESSION_SECRET_FILE", writeFile(t, "session", "from-a-secret\n"))
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.SessionSecret != "from-a-secret" {
+		t.Errorf("SessionSecret = %q, want the file's contents without the newline", cfg.SessionSecret)
+	}
+
+	t.Setenv("SESSION_SECRET", "inline")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "set either SESSION_SECRET or SESSION_SECRET_FILE") {
+		t.Errorf("Load with both set = %v, want an error naming both", err)
+	}
+
+	t.Setenv("SESSION_SECRET", "")
+	t.Setenv("SESSION_SECRET_FILE", filepath.Join(t.TempDir(), "missing"))
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "SESSION_SECRET_FILE:") {
+		t.Errorf("Load with a missing file = %v, want an error naming SESSION_SECRET_FILE", err)
+	}
+}
+
+func TestLoadReportsEveryError(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("CONFIG_FILE", writeFile(t, "config.yaml", "prot: 8080\n"))
+	t.Setenv("ENV", Production)
+	t.Setenv("PORT", "http")
+	t.Setenv("PREVIEW_TIMEOUT", "soon")
+	t.Setenv("DEV_SERVER", "localhost:5173")
+	t.Setenv("SESSION_SECRET", "short")
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("Load succeeded, want errors")
+	}
+	for _, want := range []string{
+		"unknown setting prot",
+		"PORT: must be a port number",
+		`PREVIEW_TIMEOUT: must be a duration such as 10m, got "soon"`,
+		"DEV_SERVER: must be an http or https origin",
+		"DATABASE_URL: must be set in production",
+		"GITHUB_CLIENT_ID: must be set in production",
+		"SESSION_SECRET: must be at least 32 characters in production",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("errors %q do not include %q", err, want)
+		}
+	}
+	// A setting that failed to parse is not validated again
+	if strings.Contains(err.Error(), "PREVIEW_TIMEOUT: must be positive") {
+		t.Errorf("errors %q report PREVIEW_TIMEOUT twice", err)
+	}
+}
+
+func TestPrint(t *testing.T) {
+	cfg := &Config{Port: "8080", SessionSecret: "s3cret"}
+
+	var out bytes.Buffer
+	if err := cfg.Print(&out, true); err != nil {
+		t.Fatal(err)
+	}
+	printed := out.String()
+	for _, want := range []string{`port: "8080"`, `session_secret: "[redacted]"`, `database_url: ""`} {
+		if !strings.Contains(printed, want) {
+			t.Errorf("redacted config does not contain %s:\n%s", want, printed)
+		}
+	}
+	if strings.Contains(printed, "s3cret") {
+		t.Errorf("redacted config shows the session secret:\n%s", printed)
+	}
+
+	out.Reset()
+	if err := cfg.Print(&out, false); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), `session_secret: "s3cret"`) {
+		t.Errorf("unredacted config does not show the session secret:\n%s", out.String())
+	}
+}
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fileSettings is a YAML config file: settings keyed by their environment
+// variable name in lower case, and per-environment profiles overriding them.
+//
+//	repos_dir: /data/repos
+//	preview_timeout: 15m
+//	environments:
+//	  production:
+//	    auto_migrate: true
+type fileSettings struct {
+	path         string
+	base         map[string]string
+	environments map[string]map[string]string
+}
+
+// readFile parses the config file at path.
+func readFile(path string) (*fileSettings, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var raw struct {
+		Settings     map[string]yaml.Node            `yaml:",inline"`
+		Environments map[string]map[string]yaml.Node `yaml:"environments"`
+	}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	f := &fileSettings{path: path, environments: make(map[string]map[string]string)}
+	if f.base, err = scalars(raw.Settings); err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+	for name, settings := range raw.Environments {
+		if f.environments[name], err = scalars(settings); err != nil {
+			return nil, fmt.Errorf("config file %s: environments.%s: %w", path, name, err)
+		}
+	}
+	return f, nil
+}
+
+// scalars keeps settings as the text they were written as, so they parse
+// the same way as environment variables.
+func scalars(nodes map[string]yaml.Node) (map[string]string, error) {
+	values := make(map[string]string, len(nodes))
+	for key, node := range nodes {
+		if node.Kind != yaml.ScalarNode {
+			return nil, fmt.Errorf("%s must be a single value", key)
+		}
+		values[key] = node.Value
+	}
+	return values, nil
+}
+
+// get returns a setting from the environment's profile or the top level.
+func (f *fileSettings) get(key, environment string) (string, bool) {
+	if f == nil {
+		return "", false
+	}
+	if value, ok := f.environments[environment][key]; ok {
+		return value, true
+	}
+	value, ok := f.base[key]
+	return value, ok
+}
+
+// unknownKeys reports settings in the file that Config does not have, which
+// are most likely typos.
+func (f *fileSettings) unknownKeys() []error {
+	if f == nil {
+		return nil
+	}
+	var known []string
+	for _, s := range settingsOf(&Config{}) {
+		known = append(known, s.key())
+	}
+
+	var errs []error
+	check := func(prefix string, values map[string]string) {
+		for key := range values {
+			if !slices.Contains(known, key) {
+				errs = append(errs, fmt.Errorf("config file %s: unknown setting %s%s", f.path, prefix, key))
+			}
+		}
+	}
+	check("", f.base)
+	for name, values := range f.environments {
+		if name != Development && name != Production {
+			errs = append(errs, fmt.Errorf("config file %s: unknown environment %q", f.path, name))
+		}
+		check("environments."+name+".", values)
+	}
+	slices.SortFunc(errs, func(a, b error) int { return strings.Compare(a.Error(), b.Error()) })
+	return errs
+}
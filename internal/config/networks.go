@@ -0,0 +1,49 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Networks is a list of IP ranges, configured comma-separated in CIDR
+// notation, e.g. "10.0.0.0/8,192.0.2.1". A bare address is a range of one.
+type Networks []*net.IPNet
+
+// ParseNetworks parses a comma-separated list of ranges.
+func ParseNetworks(value string) (Networks, error) {
+	var networks Networks
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if !strings.Contains(field, "/") {
+			ip := net.ParseIP(field)
+			if ip == nil {
+				return nil, fmt.Errorf("must be IP addresses or CIDR ranges, got %q", field)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(field)
+		if err != nil {
+			return nil, fmt.Errorf("must be IP addresses or CIDR ranges, got %q", field)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// String formats the ranges as they are configured.
+func (n Networks) String() string {
+	ranges := make([]string, len(n))
+	for i, network := range n {
+		ranges[i] = network.String()
+	}
+	return strings.Join(ranges, ",")
+}
@@ -0,0 +1,25 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// redacted replaces secrets in printed configuration.
+const redacted = "[redacted]"
+
+// Print writes the effective settings as a config file would hold them,
+// with secrets replaced when redact is set.
+func (c *Config) Print(w io.Writer, redact bool) error {
+	for _, s := range settingsOf(c) {
+		value := s.format()
+		if redact && s.secret && value != "" {
+			value = redacted
+		}
+		if _, err := fmt.Fprintf(w, "%s: %s\n", s.key(), strconv.Quote(value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Size is a number of bytes, configured with a unit such as "512MiB" or "2GB".
+type Size uint64
+
+var sizeUnits = []struct {
+	suffix string
+	bytes  uint64
+}{
+	// Longest suffixes first, so "MiB" is not read as "B"
+	{"KiB", 1 << 10}, {"MiB", 1 << 20}, {"GiB", 1 << 30}, {"TiB", 1 << 40},
+	{"KB", 1e3}, {"MB", 1e6}, {"GB", 1e9}, {"TB", 1e12},
+	{"B", 1},
+}
+
+// ParseSize parses a size such as "1GiB"; a bare number is in bytes.
+func ParseSize(value string) (Size, error) {
+	number, unit := strings.TrimSpace(value), uint64(1)
+	for _, u := range sizeUnits {
+		if rest, ok := strings.CutSuffix(number, u.suffix); ok {
+			number, unit = strings.TrimSpace(rest), u.bytes
+			break
+		}
+	}
+	n, err := strconv.ParseUint(number, 10, 64)
+	if err != nil || n > (1<<64-1)/unit {
+		return 0, fmt.Errorf("must be a size such as 512MiB, got %q", value)
+	}
+	return Size(n * unit), nil
+}
+
+// String formats the size in the largest binary unit that divides it.
+func (s Size) String() string {
+	for i := 3; i >= 0; i-- {
+		u := sizeUnits[i]
+		if s != 0 && uint64(s)%u.bytes == 0 {
+			return strconv.FormatUint(uint64(s)/u.bytes, 10) + u.suffix
+		}
+	}
+	return strconv.FormatUint(uint64(s), 10) + "B"
+}
@@ -0,0 +1,169 @@
+package content
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Heading is a markdown ATX heading (e.g. "## Install") found in a document body.
+type Heading struct {
+	Level int
+	Text  string
+}
+
+// Document is a parsed Starlight content file (markdown or MDX).
+type Document struct {
+	Path        string
+	Frontmatter map[string]any
+	Title       string
+	Description string
+	Headings    []Heading
+	Body        string // Raw body without the frontmatter block
+}
+
+var (
+	frontmatterDelimiter = []byte("---")
+
+	headingPattern  = regexp.MustCompile(`^(#{1,6})\s+(.+?)\s*#*\s*$`)
+	imagePattern    = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	linkPattern     = regexp.MustCompile(`\[([^\]]+)\]\([^)]*\)`)
+	htmlTagPattern  = regexp.MustCompile(`</?[A-Za-z][^>]*>`)
+	emphasisPattern = regexp.MustCompile("[*_~`]+")
+	listPattern     = regexp.MustCompile(`^\s*(?:[-*+]|\d+\.)\s+`)
+	mdxLinePattern  = regexp.MustCompile(`^\s*(?:import|export)\s`)
+)
+
+// Parse splits a content file into frontmatter and body and extracts the
+// fields Starlight uses for navigation and search.
+func Parse(path string, src []byte) (*Document, error) {
+	doc := &Document{Path: path, Frontmatter: map[string]any{}}
+
+	front, body, err := SplitFrontmatter(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if len(front) > 0 {
+		if err := yaml.Unmarshal(front, &doc.Frontmatter); err != nil {
+			return nil, fmt.Errorf("failed to parse frontmatter in %s: %w", path, err)
+		}
+	}
+
+	doc.Body = string(body)
+	doc.Title, _ = doc.Frontmatter["title"].(string)
+	doc.Description, _ = doc.Frontmatter["description"].(string)
+	doc.Headings = extractHeadings(doc.Body)
+
+	return doc, nil
+}
+
+// PlainText returns the body with markdown, HTML and MDX syntax stripped,
+// suitable for indexing and snippets.
+func (d *Document) PlainText() string {
+	var b strings.Builder
+	inFence := false
+
+	for _, line := range strings.Split(d.Body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if !inFence {
+			if mdxLinePattern.MatchString(line) {
+				continue
+			}
+			if m := headingPattern.FindStringSubmatch(trimmed); m != nil {
+				line = m[2]
+			}
+			line = strings.TrimLeft(strings.TrimSpace(line), "> ")
+			line = listPattern.ReplaceAllString(line, "")
+			line = imagePattern.ReplaceAllString(line, "$1")
+			line = linkPattern.ReplaceAllString(line, "$1")
+			line = htmlTagPattern.ReplaceAllString(line, "")
+			line = emphasisPattern.ReplaceAllString(line, "")
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(line)
+	}
+
+	return b.String()
+}
+
+// HeadingText joins all heading texts with newlines.
+func (d *Document) HeadingText() string {
+	texts := make([]string, len(d.Headings))
+	for i, h := range d.Headings {
+		texts[i] = h.Text
+	}
+	return strings.Join(texts, "\n")
+}
+
+// SplitFrontmatter separates a leading "---" delimited YAML block from the body.
+func SplitFrontmatter(src []byte) (front, body []byte, err error) {
+	src = bytes.TrimPrefix(src, []byte("\uFEFF"))
+	if !bytes.HasPrefix(src, frontmatterDelimiter) {
+		return nil, src, nil
+	}
+
+	rest := src[len(frontmatterDelimiter):]
+	nl := bytes.IndexByte(rest, '\n')
+	if nl < 0 || len(bytes.TrimSpace(rest[:nl])) > 0 {
+		// "---" followed by text on the same line is a thematic break, not frontmatter
+		return nil, src, nil
+	}
+	rest = rest[nl+1:]
+
+	for offset := 0; offset <= len(rest); {
+		end := bytes.IndexByte(rest[offset:], '\n')
+		line := rest[offset:]
+		if end >= 0 {
+			line = rest[offset : offset+end]
+		}
+		if bytes.Equal(bytes.TrimRight(line, " \t\r"), frontmatterDelimiter) {
+			front = rest[:offset]
+			if end < 0 {
+				return front, nil, nil
+			}
+			return front, rest[offset+end+1:], nil
+		}
+		if end < 0 {
+			break
+		}
+		offset += end + 1
+	}
+
+	return nil, nil, fmt.Errorf("unterminated frontmatter block")
+}
+
+// extractHeadings collects ATX headings outside fenced code blocks.
+func extractHeadings(body string) []Heading {
+	var headings []Heading
+	inFence := false
+
+	for _, line := range strings.Split(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if m := headingPattern.FindStringSubmatch(trimmed); m != nil {
+			text := emphasisPattern.ReplaceAllString(linkPattern.ReplaceAllString(m[2], "$1"), "")
+			headings = append(headings, Heading{Level: len(m[1]), Text: text})
+		}
+	}
+
+	return headings
+}
@@ -0,0 +1,185 @@
+package content
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrConflict is returned when a file changed since the caller read it.
+	ErrConflict = errors.New("file was modified by someone else")
+
+	// ErrInvalidPath is returned for paths that escape the content directory.
+	ErrInvalidPath = errors.New("invalid content path")
+)
+
+// Hash returns the hex SHA-256 of file contents, used for optimistic locking.
+func Hash(src []byte) string {
+	sum := sha256.Sum256(src)
+	return hex.EncodeToString(sum[:])
+}
+
+// SafeJoin resolves a slash-separated content path under root, rejecting
+// absolute paths, ".." segments and symlinks that lead out of root.
+func SafeJoin(root, rel string) (string, error) {
+	if rel == "" || strings.HasPrefix(rel, "/") || strings.Contains(rel, "\\") {
+		return "", ErrInvalidPath
+	}
+	clean := filepath.Clean(filepath.FromSlash(rel))
+	if clean == "." || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", ErrInvalidPath
+	}
+	path := filepath.Join(root, clean)
+	if err := staysWithin(root, path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// staysWithin checks that path, once its symlinks are resolved, is still
+// under root. A path that does not exist yet is judged by its deepest
+// existing ancestor, which is where creating it would put it.
+func staysWithin(root, path string) error {
+	realRoot, err := filepath.EvalSymlinks(root)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", root, err)
+	}
+
+	for existing := path; ; {
+		real, err := filepath.EvalSymlinks(existing)
+		if err == nil {
+			rel, err := filepath.Rel(realRoot, real)
+			if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				return ErrInvalidPath
+			}
+			return nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to resolve %s: %w", existing, err)
+		}
+		// A dangling symlink would be followed when the file is created
+		if _, err := os.Lstat(existing); err == nil {
+			return ErrInvalidPath
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return nil
+		}
+		existing = parent
+	}
+}
+
+// ReadFile reads a content file and returns its bytes and hash.
+func ReadFile(root, rel string) ([]byte, string, error) {
+	path, err := SafeJoin(root, rel)
+	if err != nil {
+		return nil, "", err
+	}
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+	return src, Hash(src), nil
+}
+
+// WriteFile writes a content file if its current hash still matches baseHash.
+// An empty baseHash means the file must not exist yet. Returns the new hash.
+// Concurrent writes to the same file are serialized, so of two saves from
+// the same base only the first succeeds, and readers never see a partly
+// written file.
+func WriteFile(root, rel string, data []byte, baseHash string) (string, error) {
+	path, err := SafeJoin(root, rel)
+	if err != nil {
+		return "", err
+	}
+
+	unlock := lockPath(path)
+	defer unlock()
+
+	current, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		if baseHash != "" {
+			return "", ErrConflict
+		}
+	case err != nil:
+		return "", fmt.Errorf("failed to read %s: %w", rel, err)
+	case Hash(current) != baseHash:
+		return "", ErrConflict
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create directory for %s: %w", rel, err)
+	}
+	if err := replaceFile(path, data); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", rel, err)
+	}
+
+	return Hash(data), nil
+}
+
+// replaceFile writes data to a hidden temporary file next to path and
+// renames it over path.
+func replaceFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // fails harmlessly once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// pathLocks holds a mutex per file being written, dropped when no writer
+// is waiting for it.
+var pathLocks = struct {
+	sync.Mutex
+	held map[string]*pathLock
+}{held: map[string]*pathLock{}}
+
+type pathLock struct {
+	sync.Mutex
+	waiters int
+}
+
+// lockPath locks a file against other writers in this process.
+func lockPath(path string) (unlock func()) {
+	pathLocks.Lock()
+	l, ok := pathLocks.held[path]
+	if !ok {
+		l = &pathLock{}
+		pathLocks.held[path] = l
+	}
+	l.waiters++
+	pathLocks.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		pathLocks.Lock()
+		if l.waiters--; l.waiters == 0 {
+			delete(pathLocks.held, path)
+		}
+		pathLocks.Unlock()
+	}
+}
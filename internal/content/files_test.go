@@ -0,0 +1,156 @@
+package content
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSafeJoin(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "docs"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "escape")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(outside, "missing.md"), filepath.Join(root, "dangling.md")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(root, "docs"), filepath.Join(root, "alias")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		rel string
+		ok  bool
+	}{
+		{"docs/intro.md", true},
+		{"docs/new/page.mdx", true},
+		{"alias/intro.md", true},
+		{"", false},
+		{"/etc/passwd", false},
+		{"../secret.md", false},
+		{"docs/../../secret.md", false},
+		{`docs\intro.md`, false},
+		{"escape/page.md", false},
+		{"escape/new/page.md", false},
+		{"dangling.md", false},
+	}
+	for _, tt := range tests {
+		_, err := SafeJoin(root, tt.rel)
+		if tt.ok && err != nil {
+			t.Errorf("SafeJoin(%q) = %v, want ok", tt.rel, err)
+		}
+		if !tt.ok && !errors.Is(err, ErrInvalidPath) {
+			t.Errorf("SafeJoin(%q) = %v, want ErrInvalidPath", tt.rel, err)
+		}
+	}
+}
+
+func TestWriteFileConflicts(t *testing.T) {
+	root := t.TempDir()
+
+	hash, err := WriteFile(root, "docs/page.md", []byte("one"), "")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := WriteFile(root, "docs/page.md", []byte("again"), ""); !errors.Is(err, ErrConflict) {
+		t.Fatalf("create over existing file = %v, want ErrConflict", err)
+	}
+	if _, err := WriteFile(root, "docs/page.md", []byte("two"), Hash([]byte("stale"))); !errors.Is(err, ErrConflict) {
+		t.Fatalf("write from stale hash = %v, want ErrConflict", err)
+	}
+	if _, err := WriteFile(root, "docs/page.md", []byte("two"), hash); err != nil {
+		t.Fatalf("write from current hash: %v", err)
+	}
+
+	src, _, err := ReadFile(root, "docs/page.md")
+	if err != nil || string(src) != "two" {
+		t.Fatalf("ReadFile = %q, %v; want %q", src, err, "two")
+	}
+	entries, _ := os.ReadDir(filepath.Join(root, "docs"))
+	if len(entries) != 1 {
+		t.Fatalf("docs holds %d entries, want only page.md", len(entries))
+	}
+}
+
+// A save waits for the one in progress and then checks against what it
+// wrote, so it cannot overwrite it.
+func TestWriteFileWaitsForSaveInProgress(t *testing.T) {
+	root := t.TempDir()
+	base, err := WriteFile(root, "page.md", []byte("base"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(root, "page.md")
+
+	unlock := lockPath(path)
+	done := make(chan error, 1)
+	go func() {
+		_, err := WriteFile(root, "page.md", []byte("second"), base)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		unlock()
+		t.Fatalf("WriteFile returned %v while another save held the file", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if err := os.WriteFile(path, []byte("first"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	unlock()
+
+	if err := <-done; !errors.Is(err, ErrConflict) {
+		t.Fatalf("WriteFile after a concurrent save = %v, want ErrConflict", err)
+	}
+}
+
+func TestWriteFileConcurrentSaves(t *testing.T) {
+	root := t.TempDir()
+	base, err := WriteFile(root, "page.md", []byte("base"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Large bodies and a common start keep the saves overlapping
+	const writers = 20
+	body := strings.Repeat("x", 1<<20)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	errs := make([]error, writers)
+	for i := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			_, errs[i] = WriteFile(root, "page.md", []byte(strconv.Itoa(i)+body), base)
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	saved := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			saved++
+		case !errors.Is(err, ErrConflict):
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if saved != 1 {
+		t.Fatalf("%d saves from the same base succeeded, want 1", saved)
+	}
+	if len(pathLocks.held) != 0 {
+		t.Fatalf("%d path locks left behind", len(pathLocks.held))
+	}
+}
@@ -0,0 +1,141 @@
+package content
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// RootLocale is Starlight's key for content stored directly in the docs
+// directory rather than under a locale folder.
+const RootLocale = "root"
+
+// Locale is a Starlight locale. Key is the content folder name (or RootLocale).
+type Locale struct {
+	Key   string
+	Label string
+	Lang  string
+}
+
+// I18n describes the locales configured for a content tree.
+type I18n struct {
+	DefaultLocale string
+	Locales       []Locale // Default locale first
+}
+
+// localeDirPattern matches Starlight locale folder names like "fr", "pt-br" or "zh-Hans".
+var localeDirPattern = regexp.MustCompile(`^[a-z]{2}(-[a-zA-Z]{2,4})?$`)
+
+// MonolingualI18n is the configuration for a site without translations.
+func MonolingualI18n() *I18n {
+	return &I18n{
+		DefaultLocale: RootLocale,
+		Locales:       []Locale{{Key: RootLocale, Label: "Default"}},
+	}
+}
+
+// DetectLocales infers the locale layout of a docs directory from its
+// top-level folders. Top-level pages (or non-locale folders) imply a root
+// locale, which then becomes the default.
+func DetectLocales(dir string) (*I18n, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read content directory: %w", err)
+	}
+
+	hasRoot := false
+	var keys []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		switch {
+		case entry.IsDir() && localeDirPattern.MatchString(name):
+			keys = append(keys, name)
+		case entry.IsDir() || IsContentFile(name):
+			hasRoot = true
+		}
+	}
+	sort.Strings(keys)
+
+	if len(keys) == 0 {
+		return MonolingualI18n(), nil
+	}
+
+	i18n := &I18n{}
+	if hasRoot {
+		i18n.DefaultLocale = RootLocale
+		i18n.Locales = append(i18n.Locales, Locale{Key: RootLocale, Label: "Default"})
+	} else {
+		// Prefer English as the source language when every locale has a folder
+		i18n.DefaultLocale = keys[0]
+		for _, key := range keys {
+			if key == "en" {
+				i18n.DefaultLocale = key
+			}
+		}
+		i18n.Locales = append(i18n.Locales, Locale{Key: i18n.DefaultLocale, Label: strings.ToUpper(i18n.DefaultLocale), Lang: i18n.DefaultLocale})
+	}
+
+	for _, key := range keys {
+		if key == i18n.DefaultLocale {
+			continue
+		}
+		i18n.Locales = append(i18n.Locales, Locale{Key: key, Label: strings.ToUpper(key), Lang: key})
+	}
+
+	return i18n, nil
+}
+
+// IsMultilingual reports whether the site has more than one locale.
+func (i *I18n) IsMultilingual() bool {
+	return len(i.Locales) > 1
+}
+
+// Locale looks up a locale by its key.
+func (i *I18n) Locale(key string) (Locale, bool) {
+	for _, l := range i.Locales {
+		if l.Key == key {
+			return l, true
+		}
+	}
+	return Locale{}, false
+}
+
+// Translations returns every locale except the default one.
+func (i *I18n) Translations() []Locale {
+	var locales []Locale
+	for _, l := range i.Locales {
+		if l.Key != i.DefaultLocale {
+			locales = append(locales, l)
+		}
+	}
+	return locales
+}
+
+// Split returns the locale key and the locale-independent slug of a content path,
+// e.g. "fr/guides/intro.md" -> ("fr", "guides/intro.md").
+func (i *I18n) Split(path string) (locale, slug string) {
+	if first, rest, ok := strings.Cut(path, "/"); ok {
+		for _, l := range i.Locales {
+			if l.Key == first && l.Key != RootLocale {
+				return l.Key, rest
+			}
+		}
+	}
+	if _, ok := i.Locale(RootLocale); ok {
+		return RootLocale, path
+	}
+	return "", path
+}
+
+// Join builds the content path of slug in the given locale.
+func (i *I18n) Join(locale, slug string) string {
+	if locale == RootLocale || locale == "" {
+		return slug
+	}
+	return locale + "/" + slug
+}
@@ -0,0 +1,53 @@
+package content
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// IsContentFile reports whether a path is a markdown or MDX page.
+func IsContentFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".mdx", ".markdown", ".mdoc":
+		return true
+	}
+	return false
+}
+
+// Walk parses every content file under root and calls fn with its
+// slash-separated path relative to root.
+func Walk(root string, fn func(doc *Document) error) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !IsContentFile(path) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", rel, err)
+		}
+
+		doc, err := Parse(filepath.ToSlash(rel), src)
+		if err != nil {
+			return err
+		}
+		return fn(doc)
+	})
+}
@@ -0,0 +1,95 @@
+// Package files reads and writes content files for the editors and the API,
+// keeping the search index in step with what is written.
+package files
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gracchi-stdio/goaat/internal/content"
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+	"github.com/gracchi-stdio/goaat/internal/repository"
+	"github.com/gracchi-stdio/goaat/internal/search"
+)
+
+// File is a content file with the hash used for optimistic saves.
+type File struct {
+	Path string
+	Body string
+	Hash string
+}
+
+// Service defines access to the content files of a repository.
+type Service interface {
+	// Read returns a content file with its hash; os.ErrNotExist if it is missing
+	Read(ctx context.Context, repo db.Repository, path string) (*File, error)
+
+	// Exists checks that path names a file in the content directory; it
+	// returns content.ErrInvalidPath or os.ErrNotExist otherwise
+	Exists(ctx context.Context, repo db.Repository, path string) error
+
+	// Write writes a content file if it is unchanged since baseHash and returns
+	// the new hash. An empty baseHash creates the file. When the file is saved
+	// but could not be re-indexed, the hash is returned with the error.
+	Write(ctx context.Context, repo db.Repository, path string, body []byte, baseHash string) (string, error)
+}
+
+type service struct {
+	reposDir string
+	search   search.Service
+}
+
+// NewService creates a file service over the clones in reposDir.
+// searchService may be nil; when set, written files are re-indexed.
+func NewService(reposDir string, searchService search.Service) Service {
+	return &service{reposDir: reposDir, search: searchService}
+}
+
+func (s *service) contentDir(repo db.Repository) string {
+	return filepath.Join(repository.Dir(s.reposDir, repo.ID), filepath.FromSlash(repo.ContentPath))
+}
+
+func (s *service) Read(ctx context.Context, repo db.Repository, path string) (*File, error) {
+	body, hash, err := content.ReadFile(s.contentDir(repo), path)
+	if err != nil {
+		return nil, err
+	}
+	return &File{Path: path, Body: string(body), Hash: hash}, nil
+}
+
+func (s *service) Exists(ctx context.Context, repo db.Repository, path string) error {
+	full, err := content.SafeJoin(s.contentDir(repo), path)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(full)
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return os.ErrNotExist
+	}
+	return nil
+}
+
+func (s *service) Write(ctx context.Context, repo db.Repository, path string, body []byte, baseHash string) (string, error) {
+	hash, err := content.WriteFile(s.contentDir(repo), path, body, baseHash)
+	if err != nil {
+		return "", err
+	}
+
+	if s.search != nil {
+		doc, err := content.Parse(path, body)
+		if err == nil {
+			err = s.search.Index(ctx, repo, doc)
+		}
+		if err != nil {
+			// The file is saved; a stale index entry is fixed by the next rebuild
+			return hash, fmt.Errorf("saved %s but failed to update search index: %w", path, err)
+		}
+	}
+
+	return hash, nil
+}
@@ -0,0 +1,65 @@
+package files
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/gracchi-stdio/goaat/internal/content"
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+)
+
+func TestReadWrite(t *testing.T) {
+	ctx := context.Background()
+	s := NewService(t.TempDir(), nil)
+	repo := db.Repository{ID: 1, ContentPath: "src/content/docs"}
+
+	if _, err := s.Read(ctx, repo, "intro.md"); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("Read of a missing file = %v, want os.ErrNotExist", err)
+	}
+	hash, err := s.Write(ctx, repo, "intro.md", []byte("# Intro"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := s.Read(ctx, repo, "intro.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.Body != "# Intro" || file.Hash != hash {
+		t.Fatalf("Read = %+v, want the body written with hash %s", file, hash)
+	}
+
+	if _, err := s.Write(ctx, repo, "intro.md", []byte("stale"), content.Hash([]byte("old"))); !errors.Is(err, content.ErrConflict) {
+		t.Fatalf("Write from a stale hash = %v, want ErrConflict", err)
+	}
+	if _, err := s.Read(ctx, repo, "../../../etc/passwd"); !errors.Is(err, content.ErrInvalidPath) {
+		t.Fatalf("Read outside the content directory = %v, want ErrInvalidPath", err)
+	}
+}
+
+func TestExists(t *testing.T) {
+	ctx := context.Background()
+	s := NewService(t.TempDir(), nil)
+	repo := db.Repository{ID: 1, ContentPath: "src/content/docs"}
+	if _, err := s.Write(ctx, repo, "guides/intro.md", []byte("# Intro"), ""); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		want error
+	}{
+		{"guides/intro.md", nil},
+		{"guides/missing.md", os.ErrNotExist},
+		{"guides", os.ErrNotExist},
+		{"", content.ErrInvalidPath},
+		{"../../../etc/passwd", content.ErrInvalidPath},
+	}
+	for _, tt := range tests {
+		err := s.Exists(ctx, repo, tt.path)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("Exists(%.40q) = %v, want %v", tt.path, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// defaultListSize is the number of items assumed for a list field without
+// a first argument.
+const defaultListSize = 10
+
+// complexity estimates how many fields an operation resolves: one per
+// field, with the fields below a list counted once per item it may return.
+// The operation must have been validated against the schema; it fails if
+// the document does not have the operation to run.
+func complexity(doc *ast.QueryDocument, operationName string, variables map[string]any) (int, error) {
+	var op *ast.OperationDefinition
+	switch {
+	case operationName != "":
+		if op = doc.Operations.ForName(operationName); op == nil {
+			return 0, fmt.Errorf("no operation with name %q", operationName)
+		}
+	case len(doc.Operations) == 1:
+		op = doc.Operations[0]
+	case len(doc.Operations) == 0:
+		return 0, errors.New("no operations in query document")
+	default:
+		return 0, errors.New("more than one operation in query document and no operation name given")
+	}
+	return selectionCost(op.SelectionSet, variables), nil
+}
+
+// selectionCost is the cost of a selection set, following fragments.
+func selectionCost(set ast.SelectionSet, variables map[string]any) int {
+	cost := 0
+	for _, selection := range set {
+		switch s := selection.(type) {
+		case *ast.Field:
+			cost += fieldCost(s, variables)
+		case *ast.InlineFragment:
+			cost += selectionCost(s.SelectionSet, variables)
+		case *ast.FragmentSpread:
+			if s.Definition != nil {
+				cost += selectionCost(s.Definition.SelectionSet, variables)
+			}
+		}
+	}
+	return cost
+}
+
+// fieldCost is the cost of a field and the fields selected below it.
+func fieldCost(field *ast.Field, variables map[string]any) int {
+	if len(field.SelectionSet) == 0 {
+		return 1
+	}
+	children := selectionCost(field.SelectionSet, variables)
+	if field.Definition == nil || field.Definition.Type.Elem == nil {
+		return 1 + children
+	}
+	return 1 + listSize(field, variables)*children
+}
+
+// listSize is the number of items a list field may return.
+func listSize(field *ast.Field, variables map[string]any) int {
+	if first, ok := toInt(field.ArgumentMap(variables)["first"]); ok {
+		return min(max(first, 0), maxFirst)
+	}
+	return defaultListSize
+}
+
+// toInt reads an integer argument, which is int64 in the query text and
+// float64 when it comes from JSON variables.
+func toInt(v any) (int, bool) {
+	switch n := v.(type) {
+	case int64:
+		return int(n), true
+	case float64:
+		return int(n), true
+	case int:
+		return n, true
+	}
+	return 0, false
+}
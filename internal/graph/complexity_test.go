@@ -0,0 +1,47 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+func TestComplexity(t *testing.T) {
+	schema := gqlparser.MustLoadSchema(&ast.Source{Name: "schema.graphql", Input: schemaSDL})
+
+	tests := []struct {
+		name      string
+		query     string
+		operation string
+		variables map[string]any
+		want      int // -1 when the operation cannot be costed
+	}{
+		{"fields", `{ viewer { id name } }`, "", nil, 3},
+		{"list without first", `{ repositories { id } }`, "", nil, 1 + defaultListSize},
+		{"schema default first", `{ repository(id: 1) { changeSets { id } } }`, "", nil, 1 + 1 + 20},
+		{"first", `{ repository(id: 1) { changeSets(first: 5) { id title } } }`, "", nil, 1 + 1 + 5*2},
+		{"first above the page limit", `{ repository(id: 1) { changeSets(first: 1000) { id } } }`, "", nil, 1 + 1 + maxFirst},
+		{"negative first", `{ repository(id: 1) { changeSets(first: -5) { id } } }`, "", nil, 1 + 1},
+		{"first from a variable", `query($n: Int) { repository(id: 1) { changeSets(first: $n) { id } } }`, "", map[string]any{"n": float64(3)}, 1 + 1 + 3},
+		{"nested lists multiply", `{ repositories { changeSets(first: 4) { files } } }`, "", nil, 1 + defaultListSize*(1+4*1)},
+		{"fragments", `{ viewer { ...user } } fragment user on User { id name }`, "", nil, 3},
+		{"inline fragments", `{ viewer { ... on User { id } } }`, "", nil, 2},
+		{"named operation", `query A { viewer { id } } query B { repositories { id } }`, "B", nil, 1 + defaultListSize},
+		{"unknown operation", `query A { viewer { id } }`, "B", nil, -1},
+		{"no operation name", `query A { viewer { id } } query B { viewer { id } }`, "", nil, -1},
+	}
+	for _, tt := range tests {
+		doc, errs := gqlparser.LoadQuery(schema, tt.query)
+		if len(errs) > 0 {
+			t.Fatalf("%s: %v", tt.name, errs)
+		}
+		got, err := complexity(doc, tt.operation, tt.variables)
+		switch {
+		case tt.want < 0 && err == nil:
+			t.Errorf("%s: complexity = %d, want an error", tt.name, got)
+		case tt.want >= 0 && (err != nil || got != tt.want):
+			t.Errorf("%s: complexity = %d, %v; want %d", tt.name, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package graph
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+)
+
+const (
+	// batchWait is how long a loader collects keys before fetching them,
+	// long enough for sibling resolvers running in parallel to join.
+	batchWait = 2 * time.Millisecond
+
+	// maxBatch fetches a batch early once it has this many keys.
+	maxBatch = 100
+)
+
+// loader batches the keys resolvers ask for during one request into a
+// single query and remembers the results, so resolving a list does not
+// query once per item.
+type loader[K comparable, V any] struct {
+	ctx   context.Context
+	fetch func(ctx context.Context, keys []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	results map[K]*result[V]
+	pending []K
+	timer   *time.Timer
+}
+
+// result is a key's value once its batch has been fetched.
+type result[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+func newLoader[K comparable, V any](ctx context.Context, fetch func(ctx context.Context, keys []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{ctx: ctx, fetch: fetch, results: make(map[K]*result[V])}
+}
+
+// Load returns the value for key, the zero value if the fetch found none.
+func (l *loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+	r, ok := l.results[key]
+	if !ok {
+		r = &result[V]{done: make(chan struct{})}
+		l.results[key] = r
+		l.pending = append(l.pending, key)
+		switch {
+		case len(l.pending) >= maxBatch:
+			l.timer.Stop()
+			go l.dispatch(l.take())
+		case len(l.pending) == 1:
+			l.timer = time.AfterFunc(batchWait, func() {
+				l.mu.Lock()
+				keys := l.take()
+				l.mu.Unlock()
+				l.dispatch(keys)
+			})
+		}
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-r.done:
+		return r.value, r.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// take removes the pending keys; the caller holds the lock.
+func (l *loader[K, V]) take() []K {
+	keys := l.pending
+	l.pending = nil
+	return keys
+}
+
+// dispatch fetches a batch and hands each key its result.
+func (l *loader[K, V]) dispatch(keys []K) {
+	if len(keys) == 0 {
+		return
+	}
+	values, err := l.fetch(l.ctx, keys)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, key := range keys {
+		r := l.results[key]
+		r.value, r.err = values[key], err
+		close(r.done)
+	}
+}
+
+// loaders are the batch loaders of one request.
+type loaders struct {
+	users          *loader[int64, *db.User]
+	members        *loader[int64, []db.RepositoryMember]
+	repoChangeSets *loader[int64, []db.ChangeSet]
+	changeSets     *loader[int64, *db.ChangeSet]
+	files          *loader[int64, []string]
+	transitions    *loader[int64, []db.ChangeSetTransition]
+	activity       *loader[int64, []db.ChangeSetTransition]
+}
+
+func newLoaders(ctx context.Context, queries db.Querier) *loaders {
+	return &loaders{
+		users: newLoader(ctx, func(ctx context.Context, ids []int64) (map[int64]*db.User, error) {
+			rows, err := queries.ListUsersByIDs(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			return index(rows, func(u db.User) int64 { return u.ID }), nil
+		}),
+		members: newLoader(ctx, func(ctx context.Context, ids []int64) (map[int64][]db.RepositoryMember, error) {
+			rows, err := queries.ListRepositoryMembersByRepositories(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			return group(rows, func(m db.RepositoryMember) int64 { return m.RepositoryID }), nil
+		}),
+		repoChangeSets: newLoader(ctx, func(ctx context.Context, ids []int64) (map[int64][]db.ChangeSet, error) {
+			rows, err := queries.ListChangeSetsByRepositories(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			return group(rows, func(cs db.ChangeSet) int64 { return cs.RepositoryID }), nil
+		}),
+		changeSets: newLoader(ctx, func(ctx context.Context, ids []int64) (map[int64]*db.ChangeSet, error) {
+			rows, err := queries.ListChangeSetsByIDs(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			return index(rows, func(cs db.ChangeSet) int64 { return cs.ID }), nil
+		}),
+		files: newLoader(ctx, func(ctx context.Context, ids []int64) (map[int64][]string, error) {
+			rows, err := queries.ListChangeSetFilesByChangeSets(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			files := make(map[int64][]string)
+			for _, f := range rows {
+				files[f.ChangeSetID] = append(files[f.ChangeSetID], f.Path)
+			}
+			return files, nil
+		}),
+		transitions: newLoader(ctx, func(ctx context.Context, ids []int64) (map[int64][]db.ChangeSetTransition, error) {
+			rows, err := queries.ListChangeSetTransitionsByChangeSets(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			return group(rows, func(t db.ChangeSetTransition) int64 { return t.ChangeSetID }), nil
+		}),
+		activity: newLoader(ctx, func(ctx context.Context, ids []int64) (map[int64][]db.ChangeSetTransition, error) {
+			rows, err := queries.ListActivityByRepositories(ctx, db.ListActivityByRepositoriesParams{RepositoryIds: ids, PerRepository: maxFirst})
+			if err != nil {
+				return nil, err
+			}
+			activity := make(map[int64][]db.ChangeSetTransition)
+			for _, r := range rows {
+				activity[r.RepositoryID] = append(activity[r.RepositoryID], db.ChangeSetTransition{
+					ID:          r.ID,
+					ChangeSetID: r.ChangeSetID,
+					UserID:      r.UserID,
+					Action:      r.Action,
+					FromState:   r.FromState,
+					ToState:     r.ToState,
+					Note:        r.Note,
+					CreatedAt:   r.CreatedAt,
+				})
+			}
+			return activity, nil
+		}),
+	}
+}
+
+// index keys rows by id.
+func index[V any](rows []V, id func(V) int64) map[int64]*V {
+	m := make(map[int64]*V, len(rows))
+	for i := range rows {
+		m[id(rows[i])] = &rows[i]
+	}
+	return m
+}
+
+// group collects rows under the id they belong to, keeping their order.
+func group[V any](rows []V, id func(V) int64) map[int64][]V {
+	m := make(map[int64][]V)
+	for _, row := range rows {
+		m[id(row)] = append(m[id(row)], row)
+	}
+	return m
+}
@@ -0,0 +1,258 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gracchi-stdio/goaat/internal/content"
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+	"github.com/gracchi-stdio/goaat/internal/repository"
+	"github.com/gracchi-stdio/goaat/internal/workflow"
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// repositoryResolver resolves a Repository the viewer may see.
+type repositoryResolver struct {
+	repo     db.Repository
+	reposDir string
+}
+
+func (r *repositoryResolver) ID() graphql.ID {
+	return graphql.ID(strconv.FormatInt(r.repo.ID, 10))
+}
+
+func (r *repositoryResolver) FullName() string {
+	return r.repo.FullName
+}
+
+func (r *repositoryResolver) DefaultBranch() string {
+	return r.repo.DefaultBranch
+}
+
+func (r *repositoryResolver) ContentPath() string {
+	return r.repo.ContentPath
+}
+
+func (r *repositoryResolver) Owner(ctx context.Context) (*userResolver, error) {
+	return loadUser(ctx, r.repo.OwnerID)
+}
+
+func (r *repositoryResolver) Editors(ctx context.Context) ([]*editorResolver, error) {
+	members, err := requestFrom(ctx).loaders.members.Load(ctx, r.repo.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load repository members: %w", err)
+	}
+	editors := []*editorResolver{{userID: r.repo.OwnerID, role: workflow.RoleOwner}}
+	for _, m := range members {
+		editors = append(editors, &editorResolver{userID: m.UserID, role: workflow.Role(m.Role)})
+	}
+	return editors, nil
+}
+
+func (r *repositoryResolver) Tree(args struct{ Path string }) ([]*treeNodeResolver, error) {
+	return r.list(strings.Trim(args.Path, "/"))
+}
+
+func (r *repositoryResolver) Document(args struct{ Path string }) (*documentResolver, error) {
+	if !content.IsContentFile(args.Path) {
+		return nil, nil
+	}
+	return r.document(args.Path)
+}
+
+func (r *repositoryResolver) ChangeSets(ctx context.Context, args struct {
+	State *string
+	First int32
+}) ([]*changeSetResolver, error) {
+	changeSets, err := requestFrom(ctx).loaders.repoChangeSets.Load(ctx, r.repo.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load change sets: %w", err)
+	}
+
+	limit := pageSize(args.First)
+	resolvers := make([]*changeSetResolver, 0, min(limit, len(changeSets)))
+	for i := range changeSets {
+		if len(resolvers) == limit {
+			break
+		}
+		if args.State != nil && enumValue(changeSets[i].State) != *args.State {
+			continue
+		}
+		resolvers = append(resolvers, &changeSetResolver{cs: &changeSets[i], repo: r})
+	}
+	return resolvers, nil
+}
+
+func (r *repositoryResolver) Activity(ctx context.Context, args struct{ First int32 }) ([]*activityResolver, error) {
+	events, err := requestFrom(ctx).loaders.activity.Load(ctx, r.repo.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load activity: %w", err)
+	}
+
+	events = events[:min(pageSize(args.First), len(events))]
+	resolvers := make([]*activityResolver, 0, len(events))
+	for _, e := range events {
+		resolvers = append(resolvers, &activityResolver{event: e, repo: r})
+	}
+	return resolvers, nil
+}
+
+// contentDir is where the repository's content files are in its clone.
+func (r *repositoryResolver) contentDir() string {
+	return filepath.Join(repository.Dir(r.reposDir, r.repo.ID), r.repo.ContentPath)
+}
+
+// list returns the directories and content files in dir, directories
+// first. Hidden entries are skipped, as when indexing; a missing
+// directory is empty.
+func (r *repositoryResolver) list(dir string) ([]*treeNodeResolver, error) {
+	abs := r.contentDir()
+	if dir != "" {
+		var err error
+		if abs, err = content.SafeJoin(abs, dir); err != nil || isHidden(dir) {
+			return []*treeNodeResolver{}, nil
+		}
+	}
+	entries, err := os.ReadDir(abs)
+	if errors.Is(err, os.ErrNotExist) {
+		return []*treeNodeResolver{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", dir, err)
+	}
+
+	var dirs, files []*treeNodeResolver
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		node := &treeNodeResolver{repo: r, name: e.Name(), path: path.Join(dir, e.Name()), dir: e.IsDir()}
+		switch {
+		case node.dir:
+			dirs = append(dirs, node)
+		case content.IsContentFile(e.Name()):
+			files = append(files, node)
+		}
+	}
+	return append(dirs, files...), nil
+}
+
+// document reads and parses a content file, nil if it does not exist.
+func (r *repositoryResolver) document(rel string) (*documentResolver, error) {
+	src, hash, err := content.ReadFile(r.contentDir(), rel)
+	if errors.Is(err, content.ErrInvalidPath) || errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", rel, err)
+	}
+	doc, err := content.Parse(rel, src)
+	if err != nil {
+		return nil, err
+	}
+	return &documentResolver{doc: doc, hash: hash}, nil
+}
+
+// isHidden reports whether a path is in or is a hidden directory.
+func isHidden(rel string) bool {
+	for _, segment := range strings.Split(rel, "/") {
+		if strings.HasPrefix(segment, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+// treeNodeResolver resolves a TreeNode, a directory or content file.
+type treeNodeResolver struct {
+	repo *repositoryResolver
+	name string
+	path string // relative to the content directory
+	dir  bool
+}
+
+func (n *treeNodeResolver) Name() string {
+	return n.name
+}
+
+func (n *treeNodeResolver) Path() string {
+	return n.path
+}
+
+func (n *treeNodeResolver) Kind() string {
+	if n.dir {
+		return "DIRECTORY"
+	}
+	return "FILE"
+}
+
+func (n *treeNodeResolver) Children() ([]*treeNodeResolver, error) {
+	if !n.dir {
+		return []*treeNodeResolver{}, nil
+	}
+	return n.repo.list(n.path)
+}
+
+func (n *treeNodeResolver) Document() (*documentResolver, error) {
+	if n.dir {
+		return nil, nil
+	}
+	return n.repo.document(n.path)
+}
+
+// documentResolver resolves a Document, a parsed content file.
+type documentResolver struct {
+	doc  *content.Document
+	hash string
+}
+
+func (d *documentResolver) Path() string {
+	return d.doc.Path
+}
+
+func (d *documentResolver) Hash() string {
+	return d.hash
+}
+
+func (d *documentResolver) Title() string {
+	return d.doc.Title
+}
+
+func (d *documentResolver) Description() string {
+	return d.doc.Description
+}
+
+func (d *documentResolver) Frontmatter() JSON {
+	return JSON{Value: d.doc.Frontmatter}
+}
+
+func (d *documentResolver) Body() string {
+	return d.doc.Body
+}
+
+func (d *documentResolver) Headings() []*headingResolver {
+	headings := make([]*headingResolver, 0, len(d.doc.Headings))
+	for _, h := range d.doc.Headings {
+		headings = append(headings, &headingResolver{heading: h})
+	}
+	return headings
+}
+
+// headingResolver resolves a Heading.
+type headingResolver struct {
+	heading content.Heading
+}
+
+func (h *headingResolver) Level() int32 {
+	return int32(h.heading.Level)
+}
+
+func (h *headingResolver) Text() string {
+	return h.heading.Text
+}
@@ -0,0 +1,259 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+	"github.com/gracchi-stdio/goaat/internal/workflow"
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/jackc/pgx/v5"
+)
+
+// queryResolver resolves the root Query type.
+type queryResolver struct {
+	queries  db.Querier
+	reposDir string
+}
+
+func (q *queryResolver) Viewer(ctx context.Context) (*userResolver, error) {
+	return loadUser(ctx, requestFrom(ctx).grant.UserID)
+}
+
+func (q *queryResolver) Repositories(ctx context.Context) ([]*repositoryResolver, error) {
+	req := requestFrom(ctx)
+	repos, err := q.queries.ListRepositoriesForUser(ctx, req.grant.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repositories: %w", err)
+	}
+
+	resolvers := make([]*repositoryResolver, 0, len(repos))
+	for _, repo := range repos {
+		if req.grant.Reaches(repo.ID) {
+			resolvers = append(resolvers, &repositoryResolver{repo: repo, reposDir: q.reposDir})
+		}
+	}
+	return resolvers, nil
+}
+
+func (q *queryResolver) Repository(ctx context.Context, args struct{ ID graphql.ID }) (*repositoryResolver, error) {
+	id, err := strconv.ParseInt(string(args.ID), 10, 64)
+	if err != nil {
+		return nil, nil
+	}
+	req := requestFrom(ctx)
+	if !req.grant.Reaches(id) {
+		return nil, nil
+	}
+
+	repo, err := q.queries.GetRepository(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load repository: %w", err)
+	}
+
+	// Members share the owner's access, as on the web pages
+	if repo.OwnerID != req.grant.UserID {
+		members, err := req.loaders.members.Load(ctx, repo.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load repository members: %w", err)
+		}
+		if !isMember(members, req.grant.UserID) {
+			return nil, nil
+		}
+	}
+	return &repositoryResolver{repo: repo, reposDir: q.reposDir}, nil
+}
+
+// isMember reports whether a user is among a repository's members.
+func isMember(members []db.RepositoryMember, userID int64) bool {
+	for _, m := range members {
+		if m.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// userResolver resolves a User.
+type userResolver struct {
+	user *db.User
+}
+
+// loadUser resolves a user through the request's batch loader.
+func loadUser(ctx context.Context, id int64) (*userResolver, error) {
+	user, err := requestFrom(ctx).loaders.users.Load(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load users: %w", err)
+	}
+	if user == nil {
+		return nil, fmt.Errorf("user %d not found", id)
+	}
+	return &userResolver{user: user}, nil
+}
+
+func (u *userResolver) ID() graphql.ID {
+	return graphql.ID(strconv.FormatInt(u.user.ID, 10))
+}
+
+func (u *userResolver) Name() string {
+	return u.user.Name
+}
+
+func (u *userResolver) Email() string {
+	return u.user.Email
+}
+
+func (u *userResolver) AvatarURL() *string {
+	if !u.user.AvatarUrl.Valid || u.user.AvatarUrl.String == "" {
+		return nil
+	}
+	return &u.user.AvatarUrl.String
+}
+
+// editorResolver resolves an Editor: the owner or a member.
+type editorResolver struct {
+	userID int64
+	role   workflow.Role
+}
+
+func (e *editorResolver) User(ctx context.Context) (*userResolver, error) {
+	return loadUser(ctx, e.userID)
+}
+
+func (e *editorResolver) Role() string {
+	return enumValue(string(e.role))
+}
+
+// changeSetResolver resolves a ChangeSet.
+type changeSetResolver struct {
+	cs   *db.ChangeSet
+	repo *repositoryResolver
+}
+
+func (c *changeSetResolver) ID() graphql.ID {
+	return graphql.ID(strconv.FormatInt(c.cs.ID, 10))
+}
+
+func (c *changeSetResolver) Repository() *repositoryResolver {
+	return c.repo
+}
+
+func (c *changeSetResolver) Title() string {
+	return c.cs.Title
+}
+
+func (c *changeSetResolver) Description() string {
+	return c.cs.Description
+}
+
+func (c *changeSetResolver) State() string {
+	return enumValue(c.cs.State)
+}
+
+func (c *changeSetResolver) Author(ctx context.Context) (*userResolver, error) {
+	return loadUser(ctx, c.cs.AuthorID)
+}
+
+func (c *changeSetResolver) Files(ctx context.Context) ([]string, error) {
+	files, err := requestFrom(ctx).loaders.files.Load(ctx, c.cs.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load change set files: %w", err)
+	}
+	if files == nil {
+		files = []string{}
+	}
+	return files, nil
+}
+
+func (c *changeSetResolver) PublishedCommit() *string {
+	if c.cs.PublishedCommit == "" {
+		return nil
+	}
+	return &c.cs.PublishedCommit
+}
+
+func (c *changeSetResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: c.cs.CreatedAt.Time}
+}
+
+func (c *changeSetResolver) UpdatedAt() graphql.Time {
+	return graphql.Time{Time: c.cs.UpdatedAt.Time}
+}
+
+func (c *changeSetResolver) Activity(ctx context.Context) ([]*activityResolver, error) {
+	transitions, err := requestFrom(ctx).loaders.transitions.Load(ctx, c.cs.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load change set activity: %w", err)
+	}
+	resolvers := make([]*activityResolver, 0, len(transitions))
+	for _, t := range transitions {
+		resolvers = append(resolvers, &activityResolver{event: t, changeSet: c, repo: c.repo})
+	}
+	return resolvers, nil
+}
+
+// activityResolver resolves an ActivityEvent, a change set transition.
+type activityResolver struct {
+	event     db.ChangeSetTransition
+	changeSet *changeSetResolver // nil until loaded
+	repo      *repositoryResolver
+}
+
+func (a *activityResolver) ID() graphql.ID {
+	return graphql.ID(strconv.FormatInt(a.event.ID, 10))
+}
+
+func (a *activityResolver) ChangeSet(ctx context.Context) (*changeSetResolver, error) {
+	if a.changeSet != nil {
+		return a.changeSet, nil
+	}
+	cs, err := requestFrom(ctx).loaders.changeSets.Load(ctx, a.event.ChangeSetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load change sets: %w", err)
+	}
+	if cs == nil {
+		return nil, fmt.Errorf("change set %d not found", a.event.ChangeSetID)
+	}
+	return &changeSetResolver{cs: cs, repo: a.repo}, nil
+}
+
+func (a *activityResolver) User(ctx context.Context) (*userResolver, error) {
+	return loadUser(ctx, a.event.UserID)
+}
+
+func (a *activityResolver) Action() string {
+	return a.event.Action
+}
+
+func (a *activityResolver) FromState() string {
+	return enumValue(a.event.FromState)
+}
+
+func (a *activityResolver) ToState() string {
+	return enumValue(a.event.ToState)
+}
+
+func (a *activityResolver) Note() string {
+	return a.event.Note
+}
+
+func (a *activityResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: a.event.CreatedAt.Time}
+}
+
+// enumValue turns a stored value such as in_review into its GraphQL enum
+// value, IN_REVIEW.
+func enumValue(s string) string {
+	return strings.ToUpper(s)
+}
+
+// pageSize clamps a first argument to what a list may return.
+func pageSize(first int32) int {
+	return min(max(int(first), 0), maxFirst)
+}
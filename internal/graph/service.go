@@ -0,0 +1,155 @@
+// Package graph serves a read-only GraphQL view of repositories, their
+// content files, change sets and workflow activity.
+package graph
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+
+	"github.com/gracchi-stdio/goaat/internal/apitoken"
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+	graphql "github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+//go:embed schema.graphql
+var schemaSDL string
+
+const (
+	// MaxDepth is how deeply fields may nest.
+	MaxDepth = 10
+
+	// MaxComplexity caps the estimated number of fields a query resolves;
+	// see complexity.
+	MaxComplexity = 5000
+
+	// MaxQueryLength is the longest query text accepted, in bytes.
+	MaxQueryLength = 10000
+
+	// maxFirst is the largest page a list field returns.
+	maxFirst = 100
+)
+
+// Request is a GraphQL request as clients POST it.
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// Response is the result of a request, data and errors side by side.
+type Response = graphql.Response
+
+// Service runs GraphQL queries on behalf of an API token.
+type Service interface {
+	// Execute runs a query as the grant's user, seeing only the repositories
+	// the grant reaches that the user owns or is a member of
+	Execute(ctx context.Context, grant apitoken.Grant, req Request) *Response
+}
+
+type service struct {
+	queries  db.Querier
+	schema   *graphql.Schema
+	analysis *ast.Schema // the schema again, for the complexity check
+}
+
+// NewService creates a GraphQL service over the clones in reposDir.
+func NewService(queries db.Querier, reposDir string) Service {
+	root := &queryResolver{queries: queries, reposDir: reposDir}
+	return &service{
+		queries: queries,
+		schema: graphql.MustParseSchema(schemaSDL, root,
+			graphql.MaxDepth(MaxDepth),
+			graphql.MaxQueryLength(MaxQueryLength),
+			graphql.MaxParallelism(20),
+		),
+		analysis: gqlparser.MustLoadSchema(&ast.Source{Name: "schema.graphql", Input: schemaSDL}),
+	}
+}
+
+func (s *service) Execute(ctx context.Context, grant apitoken.Grant, req Request) *Response {
+	// A query is only run once its cost is known, so one the analysis
+	// cannot parse, validate or cost is refused here rather than by Exec
+	if len(req.Query) > MaxQueryLength {
+		return failed(gqlerrors.Errorf("query length %d exceeds the maximum allowed query length of %d bytes", len(req.Query), MaxQueryLength))
+	}
+	doc, errs := gqlparser.LoadQuery(s.analysis, req.Query)
+	if len(errs) > 0 {
+		return &Response{Errors: queryErrors(errs)}
+	}
+	cost, err := complexity(doc, req.OperationName, req.Variables)
+	if err != nil {
+		return failed(&gqlerrors.QueryError{Message: err.Error(), Err: err})
+	}
+	if cost > MaxComplexity {
+		return failed(gqlerrors.Errorf("query is too complex: %d exceeds the limit of %d", cost, MaxComplexity))
+	}
+
+	ctx = context.WithValue(ctx, requestKey{}, &request{
+		grant:   grant,
+		loaders: newLoaders(ctx, s.queries),
+	})
+	return s.schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+}
+
+// failed is the response to a query that is not run.
+func failed(err *gqlerrors.QueryError) *Response {
+	return &Response{Errors: []*gqlerrors.QueryError{err}}
+}
+
+// queryErrors converts the analysis's errors to the ones Exec reports.
+func queryErrors(list gqlerror.List) []*gqlerrors.QueryError {
+	errs := make([]*gqlerrors.QueryError, 0, len(list))
+	for _, e := range list {
+		qe := &gqlerrors.QueryError{Message: e.Message, Rule: e.Rule}
+		for _, l := range e.Locations {
+			qe.Locations = append(qe.Locations, gqlerrors.Location{Line: l.Line, Column: l.Column})
+		}
+		errs = append(errs, qe)
+	}
+	return errs
+}
+
+// requestKey is the context key of the current request's state.
+type requestKey struct{}
+
+// request is what resolvers share during one query.
+type request struct {
+	grant   apitoken.Grant
+	loaders *loaders
+}
+
+// requestFrom returns the state of the query being resolved.
+func requestFrom(ctx context.Context) *request {
+	return ctx.Value(requestKey{}).(*request)
+}
+
+// JSON is a scalar holding any JSON value.
+type JSON struct {
+	Value any
+}
+
+// ImplementsGraphQLType maps JSON to the JSON scalar.
+func (JSON) ImplementsGraphQLType(name string) bool {
+	return name == "JSON"
+}
+
+// UnmarshalGraphQL accepts any input; JSON is only returned by this schema.
+func (j *JSON) UnmarshalGraphQL(input any) error {
+	j.Value = input
+	return nil
+}
+
+// MarshalJSON writes the value as it is.
+func (j JSON) MarshalJSON() ([]byte, error) {
+	out, err := json.Marshal(j.Value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode JSON scalar: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,86 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/gracchi-stdio/goaat/internal/apitoken"
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+	"github.com/jackc/pgx/v5"
+)
+
+// fakeQueries has repository 1, owned by user 1 with user 3 as an editor.
+type fakeQueries struct {
+	db.Querier
+}
+
+func (fakeQueries) GetRepository(ctx context.Context, id int64) (db.Repository, error) {
+	if id != 1 {
+		return db.Repository{}, pgx.ErrNoRows
+	}
+	return db.Repository{ID: 1, OwnerID: 1, FullName: "acme/docs"}, nil
+}
+
+func (fakeQueries) ListRepositoryMembersByRepositories(ctx context.Context, ids []int64) ([]db.RepositoryMember, error) {
+	return []db.RepositoryMember{{RepositoryID: 1, UserID: 3, Role: "editor"}}, nil
+}
+
+func TestRepositoryVisibility(t *testing.T) {
+	s := NewService(fakeQueries{}, t.TempDir())
+	tests := []struct {
+		name  string
+		grant apitoken.Grant
+		want  string
+	}{
+		{"owner", apitoken.Grant{UserID: 1}, `{"repository":{"fullName":"acme/docs"}}`},
+		{"member", apitoken.Grant{UserID: 3}, `{"repository":{"fullName":"acme/docs"}}`},
+		{"not a member", apitoken.Grant{UserID: 2}, `{"repository":null}`},
+		{"token for another repository", apitoken.Grant{UserID: 1, RepositoryID: 2}, `{"repository":null}`},
+	}
+	for _, tt := range tests {
+		resp := s.Execute(context.Background(), tt.grant, Request{Query: `{ repository(id: 1) { fullName } }`})
+		if len(resp.Errors) > 0 {
+			t.Fatalf("%s: %v", tt.name, resp.Errors)
+		}
+		if string(resp.Data) != tt.want {
+			t.Errorf("%s: data = %s, want %s", tt.name, resp.Data, tt.want)
+		}
+	}
+}
+
+func TestRefusedQueries(t *testing.T) {
+	// Each level of nesting adds two fields of depth
+	deep := "id"
+	for range MaxDepth/2 + 1 {
+		deep = "changeSets(first: 1) { repository { " + deep + " } }"
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"too deep", "{ repository(id: 1) { " + deep + " } }", "depth"},
+		{"too complex", `{ repositories { changeSets(first: 100) { activity { id } } } }`, "too complex"},
+		{"too long", "{ viewer { " + strings.Repeat("id ", MaxQueryLength/3+1) + "} }", "maximum allowed query length"},
+		{"not valid", `{ viewer { secret } }`, `Cannot query field "secret"`},
+		{"not parseable", `{ viewer { id }`, "Expected"},
+		{"no operation chosen", `query A { viewer { id } } query B { viewer { id } }`, "no operation name given"},
+	}
+	s := NewService(fakeQueries{}, t.TempDir())
+	for _, tt := range tests {
+		resp := s.Execute(context.Background(), apitoken.Grant{UserID: 1}, Request{Query: tt.query})
+		if len(resp.Errors) == 0 {
+			t.Errorf("%s: query was run, want it refused", tt.name)
+			continue
+		}
+		if !strings.Contains(resp.Errors[0].Message, tt.want) {
+			t.Errorf("%s: error %q, want it to mention %q", tt.name, resp.Errors[0].Message, tt.want)
+		}
+		if data, _ := json.Marshal(resp.Data); string(data) != "null" {
+			t.Errorf("%s: data = %s, want none", tt.name, data)
+		}
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package health
+
+import "math"
+
+// freeSpace is not measured where statfs is not available.
+func freeSpace(string) (uint64, error) {
+	return math.MaxUint64, nil
+}
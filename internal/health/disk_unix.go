@@ -0,0 +1,15 @@
+//go:build unix
+
+package health
+
+import "golang.org/x/sys/unix"
+
+// freeSpace returns the bytes available to unprivileged users on dir's
+// filesystem.
+func freeSpace(dir string) (uint64, error) {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(dir, &stat); err != nil {
+		return 0, err
+	}
+	return stat.Bavail * uint64(stat.Bsize), nil
+}
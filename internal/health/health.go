@@ -0,0 +1,147 @@
+// Package health reports whether this replica is ready to serve traffic.
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gracchi-stdio/goaat/db/migrations"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// checkTimeout bounds each check, so a hung dependency reports as failed
+// rather than hanging the probe.
+const checkTimeout = 2 * time.Second
+
+// Status is the outcome of a check or of all of them.
+type Status string
+
+const (
+	StatusOK       Status = "ok"
+	StatusDegraded Status = "degraded"
+	StatusFailed   Status = "failed"
+)
+
+// Check tests one dependency, returning why it is not usable.
+type Check func(ctx context.Context) error
+
+// Result is the outcome of one check.
+type Result struct {
+	Status   Status `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// Report is the outcome of every check; it is degraded if any failed.
+type Report struct {
+	Status Status            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+// Checker runs named checks concurrently.
+type Checker struct {
+	names  []string
+	checks []Check
+}
+
+// Add registers a check under name.
+func (c *Checker) Add(name string, check Check) {
+	c.names = append(c.names, name)
+	c.checks = append(c.checks, check)
+}
+
+// Run runs every check and reports their outcomes.
+func (c *Checker) Run(ctx context.Context) Report {
+	report := Report{Status: StatusOK, Checks: make(map[string]Result, len(c.checks))}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i, check := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+			defer cancel()
+
+			start := time.Now()
+			err := check(ctx)
+			result := Result{Status: StatusOK, Duration: time.Since(start).Round(time.Microsecond).String()}
+			if err != nil {
+				result.Status = StatusFailed
+				result.Error = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[c.names[i]] = result
+			if err != nil {
+				report.Status = StatusDegraded
+			}
+		}()
+	}
+	wg.Wait()
+	return report
+}
+
+// Database checks that the pool can reach Postgres. A nil pool means the
+// connection failed at startup.
+func Database(pool *pgxpool.Pool) Check {
+	return func(ctx context.Context) error {
+		if pool == nil {
+			return errors.New("not connected")
+		}
+		return pool.Ping(ctx)
+	}
+}
+
+// Migrations checks that the schema is at the version the binary expects.
+func Migrations(pool *pgxpool.Pool, expected int32) Check {
+	return func(ctx context.Context) error {
+		if pool == nil {
+			return errors.New("not connected")
+		}
+		var version int32
+		if err := pool.QueryRow(ctx, "SELECT version FROM "+migrations.VersionTable).Scan(&version); err != nil {
+			return fmt.Errorf("failed to read schema version: %w", err)
+		}
+		if version != expected {
+			return fmt.Errorf("schema is at version %d, binary expects %d", version, expected)
+		}
+		return nil
+	}
+}
+
+// Storage checks that dir is writable and has at least minFree bytes free.
+func Storage(dir string, minFree uint64) Check {
+	return func(ctx context.Context) error {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", dir, err)
+		}
+		f, err := os.CreateTemp(dir, ".readyz-*")
+		if err != nil {
+			return fmt.Errorf("%s is not writable: %w", dir, err)
+		}
+		f.Close()
+		os.Remove(f.Name())
+
+		free, err := freeSpace(dir)
+		if err != nil {
+			return fmt.Errorf("failed to read free space: %w", err)
+		}
+		if free < minFree {
+			return fmt.Errorf("%d MiB free, below the %d MiB minimum", free>>20, minFree>>20)
+		}
+		return nil
+	}
+}
+
+// Worker checks a background worker through its own health report.
+func Worker(healthy func() error) Check {
+	return func(context.Context) error {
+		return healthy()
+	}
+}
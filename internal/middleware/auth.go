@@ -3,9 +3,12 @@ package middleware
 import (
 	"context"
 	"net/http"
+	"net/url"
 
 	"github.com/gracchi-stdio/goaat/internal/auth"
+	"github.com/gracchi-stdio/goaat/internal/platform/logger"
 	"github.com/labstack/echo/v4"
+	"github.com/starfederation/datastar-go/datastar"
 )
 
 // RequireAuth checks if the user is logged in
@@ -14,18 +17,38 @@ func RequireAuth(next echo.HandlerFunc) echo.HandlerFunc {
 		userSession := auth.GetSession(c)
 		if !userSession.IsAuthenticated() {
 			// Log the unauthorized access attempt using the custom logger
-			c.Logger().Warnf("Unauthorized access attempt to: %s", c.Request().URL.String())
+			c.Logger().Warnf("Unauthorized access attempt to: %s", c.Request().URL.Path)
 
-			// Save the current URL to redirect back after login
-			auth.SetReturnTo(c, c.Request().URL.String())
-			return c.Redirect(http.StatusTemporaryRedirect, "/login")
+			// Send the whole page to the login page, returning here afterwards;
+			// Datastar requests redirect the browser instead of patching
+			login := auth.LoginURL(returnTarget(c.Request()))
+			if c.Request().Header.Get("Datastar-Request") != "" {
+				sse := datastar.NewSSE(c.Response().Writer, c.Request())
+				return sse.Redirect(login)
+			}
+			return c.Redirect(http.StatusSeeOther, login)
 		}
 		c.Logger().Infof("Authenticated access by user: %s", userSession.Name)
 
 		// Inject user into context for templates
 		ctx := context.WithValue(c.Request().Context(), auth.UserContextKey, userSession)
+		ctx = logger.WithUserID(ctx, userSession.UserID)
 		c.SetRequest(c.Request().WithContext(ctx))
 
 		return next(c)
 	}
 }
+
+// returnTarget is the page to come back to after signing in: the one
+// requested, or for actions, which cannot be replayed, the page they were
+// taken on.
+func returnTarget(r *http.Request) string {
+	if r.Method == http.MethodGet {
+		return r.URL.RequestURI()
+	}
+	referer, err := url.Parse(r.Referer())
+	if err != nil || referer.Host != r.Host {
+		return "/"
+	}
+	return referer.RequestURI()
+}
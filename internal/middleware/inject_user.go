@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/gracchi-stdio/goaat/internal/auth"
+	"github.com/gracchi-stdio/goaat/internal/platform/logger"
 	"github.com/labstack/echo/v4"
 )
 
@@ -16,6 +17,9 @@ func InjectUser(next echo.HandlerFunc) echo.HandlerFunc {
 
 		// Inject user into context (will be empty if not authenticated)
 		ctx := context.WithValue(c.Request().Context(), auth.UserContextKey, userSession)
+		if userSession.IsAuthenticated() {
+			ctx = logger.WithUserID(ctx, userSession.UserID)
+		}
 		c.SetRequest(c.Request().WithContext(ctx))
 
 		return next(c)
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"errors"
+
+	"github.com/gracchi-stdio/goaat/internal/openapi"
+	"github.com/labstack/echo/v4"
+)
+
+// ValidateRequest rejects requests whose parameters or body do not match
+// the operation spec describes for their route, before the handler sees
+// them. Routes the spec does not describe pass through.
+func ValidateRequest(spec *openapi.Spec) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			op, ok := spec.Operation(c.Request().Method, c.Path())
+			if !ok {
+				return next(c)
+			}
+
+			var invalid *openapi.RequestError
+			if err := spec.ValidateRequest(op, c.Request(), c.Param); errors.As(err, &invalid) {
+				return echo.NewHTTPError(invalid.Status, invalid.Message)
+			}
+			return next(c)
+		}
+	}
+}
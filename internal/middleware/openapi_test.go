@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gracchi-stdio/goaat/internal/openapi"
+	"github.com/labstack/echo/v4"
+)
+
+func TestValidateRequestRendersProblems(t *testing.T) {
+	type note struct {
+		Note string `json:"note"`
+	}
+	spec := openapi.New(openapi.Options{}, openapi.Route{
+		Method: http.MethodPost, Path: "/api/v1/notes", ID: "createNote",
+		Params:  []openapi.Param{{Name: "limit", In: "query", Type: "integer"}},
+		Request: note{},
+	})
+	e := echo.New()
+	e.POST("/api/v1/notes", func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	}, Problems, ValidateRequest(spec))
+
+	tests := []struct {
+		name   string
+		query  string
+		body   string
+		status int
+	}{
+		{"valid", "limit=5", `{"note":"hi"}`, http.StatusNoContent},
+		{"wrong parameter type", "limit=five", `{"note":"hi"}`, http.StatusBadRequest},
+		{"missing required field", "", `{}`, http.StatusBadRequest},
+		{"unknown body field", "", `{"note":"hi","extra":true}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/notes?"+tt.query, strings.NewReader(tt.body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		if rec.Code != tt.status {
+			t.Errorf("%s: status %d, want %d", tt.name, rec.Code, tt.status)
+			continue
+		}
+		if tt.status < 400 {
+			continue
+		}
+		var problem Problem
+		if ct := rec.Header().Get(echo.HeaderContentType); ct != "application/problem+json" {
+			t.Errorf("%s: content type %q, want application/problem+json", tt.name, ct)
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil || problem.Status != tt.status || problem.Detail == "" {
+			t.Errorf("%s: problem %+v (%v), want status %d with a detail", tt.name, problem, err, tt.status)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Problem is an RFC 9457 problem details object, the body of every API
+// error response.
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+}
+
+// Problems renders errors returned by the handlers after it as
+// application/problem+json rather than the HTML error page. Errors other
+// than echo.HTTPError are logged and reported as internal errors.
+func Problems(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		err := next(c)
+		if err == nil || c.Response().Committed {
+			return err
+		}
+
+		var he *echo.HTTPError
+		if !errors.As(err, &he) {
+			c.Logger().Errorf("API request failed: %v", err)
+			he = echo.NewHTTPError(http.StatusInternalServerError)
+		}
+
+		problem := Problem{
+			Type:     "about:blank",
+			Title:    http.StatusText(he.Code),
+			Status:   he.Code,
+			Instance: c.Request().URL.Path,
+		}
+		if detail := fmt.Sprint(he.Message); detail != problem.Title {
+			problem.Detail = detail
+		}
+
+		c.Response().Header().Set(echo.HeaderContentType, "application/problem+json")
+		return c.JSON(he.Code, problem)
+	}
+}
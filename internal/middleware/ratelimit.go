@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gracchi-stdio/goaat/internal/auth"
+	"github.com/gracchi-stdio/goaat/internal/platform/metrics"
+	"github.com/gracchi-stdio/goaat/internal/ratelimit"
+	"github.com/labstack/echo/v4"
+)
+
+// RateKey picks whose budget a request spends from.
+type RateKey func(c echo.Context) string
+
+// ByIP charges requests to the client's address. It is only as reliable as
+// the server's IP extractor, which trusts X-Forwarded-For from configured
+// proxies alone.
+func ByIP(c echo.Context) string {
+	return "ip:" + c.RealIP()
+}
+
+// ByUser charges requests to the signed-in user or the user of their API
+// token, or to the client's address for anonymous requests.
+func ByUser(c echo.Context) string {
+	if user := auth.GetUserFromContext(c.Request().Context()); user.IsAuthenticated() {
+		return "user:" + strconv.FormatInt(user.UserID, 10)
+	}
+	if s := auth.GetSession(c); s.IsAuthenticated() {
+		return "user:" + strconv.FormatInt(s.UserID, 10)
+	}
+	return ByIP(c)
+}
+
+// RateLimit spends a token from the named budget for each request and
+// answers 429 with Retry-After once it is exhausted. Requests are let
+// through when the store fails, so a database outage does not also lock
+// everyone out.
+func RateLimit(store ratelimit.Store, budget string, limit ratelimit.Limit, key RateKey) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			retryAfter, err := store.Take(c.Request().Context(), budget+":"+key(c), limit)
+			if err != nil {
+				c.Logger().Warn("Rate limit unavailable, allowing request:", err)
+				return next(c)
+			}
+			if retryAfter > 0 {
+				metrics.ObserveRateLimited(budget)
+				seconds := int(math.Ceil(retryAfter.Seconds()))
+				c.Response().Header().Set("Retry-After", strconv.Itoa(seconds))
+				return echo.NewHTTPError(http.StatusTooManyRequests, fmt.Sprintf("Too many requests, try again in %ds", seconds))
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+
+	"github.com/gracchi-stdio/goaat/internal/platform/logger"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// requestIDPattern limits the ids accepted from upstream proxies, so log
+// lines cannot be forged through the header.
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID takes the request id from the X-Request-ID header or generates
+// one, echoes it in the response, and tags the request's span and log
+// records with it. c.Logger() is bound to the request context so handler
+// logs carry it too.
+func RequestID(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		id := c.Request().Header.Get(echo.HeaderXRequestID)
+		if !requestIDPattern.MatchString(id) {
+			id = newRequestID()
+		}
+		c.Response().Header().Set(echo.HeaderXRequestID, id)
+
+		ctx := logger.WithRequestID(c.Request().Context(), id)
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("request_id", id))
+		c.SetRequest(c.Request().WithContext(ctx))
+		if l, ok := c.Echo().Logger.(*logger.EchoLogger); ok {
+			c.SetLogger(l.WithContext(func() context.Context { return c.Request().Context() }))
+		}
+
+		return next(c)
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"log/slog"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// RequestLogger logs one record per request. Server errors are logged at
+// error level and client errors at warn, with the ids from the context.
+// The query string is left out: it carries OAuth codes, return_to targets
+// and search terms.
+func RequestLogger(l *slog.Logger) echo.MiddlewareFunc {
+	return middleware.RequestLoggerWithConfig(middleware.RequestLoggerConfig{
+		LogStatus:    true,
+		LogRoutePath: true,
+		LogURIPath:   true,
+		LogError:     true,
+		LogMethod:    true,
+		LogLatency:   true,
+		LogRemoteIP:  true,
+		HandleError:  true,
+		LogValuesFunc: func(c echo.Context, v middleware.RequestLoggerValues) error {
+			level := slog.LevelInfo
+			if v.Status >= 500 {
+				level = slog.LevelError
+			} else if v.Status >= 400 {
+				level = slog.LevelWarn
+			}
+
+			attrs := []slog.Attr{
+				slog.String("method", v.Method),
+				slog.String("route", v.RoutePath),
+				slog.String("path", v.URIPath),
+				slog.Int("status", v.Status),
+				slog.Duration("latency", v.Latency),
+				slog.String("remote_ip", v.RemoteIP),
+			}
+			if v.Error != nil {
+				attrs = append(attrs, slog.String("error", v.Error.Error()))
+			}
+			l.LogAttrs(c.Request().Context(), level, "request", attrs...)
+			return nil
+		},
+	})
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestRequestLoggerLeavesOutQuery(t *testing.T) {
+	var out bytes.Buffer
+	e := echo.New()
+	e.Use(RequestLogger(slog.New(slog.NewTextHandler(&out, nil))))
+	e.GET("/auth/:provider/callback", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/auth/github/callback?code=s3cret&state=abc", nil)
+	e.ServeHTTP(httptest.NewRecorder(), req)
+
+	line := out.String()
+	for _, want := range []string{"route=/auth/:provider/callback", "path=/auth/github/callback", "status=200"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("log %q does not contain %s", line, want)
+		}
+	}
+	for _, secret := range []string{"s3cret", "state="} {
+		if strings.Contains(line, secret) {
+			t.Errorf("log %q contains the query string", line)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/gracchi-stdio/goaat/internal/auth"
+	"github.com/gracchi-stdio/goaat/internal/preview"
+	"github.com/labstack/echo/v4"
+	echomiddleware "github.com/labstack/echo/v4/middleware"
+)
+
+// SecurityConfig adjusts the security middleware to the deployment.
+type SecurityConfig struct {
+	// HTTPS enables HSTS and Secure cookies
+	HTTPS bool
+
+	// DevServer is the Vite dev server origin pages load assets from in
+	// development, e.g. "http://localhost:5173"
+	DevServer string
+}
+
+// hstsMaxAge is two years, as the HSTS preload list requires.
+const hstsMaxAge = 2 * 365 * 24 * 60 * 60
+
+// Security returns the security middleware: hardening headers, a content
+// security policy with a per-request script nonce, and CSRF protection for
+// state-changing requests.
+func Security(cfg SecurityConfig) []echo.MiddlewareFunc {
+	secure := echomiddleware.SecureConfig{
+		XSSProtection:      "0", // The legacy filter is itself exploitable; the CSP replaces it
+		ContentTypeNosniff: "nosniff",
+		XFrameOptions:      "DENY",
+		ReferrerPolicy:     "strict-origin-when-cross-origin",
+	}
+	if cfg.HTTPS {
+		secure.HSTSMaxAge = hstsMaxAge
+	}
+
+	csrf := echomiddleware.CSRFWithConfig(echomiddleware.CSRFConfig{
+		// The versioned API and GraphQL authenticate with tokens, never cookies
+		Skipper: func(c echo.Context) bool {
+			path := c.Request().URL.Path
+			return strings.HasPrefix(path, "/api/v1/") || path == "/graphql"
+		},
+		TokenLookup:    "header:" + auth.CSRFHeader + ",form:" + auth.CSRFField,
+		CookieName:     "_csrf",
+		CookiePath:     "/",
+		CookieMaxAge:   int((7 * 24 * time.Hour).Seconds()),
+		CookieHTTPOnly: true,
+		CookieSecure:   cfg.HTTPS,
+		CookieSameSite: http.SameSiteLaxMode,
+	})
+
+	return []echo.MiddlewareFunc{
+		echomiddleware.SecureWithConfig(secure),
+		contentSecurityPolicy(cfg.DevServer),
+		csrf,
+		exposeCSRFToken,
+	}
+}
+
+// contentSecurityPolicy allows scripts carrying the request's nonce and
+// those they load; templates read the nonce with templ.GetNonce, and the
+// dev server the policy allows is passed on for the layout to load from.
+// 'strict-dynamic' also covers the scripts Datastar executes from SSE
+// streams, which cannot know the page's nonce. Datastar evaluates its
+// data-* expressions with Function, hence 'unsafe-eval'. Built previews rely
+// on inline scripts, so they get the preview sandbox policy instead.
+func contentSecurityPolicy(devServer string) echo.MiddlewareFunc {
+	self := "'self'"
+	if devServer != "" {
+		devSocket := strings.Replace(devServer, "http", "ws", 1)
+		self += " " + devServer + " " + devSocket
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if strings.HasPrefix(c.Request().URL.Path, "/preview/") {
+				c.Response().Header().Set("Content-Security-Policy", preview.ContentSecurityPolicy)
+				return next(c)
+			}
+
+			nonce := newNonce()
+			c.Response().Header().Set("Content-Security-Policy", strings.Join([]string{
+				"default-src " + self,
+				"script-src 'nonce-" + nonce + "' 'strict-dynamic' 'unsafe-eval' https: " + self,
+				"style-src 'unsafe-inline' " + self,
+				"img-src data: https: " + self,
+				"font-src data: " + self,
+				"connect-src " + self,
+				"object-src 'none'",
+				"base-uri 'self'",
+				"form-action 'self'",
+				"frame-ancestors 'none'",
+			}, "; "))
+
+			ctx := templ.WithNonce(c.Request().Context(), nonce)
+			if devServer != "" {
+				ctx = context.WithValue(ctx, devServerKey{}, devServer)
+			}
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
+
+type devServerKey struct{}
+
+// DevServer returns the Vite dev server origin pages load their assets
+// from, or "" when they use the built files.
+func DevServer(ctx context.Context) string {
+	origin, _ := ctx.Value(devServerKey{}).(string)
+	return origin
+}
+
+// exposeCSRFToken passes the token the CSRF middleware expects on to
+// templates, which embed it in forms and the page head.
+func exposeCSRFToken(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if token, ok := c.Get(echomiddleware.DefaultCSRFConfig.ContextKey).(string); ok {
+			req := c.Request()
+			c.SetRequest(req.WithContext(auth.WithCSRFToken(req.Context(), token)))
+		}
+		return next(c)
+	}
+}
+
+func newNonce() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return base64.RawStdEncoding.EncodeToString(b)
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gracchi-stdio/goaat/internal/preview"
+	"github.com/labstack/echo/v4"
+)
+
+func TestContentSecurityPolicy(t *testing.T) {
+	e := echo.New()
+	handler := contentSecurityPolicy("")(func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/admin/dashboard", "script-src 'nonce-"},
+		{"/preview/1/", preview.ContentSecurityPolicy},
+		{"/preview/1/docs/index.html", preview.ContentSecurityPolicy},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		if err := handler(e.NewContext(httptest.NewRequest(http.MethodGet, tt.path, nil), rec)); err != nil {
+			t.Fatal(err)
+		}
+		policy := rec.Header().Get("Content-Security-Policy")
+		if !strings.Contains(policy, tt.want) {
+			t.Errorf("%s: policy %q, want it to contain %q", tt.path, policy, tt.want)
+		}
+		if strings.HasPrefix(tt.path, "/preview/") && strings.Contains(policy, "allow-same-origin") {
+			t.Errorf("%s: policy %q lets the preview share the application's origin", tt.path, policy)
+		}
+	}
+}
+
+func TestDevServer(t *testing.T) {
+	e := echo.New()
+	tests := []struct {
+		devServer  string
+		wantPolicy string
+	}{
+		{"", "default-src 'self';"},
+		{"http://localhost:5173", "default-src 'self' http://localhost:5173 ws://localhost:5173;"},
+	}
+	for _, tt := range tests {
+		var got string
+		handler := contentSecurityPolicy(tt.devServer)(func(c echo.Context) error {
+			got = DevServer(c.Request().Context())
+			return c.NoContent(http.StatusOK)
+		})
+		rec := httptest.NewRecorder()
+		if err := handler(e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)); err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.devServer {
+			t.Errorf("DevServer = %q, want %q", got, tt.devServer)
+		}
+		if policy := rec.Header().Get("Content-Security-Policy"); !strings.Contains(policy, tt.wantPolicy) {
+			t.Errorf("dev server %q: policy %q, want it to contain %q", tt.devServer, policy, tt.wantPolicy)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gracchi-stdio/goaat/internal/apitoken"
+	"github.com/gracchi-stdio/goaat/internal/auth"
+	"github.com/gracchi-stdio/goaat/internal/platform/logger"
+	"github.com/labstack/echo/v4"
+)
+
+// RequireToken authenticates API requests by the personal access token in
+// their Authorization header, acting as the token's user within the limits
+// of its grant. Session cookies are not accepted, so the API needs no CSRF
+// protection.
+func RequireToken(tokens apitoken.Service) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if tokens == nil {
+				return echo.NewHTTPError(http.StatusServiceUnavailable, "database unavailable")
+			}
+
+			secret, ok := strings.CutPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+			if !ok || strings.TrimSpace(secret) == "" {
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer realm="goaat"`)
+				return echo.NewHTTPError(http.StatusUnauthorized, "a personal access token is required")
+			}
+
+			ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+			grant, err := tokens.Authenticate(ctx, strings.TrimSpace(secret))
+			cancel()
+			if errors.Is(err, apitoken.ErrInvalidToken) {
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer realm="goaat", error="invalid_token"`)
+				return echo.NewHTTPError(http.StatusUnauthorized, "the token is invalid, expired or revoked")
+			}
+			if err != nil {
+				c.Logger().Errorf("Failed to authenticate token: %v", err)
+				return echo.NewHTTPError(http.StatusInternalServerError, "failed to check token")
+			}
+
+			ctx = apitoken.WithGrant(c.Request().Context(), grant)
+			ctx = context.WithValue(ctx, auth.UserContextKey, auth.UserSession{UserID: grant.UserID, Name: grant.UserName, Email: grant.UserEmail})
+			ctx = logger.WithUserID(ctx, grant.UserID)
+			c.SetRequest(c.Request().WithContext(ctx))
+
+			return next(c)
+		}
+	}
+}
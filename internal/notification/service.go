@@ -0,0 +1,90 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+)
+
+// Level is a notification's severity, matching Shoelace alert variants.
+type Level string
+
+const (
+	LevelPrimary Level = "primary"
+	LevelSuccess Level = "success"
+	LevelDanger  Level = "danger"
+)
+
+// unreadLimit caps how many unread notifications are shown at once.
+const unreadLimit = 20
+
+// Notification is an in-app message for a user about something that
+// happened while they were away.
+type Notification struct {
+	ID      int64
+	Level   Level
+	Message string
+	Link    string
+	Time    time.Time
+}
+
+// Service stores and lists in-app notifications.
+type Service interface {
+	// Notify sends a notification to a user
+	Notify(ctx context.Context, userID int64, level Level, message, link string) error
+
+	// Unread lists the user's unread notifications, newest first
+	Unread(ctx context.Context, userID int64) ([]Notification, error)
+
+	// Dismiss marks one of the user's notifications as read
+	Dismiss(ctx context.Context, userID, id int64) error
+}
+
+type service struct {
+	queries db.Querier
+}
+
+// NewService creates a notification service.
+func NewService(queries db.Querier) Service {
+	return &service{queries: queries}
+}
+
+func (s *service) Notify(ctx context.Context, userID int64, level Level, message, link string) error {
+	err := s.queries.CreateNotification(ctx, db.CreateNotificationParams{
+		UserID:  userID,
+		Level:   string(level),
+		Message: message,
+		Link:    link,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create notification: %w", err)
+	}
+	return nil
+}
+
+func (s *service) Unread(ctx context.Context, userID int64) ([]Notification, error) {
+	rows, err := s.queries.ListUnreadNotifications(ctx, db.ListUnreadNotificationsParams{UserID: userID, Limit: unreadLimit})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notifications: %w", err)
+	}
+	notifications := make([]Notification, 0, len(rows))
+	for _, r := range rows {
+		notifications = append(notifications, Notification{
+			ID:      r.ID,
+			Level:   Level(r.Level),
+			Message: r.Message,
+			Link:    r.Link,
+			Time:    r.CreatedAt.Time,
+		})
+	}
+	return notifications, nil
+}
+
+func (s *service) Dismiss(ctx context.Context, userID, id int64) error {
+	if err := s.queries.MarkNotificationRead(ctx, db.MarkNotificationReadParams{ID: id, UserID: userID}); err != nil {
+		return fmt.Errorf("failed to dismiss notification: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package openapi
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// known are types whose JSON form their reflected structure does not show.
+// The pgtype values marshal to null when not valid.
+var known = map[reflect.Type]Schema{
+	reflect.TypeFor[time.Time]():          {Type: "string", Format: "date-time"},
+	reflect.TypeFor[pgtype.Timestamp]():   {Type: "string", Format: "date-time", Nullable: true},
+	reflect.TypeFor[pgtype.Timestamptz](): {Type: "string", Format: "date-time", Nullable: true},
+	reflect.TypeFor[pgtype.Date]():        {Type: "string", Format: "date", Nullable: true},
+	reflect.TypeFor[pgtype.Text]():        {Type: "string", Nullable: true},
+	reflect.TypeFor[pgtype.Bool]():        {Type: "boolean", Nullable: true},
+	reflect.TypeFor[pgtype.Int4]():        {Type: "integer", Format: "int32", Nullable: true},
+	reflect.TypeFor[pgtype.Int8]():        {Type: "integer", Format: "int64", Nullable: true},
+	reflect.TypeFor[pgtype.Float8]():      {Type: "number", Format: "double", Nullable: true},
+}
+
+// schemas derives schemas from Go types, collecting named structs as
+// components.
+type schemas struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+}
+
+func newSchemas() *schemas {
+	return &schemas{
+		components: make(map[string]*Schema),
+		names:      make(map[reflect.Type]string),
+	}
+}
+
+// of returns the schema of t, a reference for named structs.
+func (s *schemas) of(t reflect.Type) *Schema {
+	if schema, ok := known[t]; ok {
+		return &schema
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := s.of(t.Elem())
+		if schema.Ref == "" {
+			schema.Nullable = true
+		}
+		return schema
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: s.of(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.of(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		return s.component(t)
+	default:
+		return &Schema{}
+	}
+}
+
+// component adds a named struct to the components, once, and refers to it.
+func (s *schemas) component(t reflect.Type) *Schema {
+	name, ok := s.names[t]
+	if !ok {
+		name = t.Name()
+		if _, taken := s.components[name]; taken {
+			name = strings.ReplaceAll(t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:], ".", "_") + "_" + name
+		}
+		s.names[t] = name
+		s.components[name] = &Schema{} // placeholder for recursive types
+		s.components[name] = s.object(t)
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// object describes a struct's JSON fields. Fields are required unless
+// tagged omitempty, so request types mark optional fields the same way
+// response types do.
+func (s *schemas) object(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	s.fields(t, schema)
+	return schema
+}
+
+func (s *schemas) fields(t reflect.Type, schema *Schema) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			s.fields(field.Type, schema)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema.Properties[name] = s.of(field.Type)
+		if !strings.Contains(options, "omitempty") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// resolve follows a reference to its component.
+func (s *schemas) resolve(schema *Schema) *Schema {
+	return resolve(s.components, schema)
+}
+
+func resolve(components map[string]*Schema, schema *Schema) *Schema {
+	if name, ok := strings.CutPrefix(schema.Ref, "#/components/schemas/"); ok {
+		if component, ok := components[name]; ok {
+			return component
+		}
+	}
+	return schema
+}
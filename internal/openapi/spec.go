@@ -0,0 +1,227 @@
+// Package openapi describes the HTTP API as an OpenAPI document, built from
+// a description of each route and the Go types its handler binds and
+// renders, and validates requests against it.
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Route describes one API operation. Request and Response are zero values
+// of the types the handler binds and renders; their schemas are derived
+// from the types' json tags.
+type Route struct {
+	Method      string
+	Path        string // as registered with Echo, e.g. /api/v1/repositories/:id
+	ID          string // operationId
+	Summary     string
+	Description string
+	Tag         string
+	Params      []Param // path parameters not listed here are strings
+	Request     any     // nil for no body
+	Status      int     // of a successful response; 200 if zero
+	Response    any     // nil for no body
+	Headers     []string
+	Errors      []int
+	Token       bool // needs a personal access token
+	Problems    bool // errors are problem details
+}
+
+// Param describes a path, query or header parameter.
+type Param struct {
+	Name        string
+	In          string // path, query or header
+	Description string
+	Required    bool
+	Type        string // string, integer or boolean; string if empty
+	Enum        []string
+}
+
+// Options are what every route in a Spec shares.
+type Options struct {
+	Info    Info
+	Problem any // type of problem details bodies
+}
+
+// Spec is an API's OpenAPI document and the routes it was built from.
+type Spec struct {
+	doc        *Document
+	operations map[string]*Operation // by method and Echo path
+	schemas    *schemas
+}
+
+// tokenScheme names the security scheme of routes that need a token.
+const tokenScheme = "token"
+
+// New builds the document describing routes.
+func New(options Options, routes ...Route) *Spec {
+	s := &Spec{
+		doc: &Document{
+			OpenAPI: Version,
+			Info:    options.Info,
+			Paths:   make(map[string]PathItem),
+			Components: Components{
+				SecuritySchemes: map[string]SecurityScheme{
+					tokenScheme: {Type: "http", Scheme: "bearer", Description: "A personal access token created in the settings"},
+				},
+			},
+		},
+		operations: make(map[string]*Operation),
+		schemas:    newSchemas(),
+	}
+
+	var problem *Schema
+	if options.Problem != nil {
+		problem = s.schemas.of(reflect.TypeOf(options.Problem))
+	}
+	for _, route := range routes {
+		op := s.operation(route, problem)
+		path := templatePath(route.Path)
+		if s.doc.Paths[path] == nil {
+			s.doc.Paths[path] = make(PathItem)
+		}
+		s.doc.Paths[path][strings.ToLower(route.Method)] = op
+		s.operations[route.Method+" "+route.Path] = op
+	}
+	s.doc.Components.Schemas = s.schemas.components
+	return s
+}
+
+// Document returns the OpenAPI document.
+func (s *Spec) Document() *Document {
+	return s.doc
+}
+
+// Operation returns the operation for a method and Echo route path.
+func (s *Spec) Operation(method, path string) (*Operation, bool) {
+	op, ok := s.operations[method+" "+path]
+	return op, ok
+}
+
+// Check reports routes under prefix registered without a description, and
+// descriptions of routes that are not registered, so the document cannot
+// drift from the router.
+func (s *Spec) Check(routes []*echo.Route, prefix string) error {
+	registered := make(map[string]bool)
+	var errs []error
+	for _, route := range routes {
+		if route.Method == echo.RouteNotFound || !strings.HasPrefix(route.Path, prefix) {
+			continue
+		}
+		key := route.Method + " " + route.Path
+		registered[key] = true
+		if _, ok := s.operations[key]; !ok {
+			errs = append(errs, fmt.Errorf("%s is not described in the API specification", key))
+		}
+	}
+	for key := range s.operations {
+		if !registered[key] {
+			errs = append(errs, fmt.Errorf("%s is described in the API specification but not registered", key))
+		}
+	}
+	slices.SortFunc(errs, func(a, b error) int { return strings.Compare(a.Error(), b.Error()) })
+	return errors.Join(errs...)
+}
+
+// operation converts a route description to an OpenAPI operation.
+func (s *Spec) operation(route Route, problem *Schema) *Operation {
+	op := &Operation{
+		OperationID: route.ID,
+		Summary:     route.Summary,
+		Description: route.Description,
+		Responses:   make(map[string]Response),
+	}
+	if route.Tag != "" {
+		op.Tags = []string{route.Tag}
+	}
+
+	// Path parameters come from the route, described by Params if listed
+	described := make(map[string]Param)
+	for _, p := range route.Params {
+		described[p.In+" "+p.Name] = p
+	}
+	for _, segment := range strings.Split(route.Path, "/") {
+		name, ok := strings.CutPrefix(segment, ":")
+		if !ok {
+			continue
+		}
+		p, ok := described["path "+name]
+		if !ok {
+			p = Param{Name: name, In: "path"}
+		}
+		p.Required = true
+		op.Parameters = append(op.Parameters, parameter(p))
+	}
+	for _, p := range route.Params {
+		if p.In != "path" {
+			op.Parameters = append(op.Parameters, parameter(p))
+		}
+	}
+
+	if route.Request != nil {
+		schema := s.schemas.of(reflect.TypeOf(route.Request))
+		op.RequestBody = &RequestBody{
+			Required: len(s.schemas.resolve(schema).Required) > 0,
+			Content:  map[string]MediaType{echo.MIMEApplicationJSON: {Schema: schema}},
+		}
+	}
+
+	status := route.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := Response{Description: http.StatusText(status)}
+	if route.Response != nil {
+		success.Content = map[string]MediaType{echo.MIMEApplicationJSON: {Schema: s.schemas.of(reflect.TypeOf(route.Response))}}
+	}
+	for _, name := range route.Headers {
+		if success.Headers == nil {
+			success.Headers = make(map[string]Header)
+		}
+		success.Headers[name] = Header{Schema: &Schema{Type: "string"}}
+	}
+	op.Responses[strconv.Itoa(status)] = success
+
+	if route.Token {
+		op.Security = []map[string][]string{{tokenScheme: {}}}
+	}
+	for _, code := range route.Errors {
+		response := Response{Description: http.StatusText(code)}
+		if route.Problems && problem != nil {
+			response.Content = map[string]MediaType{"application/problem+json": {Schema: problem}}
+		}
+		op.Responses[strconv.Itoa(code)] = response
+	}
+	return op
+}
+
+// parameter converts a parameter description.
+func parameter(p Param) Parameter {
+	schema := &Schema{Type: p.Type, Enum: p.Enum}
+	if schema.Type == "" {
+		schema.Type = "string"
+	}
+	if schema.Type == "integer" {
+		schema.Format = "int64"
+	}
+	return Parameter{Name: p.Name, In: p.In, Description: p.Description, Required: p.Required, Schema: schema}
+}
+
+// templatePath converts an Echo route path to an OpenAPI path template.
+func templatePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if name, ok := strings.CutPrefix(segment, ":"); ok {
+			segments[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,188 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RequestError is a request that does not match its operation, with the
+// status to reject it with.
+type RequestError struct {
+	Status  int
+	Message string
+}
+
+func (e *RequestError) Error() string {
+	return e.Message
+}
+
+// ValidateRequest checks a request's parameters and body against op; param
+// returns the value of a path parameter. The body is read and replaced, so
+// the handler can still bind it. A body that does not match its schema,
+// including one with fields the schema does not list, is a 400.
+func (s *Spec) ValidateRequest(op *Operation, r *http.Request, param func(string) string) error {
+	for _, p := range op.Parameters {
+		var value string
+		switch p.In {
+		case "path":
+			value = param(p.Name)
+		case "query":
+			value = r.URL.Query().Get(p.Name)
+		case "header":
+			value = r.Header.Get(p.Name)
+		}
+		if err := checkParameter(p, value); err != "" {
+			// A path that does not fit its route names no resource
+			status := http.StatusBadRequest
+			if p.In == "path" {
+				status = http.StatusNotFound
+			}
+			return &RequestError{Status: status, Message: err}
+		}
+	}
+
+	if op.RequestBody == nil {
+		return nil
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return &RequestError{Status: http.StatusBadRequest, Message: "failed to read the request body"}
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		if op.RequestBody.Required {
+			return &RequestError{Status: http.StatusBadRequest, Message: "a request body is required"}
+		}
+		return nil
+	}
+	media, _, _ := mime.ParseMediaType(r.Header.Get(echo.HeaderContentType))
+	content, ok := op.RequestBody.Content[media]
+	if !ok {
+		return &RequestError{Status: http.StatusUnsupportedMediaType, Message: "the request body must be " + echo.MIMEApplicationJSON}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return &RequestError{Status: http.StatusBadRequest, Message: "the request body is not valid JSON"}
+	}
+	if msg := s.check(content.Schema, value, "body"); msg != "" {
+		return &RequestError{Status: http.StatusBadRequest, Message: msg}
+	}
+	return nil
+}
+
+// checkParameter describes what is wrong with a parameter's value, if
+// anything.
+func checkParameter(p Parameter, value string) string {
+	if value == "" {
+		if p.Required {
+			return fmt.Sprintf("the %s %s parameter is required", p.Name, p.In)
+		}
+		return ""
+	}
+	switch p.Schema.Type {
+	case "integer":
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return fmt.Sprintf("the %s %s parameter must be an integer", p.Name, p.In)
+		}
+	case "boolean":
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Sprintf("the %s %s parameter must be true or false", p.Name, p.In)
+		}
+	}
+	if len(p.Schema.Enum) > 0 && !slices.Contains(p.Schema.Enum, value) {
+		return fmt.Sprintf("the %s %s parameter must be one of %v", p.Name, p.In, p.Schema.Enum)
+	}
+	return ""
+}
+
+// check describes the first way value, decoded from JSON at path, does not
+// match schema, or returns "" if it matches.
+func (s *Spec) check(schema *Schema, value any, path string) string {
+	schema = resolve(s.doc.Components.Schemas, schema)
+	if value == nil {
+		if schema.Nullable || schema.Type == "" {
+			return ""
+		}
+		return path + " must not be null"
+	}
+
+	switch schema.Type {
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			return path + " must be an object"
+		}
+		for _, name := range schema.Required {
+			if _, ok := object[name]; !ok {
+				return path + "." + name + " is required"
+			}
+		}
+		for _, name := range slices.Sorted(maps.Keys(object)) {
+			field := object[name]
+			fieldSchema, ok := schema.Properties[name]
+			if !ok {
+				fieldSchema = schema.AdditionalProperties
+			}
+			if fieldSchema == nil {
+				return path + "." + name + " is not a known field"
+			}
+			if msg := s.check(fieldSchema, field, path+"."+name); msg != "" {
+				return msg
+			}
+		}
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			return path + " must be an array"
+		}
+		for i, item := range items {
+			if msg := s.check(schema.Items, item, fmt.Sprintf("%s[%d]", path, i)); msg != "" {
+				return msg
+			}
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return path + " must be a string"
+		}
+		if len(schema.Enum) > 0 && !slices.Contains(schema.Enum, str) {
+			return fmt.Sprintf("%s must be one of %v", path, schema.Enum)
+		}
+		if schema.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339, str); err != nil {
+				return path + " must be an RFC 3339 date and time"
+			}
+		}
+	case "integer":
+		number, ok := value.(json.Number)
+		if !ok {
+			return path + " must be an integer"
+		}
+		if _, err := number.Int64(); err != nil {
+			return path + " must be an integer"
+		}
+	case "number":
+		if _, ok := value.(json.Number); !ok {
+			return path + " must be a number"
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return path + " must be true or false"
+		}
+	}
+	return ""
+}
@@ -0,0 +1,76 @@
+package openapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+// widget is the body of the test route.
+type widget struct {
+	Name  string   `json:"name"`
+	Count int      `json:"count"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+func TestValidateRequest(t *testing.T) {
+	spec := New(Options{}, Route{
+		Method: http.MethodPut, Path: "/widgets/:id", ID: "putWidget",
+		Params: []Param{
+			{Name: "id", In: "path", Type: "integer"},
+			{Name: "mode", In: "query", Required: true, Enum: []string{"replace", "merge"}},
+			{Name: "dry_run", In: "query", Type: "boolean"},
+		},
+		Request: widget{},
+	})
+	op, ok := spec.Operation(http.MethodPut, "/widgets/:id")
+	if !ok {
+		t.Fatal("operation not found")
+	}
+
+	tests := []struct {
+		name   string
+		id     string
+		query  string
+		body   string
+		status int // 0 when the request is valid
+		msg    string
+	}{
+		{"valid", "1", "mode=merge", `{"name":"a","count":2,"tags":["x"]}`, 0, ""},
+		{"missing required parameter", "1", "", `{"name":"a","count":2}`, http.StatusBadRequest, "the mode query parameter is required"},
+		{"parameter not in enum", "1", "mode=append", `{"name":"a","count":2}`, http.StatusBadRequest, "must be one of [replace merge]"},
+		{"parameter of the wrong type", "1", "mode=merge&dry_run=maybe", `{"name":"a","count":2}`, http.StatusBadRequest, "the dry_run query parameter must be true or false"},
+		{"path parameter of the wrong type", "one", "mode=merge", `{"name":"a","count":2}`, http.StatusNotFound, "the id path parameter must be an integer"},
+		{"body field of the wrong type", "1", "mode=merge", `{"name":"a","count":"2"}`, http.StatusBadRequest, "body.count must be an integer"},
+		{"unknown body field", "1", "mode=merge", `{"name":"a","count":2,"colour":"red"}`, http.StatusBadRequest, "body.colour is not a known field"},
+		{"missing body field", "1", "mode=merge", `{"name":"a"}`, http.StatusBadRequest, "body.count is required"},
+		{"array item of the wrong type", "1", "mode=merge", `{"name":"a","count":2,"tags":[1]}`, http.StatusBadRequest, "body.tags[0] must be a string"},
+		{"missing body", "1", "mode=merge", ``, http.StatusBadRequest, "a request body is required"},
+		{"invalid JSON", "1", "mode=merge", `{"name":`, http.StatusBadRequest, "not valid JSON"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/widgets/"+tt.id+"?"+tt.query, strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			param := func(name string) string { return map[string]string{"id": tt.id}[name] }
+
+			err := spec.ValidateRequest(op, req, param)
+			if tt.status == 0 {
+				if err != nil {
+					t.Fatalf("ValidateRequest = %v, want valid", err)
+				}
+				return
+			}
+			invalid, ok := err.(*RequestError)
+			if !ok {
+				t.Fatalf("ValidateRequest = %v, want a RequestError", err)
+			}
+			if invalid.Status != tt.status || !strings.Contains(invalid.Message, tt.msg) {
+				t.Errorf("ValidateRequest = %d %q, want %d containing %q", invalid.Status, invalid.Message, tt.status, tt.msg)
+			}
+		})
+	}
+}
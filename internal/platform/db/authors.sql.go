@@ -7,21 +7,24 @@ package db
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const createAuthor = `-- name: CreateAuthor :one
-INSERT INTO authors (name, email) 
-VALUES ($1, $2) 
-RETURNING id, name, email, created_at, updated_at
+INSERT INTO authors (name, email, user_id) 
+VALUES ($1, $2, $3) 
+RETURNING id, name, email, created_at, updated_at, user_id
 `
 
 type CreateAuthorParams struct {
-	Name  string `json:"name"`
-	Email string `json:"email"`
+	Name   string      `json:"name"`
+	Email  string      `json:"email"`
+	UserID pgtype.Int8 `json:"user_id"`
 }
 
 func (q *Queries) CreateAuthor(ctx context.Context, arg CreateAuthorParams) (Author, error) {
-	row := q.db.QueryRow(ctx, createAuthor, arg.Name, arg.Email)
+	row := q.db.QueryRow(ctx, createAuthor, arg.Name, arg.Email, arg.UserID)
 	var i Author
 	err := row.Scan(
 		&i.ID,
@@ -29,21 +32,25 @@ func (q *Queries) CreateAuthor(ctx context.Context, arg CreateAuthorParams) (Aut
 		&i.Email,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.UserID,
 	)
 	return i, err
 }
 
-const deleteAuthor = `-- name: DeleteAuthor :exec
+const deleteAuthor = `-- name: DeleteAuthor :execrows
 DELETE FROM authors WHERE id = $1
 `
 
-func (q *Queries) DeleteAuthor(ctx context.Context, id int64) error {
-	_, err := q.db.Exec(ctx, deleteAuthor, id)
-	return err
+func (q *Queries) DeleteAuthor(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteAuthor, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
 const getAuthorByEmail = `-- name: GetAuthorByEmail :one
-SELECT id, name, email, created_at, updated_at FROM authors WHERE email = $1 LIMIT 1
+SELECT id, name, email, created_at, updated_at, user_id FROM authors WHERE email = $1 LIMIT 1
 `
 
 func (q *Queries) GetAuthorByEmail(ctx context.Context, email string) (Author, error) {
@@ -55,12 +62,13 @@ func (q *Queries) GetAuthorByEmail(ctx context.Context, email string) (Author, e
 		&i.Email,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.UserID,
 	)
 	return i, err
 }
 
 const getAuthorByID = `-- name: GetAuthorByID :one
-SELECT id, name, email, created_at, updated_at FROM authors WHERE id = $1 LIMIT 1
+SELECT id, name, email, created_at, updated_at, user_id FROM authors WHERE id = $1 LIMIT 1
 `
 
 func (q *Queries) GetAuthorByID(ctx context.Context, id int64) (Author, error) {
@@ -72,16 +80,35 @@ func (q *Queries) GetAuthorByID(ctx context.Context, id int64) (Author, error) {
 		&i.Email,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.UserID,
 	)
 	return i, err
 }
 
 const listAuthors = `-- name: ListAuthors :many
-SELECT id, name, email, created_at, updated_at FROM authors ORDER BY name
+SELECT id, name, email, created_at, updated_at, user_id FROM authors
+WHERE name ILIKE '%' || $1::text || '%'
+  AND (name, id) > ($2::text, $3::bigint)
+ORDER BY name, id
+LIMIT $4
 `
 
-func (q *Queries) ListAuthors(ctx context.Context) ([]Author, error) {
-	rows, err := q.db.Query(ctx, listAuthors)
+type ListAuthorsParams struct {
+	Search    string `json:"search"`
+	AfterName string `json:"after_name"`
+	AfterID   int64  `json:"after_id"`
+	PageSize  int32  `json:"page_size"`
+}
+
+// A page of authors whose names contain search, ordered by name after the
+// (name, id) keyset cursor; the first page starts after an empty name.
+func (q *Queries) ListAuthors(ctx context.Context, arg ListAuthorsParams) ([]Author, error) {
+	rows, err := q.db.Query(ctx, listAuthors,
+		arg.Search,
+		arg.AfterName,
+		arg.AfterID,
+		arg.PageSize,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -95,6 +122,7 @@ func (q *Queries) ListAuthors(ctx context.Context) ([]Author, error) {
 			&i.Email,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.UserID,
 		); err != nil {
 			return nil, err
 		}
@@ -106,19 +134,35 @@ func (q *Queries) ListAuthors(ctx context.Context) ([]Author, error) {
 	return items, nil
 }
 
-const updateAuthor = `-- name: UpdateAuthor :exec
+const updateAuthor = `-- name: UpdateAuthor :one
 UPDATE authors 
-SET name = $2, email = $3, updated_at = NOW() 
+SET name = $2, email = $3, user_id = $4, updated_at = NOW() 
 WHERE id = $1
+RETURNING id, name, email, created_at, updated_at, user_id
 `
 
 type UpdateAuthorParams struct {
-	ID    int64  `json:"id"`
-	Name  string `json:"name"`
-	Email string `json:"email"`
+	ID     int64       `json:"id"`
+	Name   string      `json:"name"`
+	Email  string      `json:"email"`
+	UserID pgtype.Int8 `json:"user_id"`
 }
 
-func (q *Queries) UpdateAuthor(ctx context.Context, arg UpdateAuthorParams) error {
-	_, err := q.db.Exec(ctx, updateAuthor, arg.ID, arg.Name, arg.Email)
-	return err
+func (q *Queries) UpdateAuthor(ctx context.Context, arg UpdateAuthorParams) (Author, error) {
+	row := q.db.QueryRow(ctx, updateAuthor,
+		arg.ID,
+		arg.Name,
+		arg.Email,
+		arg.UserID,
+	)
+	var i Author
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+	)
+	return i, err
 }
@@ -14,6 +14,213 @@ type Author struct {
 	Email     string           `json:"email"`
 	CreatedAt pgtype.Timestamp `json:"created_at"`
 	UpdatedAt pgtype.Timestamp `json:"updated_at"`
+	UserID    pgtype.Int8      `json:"user_id"`
+}
+
+type AuthorCollection struct {
+	RepositoryID   int64              `json:"repository_id"`
+	Path           string             `json:"path"`
+	FrontmatterKey string             `json:"frontmatter_key"`
+	SyncedAt       pgtype.Timestamptz `json:"synced_at"`
+	CreatedAt      pgtype.Timestamp   `json:"created_at"`
+}
+
+type AuthorCollectionEntry struct {
+	RepositoryID    int64            `json:"repository_id"`
+	Slug            string           `json:"slug"`
+	AuthorID        pgtype.Int8      `json:"author_id"`
+	FileHash        string           `json:"file_hash"`
+	AuthorUpdatedAt pgtype.Timestamp `json:"author_updated_at"`
+}
+
+type AuthorCollectionMember struct {
+	RepositoryID int64            `json:"repository_id"`
+	AuthorID     int64            `json:"author_id"`
+	CreatedAt    pgtype.Timestamp `json:"created_at"`
+}
+
+type ChangeSet struct {
+	ID              int64            `json:"id"`
+	RepositoryID    int64            `json:"repository_id"`
+	Title           string           `json:"title"`
+	Description     string           `json:"description"`
+	AuthorID        int64            `json:"author_id"`
+	State           string           `json:"state"`
+	PublishedCommit string           `json:"published_commit"`
+	CreatedAt       pgtype.Timestamp `json:"created_at"`
+	UpdatedAt       pgtype.Timestamp `json:"updated_at"`
+}
+
+type ChangeSetApproval struct {
+	ChangeSetID int64            `json:"change_set_id"`
+	UserID      int64            `json:"user_id"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+}
+
+type ChangeSetFile struct {
+	ChangeSetID int64  `json:"change_set_id"`
+	Path        string `json:"path"`
+}
+
+type ChangeSetTransition struct {
+	ID          int64            `json:"id"`
+	ChangeSetID int64            `json:"change_set_id"`
+	UserID      int64            `json:"user_id"`
+	Action      string           `json:"action"`
+	FromState   string           `json:"from_state"`
+	ToState     string           `json:"to_state"`
+	Note        string           `json:"note"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+}
+
+type CollabDocument struct {
+	ID           int64            `json:"id"`
+	RepositoryID int64            `json:"repository_id"`
+	Path         string           `json:"path"`
+	BaseHash     string           `json:"base_hash"`
+	BaseBody     string           `json:"base_body"`
+	FileHash     string           `json:"file_hash"`
+	CreatedAt    pgtype.Timestamp `json:"created_at"`
+	UpdatedAt    pgtype.Timestamp `json:"updated_at"`
+}
+
+type CollabOp struct {
+	DocumentID int64            `json:"document_id"`
+	Seq        int64            `json:"seq"`
+	UserID     int64            `json:"user_id"`
+	Op         []byte           `json:"op"`
+	CreatedAt  pgtype.Timestamp `json:"created_at"`
+}
+
+type Comment struct {
+	ID        int64            `json:"id"`
+	ThreadID  int64            `json:"thread_id"`
+	UserID    int64            `json:"user_id"`
+	Body      string           `json:"body"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type CommentMention struct {
+	CommentID int64 `json:"comment_id"`
+	UserID    int64 `json:"user_id"`
+}
+
+type CommentThread struct {
+	ID           int64            `json:"id"`
+	RepositoryID int64            `json:"repository_id"`
+	Path         string           `json:"path"`
+	LineStart    int32            `json:"line_start"`
+	LineEnd      int32            `json:"line_end"`
+	Field        string           `json:"field"`
+	AnchorText   string           `json:"anchor_text"`
+	BaseHash     string           `json:"base_hash"`
+	Outdated     bool             `json:"outdated"`
+	CreatedBy    int64            `json:"created_by"`
+	ResolvedBy   pgtype.Int8      `json:"resolved_by"`
+	ResolvedAt   pgtype.Timestamp `json:"resolved_at"`
+	CreatedAt    pgtype.Timestamp `json:"created_at"`
+	UpdatedAt    pgtype.Timestamp `json:"updated_at"`
+}
+
+type Draft struct {
+	ID           int64            `json:"id"`
+	UserID       int64            `json:"user_id"`
+	RepositoryID int64            `json:"repository_id"`
+	Path         string           `json:"path"`
+	BaseHash     string           `json:"base_hash"`
+	Body         string           `json:"body"`
+	CreatedAt    pgtype.Timestamp `json:"created_at"`
+	UpdatedAt    pgtype.Timestamp `json:"updated_at"`
+}
+
+type FileLock struct {
+	RepositoryID int64            `json:"repository_id"`
+	Path         string           `json:"path"`
+	UserID       int64            `json:"user_id"`
+	AcquiredAt   pgtype.Timestamp `json:"acquired_at"`
+	ExpiresAt    pgtype.Timestamp `json:"expires_at"`
+}
+
+type FilePresence struct {
+	UserID       int64            `json:"user_id"`
+	RepositoryID int64            `json:"repository_id"`
+	Path         string           `json:"path"`
+	LastSeenAt   pgtype.Timestamp `json:"last_seen_at"`
+}
+
+type Notification struct {
+	ID        int64            `json:"id"`
+	UserID    int64            `json:"user_id"`
+	Level     string           `json:"level"`
+	Message   string           `json:"message"`
+	Link      string           `json:"link"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+	ReadAt    pgtype.Timestamp `json:"read_at"`
+}
+
+type PersonalAccessToken struct {
+	ID           int64              `json:"id"`
+	UserID       int64              `json:"user_id"`
+	Name         string             `json:"name"`
+	TokenHash    string             `json:"token_hash"`
+	RepositoryID pgtype.Int8        `json:"repository_id"`
+	Permission   string             `json:"permission"`
+	ExpiresAt    pgtype.Timestamptz `json:"expires_at"`
+	LastUsedAt   pgtype.Timestamptz `json:"last_used_at"`
+	RevokedAt    pgtype.Timestamptz `json:"revoked_at"`
+	CreatedAt    pgtype.Timestamp   `json:"created_at"`
+}
+
+type RateLimit struct {
+	Key       string             `json:"key"`
+	Tokens    float64            `json:"tokens"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+type Repository struct {
+	ID             int64            `json:"id"`
+	OwnerID        int64            `json:"owner_id"`
+	FullName       string           `json:"full_name"`
+	DefaultBranch  string           `json:"default_branch"`
+	ContentPath    string           `json:"content_path"`
+	SearchLanguage string           `json:"search_language"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+	UpdatedAt      pgtype.Timestamp `json:"updated_at"`
+}
+
+type RepositoryMember struct {
+	RepositoryID int64            `json:"repository_id"`
+	UserID       int64            `json:"user_id"`
+	Role         string           `json:"role"`
+	CreatedAt    pgtype.Timestamp `json:"created_at"`
+}
+
+type ScheduledPublish struct {
+	ID            int64              `json:"id"`
+	ChangeSetID   int64              `json:"change_set_id"`
+	RepositoryID  int64              `json:"repository_id"`
+	ScheduledBy   int64              `json:"scheduled_by"`
+	PublishAt     pgtype.Timestamptz `json:"publish_at"`
+	Timezone      string             `json:"timezone"`
+	Status        string             `json:"status"`
+	Attempts      int32              `json:"attempts"`
+	NextAttemptAt pgtype.Timestamptz `json:"next_attempt_at"`
+	LastError     string             `json:"last_error"`
+	CreatedAt     pgtype.Timestamp   `json:"created_at"`
+	UpdatedAt     pgtype.Timestamp   `json:"updated_at"`
+}
+
+type SearchDocument struct {
+	ID           int64            `json:"id"`
+	RepositoryID int64            `json:"repository_id"`
+	Path         string           `json:"path"`
+	Language     string           `json:"language"`
+	Title        string           `json:"title"`
+	Description  string           `json:"description"`
+	Headings     string           `json:"headings"`
+	Body         string           `json:"body"`
+	SearchVector interface{}      `json:"search_vector"`
+	UpdatedAt    pgtype.Timestamp `json:"updated_at"`
 }
 
 type User struct {
@@ -25,3 +232,34 @@ type User struct {
 	CreatedAt pgtype.Timestamp `json:"created_at"`
 	UpdatedAt pgtype.Timestamp `json:"updated_at"`
 }
+
+type WebhookDelivery struct {
+	ID             int64              `json:"id"`
+	EndpointID     int64              `json:"endpoint_id"`
+	Event          string             `json:"event"`
+	Payload        []byte             `json:"payload"`
+	Status         string             `json:"status"`
+	Attempts       int32              `json:"attempts"`
+	NextAttemptAt  pgtype.Timestamptz `json:"next_attempt_at"`
+	ResponseStatus int32              `json:"response_status"`
+	LastError      string             `json:"last_error"`
+	DeliveredAt    pgtype.Timestamptz `json:"delivered_at"`
+	RedeliveryOf   pgtype.Int8        `json:"redelivery_of"`
+	CreatedAt      pgtype.Timestamp   `json:"created_at"`
+}
+
+type WebhookEndpoint struct {
+	ID           int64            `json:"id"`
+	RepositoryID int64            `json:"repository_id"`
+	Url          string           `json:"url"`
+	Secret       string           `json:"secret"`
+	Events       []string         `json:"events"`
+	CreatedAt    pgtype.Timestamp `json:"created_at"`
+}
+
+type WorkflowSetting struct {
+	RepositoryID      int64            `json:"repository_id"`
+	RequiredApprovals int32            `json:"required_approvals"`
+	ApproverRole      string           `json:"approver_role"`
+	UpdatedAt         pgtype.Timestamp `json:"updated_at"`
+}
@@ -6,18 +6,153 @@ package db
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Querier interface {
+	AcquireFileLock(ctx context.Context, arg AcquireFileLockParams) (FileLock, error)
+	AddAuthorCollectionMember(ctx context.Context, arg AddAuthorCollectionMemberParams) error
+	AddChangeSetFile(ctx context.Context, arg AddChangeSetFileParams) error
+	AdvisoryUnlock(ctx context.Context, key int64) error
+	CountDueScheduledPublishes(ctx context.Context) (int64, error)
+	CountDueWebhookDeliveries(ctx context.Context) (int64, error)
 	CreateAuthor(ctx context.Context, arg CreateAuthorParams) (Author, error)
-	DeleteAuthor(ctx context.Context, id int64) error
+	CreateChangeSet(ctx context.Context, arg CreateChangeSetParams) (ChangeSet, error)
+	CreateChangeSetApproval(ctx context.Context, arg CreateChangeSetApprovalParams) error
+	CreateChangeSetTransition(ctx context.Context, arg CreateChangeSetTransitionParams) error
+	CreateComment(ctx context.Context, arg CreateCommentParams) (Comment, error)
+	CreateCommentMention(ctx context.Context, arg CreateCommentMentionParams) error
+	CreateCommentThread(ctx context.Context, arg CreateCommentThreadParams) (CommentThread, error)
+	CreateNotification(ctx context.Context, arg CreateNotificationParams) error
+	CreatePersonalAccessToken(ctx context.Context, arg CreatePersonalAccessTokenParams) (PersonalAccessToken, error)
+	CreateWebhookEndpoint(ctx context.Context, arg CreateWebhookEndpointParams) (WebhookEndpoint, error)
+	DeleteAuthor(ctx context.Context, id int64) (int64, error)
+	DeleteAuthorCollection(ctx context.Context, repositoryID int64) error
+	DeleteAuthorCollectionEntry(ctx context.Context, arg DeleteAuthorCollectionEntryParams) error
+	DeleteAuthorCollectionMember(ctx context.Context, arg DeleteAuthorCollectionMemberParams) error
+	DeleteChangeSetApprovals(ctx context.Context, changeSetID int64) error
+	DeleteDraft(ctx context.Context, arg DeleteDraftParams) error
+	DeleteFilePresence(ctx context.Context, arg DeleteFilePresenceParams) error
+	// Buckets idle this long have refilled, so dropping them changes nothing.
+	DeleteIdleRateLimits(ctx context.Context, idleSince pgtype.Timestamptz) error
+	DeleteRepositoryMember(ctx context.Context, arg DeleteRepositoryMemberParams) error
+	DeleteScheduledPublish(ctx context.Context, changeSetID int64) error
+	DeleteSearchDocument(ctx context.Context, arg DeleteSearchDocumentParams) error
+	DeleteStaleSearchDocuments(ctx context.Context, arg DeleteStaleSearchDocumentsParams) error
+	DeleteWebhookEndpoint(ctx context.Context, arg DeleteWebhookEndpointParams) (int64, error)
+	// Queues an event for every endpoint of the repository subscribed to it.
+	EnqueueWebhookDeliveries(ctx context.Context, arg EnqueueWebhookDeliveriesParams) (int64, error)
+	// An unrevoked, unexpired token by the hash of its secret, with its user.
+	GetActivePersonalAccessToken(ctx context.Context, tokenHash string) (GetActivePersonalAccessTokenRow, error)
 	GetAuthorByEmail(ctx context.Context, email string) (Author, error)
 	GetAuthorByID(ctx context.Context, id int64) (Author, error)
+	GetAuthorCollection(ctx context.Context, repositoryID int64) (AuthorCollection, error)
+	GetChangeSet(ctx context.Context, arg GetChangeSetParams) (GetChangeSetRow, error)
+	GetChangeSetRepositoryID(ctx context.Context, id int64) (int64, error)
+	GetCollabDocument(ctx context.Context, arg GetCollabDocumentParams) (CollabDocument, error)
+	GetCommentThread(ctx context.Context, arg GetCommentThreadParams) (CommentThread, error)
+	GetDraft(ctx context.Context, arg GetDraftParams) (Draft, error)
+	GetDraftByID(ctx context.Context, arg GetDraftByIDParams) (Draft, error)
+	GetFileLock(ctx context.Context, arg GetFileLockParams) (GetFileLockRow, error)
+	GetRepository(ctx context.Context, id int64) (Repository, error)
+	GetRepositoryMemberRole(ctx context.Context, arg GetRepositoryMemberRoleParams) (string, error)
+	GetScheduledPublish(ctx context.Context, changeSetID int64) (ScheduledPublish, error)
 	GetUser(ctx context.Context, id int64) (User, error)
+	GetUserByEmail(ctx context.Context, email string) (User, error)
 	GetUserByGithubID(ctx context.Context, githubID string) (User, error)
-	ListAuthors(ctx context.Context) ([]Author, error)
-	UpdateAuthor(ctx context.Context, arg UpdateAuthorParams) error
+	GetWebhookEndpoint(ctx context.Context, arg GetWebhookEndpointParams) (WebhookEndpoint, error)
+	GetWorkflowSettings(ctx context.Context, repositoryID int64) (WorkflowSetting, error)
+	InsertCollabOp(ctx context.Context, arg InsertCollabOpParams) error
+	// The newest change set transitions of each repository, at most
+	// per_repository of them.
+	ListActivityByRepositories(ctx context.Context, arg ListActivityByRepositoriesParams) ([]ListActivityByRepositoriesRow, error)
+	ListAuthorCollectionEntries(ctx context.Context, repositoryID int64) ([]AuthorCollectionEntry, error)
+	ListAuthorCollectionMembers(ctx context.Context, repositoryID int64) ([]int64, error)
+	// A page of authors whose names contain search, ordered by name after the
+	// (name, id) keyset cursor; the first page starts after an empty name.
+	ListAuthors(ctx context.Context, arg ListAuthorsParams) ([]Author, error)
+	ListChangeSetApprovals(ctx context.Context, changeSetID int64) ([]ListChangeSetApprovalsRow, error)
+	ListChangeSetFiles(ctx context.Context, changeSetID int64) ([]string, error)
+	ListChangeSetFilesByChangeSets(ctx context.Context, changeSetIds []int64) ([]ChangeSetFile, error)
+	ListChangeSetTransitions(ctx context.Context, changeSetID int64) ([]ListChangeSetTransitionsRow, error)
+	ListChangeSetTransitionsByChangeSets(ctx context.Context, changeSetIds []int64) ([]ChangeSetTransition, error)
+	ListChangeSets(ctx context.Context, repositoryID int64) ([]ListChangeSetsRow, error)
+	ListChangeSetsByIDs(ctx context.Context, ids []int64) ([]ChangeSet, error)
+	ListChangeSetsByRepositories(ctx context.Context, repositoryIds []int64) ([]ChangeSet, error)
+	// Change sets awaiting review in every repository the user can access.
+	ListChangeSetsInReview(ctx context.Context, userID int64) ([]ListChangeSetsInReviewRow, error)
+	ListCollabOps(ctx context.Context, arg ListCollabOpsParams) ([]ListCollabOpsRow, error)
+	ListCommentThreads(ctx context.Context, arg ListCommentThreadsParams) ([]ListCommentThreadsRow, error)
+	ListCommentsByPath(ctx context.Context, arg ListCommentsByPathParams) ([]ListCommentsByPathRow, error)
+	ListDraftsByPath(ctx context.Context, arg ListDraftsByPathParams) ([]ListDraftsByPathRow, error)
+	ListDueScheduledPublishes(ctx context.Context, limit int32) ([]ScheduledPublish, error)
+	// Pending deliveries whose next attempt is due, with where to send them.
+	ListDueWebhookDeliveries(ctx context.Context, limit int32) ([]ListDueWebhookDeliveriesRow, error)
+	ListFilePresence(ctx context.Context, arg ListFilePresenceParams) ([]ListFilePresenceRow, error)
+	// The user's unrevoked tokens, expired ones included, newest first.
+	ListPersonalAccessTokens(ctx context.Context, userID int64) ([]ListPersonalAccessTokensRow, error)
+	ListRepositories(ctx context.Context) ([]Repository, error)
+	// Repositories whose authors collection lists the author.
+	ListRepositoriesByAuthor(ctx context.Context, authorID int64) ([]Repository, error)
+	ListRepositoriesByOwner(ctx context.Context, ownerID int64) ([]Repository, error)
+	// Repositories the user owns or is a member of.
+	ListRepositoriesForUser(ctx context.Context, userID int64) ([]Repository, error)
+	ListRepositoryMembers(ctx context.Context, repositoryID int64) ([]ListRepositoryMembersRow, error)
+	ListRepositoryMembersByRepositories(ctx context.Context, repositoryIds []int64) ([]RepositoryMember, error)
+	// The owner, members and everyone who has commented: the users that can be @mentioned.
+	ListRepositoryParticipants(ctx context.Context, repositoryID int64) ([]User, error)
+	ListUnpublishedChangeSetFiles(ctx context.Context, repositoryID int64) ([]ListUnpublishedChangeSetFilesRow, error)
+	ListUnreadNotifications(ctx context.Context, arg ListUnreadNotificationsParams) ([]Notification, error)
+	// Pending publishes in every repository the user can access, soonest first.
+	ListUpcomingScheduledPublishes(ctx context.Context, userID int64) ([]ListUpcomingScheduledPublishesRow, error)
+	ListUsers(ctx context.Context) ([]User, error)
+	ListUsersByIDs(ctx context.Context, ids []int64) ([]User, error)
+	// The endpoint's most recent deliveries, newest first.
+	ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	ListWebhookEndpoints(ctx context.Context, repositoryID int64) ([]WebhookEndpoint, error)
+	MarkAuthorCollectionSynced(ctx context.Context, repositoryID int64) error
+	// Holds the change set until the transaction ends, so transitions on it
+	// run one at a time.
+	LockChangeSet(ctx context.Context, arg LockChangeSetParams) (string, error)
+	MarkNotificationRead(ctx context.Context, arg MarkNotificationReadParams) error
+	NotifyFileEvent(ctx context.Context, payload string) error
+	OpenCollabDocument(ctx context.Context, arg OpenCollabDocumentParams) (CollabDocument, error)
+	// Queues a delivery of the same event to an endpoint of the repository again.
+	RedeliverWebhookDelivery(ctx context.Context, arg RedeliverWebhookDeliveryParams) (WebhookDelivery, error)
+	ReleaseFileLock(ctx context.Context, arg ReleaseFileLockParams) error
+	ReopenCommentThread(ctx context.Context, id int64) error
+	ResolveCommentThread(ctx context.Context, arg ResolveCommentThreadParams) error
+	// Frees a file hash for the session that just wrote it; retired sessions
+	// can no longer be joined.
+	RetireCollabDocuments(ctx context.Context, arg RetireCollabDocumentsParams) error
+	RevokePersonalAccessToken(ctx context.Context, arg RevokePersonalAccessTokenParams) (int64, error)
+	SearchDocuments(ctx context.Context, arg SearchDocumentsParams) ([]SearchDocumentsRow, error)
+	SetChangeSetPublishedCommit(ctx context.Context, arg SetChangeSetPublishedCommitParams) error
+	// Refills the bucket for the time since it was last used and takes a token
+	// if one is available. available is the balance before taking: the request
+	// is allowed when it is at least 1.
+	TakeRateLimitToken(ctx context.Context, arg TakeRateLimitTokenParams) (float64, error)
+	TouchPersonalAccessToken(ctx context.Context, id int64) error
+	// Session-level lock; must be released on the same connection.
+	TryAdvisoryLock(ctx context.Context, key int64) (bool, error)
+	UpdateAuthor(ctx context.Context, arg UpdateAuthorParams) (Author, error)
+	// Moves a change set on only if it is still in from_state, so concurrent
+	// transitions cannot both apply.
+	UpdateChangeSetState(ctx context.Context, arg UpdateChangeSetStateParams) (int64, error)
+	UpdateCollabDocumentFileHash(ctx context.Context, arg UpdateCollabDocumentFileHashParams) error
+	UpdateCommentThreadAnchor(ctx context.Context, arg UpdateCommentThreadAnchorParams) error
+	UpdateScheduledPublishAttempt(ctx context.Context, arg UpdateScheduledPublishAttemptParams) error
+	UpdateWebhookDeliveryAttempt(ctx context.Context, arg UpdateWebhookDeliveryAttemptParams) error
+	UpsertAuthorCollection(ctx context.Context, arg UpsertAuthorCollectionParams) (AuthorCollection, error)
+	UpsertAuthorCollectionEntry(ctx context.Context, arg UpsertAuthorCollectionEntryParams) error
+	UpsertDraft(ctx context.Context, arg UpsertDraftParams) (Draft, error)
+	UpsertFilePresence(ctx context.Context, arg UpsertFilePresenceParams) error
+	UpsertRepositoryMember(ctx context.Context, arg UpsertRepositoryMemberParams) error
+	UpsertScheduledPublish(ctx context.Context, arg UpsertScheduledPublishParams) (ScheduledPublish, error)
+	UpsertSearchDocument(ctx context.Context, arg UpsertSearchDocumentParams) error
 	UpsertUser(ctx context.Context, arg UpsertUserParams) (User, error)
+	UpsertWorkflowSettings(ctx context.Context, arg UpsertWorkflowSettingsParams) error
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,58 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type attrsKey struct{}
+
+// With returns a context whose log records carry the given attributes.
+func With(ctx context.Context, attrs ...slog.Attr) context.Context {
+	existing, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	merged := make([]slog.Attr, 0, len(existing)+len(attrs))
+	merged = append(append(merged, existing...), attrs...)
+	return context.WithValue(ctx, attrsKey{}, merged)
+}
+
+// WithRequestID tags log records with the request's id.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return With(ctx, slog.String("request_id", id))
+}
+
+// WithUserID tags log records with the signed-in user.
+func WithUserID(ctx context.Context, id int64) context.Context {
+	return With(ctx, slog.Int64("user_id", id))
+}
+
+// WithRepositoryID tags log records with the repository being worked on.
+func WithRepositoryID(ctx context.Context, id int64) context.Context {
+	return With(ctx, slog.Int64("repo_id", id))
+}
+
+// contextHandler adds the attributes stored in the context, and the current
+// span's ids, to each record.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if attrs, ok := ctx.Value(attrsKey{}).([]slog.Attr); ok {
+		r.AddAttrs(attrs...)
+	}
+	// Records logged within a span link to its trace
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,147 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/labstack/gommon/log"
+)
+
+// EchoLogger adapts a slog logger to echo.Logger, so Echo and handlers
+// calling c.Logger() log through slog.
+type EchoLogger struct {
+	logger *slog.Logger
+	level  log.Lvl
+	prefix string
+	ctx    func() context.Context
+}
+
+// NewEchoLogger wraps logger for use as e.Logger.
+func NewEchoLogger(logger *slog.Logger) *EchoLogger {
+	return &EchoLogger{logger: logger, level: log.DEBUG, ctx: context.Background}
+}
+
+// WithContext returns a logger that takes its attributes from ctx on every
+// call, such as the request, user and repository ids of a request whose
+// context changes as middleware runs.
+func (l *EchoLogger) WithContext(ctx func() context.Context) *EchoLogger {
+	clone := *l
+	clone.ctx = ctx
+	return &clone
+}
+
+// Output returns a writer that logs each write as one record, for Echo's
+// startup messages and the standard library logger.
+func (l *EchoLogger) Output() io.Writer {
+	return writer{l}
+}
+
+// SetOutput is a no-op; the output is chosen by the slog handler.
+func (l *EchoLogger) SetOutput(io.Writer) {}
+
+func (l *EchoLogger) Prefix() string          { return l.prefix }
+func (l *EchoLogger) SetPrefix(prefix string) { l.prefix = prefix }
+func (l *EchoLogger) Level() log.Lvl          { return l.level }
+func (l *EchoLogger) SetLevel(level log.Lvl)  { l.level = level }
+
+// SetHeader is a no-op; slog handlers format their own records.
+func (l *EchoLogger) SetHeader(string) {}
+
+func (l *EchoLogger) Print(i ...interface{})                 { l.log(log.INFO, fmt.Sprint(i...)) }
+func (l *EchoLogger) Printf(format string, a ...interface{}) { l.log(log.INFO, fmt.Sprintf(format, a...)) }
+func (l *EchoLogger) Printj(j log.JSON)                      { l.logj(log.INFO, j) }
+func (l *EchoLogger) Debug(i ...interface{})                 { l.log(log.DEBUG, fmt.Sprint(i...)) }
+func (l *EchoLogger) Debugf(format string, a ...interface{}) { l.log(log.DEBUG, fmt.Sprintf(format, a...)) }
+func (l *EchoLogger) Debugj(j log.JSON)                      { l.logj(log.DEBUG, j) }
+func (l *EchoLogger) Info(i ...interface{})                  { l.log(log.INFO, fmt.Sprint(i...)) }
+func (l *EchoLogger) Infof(format string, a ...interface{})  { l.log(log.INFO, fmt.Sprintf(format, a...)) }
+func (l *EchoLogger) Infoj(j log.JSON)                       { l.logj(log.INFO, j) }
+func (l *EchoLogger) Warn(i ...interface{})                  { l.log(log.WARN, fmt.Sprint(i...)) }
+func (l *EchoLogger) Warnf(format string, a ...interface{})  { l.log(log.WARN, fmt.Sprintf(format, a...)) }
+func (l *EchoLogger) Warnj(j log.JSON)                       { l.logj(log.WARN, j) }
+func (l *EchoLogger) Error(i ...interface{})                 { l.log(log.ERROR, fmt.Sprint(i...)) }
+func (l *EchoLogger) Errorf(format string, a ...interface{}) { l.log(log.ERROR, fmt.Sprintf(format, a...)) }
+func (l *EchoLogger) Errorj(j log.JSON)                      { l.logj(log.ERROR, j) }
+
+func (l *EchoLogger) Fatal(i ...interface{}) {
+	l.log(log.ERROR, fmt.Sprint(i...))
+	os.Exit(1)
+}
+
+func (l *EchoLogger) Fatalf(format string, a ...interface{}) {
+	l.log(log.ERROR, fmt.Sprintf(format, a...))
+	os.Exit(1)
+}
+
+func (l *EchoLogger) Fatalj(j log.JSON) {
+	l.logj(log.ERROR, j)
+	os.Exit(1)
+}
+
+func (l *EchoLogger) Panic(i ...interface{}) {
+	msg := fmt.Sprint(i...)
+	l.log(log.ERROR, msg)
+	panic(msg)
+}
+
+func (l *EchoLogger) Panicf(format string, a ...interface{}) {
+	msg := fmt.Sprintf(format, a...)
+	l.log(log.ERROR, msg)
+	panic(msg)
+}
+
+func (l *EchoLogger) Panicj(j log.JSON) {
+	l.logj(log.ERROR, j)
+	panic(fmt.Sprint(j))
+}
+
+func (l *EchoLogger) log(level log.Lvl, msg string, attrs ...slog.Attr) {
+	if level < l.level {
+		return
+	}
+	if l.prefix != "" {
+		msg = l.prefix + ": " + msg
+	}
+	l.logger.LogAttrs(l.ctx(), slogLevel(level), msg, attrs...)
+}
+
+// logj logs a JSON map's "message" key as the message and the rest as attributes.
+func (l *EchoLogger) logj(level log.Lvl, j log.JSON) {
+	msg, _ := j["message"].(string)
+	attrs := make([]slog.Attr, 0, len(j))
+	for k, v := range j {
+		if k != "message" {
+			attrs = append(attrs, slog.Any(k, v))
+		}
+	}
+	l.log(level, msg, attrs...)
+}
+
+func slogLevel(level log.Lvl) slog.Level {
+	switch level {
+	case log.DEBUG:
+		return slog.LevelDebug
+	case log.WARN:
+		return slog.LevelWarn
+	case log.ERROR:
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// writer logs each write as an info record.
+type writer struct {
+	l *EchoLogger
+}
+
+func (w writer) Write(p []byte) (int, error) {
+	if msg := strings.TrimSpace(string(p)); msg != "" {
+		w.l.log(log.INFO, msg)
+	}
+	return len(p), nil
+}
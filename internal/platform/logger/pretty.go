@@ -0,0 +1,123 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ANSI colors for development output
+const (
+	reset  = "\033[0m"
+	dim    = "\033[2m"
+	cyan   = "\033[36m"
+	green  = "\033[32m"
+	yellow = "\033[33m"
+	red    = "\033[31m"
+)
+
+// prettyHandler writes one colored line per record:
+//
+//	15:04:05.000 INFO  message key=value
+type prettyHandler struct {
+	w      io.Writer
+	mu     *sync.Mutex
+	level  slog.Leveler
+	attrs  []slog.Attr // Already qualified with their groups
+	prefix string      // Group names of attributes added later, dot-separated
+}
+
+func newPrettyHandler(w io.Writer, level slog.Leveler) *prettyHandler {
+	return &prettyHandler{w: w, mu: &sync.Mutex{}, level: level}
+}
+
+func (h *prettyHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *prettyHandler) Handle(_ context.Context, r slog.Record) error {
+	var buf bytes.Buffer
+	buf.WriteString(dim + r.Time.Format("15:04:05.000") + reset + " ")
+	buf.WriteString(levelColor(r.Level) + fmt.Sprintf("%-5s", r.Level.String()) + reset + " ")
+	buf.WriteString(r.Message)
+
+	for _, a := range h.attrs {
+		writeAttr(&buf, "", a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		writeAttr(&buf, h.prefix, a)
+		return true
+	})
+	buf.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := h.w.Write(buf.Bytes())
+	return err
+}
+
+func (h *prettyHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = append([]slog.Attr(nil), h.attrs...)
+	for _, a := range attrs {
+		a.Key = h.prefix + a.Key
+		clone.attrs = append(clone.attrs, a)
+	}
+	return &clone
+}
+
+func (h *prettyHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.prefix = h.prefix + name + "."
+	return &clone
+}
+
+// writeAttr writes " key=value", flattening groups into dotted keys.
+func writeAttr(buf *bytes.Buffer, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, g := range a.Value.Group() {
+			writeAttr(buf, prefix, g)
+		}
+		return
+	}
+
+	var value string
+	switch a.Value.Kind() {
+	case slog.KindTime:
+		value = a.Value.Time().Format(time.RFC3339)
+	default:
+		value = a.Value.String()
+	}
+	if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+		value = fmt.Sprintf("%q", value)
+	}
+	buf.WriteString(" " + dim + prefix + a.Key + "=" + reset + value)
+}
+
+func levelColor(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return red
+	case level >= slog.LevelWarn:
+		return yellow
+	case level >= slog.LevelInfo:
+		return cyan
+	default:
+		return green
+	}
+}
@@ -0,0 +1,120 @@
+// Package metrics exposes the application's Prometheus metrics.
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo-contrib/echoprometheus"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "goaat"
+
+// Background jobs, as labelled in the job metrics.
+const (
+	JobScheduledPublish = "scheduled_publish"
+	JobPreviewBuild     = "preview_build"
+	JobWebhookDelivery  = "webhook_delivery"
+)
+
+// Registry holds every metric the application exports, so only ours and the
+// runtime's are served rather than whatever libraries register globally.
+var Registry = prometheus.NewRegistry()
+
+var (
+	gitDuration = promauto.With(Registry).NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "git",
+		Name:      "operation_duration_seconds",
+		Help:      "Time taken by git commands, by subcommand.",
+		Buckets:   []float64{.01, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"operation"})
+
+	gitFailures = promauto.With(Registry).NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "git",
+		Name:      "operation_failures_total",
+		Help:      "Failed git commands, by subcommand.",
+	}, []string{"operation"})
+
+	jobDuration = promauto.With(Registry).NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "job",
+		Name:      "duration_seconds",
+		Help:      "Time taken by background jobs, by job and outcome.",
+		Buckets:   []float64{.1, .5, 1, 5, 10, 30, 60, 120, 300, 600},
+	}, []string{"job", "outcome"})
+
+	jobQueueDepth = promauto.With(Registry).NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "job",
+		Name:      "queue_depth",
+		Help:      "Background jobs waiting to run, by job.",
+	}, []string{"job"})
+
+	rateLimited = promauto.With(Registry).NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "rate_limited_total",
+		Help:      "Requests refused for exceeding a rate limit, by budget.",
+	}, []string{"budget"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Handler serves the registry in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// HTTP counts requests and their latency by route template, method and
+// status. Requests no route matched share an empty route label.
+func HTTP() echo.MiddlewareFunc {
+	return echoprometheus.NewMiddlewareWithConfig(echoprometheus.MiddlewareConfig{
+		Namespace:                 namespace,
+		Subsystem:                 "http",
+		Registerer:                Registry,
+		DoNotUseRequestPathFor404: true,
+		LabelFuncs: map[string]echoprometheus.LabelValueFunc{
+			// Host headers are client-controlled; one server has one host
+			"host": func(echo.Context, error) string { return "" },
+		},
+	})
+}
+
+// ObserveGit records a git command's duration and whether it failed.
+func ObserveGit(operation string, d time.Duration, err error) {
+	gitDuration.WithLabelValues(operation).Observe(d.Seconds())
+	if err != nil {
+		gitFailures.WithLabelValues(operation).Inc()
+	}
+}
+
+// ObserveJob records a background job's duration and outcome.
+func ObserveJob(job string, d time.Duration, err error) {
+	outcome := "success"
+	if err != nil {
+		outcome = "failure"
+	}
+	jobDuration.WithLabelValues(job, outcome).Observe(d.Seconds())
+}
+
+// SetQueueDepth records how many of a job are waiting to run.
+func SetQueueDepth(job string, n int) {
+	jobQueueDepth.WithLabelValues(job).Set(float64(n))
+}
+
+// ObserveRateLimited counts a request refused by a rate limit budget.
+func ObserveRateLimited(budget string) {
+	rateLimited.WithLabelValues(budget).Inc()
+}
@@ -0,0 +1,76 @@
+// Package tracing sets up OpenTelemetry tracing.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gracchi-stdio/goaat/internal/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ServiceName identifies this application in traces.
+const ServiceName = "goaat"
+
+const tracerName = "github.com/gracchi-stdio/goaat"
+
+// New creates the tracer provider and installs it globally with W3C trace
+// context propagation. Spans are exported over OTLP/HTTP when
+// cfg.TracingEndpoint is set, and dropped otherwise. Shut the provider down
+// to flush pending spans.
+func New(ctx context.Context, cfg *config.Config) (*sdktrace.TracerProvider, error) {
+	var exporter sdktrace.SpanExporter
+	if cfg.TracingEndpoint != "" {
+		var err error
+		exporter, err = otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(cfg.TracingEndpoint))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+		}
+	}
+
+	provider := NewProvider(exporter, cfg.Environment)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	return provider, nil
+}
+
+// NewProvider creates a tracer provider sending spans to exporter, or
+// dropping them if it is nil. Tests pass a tracetest.InMemoryExporter to
+// inspect the spans recorded.
+func NewProvider(exporter sdktrace.SpanExporter, environment string) *sdktrace.TracerProvider {
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewSchemaless(
+			semconv.ServiceName(ServiceName),
+			semconv.DeploymentEnvironmentName(environment),
+		)),
+	}
+	if exporter != nil {
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+	return sdktrace.NewTracerProvider(opts...)
+}
+
+// Start starts a span as a child of any span in ctx.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End ends a span, marking it failed if err is not nil.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
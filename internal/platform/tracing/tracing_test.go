@@ -0,0 +1,71 @@
+package tracing_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gracchi-stdio/goaat/internal/middleware"
+	"github.com/gracchi-stdio/goaat/internal/platform/tracing"
+	"github.com/jackc/pgx/v5"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/labstack/echo/otelecho"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestRequestAndQuerySpans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := tracing.NewProvider(exporter, "test")
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	e := echo.New()
+	e.Use(otelecho.Middleware(tracing.ServiceName))
+	e.Use(middleware.RequestID)
+	e.GET("/repositories/:id", func(c echo.Context) error {
+		// What the pool's tracer sees for a sqlc query run by the handler
+		var tracer pgx.QueryTracer = tracing.QueryTracer{}
+		ctx := tracer.TraceQueryStart(c.Request().Context(), nil, pgx.TraceQueryStartData{
+			SQL: "-- name: GetRepository :one\nSELECT * FROM repositories WHERE id = $1",
+		})
+		tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+		return c.NoContent(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/repositories/1", nil)
+	req.Header.Set(echo.HeaderXRequestID, "req-1")
+	e.ServeHTTP(httptest.NewRecorder(), req)
+	if err := provider.ForceFlush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("recorded %d spans, want the request and its query", len(spans))
+	}
+	query, server := spans[0], spans[1]
+
+	if server.Name != "GET /repositories/:id" || server.SpanKind != trace.SpanKindServer {
+		t.Errorf("server span = %q (%s), want GET /repositories/:id (server)", server.Name, server.SpanKind)
+	}
+	var requestID string
+	for _, attr := range server.Attributes {
+		if attr.Key == "request_id" {
+			requestID = attr.Value.AsString()
+		}
+	}
+	if requestID != "req-1" {
+		t.Errorf("server span request_id = %q, want %q", requestID, "req-1")
+	}
+
+	if query.Name != "GetRepository" {
+		t.Errorf("query span = %q, want GetRepository", query.Name)
+	}
+	if query.Parent.SpanID() != server.SpanContext.SpanID() || query.SpanContext.TraceID() != server.SpanContext.TraceID() {
+		t.Error("the query span is not a child of the request span")
+	}
+}
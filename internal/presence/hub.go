@@ -0,0 +1,151 @@
+package presence
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/labstack/echo/v4"
+)
+
+// Channel is the Postgres notification channel shared by all replicas.
+const Channel = "file_events"
+
+// EventType is what happened to a file.
+type EventType string
+
+const (
+	EventJoined   EventType = "joined"
+	EventLeft     EventType = "left"
+	EventEdited   EventType = "edited" // New operations in a co-editing session
+	EventSaved    EventType = "saved"
+	EventLocked   EventType = "locked"
+	EventUnlocked EventType = "unlocked"
+)
+
+// Event is a file activity notification, sent as JSON through NOTIFY.
+type Event struct {
+	Type         EventType `json:"type"`
+	RepositoryID int64     `json:"repository_id"`
+	Path         string    `json:"path"`
+	UserID       int64     `json:"user_id"`
+	UserName     string    `json:"user_name"`
+}
+
+// subscriberBuffer is how many events a slow subscriber may lag behind
+// before further events are dropped for it.
+const subscriberBuffer = 16
+
+// Hub listens for file events on a dedicated connection and fans them out
+// to this replica's subscribers by repository.
+type Hub struct {
+	pool      *pgxpool.Pool
+	logger    echo.Logger
+	listening atomic.Bool
+
+	mu   sync.Mutex
+	subs map[int64]map[chan Event]struct{}
+}
+
+// NewHub creates a hub that listens through a connection from pool.
+func NewHub(pool *pgxpool.Pool, logger echo.Logger) *Hub {
+	return &Hub{pool: pool, logger: logger, subs: make(map[int64]map[chan Event]struct{})}
+}
+
+// Subscribe registers for a repository's events. The returned func
+// unsubscribes and closes the channel.
+func (h *Hub) Subscribe(repoID int64) (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+
+	h.mu.Lock()
+	if h.subs[repoID] == nil {
+		h.subs[repoID] = make(map[chan Event]struct{})
+	}
+	h.subs[repoID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subs[repoID], ch)
+			if len(h.subs[repoID]) == 0 {
+				delete(h.subs, repoID)
+			}
+			h.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// Listen receives notifications until ctx is cancelled, reconnecting with
+// a short delay when the connection is lost.
+func (h *Hub) Listen(ctx context.Context) {
+	for {
+		if err := h.listen(ctx); err != nil && ctx.Err() == nil {
+			h.logger.Warnf("File event listener stopped: %v; reconnecting", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(2 * time.Second):
+		}
+	}
+}
+
+// Healthy reports whether the hub is listening for other replicas' events.
+func (h *Hub) Healthy() error {
+	if !h.listening.Load() {
+		return errors.New("not listening for file events")
+	}
+	return nil
+}
+
+func (h *Hub) listen(ctx context.Context) error {
+	conn, err := h.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, "LISTEN "+Channel); err != nil {
+		return err
+	}
+	h.listening.Store(true)
+	defer h.listening.Store(false)
+
+	for {
+		notification, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			// The connection may still be listening; never return it to the pool
+			conn.Conn().Close(context.Background())
+			return err
+		}
+
+		var event Event
+		if err := json.Unmarshal([]byte(notification.Payload), &event); err != nil {
+			h.logger.Warnf("Ignoring malformed file event: %v", err)
+			continue
+		}
+		h.dispatch(event)
+	}
+}
+
+func (h *Hub) dispatch(event Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subs[event.RepositoryID] {
+		select {
+		case ch <- event:
+		default:
+			// Subscribers refresh from the database, so a dropped event
+			// is caught up by the next heartbeat
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package presence
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// HeartbeatInterval is how often an open editor refreshes its presence.
+	HeartbeatInterval = 15 * time.Second
+
+	// presenceTTL is how long a presence row counts after its last heartbeat,
+	// so users whose connection dropped without a goodbye disappear.
+	presenceTTL = 3 * HeartbeatInterval
+
+	// LockTTL is how long a soft lock lasts unless it is renewed.
+	LockTTL = 30 * time.Minute
+)
+
+// ErrLocked is returned when another user holds the soft lock on a file.
+var ErrLocked = errors.New("file is locked by another user")
+
+// Viewer is a user with a file open.
+type Viewer struct {
+	UserID    int64
+	Name      string
+	AvatarURL string
+	Path      string
+}
+
+// Lock is an advisory soft lock on a file.
+type Lock struct {
+	Path      string
+	UserID    int64
+	UserName  string
+	ExpiresAt time.Time
+}
+
+// Service defines file presence, soft locks and file event delivery.
+type Service interface {
+	// Heartbeat marks the user as having the file open
+	Heartbeat(ctx context.Context, userID int64, repo db.Repository, path string) error
+
+	// Leave removes the user's presence on the file
+	Leave(ctx context.Context, userID int64, repo db.Repository, path string) error
+
+	// Viewers lists the users with files of the repository open, by path
+	Viewers(ctx context.Context, repo db.Repository) (map[string][]Viewer, error)
+
+	// Lock takes or renews the soft lock on a file
+	Lock(ctx context.Context, userID int64, repo db.Repository, path string) (*Lock, error)
+
+	// Unlock releases the user's soft lock on a file
+	Unlock(ctx context.Context, userID int64, repo db.Repository, path string) error
+
+	// CurrentLock returns the active soft lock on a file, or nil
+	CurrentLock(ctx context.Context, repo db.Repository, path string) (*Lock, error)
+
+	// Publish sends an event to subscribers on every replica
+	Publish(ctx context.Context, event Event) error
+
+	// Subscribe returns this replica's events for a repository and a cancel func
+	Subscribe(repoID int64) (<-chan Event, func())
+}
+
+type service struct {
+	queries db.Querier
+	hub     *Hub
+}
+
+// NewService creates a presence service. Events published through it reach
+// subscribers once hub is listening.
+func NewService(queries db.Querier, hub *Hub) Service {
+	return &service{queries: queries, hub: hub}
+}
+
+func (s *service) Heartbeat(ctx context.Context, userID int64, repo db.Repository, path string) error {
+	err := s.queries.UpsertFilePresence(ctx, db.UpsertFilePresenceParams{
+		UserID:       userID,
+		RepositoryID: repo.ID,
+		Path:         path,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record presence: %w", err)
+	}
+	return nil
+}
+
+func (s *service) Leave(ctx context.Context, userID int64, repo db.Repository, path string) error {
+	err := s.queries.DeleteFilePresence(ctx, db.DeleteFilePresenceParams{
+		UserID:       userID,
+		RepositoryID: repo.ID,
+		Path:         path,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove presence: %w", err)
+	}
+	return nil
+}
+
+func (s *service) Viewers(ctx context.Context, repo db.Repository) (map[string][]Viewer, error) {
+	rows, err := s.queries.ListFilePresence(ctx, db.ListFilePresenceParams{
+		RepositoryID: repo.ID,
+		TtlSeconds:   int32(presenceTTL / time.Second),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list presence: %w", err)
+	}
+
+	viewers := make(map[string][]Viewer)
+	for _, row := range rows {
+		viewers[row.Path] = append(viewers[row.Path], Viewer{
+			UserID:    row.UserID,
+			Name:      row.Name,
+			AvatarURL: row.AvatarUrl.String,
+			Path:      row.Path,
+		})
+	}
+	return viewers, nil
+}
+
+func (s *service) Lock(ctx context.Context, userID int64, repo db.Repository, path string) (*Lock, error) {
+	_, err := s.queries.AcquireFileLock(ctx, db.AcquireFileLockParams{
+		RepositoryID: repo.ID,
+		Path:         path,
+		UserID:       userID,
+		TtlSeconds:   int32(LockTTL / time.Second),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		// The conflict clause skipped the update: someone else holds a live lock
+		return nil, ErrLocked
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock file: %w", err)
+	}
+	return s.CurrentLock(ctx, repo, path)
+}
+
+func (s *service) Unlock(ctx context.Context, userID int64, repo db.Repository, path string) error {
+	err := s.queries.ReleaseFileLock(ctx, db.ReleaseFileLockParams{
+		RepositoryID: repo.ID,
+		Path:         path,
+		UserID:       userID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to unlock file: %w", err)
+	}
+	return nil
+}
+
+func (s *service) CurrentLock(ctx context.Context, repo db.Repository, path string) (*Lock, error) {
+	row, err := s.queries.GetFileLock(ctx, db.GetFileLockParams{RepositoryID: repo.ID, Path: path})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load file lock: %w", err)
+	}
+	return &Lock{Path: row.Path, UserID: row.UserID, UserName: row.UserName, ExpiresAt: row.ExpiresAt.Time}, nil
+}
+
+func (s *service) Publish(ctx context.Context, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode file event: %w", err)
+	}
+	// Delivered back to this replica through its own LISTEN connection
+	if err := s.queries.NotifyFileEvent(ctx, string(payload)); err != nil {
+		return fmt.Errorf("failed to publish file event: %w", err)
+	}
+	return nil
+}
+
+func (s *service) Subscribe(repoID int64) (<-chan Event, func()) {
+	return s.hub.Subscribe(repoID)
+}
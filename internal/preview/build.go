@@ -0,0 +1,236 @@
+package preview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gracchi-stdio/goaat/internal/content"
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+	"github.com/gracchi-stdio/goaat/internal/repository"
+)
+
+// build renders a change set in an isolated working copy: the repository's
+// current commit with the change set's files taken from the working copy.
+// Dependencies are installed once per repository and lockfile hash, and
+// later builds get a copy. Returns the lockfile hash.
+func (s *service) build(ctx context.Context, r *run, repo db.Repository, changeSetID int64, files []string) (string, error) {
+	work := filepath.Join(s.dir, "work", strconv.FormatInt(changeSetID, 10))
+	if err := os.RemoveAll(work); err != nil {
+		return "", fmt.Errorf("failed to clear working copy: %w", err)
+	}
+	defer os.RemoveAll(work)
+
+	src := repository.Dir(s.reposDir, repo.ID)
+	r.logf("Copying %s", repo.FullName)
+	if err := repository.Clone(ctx, src, work); err != nil {
+		return "", err
+	}
+	for _, file := range files {
+		if err := overlay(src, work, path.Join(repo.ContentPath, file)); err != nil {
+			return "", fmt.Errorf("failed to copy %s: %w", file, err)
+		}
+	}
+
+	lockfile, err := os.ReadFile(filepath.Join(work, "package-lock.json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", ErrNoLockfile
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read lockfile: %w", err)
+	}
+	hash := content.Hash(lockfile)
+
+	if err := s.dependencies(ctx, r, repo, work, hash); err != nil {
+		return hash, err
+	}
+	if err := s.exec(ctx, r, work, "npx", "--no-install", "astro", "build", "--base", URL(changeSetID)); err != nil {
+		return hash, err
+	}
+	return hash, s.publish(changeSetID, filepath.Join(work, "dist"))
+}
+
+// dependencies copies node_modules from the repository's cache for the
+// lockfile hash, running npm ci and filling the cache first if needed.
+// Each build gets its own copy, so nothing a build runs can change what
+// later builds install.
+func (s *service) dependencies(ctx context.Context, r *run, repo db.Repository, work, hash string) error {
+	cached := filepath.Join(s.dir, "deps", strconv.FormatInt(repo.ID, 10), hash, "node_modules")
+	modules := filepath.Join(work, "node_modules")
+
+	if _, err := os.Stat(cached); err == nil {
+		r.logf("Reusing dependencies for lockfile %s", hash[:12])
+		if err := copyTree(cached, modules); err != nil {
+			return fmt.Errorf("failed to copy cached dependencies: %w", err)
+		}
+		return nil
+	}
+
+	if err := s.exec(ctx, r, work, "npm", "ci", "--no-audit", "--no-fund"); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(cached), 0o755); err != nil {
+		return fmt.Errorf("failed to create dependency cache: %w", err)
+	}
+	// Cache what npm installed before the build runs, via a temporary
+	// directory so a half-written cache is never reused
+	tmp, err := os.MkdirTemp(filepath.Dir(cached), "node_modules-")
+	if err != nil {
+		return fmt.Errorf("failed to create dependency cache: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+	if err := copyTree(modules, filepath.Join(tmp, "node_modules")); err != nil {
+		return fmt.Errorf("failed to cache dependencies: %w", err)
+	}
+	if err := os.Rename(filepath.Join(tmp, "node_modules"), cached); err != nil {
+		// Another build cached the same lockfile first
+		if _, statErr := os.Stat(cached); statErr == nil {
+			return nil
+		}
+		return fmt.Errorf("failed to cache dependencies: %w", err)
+	}
+	return nil
+}
+
+// copyTree copies the directory src to dst, keeping file modes and
+// symlinks. io.Copy between files uses copy_file_range on Linux, which
+// shares blocks on filesystems that support reflinks.
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		to := filepath.Join(dst, rel)
+
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(to, 0o755)
+		case d.Type()&fs.ModeSymlink != 0:
+			target, err := os.Readlink(p)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(target, to)
+		case d.Type().IsRegular():
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			return copyFile(p, to, info.Mode().Perm())
+		}
+		return nil
+	})
+}
+
+func copyFile(src, dst string, perm fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// publish swaps in a new build output, so the previous preview is served
+// until the new one is complete.
+func (s *service) publish(changeSetID int64, dist string) error {
+	site := s.site(changeSetID)
+	if err := os.RemoveAll(site + ".next"); err != nil {
+		return fmt.Errorf("failed to clear previous output: %w", err)
+	}
+	if err := os.Rename(dist, site+".next"); err != nil {
+		return fmt.Errorf("failed to store build output: %w", err)
+	}
+	os.RemoveAll(site + ".old")
+	if err := os.Rename(site, site+".old"); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to replace previous output: %w", err)
+	}
+	if err := os.Rename(site+".next", site); err != nil {
+		return fmt.Errorf("failed to store build output: %w", err)
+	}
+	os.RemoveAll(site + ".old")
+	return nil
+}
+
+// exec runs a build step with the CPU limit, in its own process group so
+// the whole tree is killed when the build times out.
+func (s *service) exec(ctx context.Context, r *run, dir, name string, args ...string) error {
+	r.logf("$ %s %s", name, strings.Join(args, " "))
+
+	// ulimit applies to the shell and everything it execs
+	script := `ulimit -t "$0" && exec "$@"`
+	cmd := exec.CommandContext(ctx, "sh", append([]string{"-c", script, strconv.Itoa(s.options.CPUSeconds), name}, args...)...)
+	cmd.Dir = dir
+	cmd.Env = s.env(dir)
+	cmd.Stdout = r
+	cmd.Stderr = r
+	cmd.WaitDelay = 5 * time.Second
+	isolate(cmd)
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s %s failed: %w", name, args[0], err)
+	}
+	return nil
+}
+
+// env is a minimal environment, so builds do not see the server's secrets.
+func (s *service) env(work string) []string {
+	return []string{
+		"PATH=" + os.Getenv("PATH"),
+		"HOME=" + work,
+		"CI=true",
+		"ASTRO_TELEMETRY_DISABLED=1",
+		"npm_config_cache=" + filepath.Join(s.dir, "npm-cache"),
+		"npm_config_update_notifier=false",
+	}
+}
+
+// overlay copies a repo-relative file from the working copy src into the
+// build copy, or removes it there if it was deleted.
+func overlay(src, work, rel string) error {
+	from, err := content.SafeJoin(src, rel)
+	if err != nil {
+		return err
+	}
+	to, err := content.SafeJoin(work, rel)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(from)
+	if errors.Is(err, fs.ErrNotExist) {
+		if err := os.Remove(to); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(to), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(to, data, 0o644)
+}
@@ -0,0 +1,49 @@
+package preview
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCopyTree(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "node_modules")
+	for name, body := range map[string]string{
+		"astro/package.json":  `{"name":"astro"}`,
+		"astro/bin/astro.mjs": "#!/usr/bin/env node",
+	} {
+		file := filepath.Join(src, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(body), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(src, ".bin"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("../astro/bin/astro.mjs", filepath.Join(src, ".bin", "astro")); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := filepath.Join(t.TempDir(), "node_modules")
+	if err := copyTree(src, dst); err != nil {
+		t.Fatal(err)
+	}
+
+	if target, err := os.Readlink(filepath.Join(dst, ".bin", "astro")); err != nil || target != "../astro/bin/astro.mjs" {
+		t.Errorf("symlink = %q, %v; want it copied as a relative link", target, err)
+	}
+	if info, err := os.Stat(filepath.Join(dst, "astro", "bin", "astro.mjs")); err != nil || info.Mode().Perm() != 0o755 {
+		t.Errorf("executable = %v, %v; want mode 0755", info, err)
+	}
+
+	// A build writing to its copy leaves the cache alone
+	if err := os.WriteFile(filepath.Join(dst, "astro", "package.json"), []byte("tampered"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if body, _ := os.ReadFile(filepath.Join(src, "astro", "package.json")); string(body) != `{"name":"astro"}` {
+		t.Errorf("cache changed to %q after writing to the copy", body)
+	}
+}
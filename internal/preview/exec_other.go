@@ -0,0 +1,9 @@
+//go:build !unix
+
+package preview
+
+import "os/exec"
+
+// isolate is a no-op where process groups are not available; only the
+// direct child is killed on cancellation.
+func isolate(cmd *exec.Cmd) {}
@@ -0,0 +1,17 @@
+//go:build unix
+
+package preview
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// isolate starts the command in a new process group and kills the whole
+// group on cancellation, since npm and astro spawn children of their own.
+func isolate(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
@@ -0,0 +1,58 @@
+package preview
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrBuilding is returned when a change set's preview is already being built.
+	ErrBuilding = errors.New("preview is already building")
+
+	// ErrNoLockfile is returned for repositories without a package-lock.json,
+	// which npm ci needs.
+	ErrNoLockfile = errors.New("repository has no package-lock.json")
+)
+
+// Status is where a preview build stands.
+type Status string
+
+const (
+	StatusBuilding Status = "building"
+	StatusReady    Status = "ready"
+	StatusFailed   Status = "failed"
+)
+
+// Label returns the status's display name.
+func (s Status) Label() string {
+	switch s {
+	case StatusBuilding:
+		return "Building"
+	case StatusReady:
+		return "Ready"
+	default:
+		return "Failed"
+	}
+}
+
+// Build is the latest preview build of a change set, stored next to its
+// output so every replica sharing the preview directory sees it.
+type Build struct {
+	ChangeSetID  int64     `json:"change_set_id"`
+	Status       Status    `json:"status"`
+	LockfileHash string    `json:"lockfile_hash,omitempty"`
+	StartedAt    time.Time `json:"started_at"`
+	FinishedAt   time.Time `json:"finished_at,omitempty"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// URL is where a change set's built site is served.
+func URL(changeSetID int64) string {
+	return fmt.Sprintf("/preview/%d/", changeSetID)
+}
+
+// ContentSecurityPolicy is sent with every preview response. Built sites
+// run their own scripts, so they are sandboxed into an opaque origin: they
+// cannot read the application's cookies or call it as the signed-in user.
+const ContentSecurityPolicy = "sandbox allow-scripts allow-forms"
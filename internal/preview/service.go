@@ -0,0 +1,332 @@
+package preview
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+	"github.com/gracchi-stdio/goaat/internal/platform/metrics"
+	"github.com/gracchi-stdio/goaat/internal/platform/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// maxConcurrentBuilds bounds how many builds run at once on a replica.
+	maxConcurrentBuilds = 2
+
+	// maxLogLines is how much of a running build's log late followers get.
+	maxLogLines = 2000
+
+	// followerBuffer is how many log lines a slow follower may lag behind
+	// before further lines are dropped for it.
+	followerBuffer = 256
+)
+
+// Options limit what a build may use.
+type Options struct {
+	Timeout    time.Duration // Wall-clock limit of a whole build
+	CPUSeconds int           // CPU time limit of each build step
+}
+
+// Service builds change sets into rendered Starlight sites.
+type Service interface {
+	// Start builds a preview of the change set's files in the background
+	Start(repo db.Repository, changeSetID int64, files []string) error
+
+	// Build returns the change set's latest build, or nil if it was never built
+	Build(changeSetID int64) (*Build, error)
+
+	// Follow returns the log so far and a channel of new lines, closed when
+	// the build ends. Call stop when done following.
+	Follow(changeSetID int64) (lines []string, updates <-chan string, stop func())
+
+	// Root returns the directory with a change set's built site
+	Root(changeSetID int64) (string, bool)
+}
+
+type service struct {
+	dir      string
+	reposDir string
+	options  Options
+	slots    chan struct{}
+
+	mu      sync.Mutex
+	running map[int64]*run
+}
+
+// NewService creates a preview builder that keeps work, dependency caches
+// and built sites under dir, building from the clones in reposDir.
+func NewService(dir, reposDir string, options Options) Service {
+	return &service{
+		dir:      dir,
+		reposDir: reposDir,
+		options:  options,
+		slots:    make(chan struct{}, maxConcurrentBuilds),
+		running:  make(map[int64]*run),
+	}
+}
+
+func (s *service) Start(repo db.Repository, changeSetID int64, files []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.running[changeSetID]; ok {
+		return ErrBuilding
+	}
+	if b, _ := s.Build(changeSetID); b != nil && b.Status == StatusBuilding {
+		// Another replica is building it
+		return ErrBuilding
+	}
+
+	if err := os.MkdirAll(filepath.Join(s.dir, "sites"), 0o755); err != nil {
+		return fmt.Errorf("failed to create preview directory: %w", err)
+	}
+	logFile, err := os.Create(s.site(changeSetID) + ".log")
+	if err != nil {
+		return fmt.Errorf("failed to create build log: %w", err)
+	}
+
+	b := &Build{ChangeSetID: changeSetID, Status: StatusBuilding, StartedAt: time.Now()}
+	if err := s.save(b); err != nil {
+		logFile.Close()
+		return err
+	}
+
+	r := &run{log: logFile, followers: make(map[chan string]struct{})}
+	s.running[changeSetID] = r
+	metrics.SetQueueDepth(metrics.JobPreviewBuild, s.waiting())
+	go s.execute(r, b, repo, files)
+	return nil
+}
+
+// execute runs a build to completion and records its outcome.
+func (s *service) execute(r *run, b *Build, repo db.Repository, files []string) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.running, b.ChangeSetID)
+		metrics.SetQueueDepth(metrics.JobPreviewBuild, s.waiting())
+		s.mu.Unlock()
+		r.close()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.options.Timeout)
+	defer cancel()
+
+	acquired := false
+	select {
+	case s.slots <- struct{}{}:
+		acquired = true
+	default:
+		r.logf("Waiting for another build to finish...")
+		select {
+		case s.slots <- struct{}{}:
+			acquired = true
+		case <-ctx.Done():
+		}
+	}
+
+	err := ctx.Err()
+	if acquired {
+		defer func() { <-s.slots }()
+		s.mu.Lock()
+		r.started = true
+		metrics.SetQueueDepth(metrics.JobPreviewBuild, s.waiting())
+		s.mu.Unlock()
+
+		start := time.Now()
+		buildCtx, span := tracing.Start(ctx, "preview build", attribute.Int64("change_set.id", b.ChangeSetID))
+		b.LockfileHash, err = s.build(buildCtx, r, repo, b.ChangeSetID, files)
+		tracing.End(span, err)
+		metrics.ObserveJob(metrics.JobPreviewBuild, time.Since(start), err)
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("build took longer than %s", s.options.Timeout)
+	}
+
+	b.FinishedAt = time.Now()
+	if err != nil {
+		b.Status = StatusFailed
+		b.Error = err.Error()
+		r.logf("Build failed: %v", err)
+	} else {
+		b.Status = StatusReady
+		r.logf("Build finished in %s", b.FinishedAt.Sub(b.StartedAt).Round(time.Second))
+	}
+	if err := s.save(b); err != nil {
+		r.logf("Failed to record build: %v", err)
+	}
+}
+
+func (s *service) Build(changeSetID int64) (*Build, error) {
+	data, err := os.ReadFile(s.site(changeSetID) + ".json")
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read build: %w", err)
+	}
+	var b Build
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("failed to read build: %w", err)
+	}
+
+	// A build that outlived its timeout was cut short by a restart
+	if b.Status == StatusBuilding && time.Since(b.StartedAt) > s.options.Timeout+time.Minute {
+		b.Status = StatusFailed
+		b.Error = "build was interrupted"
+	}
+	return &b, nil
+}
+
+func (s *service) Follow(changeSetID int64) ([]string, <-chan string, func()) {
+	s.mu.Lock()
+	r, ok := s.running[changeSetID]
+	s.mu.Unlock()
+
+	if ok {
+		if lines, ch, stop, ok := r.follow(); ok {
+			return lines, ch, stop
+		}
+	}
+
+	// Finished, or building on another replica: show the log as it is
+	var lines []string
+	if data, err := os.ReadFile(s.site(changeSetID) + ".log"); err == nil {
+		lines = tail(strings.Split(strings.TrimRight(string(data), "\n"), "\n"), maxLogLines)
+	}
+	ch := make(chan string)
+	close(ch)
+	return lines, ch, func() {}
+}
+
+func (s *service) Root(changeSetID int64) (string, bool) {
+	root := s.site(changeSetID)
+	info, err := os.Stat(root)
+	return root, err == nil && info.IsDir()
+}
+
+// site is the directory of a change set's built site; its build record
+// and log sit next to it.
+func (s *service) site(changeSetID int64) string {
+	return filepath.Join(s.dir, "sites", strconv.FormatInt(changeSetID, 10))
+}
+
+// waiting counts the builds waiting for a slot. Callers hold s.mu.
+func (s *service) waiting() int {
+	n := 0
+	for _, r := range s.running {
+		if !r.started {
+			n++
+		}
+	}
+	return n
+}
+
+// save writes the build record atomically.
+func (s *service) save(b *Build) error {
+	data, err := json.Marshal(b)
+	if err != nil {
+		return fmt.Errorf("failed to encode build: %w", err)
+	}
+	path := s.site(b.ChangeSetID) + ".json"
+	if err := os.WriteFile(path+".tmp", data, 0o644); err != nil {
+		return fmt.Errorf("failed to save build: %w", err)
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return fmt.Errorf("failed to save build: %w", err)
+	}
+	return nil
+}
+
+// run is a build in progress on this replica: its log file and the
+// clients following it.
+type run struct {
+	started bool // Holds a build slot; guarded by service.mu
+
+	mu        sync.Mutex
+	log       *os.File
+	lines     []string
+	followers map[chan string]struct{}
+	done      bool
+}
+
+func (r *run) logf(format string, args ...any) {
+	r.append(fmt.Sprintf(format, args...))
+}
+
+func (r *run) append(line string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fmt.Fprintln(r.log, line)
+	r.lines = tail(append(r.lines, line), maxLogLines)
+	for ch := range r.followers {
+		select {
+		case ch <- line:
+		default:
+			// The full log is shown again when the build ends
+		}
+	}
+}
+
+func (r *run) follow() ([]string, <-chan string, func(), bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.done {
+		return nil, nil, nil, false
+	}
+	ch := make(chan string, followerBuffer)
+	r.followers[ch] = struct{}{}
+	lines := append([]string(nil), r.lines...)
+
+	var once sync.Once
+	return lines, ch, func() {
+		once.Do(func() {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			if _, ok := r.followers[ch]; ok {
+				delete(r.followers, ch)
+				close(ch)
+			}
+		})
+	}, true
+}
+
+func (r *run) close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.done = true
+	r.log.Close()
+	for ch := range r.followers {
+		delete(r.followers, ch)
+		close(ch)
+	}
+}
+
+// Write splits command output into log lines. Partial trailing lines are
+// logged as they are; build tools flush whole lines in practice.
+func (r *run) Write(p []byte) (int, error) {
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		r.append(strings.TrimRight(line, "\r"))
+	}
+	return len(p), nil
+}
+
+// tail keeps the last n lines.
+func tail(lines []string, n int) []string {
+	if len(lines) > n {
+		return lines[len(lines)-n:]
+	}
+	return lines
+}
@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often full buckets are dropped, bounding memory to
+// the clients seen within roughly the longest refill time.
+const sweepInterval = time.Minute
+
+type memoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	swept   time.Time
+	now     func() time.Time
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	full    time.Time // when the bucket will have refilled
+}
+
+// NewMemoryStore creates a store that keeps buckets in this process. Each
+// replica then enforces its own budget.
+func NewMemoryStore() Store {
+	return &memoryStore{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+func (s *memoryStore) Take(_ context.Context, key string, limit Limit) (time.Duration, error) {
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.swept) >= sweepInterval {
+		for k, b := range s.buckets {
+			if now.After(b.full) {
+				delete(s.buckets, k)
+			}
+		}
+		s.swept = now
+	}
+
+	burst := float64(limit.Burst)
+	tokens := burst
+	if b, ok := s.buckets[key]; ok {
+		tokens = min(burst, b.tokens+now.Sub(b.updated).Seconds()*limit.rate())
+	}
+	retryAfter := limit.retryAfter(tokens)
+	if retryAfter == 0 {
+		tokens--
+	}
+
+	refill := time.Duration((burst - tokens) / limit.rate() * float64(time.Second))
+	s.buckets[key] = &bucket{tokens: tokens, updated: now, full: now.Add(refill)}
+	return retryAfter, nil
+}
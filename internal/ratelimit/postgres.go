@@ -0,0 +1,51 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// idleBucket is how long a bucket goes unused before it is deleted; it must
+// exceed the time any limit takes to refill.
+const idleBucket = 24 * time.Hour
+
+type postgresStore struct {
+	queries *db.Queries
+	swept   atomic.Int64 // Unix time of the last sweep
+}
+
+// NewPostgresStore creates a store that keeps buckets in the rate_limits
+// table, so every replica spends from the same budget.
+func NewPostgresStore(queries *db.Queries) Store {
+	return &postgresStore{queries: queries}
+}
+
+func (s *postgresStore) Take(ctx context.Context, key string, limit Limit) (time.Duration, error) {
+	s.sweep(ctx)
+
+	available, err := s.queries.TakeRateLimitToken(ctx, db.TakeRateLimitTokenParams{
+		Burst: float64(limit.Burst),
+		Rate:  limit.rate(),
+		Key:   key,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to take rate limit token: %w", err)
+	}
+	return limit.retryAfter(available), nil
+}
+
+// sweep deletes idle buckets at most once per sweepInterval across the
+// requests this replica serves. A failed sweep waits for the next one.
+func (s *postgresStore) sweep(ctx context.Context) {
+	now := time.Now()
+	last := s.swept.Load()
+	if now.Sub(time.Unix(last, 0)) < sweepInterval || !s.swept.CompareAndSwap(last, now.Unix()) {
+		return
+	}
+	_ = s.queries.DeleteIdleRateLimits(ctx, pgtype.Timestamptz{Time: now.Add(-idleBucket), Valid: true})
+}
@@ -0,0 +1,49 @@
+// Package ratelimit throttles requests with token buckets, kept in memory
+// for a single replica or in Postgres to share budgets between replicas.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Limit is a token bucket: Burst requests may be made at once, and one more
+// becomes available every Every.
+type Limit struct {
+	Burst int
+	Every time.Duration
+}
+
+// PerMinute allows n requests a minute, all of which may be made at once.
+func PerMinute(n int) Limit {
+	return Limit{Burst: n, Every: time.Minute / time.Duration(n)}
+}
+
+// PerHour allows n requests an hour, all of which may be made at once.
+func PerHour(n int) Limit {
+	return Limit{Burst: n, Every: time.Hour / time.Duration(n)}
+}
+
+// rate is the number of tokens regained per second.
+func (l Limit) rate() float64 {
+	return 1 / l.Every.Seconds()
+}
+
+// retryAfter is how long a bucket holding available tokens takes to regain
+// one, or zero when a token is available now.
+func (l Limit) retryAfter(available float64) time.Duration {
+	if available >= 1 {
+		return 0
+	}
+	seconds := (1 - available) / l.rate()
+	return time.Duration(math.Ceil(seconds * float64(time.Second)))
+}
+
+// Store keeps the token buckets.
+type Store interface {
+	// Take spends a token from the bucket under key. When the bucket is
+	// empty nothing is spent, and it returns how long until a token is
+	// available; it returns zero when the request may proceed.
+	Take(ctx context.Context, key string, limit Limit) (time.Duration, error)
+}
@@ -0,0 +1,96 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLimits(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit Limit
+		burst int
+		every time.Duration
+	}{
+		{"per minute", PerMinute(30), 30, 2 * time.Second},
+		{"per hour", PerHour(20), 20, 3 * time.Minute},
+		{"one a minute", PerMinute(1), 1, time.Minute},
+	}
+	for _, tt := range tests {
+		if tt.limit.Burst != tt.burst || tt.limit.Every != tt.every {
+			t.Errorf("%s = %+v, want burst %d every %s", tt.name, tt.limit, tt.burst, tt.every)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	limit := PerMinute(60) // a token a second
+	tests := []struct {
+		available float64
+		want      time.Duration
+	}{
+		{60, 0},
+		{1, 0},
+		{0.5, 500 * time.Millisecond},
+		{0, time.Second},
+		{0.75, 250 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		if got := limit.retryAfter(tt.available); got != tt.want {
+			t.Errorf("retryAfter(%v) = %s, want %s", tt.available, got, tt.want)
+		}
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := NewMemoryStore().(*memoryStore)
+	s.now = func() time.Time { return now }
+	limit := PerMinute(3) // a token every 20s
+
+	take := func(key string) time.Duration {
+		t.Helper()
+		wait, err := s.Take(ctx, key, limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return wait
+	}
+
+	for i := range 3 {
+		if wait := take("a"); wait != 0 {
+			t.Fatalf("request %d of the burst waited %s", i+1, wait)
+		}
+	}
+	if wait := take("a"); wait != 20*time.Second {
+		t.Fatalf("request past the burst waits %s, want 20s", wait)
+	}
+	if wait := take("b"); wait != 0 {
+		t.Fatalf("another key waited %s", wait)
+	}
+
+	// A refused request spends nothing
+	now = now.Add(15 * time.Second)
+	if wait := take("a"); wait != 5*time.Second {
+		t.Fatalf("after 15s the wait is %s, want 5s", wait)
+	}
+	now = now.Add(5 * time.Second)
+	if wait := take("a"); wait != 0 {
+		t.Fatalf("a refilled token was refused for %s", wait)
+	}
+	if wait := take("a"); wait != 20*time.Second {
+		t.Fatalf("the refilled token was spent twice, wait %s", wait)
+	}
+
+	// Buckets that have refilled are dropped by the next sweep
+	now = now.Add(sweepInterval + time.Second)
+	take("c")
+	if _, ok := s.buckets["a"]; ok {
+		t.Error("a refilled bucket survived the sweep")
+	}
+	if len(s.buckets) != 1 {
+		t.Errorf("%d buckets after the sweep, want only c", len(s.buckets))
+	}
+}
@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gracchi-stdio/goaat/internal/platform/metrics"
+	"github.com/gracchi-stdio/goaat/internal/platform/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Dir returns the working copy location of a repository under reposDir.
+func Dir(reposDir string, id int64) string {
+	return filepath.Join(reposDir, strconv.FormatInt(id, 10))
+}
+
+// LastCommitTimes returns the committer time of the most recent commit that
+// touched each file under pathspec, keyed by repo-relative slash path.
+// Files that were never committed are absent from the map.
+func LastCommitTimes(ctx context.Context, dir, pathspec string) (map[string]time.Time, error) {
+	out, err := git(ctx, dir, "log", "--format=%x00%cI", "--name-only", "--no-renames", "--", pathspec)
+	if err != nil {
+		return nil, err
+	}
+
+	times := make(map[string]time.Time)
+	var current time.Time
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if stamp, ok := strings.CutPrefix(line, "\x00"); ok {
+			current, err = time.Parse(time.RFC3339, stamp)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse commit time %q: %w", stamp, err)
+			}
+			continue
+		}
+		if line == "" {
+			continue
+		}
+		// git log is newest first, so the first time we see a path wins
+		if _, seen := times[line]; !seen {
+			times[line] = current
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read git log: %w", err)
+	}
+
+	return times, nil
+}
+
+// Commit is a commit that touched a file.
+type Commit struct {
+	Hash    string
+	Author  string
+	Time    time.Time
+	Subject string
+}
+
+// commitHash matches full or abbreviated commit hashes, so revisions taken
+// from requests can never be read as git options or ref expressions.
+var commitHash = regexp.MustCompile(`^[0-9a-f]{7,64}$`)
+
+// FileLog returns the commits that touched a repo-relative file, newest first.
+func FileLog(ctx context.Context, dir, file string, limit int) ([]Commit, error) {
+	out, err := git(ctx, dir, "log", "--format=%H%x00%an%x00%cI%x00%s", "--max-count="+strconv.Itoa(limit), "--", file)
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []Commit
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), "\x00", 4)
+		if len(fields) != 4 {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse commit time %q: %w", fields[2], err)
+		}
+		commits = append(commits, Commit{Hash: fields[0], Author: fields[1], Time: t, Subject: fields[3]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read git log: %w", err)
+	}
+
+	return commits, nil
+}
+
+// ShowFile returns a repo-relative file's contents at a commit.
+func ShowFile(ctx context.Context, dir, hash, file string) ([]byte, error) {
+	if !commitHash.MatchString(hash) {
+		return nil, fmt.Errorf("invalid commit hash %q", hash)
+	}
+	return git(ctx, dir, "show", hash+":"+file)
+}
+
+// ErrNothingToCommit is returned by CommitFiles when the files have no changes.
+var ErrNothingToCommit = errors.New("nothing to commit")
+
+// literalPathspecs makes git match paths exactly, since content file names
+// may contain glob characters such as "[slug].md".
+var literalPathspecs = []string{"GIT_LITERAL_PATHSPECS=1"}
+
+// ChangedFiles returns the repo-relative paths under the pathspecs that
+// differ from HEAD in the working copy, including new and deleted files.
+func ChangedFiles(ctx context.Context, dir string, pathspecs ...string) ([]string, error) {
+	args := append([]string{"status", "--porcelain=v1", "-z", "--untracked-files=all", "--no-renames", "--"}, pathspecs...)
+	out, err := gitEnv(ctx, dir, literalPathspecs, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range strings.Split(string(out), "\x00") {
+		// Entries are "XY path"
+		if len(entry) > 3 {
+			files = append(files, entry[3:])
+		}
+	}
+	return files, nil
+}
+
+// Signature identifies the author of a commit.
+type Signature struct {
+	Name  string
+	Email string
+}
+
+// CommitFiles commits the changes to the given repo-relative files, leaving
+// other working copy changes alone, and returns the new commit hash.
+func CommitFiles(ctx context.Context, dir string, files []string, message string, author Signature) (string, error) {
+	changed, err := ChangedFiles(ctx, dir, files...)
+	if err != nil {
+		return "", err
+	}
+	if len(changed) == 0 {
+		return "", ErrNothingToCommit
+	}
+
+	if _, err := gitEnv(ctx, dir, literalPathspecs, append([]string{"add", "--all", "--"}, changed...)...); err != nil {
+		return "", err
+	}
+	env := append([]string{
+		"GIT_AUTHOR_NAME=" + author.Name, "GIT_AUTHOR_EMAIL=" + author.Email,
+		"GIT_COMMITTER_NAME=" + author.Name, "GIT_COMMITTER_EMAIL=" + author.Email,
+	}, literalPathspecs...)
+	if _, err := gitEnv(ctx, dir, env, append([]string{"commit", "--quiet", "--message", message, "--"}, changed...)...); err != nil {
+		return "", err
+	}
+
+	out, err := git(ctx, dir, "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// Clone copies the working copy's current commit into a new, independent
+// repository at dst. Uncommitted changes are not copied.
+func Clone(ctx context.Context, src, dst string) error {
+	_, err := git(ctx, "", "clone", "--quiet", "--no-hardlinks", "--", src, dst)
+	return err
+}
+
+// git runs a git command in dir and returns its stdout.
+func git(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	return gitEnv(ctx, dir, nil, args...)
+}
+
+// gitEnv runs a git command in dir with extra environment variables.
+func gitEnv(ctx context.Context, dir string, env []string, args ...string) (out []byte, err error) {
+	ctx, span := tracing.Start(ctx, "git "+args[0], attribute.String("git.operation", args[0]))
+	defer func() { tracing.End(span, err) }()
+
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir, "-c", "core.quotepath=off"}, args...)...)
+	if env != nil {
+		cmd.Env = append(os.Environ(), env...)
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	start := time.Now()
+	out, err = cmd.Output()
+	metrics.ObserveGit(args[0], time.Since(start), err)
+	if err != nil {
+		return nil, fmt.Errorf("git %s failed: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
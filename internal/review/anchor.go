@@ -0,0 +1,90 @@
+package review
+
+import "strings"
+
+// Field is a top-level frontmatter key and the line it is declared on.
+type Field struct {
+	Name string
+	Line int
+}
+
+// Lines splits a file into lines; line numbers in this package are 1-based.
+func Lines(src string) []string {
+	src = strings.TrimSuffix(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+	if src == "" {
+		return nil
+	}
+	return strings.Split(src, "\n")
+}
+
+// Fields lists the top-level keys of the file's frontmatter block.
+func Fields(lines []string) []Field {
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return nil
+	}
+	var fields []Field
+	for i := 1; i < len(lines); i++ {
+		line := lines[i]
+		if strings.TrimSpace(line) == "---" {
+			return fields
+		}
+		if line == "" || line[0] == ' ' || line[0] == '\t' || line[0] == '#' || line[0] == '-' {
+			continue
+		}
+		if key, _, ok := strings.Cut(line, ":"); ok {
+			fields = append(fields, Field{Name: strings.TrimSpace(key), Line: i + 1})
+		}
+	}
+	// An unterminated block is not frontmatter
+	return nil
+}
+
+// fieldLine returns the line a frontmatter key is declared on, or 0.
+func fieldLine(lines []string, name string) int {
+	for _, f := range Fields(lines) {
+		if f.Name == name {
+			return f.Line
+		}
+	}
+	return 0
+}
+
+// excerpt joins lines start..end, which must be in range.
+func excerpt(lines []string, start, end int) string {
+	return strings.Join(lines[start-1:end], "\n")
+}
+
+// Reanchor finds where a thread's anchor is in the current file. Field
+// threads follow their frontmatter key; line threads follow their text,
+// preferring the match nearest the old position. ok is false when the
+// anchor is gone and the thread is outdated.
+func Reanchor(lines []string, start, end int, field, anchorText string) (newStart, newEnd int, ok bool) {
+	if field != "" {
+		if line := fieldLine(lines, field); line > 0 {
+			return line, line, true
+		}
+		return start, end, false
+	}
+
+	span := end - start + 1
+	best := 0
+	for i := 1; i+span-1 <= len(lines); i++ {
+		if excerpt(lines, i, i+span-1) != anchorText {
+			continue
+		}
+		if best == 0 || distance(i, start) < distance(best, start) {
+			best = i
+		}
+	}
+	if best == 0 {
+		return start, end, false
+	}
+	return best, best + span - 1, true
+}
+
+func distance(a, b int) int {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
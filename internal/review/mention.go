@@ -0,0 +1,72 @@
+package review
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+)
+
+// mentionPattern matches @handle; the preceding character is checked separately
+// so that email addresses are not treated as mentions.
+var mentionPattern = regexp.MustCompile(`@([A-Za-z0-9][A-Za-z0-9._-]*[A-Za-z0-9_]|[A-Za-z0-9])`)
+
+// Segment is a run of comment text; Mention is set when it names a participant.
+type Segment struct {
+	Text    string
+	Mention bool
+}
+
+// Handle is the name a user is @mentioned by: the local part of their email,
+// or their user name when they have none.
+func Handle(u db.User) string {
+	if local, _, ok := strings.Cut(u.Email, "@"); ok && local != "" {
+		return strings.ToLower(local)
+	}
+	return strings.ToLower(strings.Join(strings.Fields(u.Name), ""))
+}
+
+// mentions returns the lowercased handles mentioned in body.
+func mentions(body string) []string {
+	var handles []string
+	for _, m := range findMentions(body) {
+		handles = append(handles, strings.ToLower(body[m[2]:m[3]]))
+	}
+	return handles
+}
+
+// findMentions returns submatch indexes of the @handles in body.
+func findMentions(body string) [][]int {
+	var found [][]int
+	for _, m := range mentionPattern.FindAllStringSubmatchIndex(body, -1) {
+		if m[0] > 0 && isHandleChar(body[m[0]-1]) {
+			continue
+		}
+		found = append(found, m)
+	}
+	return found
+}
+
+func isHandleChar(c byte) bool {
+	return c == '.' || c == '_' || c == '-' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// Segments splits body into plain text and mentions of known handles.
+func Segments(body string, handles map[string]bool) []Segment {
+	var segments []Segment
+	last := 0
+	for _, m := range findMentions(body) {
+		if !handles[strings.ToLower(body[m[2]:m[3]])] {
+			continue
+		}
+		if m[0] > last {
+			segments = append(segments, Segment{Text: body[last:m[0]]})
+		}
+		segments = append(segments, Segment{Text: body[m[0]:m[1]], Mention: true})
+		last = m[1]
+	}
+	if last < len(body) {
+		segments = append(segments, Segment{Text: body[last:]})
+	}
+	return segments
+}
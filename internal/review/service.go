@@ -0,0 +1,367 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gracchi-stdio/goaat/internal/content"
+	"github.com/gracchi-stdio/goaat/internal/notification"
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+	"github.com/gracchi-stdio/goaat/internal/repository"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	// ErrUnknownThread is returned for thread IDs that do not belong to the repository.
+	ErrUnknownThread = errors.New("unknown comment thread")
+
+	// ErrInvalidAnchor is returned when a new thread's lines or field are not in the file.
+	ErrInvalidAnchor = errors.New("comment lines are not in the file")
+
+	// ErrEmptyComment is returned for comments without text.
+	ErrEmptyComment = errors.New("comment is empty")
+
+	// ErrAmbiguousMention is returned when an @handle names more than one participant.
+	ErrAmbiguousMention = errors.New("mention matches more than one participant")
+)
+
+// Thread is a comment thread anchored to lines of the current file.
+type Thread struct {
+	ID         int64
+	LineStart  int
+	LineEnd    int
+	Field      string
+	AnchorText string
+	Outdated   bool
+	ResolvedBy string
+	ResolvedAt time.Time
+	Comments   []Comment
+}
+
+// Resolved reports whether the thread has been resolved.
+func (t Thread) Resolved() bool {
+	return !t.ResolvedAt.IsZero()
+}
+
+// Comment is one message in a thread, split into text and mentions.
+type Comment struct {
+	ID        int64
+	Author    string
+	AvatarURL string
+	Time      time.Time
+	Segments  []Segment
+}
+
+// File is a content file with its re-anchored comment threads.
+type File struct {
+	Path    string
+	Hash    string
+	Lines   []string
+	Fields  []Field
+	Threads []Thread
+	Handles []string // Handles that can be @mentioned
+}
+
+// NewThread is the anchor and first comment of a thread. A non-empty
+// Field anchors the thread to that frontmatter key instead of lines.
+type NewThread struct {
+	LineStart int
+	LineEnd   int
+	Field     string
+	Body      string
+}
+
+// Service defines inline review comments on content files.
+type Service interface {
+	// File loads a file and its threads, re-anchoring them if the file changed
+	File(ctx context.Context, repo db.Repository, path string) (*File, error)
+
+	// CreateThread starts a thread on the current version of a file
+	CreateThread(ctx context.Context, userID int64, repo db.Repository, path string, thread NewThread) (db.CommentThread, error)
+
+	// Reply adds a comment to a thread
+	Reply(ctx context.Context, userID int64, repo db.Repository, threadID int64, body string) error
+
+	// Resolve marks a thread as resolved by the user
+	Resolve(ctx context.Context, userID int64, repo db.Repository, threadID int64) error
+
+	// Reopen clears a thread's resolution
+	Reopen(ctx context.Context, repo db.Repository, threadID int64) error
+}
+
+type service struct {
+	queries       db.Querier
+	reposDir      string
+	notifications notification.Service
+	link          func(repoID int64, path string) string
+}
+
+// NewService creates a review service over the clones in reposDir; mentioned
+// users are notified with a link to the file's review page.
+func NewService(queries db.Querier, reposDir string, notifications notification.Service, link func(repoID int64, path string) string) Service {
+	return &service{queries: queries, reposDir: reposDir, notifications: notifications, link: link}
+}
+
+func (s *service) contentDir(repo db.Repository) string {
+	return filepath.Join(repository.Dir(s.reposDir, repo.ID), filepath.FromSlash(repo.ContentPath))
+}
+
+func (s *service) File(ctx context.Context, repo db.Repository, path string) (*File, error) {
+	src, hash, err := content.ReadFile(s.contentDir(repo), path)
+	if err != nil {
+		return nil, err
+	}
+	lines := Lines(string(src))
+
+	rows, err := s.queries.ListCommentThreads(ctx, db.ListCommentThreadsParams{RepositoryID: repo.ID, Path: path})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list comment threads: %w", err)
+	}
+	comments, err := s.queries.ListCommentsByPath(ctx, db.ListCommentsByPathParams{RepositoryID: repo.ID, Path: path})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list comments: %w", err)
+	}
+	handles, err := s.handles(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	byThread := make(map[int64][]Comment)
+	for _, c := range comments {
+		byThread[c.ThreadID] = append(byThread[c.ThreadID], Comment{
+			ID:        c.ID,
+			Author:    c.AuthorName,
+			AvatarURL: c.AuthorAvatarUrl.String,
+			Time:      c.CreatedAt.Time,
+			Segments:  Segments(c.Body, handles),
+		})
+	}
+
+	file := &File{Path: path, Hash: hash, Lines: lines, Fields: Fields(lines)}
+	for _, row := range rows {
+		thread := Thread{
+			ID:         row.ID,
+			LineStart:  int(row.LineStart),
+			LineEnd:    int(row.LineEnd),
+			Field:      row.Field,
+			AnchorText: row.AnchorText,
+			Outdated:   row.Outdated,
+			ResolvedBy: row.ResolvedByName.String,
+			ResolvedAt: row.ResolvedAt.Time,
+			Comments:   byThread[row.ID],
+		}
+		if row.BaseHash != hash && !row.Outdated {
+			if err := s.reanchor(ctx, &thread, lines, hash); err != nil {
+				return nil, err
+			}
+		}
+		file.Threads = append(file.Threads, thread)
+	}
+	for handle := range handles {
+		file.Handles = append(file.Handles, handle)
+	}
+	sort.Strings(file.Handles)
+	return file, nil
+}
+
+// reanchor moves a thread to where its anchor is in the file with the given
+// hash, or marks it outdated, and stores the result so it is done once per change.
+func (s *service) reanchor(ctx context.Context, thread *Thread, lines []string, hash string) error {
+	start, end, ok := Reanchor(lines, thread.LineStart, thread.LineEnd, thread.Field, thread.AnchorText)
+	thread.LineStart, thread.LineEnd, thread.Outdated = start, end, !ok
+
+	err := s.queries.UpdateCommentThreadAnchor(ctx, db.UpdateCommentThreadAnchorParams{
+		ID:        thread.ID,
+		LineStart: int32(start),
+		LineEnd:   int32(end),
+		BaseHash:  hash,
+		Outdated:  !ok,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to re-anchor comment thread: %w", err)
+	}
+	return nil
+}
+
+func (s *service) CreateThread(ctx context.Context, userID int64, repo db.Repository, path string, thread NewThread) (db.CommentThread, error) {
+	body := strings.TrimSpace(thread.Body)
+	if body == "" {
+		return db.CommentThread{}, ErrEmptyComment
+	}
+
+	src, hash, err := content.ReadFile(s.contentDir(repo), path)
+	if err != nil {
+		return db.CommentThread{}, err
+	}
+	lines := Lines(string(src))
+
+	start, end := thread.LineStart, thread.LineEnd
+	if thread.Field != "" {
+		start = fieldLine(lines, thread.Field)
+		end = start
+	}
+	if start < 1 || end < start || end > len(lines) {
+		return db.CommentThread{}, ErrInvalidAnchor
+	}
+	mentioned, err := s.mentioned(ctx, repo, body)
+	if err != nil {
+		return db.CommentThread{}, err
+	}
+
+	created, err := s.queries.CreateCommentThread(ctx, db.CreateCommentThreadParams{
+		RepositoryID: repo.ID,
+		Path:         path,
+		LineStart:    int32(start),
+		LineEnd:      int32(end),
+		Field:        thread.Field,
+		AnchorText:   excerpt(lines, start, end),
+		BaseHash:     hash,
+		CreatedBy:    userID,
+	})
+	if err != nil {
+		return db.CommentThread{}, fmt.Errorf("failed to create comment thread: %w", err)
+	}
+
+	if err := s.comment(ctx, userID, repo, created.ID, path, body, mentioned); err != nil {
+		return db.CommentThread{}, err
+	}
+	return created, nil
+}
+
+func (s *service) Reply(ctx context.Context, userID int64, repo db.Repository, threadID int64, body string) error {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return ErrEmptyComment
+	}
+	thread, err := s.thread(ctx, repo, threadID)
+	if err != nil {
+		return err
+	}
+	mentioned, err := s.mentioned(ctx, repo, body)
+	if err != nil {
+		return err
+	}
+	return s.comment(ctx, userID, repo, threadID, thread.Path, body, mentioned)
+}
+
+// comment stores a comment and its mentions, and notifies the mentioned
+// participants.
+func (s *service) comment(ctx context.Context, userID int64, repo db.Repository, threadID int64, path, body string, mentioned []int64) error {
+	created, err := s.queries.CreateComment(ctx, db.CreateCommentParams{ThreadID: threadID, UserID: userID, Body: body})
+	if err != nil {
+		return fmt.Errorf("failed to create comment: %w", err)
+	}
+
+	for _, id := range mentioned {
+		if err := s.queries.CreateCommentMention(ctx, db.CreateCommentMentionParams{CommentID: created.ID, UserID: id}); err != nil {
+			return fmt.Errorf("failed to record mention: %w", err)
+		}
+		if id == userID {
+			continue
+		}
+		message := fmt.Sprintf("You were mentioned in a comment on %s.", path)
+		if err := s.notifications.Notify(ctx, id, notification.LevelPrimary, message, s.link(repo.ID, path)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mentioned resolves the @mentions in body to participant IDs, rejecting
+// handles that more than one participant goes by.
+func (s *service) mentioned(ctx context.Context, repo db.Repository, body string) ([]int64, error) {
+	handles := mentions(body)
+	if len(handles) == 0 {
+		return nil, nil
+	}
+	participants, err := s.participants(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	var ids []int64
+	seen := make(map[int64]bool)
+	for _, handle := range handles {
+		matched := participants[handle]
+		if len(matched) > 1 {
+			return nil, fmt.Errorf("%w: @%s", ErrAmbiguousMention, handle)
+		}
+		if len(matched) == 1 && !seen[matched[0]] {
+			seen[matched[0]] = true
+			ids = append(ids, matched[0])
+		}
+	}
+	return ids, nil
+}
+
+func (s *service) Resolve(ctx context.Context, userID int64, repo db.Repository, threadID int64) error {
+	if _, err := s.thread(ctx, repo, threadID); err != nil {
+		return err
+	}
+	err := s.queries.ResolveCommentThread(ctx, db.ResolveCommentThreadParams{
+		ID:         threadID,
+		ResolvedBy: pgtype.Int8{Int64: userID, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to resolve comment thread: %w", err)
+	}
+	return nil
+}
+
+func (s *service) Reopen(ctx context.Context, repo db.Repository, threadID int64) error {
+	if _, err := s.thread(ctx, repo, threadID); err != nil {
+		return err
+	}
+	if err := s.queries.ReopenCommentThread(ctx, threadID); err != nil {
+		return fmt.Errorf("failed to reopen comment thread: %w", err)
+	}
+	return nil
+}
+
+// thread loads a thread of the repository.
+func (s *service) thread(ctx context.Context, repo db.Repository, threadID int64) (db.CommentThread, error) {
+	thread, err := s.queries.GetCommentThread(ctx, db.GetCommentThreadParams{ID: threadID, RepositoryID: repo.ID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return db.CommentThread{}, ErrUnknownThread
+	}
+	if err != nil {
+		return db.CommentThread{}, fmt.Errorf("failed to load comment thread: %w", err)
+	}
+	return thread, nil
+}
+
+// participants maps the @handles of the repository's participants to
+// the users who go by them.
+func (s *service) participants(ctx context.Context, repo db.Repository) (map[string][]int64, error) {
+	users, err := s.queries.ListRepositoryParticipants(ctx, repo.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list participants: %w", err)
+	}
+	participants := make(map[string][]int64, len(users))
+	for _, u := range users {
+		handle := Handle(u)
+		participants[handle] = append(participants[handle], u.ID)
+	}
+	return participants, nil
+}
+
+// handles returns the @handles that name exactly one of the repository's
+// participants.
+func (s *service) handles(ctx context.Context, repo db.Repository) (map[string]bool, error) {
+	participants, err := s.participants(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	handles := make(map[string]bool, len(participants))
+	for handle, ids := range participants {
+		if len(ids) == 1 {
+			handles[handle] = true
+		}
+	}
+	return handles, nil
+}
@@ -0,0 +1,105 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/gracchi-stdio/goaat/internal/notification"
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+)
+
+// fakeQueries keeps a thread's comments and mentions in memory.
+type fakeQueries struct {
+	db.Querier
+	participants []db.User
+	comments     []db.CreateCommentParams
+	mentions     []db.CreateCommentMentionParams
+}
+
+func (f *fakeQueries) GetCommentThread(ctx context.Context, arg db.GetCommentThreadParams) (db.CommentThread, error) {
+	return db.CommentThread{ID: arg.ID, RepositoryID: arg.RepositoryID, Path: "guides/intro.md"}, nil
+}
+
+func (f *fakeQueries) ListRepositoryParticipants(ctx context.Context, repositoryID int64) ([]db.User, error) {
+	return f.participants, nil
+}
+
+func (f *fakeQueries) CreateComment(ctx context.Context, arg db.CreateCommentParams) (db.Comment, error) {
+	f.comments = append(f.comments, arg)
+	return db.Comment{ID: int64(len(f.comments)), ThreadID: arg.ThreadID, UserID: arg.UserID, Body: arg.Body}, nil
+}
+
+func (f *fakeQueries) CreateCommentMention(ctx context.Context, arg db.CreateCommentMentionParams) error {
+	f.mentions = append(f.mentions, arg)
+	return nil
+}
+
+// fakeNotifications records who was notified.
+type fakeNotifications struct {
+	notification.Service
+	notified []int64
+	links    []string
+}
+
+func (f *fakeNotifications) Notify(ctx context.Context, userID int64, level notification.Level, message, link string) error {
+	f.notified = append(f.notified, userID)
+	f.links = append(f.links, link)
+	return nil
+}
+
+func TestReplyMentions(t *testing.T) {
+	participants := []db.User{
+		{ID: 1, Email: "ada@example.com"},
+		{ID: 2, Email: "grace@example.com"},
+		{ID: 3, Email: "sam@example.com"},
+		{ID: 4, Email: "sam@example.org"},
+	}
+	tests := []struct {
+		name      string
+		body      string
+		err       error
+		mentioned []int64
+		notified  []int64
+	}{
+		{"no mentions", "Looks good", nil, nil, nil},
+		{"one mention", "@grace can you check?", nil, []int64{2}, []int64{2}},
+		{"repeated mention", "@grace @Grace", nil, []int64{2}, []int64{2}},
+		{"self mention", "@ada note to self", nil, []int64{1}, nil},
+		{"unknown handle", "@nobody and mail ada@example.com", nil, nil, nil},
+		{"ambiguous handle", "@grace and @sam", ErrAmbiguousMention, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queries := &fakeQueries{participants: participants}
+			notifications := &fakeNotifications{}
+			link := func(repoID int64, path string) string { return path }
+			s := NewService(queries, t.TempDir(), notifications, link)
+
+			err := s.Reply(context.Background(), 1, db.Repository{ID: 1}, 7, tt.body)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Reply = %v, want %v", err, tt.err)
+			}
+			if tt.err != nil && len(queries.comments) != 0 {
+				t.Fatal("a rejected comment was stored")
+			}
+
+			var mentioned []int64
+			for _, m := range queries.mentions {
+				mentioned = append(mentioned, m.UserID)
+			}
+			if !slices.Equal(mentioned, tt.mentioned) {
+				t.Errorf("mentioned %v, want %v", mentioned, tt.mentioned)
+			}
+			if !slices.Equal(notifications.notified, tt.notified) {
+				t.Errorf("notified %v, want %v", notifications.notified, tt.notified)
+			}
+			for _, l := range notifications.links {
+				if l != "guides/intro.md" {
+					t.Errorf("notification links to %q, want the thread's file", l)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package revision
+
+import "strings"
+
+// Op is the kind of change of a diff line.
+type Op string
+
+const (
+	OpEqual  Op = "equal"
+	OpInsert Op = "insert"
+	OpDelete Op = "delete"
+)
+
+// maxDiffCells bounds the LCS table; larger inputs are shown as a full replacement.
+const maxDiffCells = 4_000_000
+
+// Line is one line of a line-based diff. OldNum and NewNum are 1-based line
+// numbers in the old and new text, zero when the line is absent from that side.
+type Line struct {
+	Op     Op
+	Text   string
+	OldNum int
+	NewNum int
+}
+
+// Diff returns the line diff turning a into b.
+func Diff(a, b string) []Line {
+	before, after := splitLines(a), splitLines(b)
+
+	// Common prefix and suffix are kept out of the LCS table
+	prefix := 0
+	for prefix < len(before) && prefix < len(after) && before[prefix] == after[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(before)-prefix && suffix < len(after)-prefix &&
+		before[len(before)-1-suffix] == after[len(after)-1-suffix] {
+		suffix++
+	}
+
+	var lines []Line
+	for i := 0; i < prefix; i++ {
+		lines = append(lines, Line{Op: OpEqual, Text: before[i], OldNum: i + 1, NewNum: i + 1})
+	}
+
+	lines = append(lines, diffMiddle(before[prefix:len(before)-suffix], after[prefix:len(after)-suffix], prefix, prefix)...)
+
+	for i := 0; i < suffix; i++ {
+		o, n := len(before)-suffix+i, len(after)-suffix+i
+		lines = append(lines, Line{Op: OpEqual, Text: before[o], OldNum: o + 1, NewNum: n + 1})
+	}
+	return lines
+}
+
+// Changed reports whether a diff contains any insertions or deletions.
+func Changed(lines []Line) bool {
+	for _, l := range lines {
+		if l.Op != OpEqual {
+			return true
+		}
+	}
+	return false
+}
+
+// diffMiddle diffs the differing middle part with a longest common
+// subsequence table. beforeOff and afterOff are the line offsets of the slices.
+func diffMiddle(before, after []string, beforeOff, afterOff int) []Line {
+	n, m := len(before), len(after)
+	if n*m > maxDiffCells {
+		lines := make([]Line, 0, n+m)
+		for i, text := range before {
+			lines = append(lines, Line{Op: OpDelete, Text: text, OldNum: beforeOff + i + 1})
+		}
+		for j, text := range after {
+			lines = append(lines, Line{Op: OpInsert, Text: text, NewNum: afterOff + j + 1})
+		}
+		return lines
+	}
+
+	// lcs[i][j] is the LCS length of before[i:] and after[j:]
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if before[i] == after[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	lines := make([]Line, 0, n+m)
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && before[i] == after[j]:
+			lines = append(lines, Line{Op: OpEqual, Text: before[i], OldNum: beforeOff + i + 1, NewNum: afterOff + j + 1})
+			i++
+			j++
+		case j < m && (i == n || lcs[i][j+1] > lcs[i+1][j]):
+			lines = append(lines, Line{Op: OpInsert, Text: after[j], NewNum: afterOff + j + 1})
+			j++
+		default:
+			lines = append(lines, Line{Op: OpDelete, Text: before[i], OldNum: beforeOff + i + 1})
+			i++
+		}
+	}
+	return lines
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -0,0 +1,37 @@
+package revision
+
+import "time"
+
+// Kind is where a revision of a file comes from.
+type Kind string
+
+const (
+	KindCurrent Kind = "current"
+	KindCommit  Kind = "commit"
+	KindDraft   Kind = "draft"
+)
+
+// CurrentID identifies the working copy version of a file.
+const CurrentID = "current"
+
+// Revision ID prefixes: "commit:<hash>" and "draft:<id>".
+const (
+	commitPrefix = "commit:"
+	draftPrefix  = "draft:"
+)
+
+// Revision is one version of a file in its history.
+type Revision struct {
+	ID     string
+	Kind   Kind
+	Label  string // Commit subject for commits
+	Author string
+	Time   time.Time // Zero for the working copy
+}
+
+// Comparison is the line diff between two revisions of a file.
+type Comparison struct {
+	From  string
+	To    string
+	Lines []Line
+}
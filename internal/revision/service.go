@@ -0,0 +1,225 @@
+package revision
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gracchi-stdio/goaat/internal/content"
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+	"github.com/gracchi-stdio/goaat/internal/repository"
+	"github.com/gracchi-stdio/goaat/internal/search"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrUnknownRevision is returned for revision IDs that do not belong to the file.
+var ErrUnknownRevision = errors.New("unknown revision")
+
+// historyLimit caps the number of commits listed per file.
+const historyLimit = 50
+
+// Service defines draft autosave and per-file revision history.
+type Service interface {
+	// SaveDraft stores the user's in-progress edit of a file version
+	SaveDraft(ctx context.Context, userID int64, repo db.Repository, path, baseHash, body string) (db.Draft, error)
+
+	// Draft returns the user's draft of a file version, or nil when there is none
+	Draft(ctx context.Context, userID int64, repo db.Repository, path, baseHash string) (*db.Draft, error)
+
+	// DiscardDraft deletes the user's draft of a file version
+	DiscardDraft(ctx context.Context, userID int64, repo db.Repository, path, baseHash string) error
+
+	// History lists the working copy, commits and drafts of a file, newest first
+	History(ctx context.Context, repo db.Repository, path string) ([]Revision, error)
+
+	// Content returns a file's contents at a revision
+	Content(ctx context.Context, repo db.Repository, path, id string) (string, error)
+
+	// Compare diffs two revisions of a file
+	Compare(ctx context.Context, repo db.Repository, path, from, to string) (*Comparison, error)
+
+	// Restore writes a revision over the file if it is unchanged since baseHash
+	Restore(ctx context.Context, repo db.Repository, path, id, baseHash string) (string, error)
+}
+
+type service struct {
+	queries  db.Querier
+	reposDir string
+	search   search.Service
+}
+
+// NewService creates a revision service over the clones in reposDir.
+// searchService may be nil; when set, restored files are re-indexed.
+func NewService(queries db.Querier, reposDir string, searchService search.Service) Service {
+	return &service{queries: queries, reposDir: reposDir, search: searchService}
+}
+
+func (s *service) contentDir(repo db.Repository) string {
+	return filepath.Join(repository.Dir(s.reposDir, repo.ID), filepath.FromSlash(repo.ContentPath))
+}
+
+func (s *service) SaveDraft(ctx context.Context, userID int64, repo db.Repository, path, baseHash, body string) (db.Draft, error) {
+	if _, err := content.SafeJoin(s.contentDir(repo), path); err != nil {
+		return db.Draft{}, err
+	}
+
+	draft, err := s.queries.UpsertDraft(ctx, db.UpsertDraftParams{
+		UserID:       userID,
+		RepositoryID: repo.ID,
+		Path:         path,
+		BaseHash:     baseHash,
+		Body:         body,
+	})
+	if err != nil {
+		return db.Draft{}, fmt.Errorf("failed to save draft: %w", err)
+	}
+	return draft, nil
+}
+
+func (s *service) Draft(ctx context.Context, userID int64, repo db.Repository, path, baseHash string) (*db.Draft, error) {
+	draft, err := s.queries.GetDraft(ctx, db.GetDraftParams{
+		UserID:       userID,
+		RepositoryID: repo.ID,
+		Path:         path,
+		BaseHash:     baseHash,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load draft: %w", err)
+	}
+	return &draft, nil
+}
+
+func (s *service) DiscardDraft(ctx context.Context, userID int64, repo db.Repository, path, baseHash string) error {
+	err := s.queries.DeleteDraft(ctx, db.DeleteDraftParams{
+		UserID:       userID,
+		RepositoryID: repo.ID,
+		Path:         path,
+		BaseHash:     baseHash,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to discard draft: %w", err)
+	}
+	return nil
+}
+
+func (s *service) History(ctx context.Context, repo db.Repository, file string) ([]Revision, error) {
+	if _, err := content.SafeJoin(s.contentDir(repo), file); err != nil {
+		return nil, err
+	}
+
+	commits, err := repository.FileLog(ctx, repository.Dir(s.reposDir, repo.ID), path.Join(repo.ContentPath, file), historyLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file history: %w", err)
+	}
+	drafts, err := s.queries.ListDraftsByPath(ctx, db.ListDraftsByPathParams{RepositoryID: repo.ID, Path: file})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list drafts: %w", err)
+	}
+
+	revisions := make([]Revision, 0, len(commits)+len(drafts))
+	for _, c := range commits {
+		revisions = append(revisions, Revision{
+			ID:     commitPrefix + c.Hash,
+			Kind:   KindCommit,
+			Label:  c.Subject,
+			Author: c.Author,
+			Time:   c.Time,
+		})
+	}
+	for _, d := range drafts {
+		revisions = append(revisions, Revision{
+			ID:     draftPrefix + strconv.FormatInt(d.ID, 10),
+			Kind:   KindDraft,
+			Label:  "Autosaved draft",
+			Author: d.UserName,
+			Time:   d.UpdatedAt.Time,
+		})
+	}
+	sort.SliceStable(revisions, func(a, b int) bool { return revisions[a].Time.After(revisions[b].Time) })
+
+	current := Revision{ID: CurrentID, Kind: KindCurrent, Label: "Current file"}
+	return append([]Revision{current}, revisions...), nil
+}
+
+func (s *service) Content(ctx context.Context, repo db.Repository, file, id string) (string, error) {
+	switch {
+	case id == CurrentID:
+		body, _, err := content.ReadFile(s.contentDir(repo), file)
+		if err != nil {
+			return "", err
+		}
+		return string(body), nil
+
+	case strings.HasPrefix(id, commitPrefix):
+		if _, err := content.SafeJoin(s.contentDir(repo), file); err != nil {
+			return "", err
+		}
+		body, err := repository.ShowFile(ctx, repository.Dir(s.reposDir, repo.ID), strings.TrimPrefix(id, commitPrefix), path.Join(repo.ContentPath, file))
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", ErrUnknownRevision, err)
+		}
+		return string(body), nil
+
+	case strings.HasPrefix(id, draftPrefix):
+		draftID, err := strconv.ParseInt(strings.TrimPrefix(id, draftPrefix), 10, 64)
+		if err != nil {
+			return "", ErrUnknownRevision
+		}
+		draft, err := s.queries.GetDraftByID(ctx, db.GetDraftByIDParams{ID: draftID, RepositoryID: repo.ID})
+		if errors.Is(err, pgx.ErrNoRows) || (err == nil && draft.Path != file) {
+			return "", ErrUnknownRevision
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to load draft: %w", err)
+		}
+		return draft.Body, nil
+
+	default:
+		return "", ErrUnknownRevision
+	}
+}
+
+func (s *service) Compare(ctx context.Context, repo db.Repository, file, from, to string) (*Comparison, error) {
+	before, err := s.Content(ctx, repo, file, from)
+	if err != nil {
+		return nil, err
+	}
+	after, err := s.Content(ctx, repo, file, to)
+	if err != nil {
+		return nil, err
+	}
+	return &Comparison{From: from, To: to, Lines: Diff(before, after)}, nil
+}
+
+func (s *service) Restore(ctx context.Context, repo db.Repository, file, id, baseHash string) (string, error) {
+	body, err := s.Content(ctx, repo, file, id)
+	if err != nil {
+		return "", err
+	}
+
+	hash, err := content.WriteFile(s.contentDir(repo), file, []byte(body), baseHash)
+	if err != nil {
+		return "", err
+	}
+
+	if s.search != nil {
+		doc, err := content.Parse(file, []byte(body))
+		if err == nil {
+			err = s.search.Index(ctx, repo, doc)
+		}
+		if err != nil {
+			// The file is restored; a stale index entry is fixed by the next rebuild
+			return hash, fmt.Errorf("restored %s but failed to update search index: %w", file, err)
+		}
+	}
+
+	return hash, nil
+}
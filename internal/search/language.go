@@ -0,0 +1,51 @@
+package search
+
+import "strings"
+
+// localeLanguages maps Starlight locale codes to the Postgres text search
+// configurations shipped with a default installation.
+var localeLanguages = map[string]string{
+	"ar": "arabic",
+	"ca": "catalan",
+	"da": "danish",
+	"de": "german",
+	"el": "greek",
+	"en": "english",
+	"es": "spanish",
+	"fi": "finnish",
+	"fr": "french",
+	"hu": "hungarian",
+	"id": "indonesian",
+	"it": "italian",
+	"lt": "lithuanian",
+	"nb": "norwegian",
+	"ne": "nepali",
+	"nl": "dutch",
+	"no": "norwegian",
+	"pt": "portuguese",
+	"ro": "romanian",
+	"ru": "russian",
+	"sr": "serbian",
+	"sv": "swedish",
+	"ta": "tamil",
+	"tr": "turkish",
+	"yi": "yiddish",
+}
+
+// LanguageForLocale returns the text search configuration for a locale such as
+// "fr" or "pt-BR", falling back to "simple" (no stemming) when unknown.
+func LanguageForLocale(locale string) string {
+	base, _, _ := strings.Cut(strings.ToLower(locale), "-")
+	if lang, ok := localeLanguages[base]; ok {
+		return lang
+	}
+	return "simple"
+}
+
+// languageOrDefault guards against empty configuration values.
+func languageOrDefault(lang string) string {
+	if lang == "" {
+		return "simple"
+	}
+	return lang
+}
@@ -0,0 +1,175 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gracchi-stdio/goaat/internal/content"
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+)
+
+// Highlight delimiters passed to ts_headline. Private-use runes never occur in
+// real content, so snippets can be split safely and rendered without raw HTML.
+const (
+	highlightStart = "\uE000"
+	highlightStop  = "\uE001"
+)
+
+var headlineOptions = fmt.Sprintf(
+	"StartSel=%s, StopSel=%s, MaxFragments=2, MaxWords=25, MinWords=8, FragmentDelimiter=\" … \"",
+	highlightStart, highlightStop,
+)
+
+// DefaultLimit caps the number of results returned by Search.
+const DefaultLimit = 20
+
+// Segment is a piece of a snippet, highlighted when it matched the query.
+type Segment struct {
+	Text      string
+	Highlight bool
+}
+
+// Result is a ranked search hit.
+type Result struct {
+	RepositoryID   int64
+	RepositoryName string
+	Path           string
+	Title          string
+	Snippet        []Segment
+	Rank           float32
+}
+
+// Service defines full-text indexing and search over repository content.
+type Service interface {
+	// Index adds or replaces a single document in the repository's index
+	Index(ctx context.Context, repo db.Repository, doc *content.Document) error
+
+	// Remove deletes a document from the index (e.g. after the file was deleted)
+	Remove(ctx context.Context, repoID int64, path string) error
+
+	// Rebuild re-indexes every content file under dir and drops stale entries
+	Rebuild(ctx context.Context, repo db.Repository, dir string) (int, error)
+
+	// Search returns ranked results across the repositories userID owns or is a member of
+	Search(ctx context.Context, userID int64, query string, limit int) ([]Result, error)
+}
+
+type service struct {
+	db db.Querier
+}
+
+// NewService creates a new search service backed by Postgres full-text search.
+func NewService(q db.Querier) Service {
+	return &service{db: q}
+}
+
+func (s *service) Index(ctx context.Context, repo db.Repository, doc *content.Document) error {
+	err := s.db.UpsertSearchDocument(ctx, db.UpsertSearchDocumentParams{
+		RepositoryID: repo.ID,
+		Path:         doc.Path,
+		Language:     languageOrDefault(repo.SearchLanguage),
+		Title:        doc.Title,
+		Description:  doc.Description,
+		Headings:     doc.HeadingText(),
+		Body:         doc.PlainText(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to index %s: %w", doc.Path, err)
+	}
+	return nil
+}
+
+func (s *service) Remove(ctx context.Context, repoID int64, path string) error {
+	err := s.db.DeleteSearchDocument(ctx, db.DeleteSearchDocumentParams{
+		RepositoryID: repoID,
+		Path:         path,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove %s from index: %w", path, err)
+	}
+	return nil
+}
+
+func (s *service) Rebuild(ctx context.Context, repo db.Repository, dir string) (int, error) {
+	paths := []string{}
+
+	err := content.Walk(dir, func(doc *content.Document) error {
+		if err := s.Index(ctx, repo, doc); err != nil {
+			return err
+		}
+		paths = append(paths, doc.Path)
+		return nil
+	})
+	if err != nil {
+		return len(paths), fmt.Errorf("failed to rebuild index for %s: %w", repo.FullName, err)
+	}
+
+	err = s.db.DeleteStaleSearchDocuments(ctx, db.DeleteStaleSearchDocumentsParams{
+		RepositoryID: repo.ID,
+		Paths:        paths,
+	})
+	if err != nil {
+		return len(paths), fmt.Errorf("failed to prune index for %s: %w", repo.FullName, err)
+	}
+
+	return len(paths), nil
+}
+
+func (s *service) Search(ctx context.Context, userID int64, query string, limit int) ([]Result, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, nil
+	}
+	if limit <= 0 || limit > DefaultLimit {
+		limit = DefaultLimit
+	}
+
+	rows, err := s.db.SearchDocuments(ctx, db.SearchDocumentsParams{
+		HeadlineOptions: headlineOptions,
+		Query:           query,
+		UserID:          userID,
+		ResultLimit:     int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search documents: %w", err)
+	}
+
+	results := make([]Result, len(rows))
+	for i, row := range rows {
+		results[i] = Result{
+			RepositoryID:   row.RepositoryID,
+			RepositoryName: row.RepositoryName,
+			Path:           row.Path,
+			Title:          row.Title,
+			Snippet:        splitSnippet(row.Snippet),
+			Rank:           row.Rank,
+		}
+	}
+	return results, nil
+}
+
+// splitSnippet turns a ts_headline result into plain and highlighted segments.
+func splitSnippet(snippet string) []Segment {
+	var segments []Segment
+	for snippet != "" {
+		start := strings.Index(snippet, highlightStart)
+		if start < 0 {
+			segments = append(segments, Segment{Text: snippet})
+			break
+		}
+		if start > 0 {
+			segments = append(segments, Segment{Text: snippet[:start]})
+		}
+		snippet = snippet[start+len(highlightStart):]
+
+		stop := strings.Index(snippet, highlightStop)
+		if stop < 0 {
+			segments = append(segments, Segment{Text: snippet, Highlight: true})
+			break
+		}
+		segments = append(segments, Segment{Text: snippet[:stop], Highlight: true})
+		snippet = snippet[stop+len(highlightStop):]
+	}
+	return segments
+}
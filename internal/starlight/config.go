@@ -0,0 +1,221 @@
+package starlight
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gracchi-stdio/goaat/internal/content"
+)
+
+// ConfigFiles are the Astro config file names, in Astro's lookup order.
+var ConfigFiles = []string{
+	"astro.config.mjs",
+	"astro.config.js",
+	"astro.config.ts",
+	"astro.config.mts",
+	"astro.config.cjs",
+	"astro.config.cts",
+}
+
+// ErrNoConfig is returned when a repository has no Astro config or the
+// config does not call the Starlight integration.
+var ErrNoConfig = errors.New("no Starlight configuration found")
+
+// starlightImport finds the local name of the Starlight integration import,
+// e.g. `import starlight from '@astrojs/starlight'`.
+var starlightImport = regexp.MustCompile(`import\s+([A-Za-z_$][\w$]*)\s+from\s+['"]@astrojs/starlight['"]`)
+
+// Locale is an entry of Starlight's `locales` option.
+type Locale struct {
+	Key   string // "root" or the locale folder name
+	Label string
+	Lang  string
+}
+
+// Config is the statically readable part of a Starlight integration config.
+type Config struct {
+	File string // Config file name relative to the repository root
+
+	Title             string
+	TitleTranslations map[string]string
+	DefaultLocale     string
+	Locales           []Locale
+
+	Sidebar []SidebarItem
+
+	// SidebarEditable is false when the sidebar depends on code (variables,
+	// spreads, function calls) and can only be shown read-only.
+	SidebarEditable bool
+	ReadOnlyReason  string
+
+	src     []byte
+	sidebar *Node
+}
+
+// Load finds and parses the Astro config file in a repository working copy.
+func Load(repoDir string) (*Config, error) {
+	for _, name := range ConfigFiles {
+		src, err := os.ReadFile(filepath.Join(repoDir, name))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		cfg, err := Parse(src)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+		cfg.File = name
+		return cfg, nil
+	}
+	return nil, ErrNoConfig
+}
+
+// Parse extracts the Starlight options from an Astro config file without
+// executing it.
+func Parse(src []byte) (*Config, error) {
+	name := "starlight"
+	if m := starlightImport.FindSubmatch(src); m != nil {
+		name = string(m[1])
+	}
+
+	options, err := findCallArgument(src, name)
+	if err != nil {
+		return nil, err
+	}
+	if options.Kind != KindObject {
+		return nil, fmt.Errorf("%s() options are not an object literal", name)
+	}
+
+	cfg := &Config{src: src}
+	cfg.readTitle(options.Get("title"))
+	cfg.readLocales(options)
+	cfg.readSidebar(options)
+
+	return cfg, nil
+}
+
+// Source returns the config file contents the Config was parsed from.
+func (c *Config) Source() []byte {
+	return c.src
+}
+
+func (c *Config) readTitle(title *Node) {
+	if title == nil {
+		return
+	}
+	switch title.Kind {
+	case KindString:
+		c.Title = title.Str
+	case KindObject:
+		c.TitleTranslations = map[string]string{}
+		for _, p := range title.Props {
+			if p.Value.Kind == KindString {
+				c.TitleTranslations[p.Key] = p.Value.Str
+			}
+		}
+		c.Title = c.TitleTranslations["en"]
+	}
+}
+
+func (c *Config) readLocales(options *Node) {
+	c.DefaultLocale = options.String("defaultLocale")
+
+	locales := options.Get("locales")
+	if locales == nil || locales.Kind != KindObject {
+		return
+	}
+
+	for _, p := range locales.Props {
+		if p.Key == "" || p.Value.Kind != KindObject {
+			continue
+		}
+		locale := Locale{
+			Key:   p.Key,
+			Label: p.Value.String("label"),
+			Lang:  p.Value.String("lang"),
+		}
+		if locale.Lang == "" && locale.Key != "root" {
+			locale.Lang = locale.Key
+		}
+		if locale.Label == "" {
+			locale.Label = strings.ToUpper(locale.Key)
+		}
+		c.Locales = append(c.Locales, locale)
+	}
+
+	if c.DefaultLocale == "" {
+		for _, l := range c.Locales {
+			if l.Key == "root" {
+				c.DefaultLocale = "root"
+			}
+		}
+	}
+
+	// Default locale first, the rest alphabetically
+	sort.SliceStable(c.Locales, func(a, b int) bool {
+		aDefault, bDefault := c.Locales[a].Key == c.DefaultLocale, c.Locales[b].Key == c.DefaultLocale
+		if aDefault != bDefault {
+			return aDefault
+		}
+		return c.Locales[a].Key < c.Locales[b].Key
+	})
+}
+
+// findCallArgument locates the first call to fn(...) outside strings and
+// comments and parses its first argument.
+func findCallArgument(src []byte, fn string) (*Node, error) {
+	p := &parser{src: src}
+	for p.pos < len(p.src) {
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			break
+		}
+
+		c := p.src[p.pos]
+		switch {
+		case c == '\'' || c == '"':
+			if _, err := p.parseString(); err != nil {
+				return nil, err
+			}
+		case c == '`':
+			if _, _, err := p.parseTemplate(); err != nil {
+				return nil, err
+			}
+		case isIdentStart(rune(c)):
+			ident := p.peekIdent()
+			p.pos += max(len(ident), 1)
+			if ident != fn {
+				continue
+			}
+			p.skipSpace()
+			if p.pos < len(p.src) && p.src[p.pos] == '(' {
+				p.pos++
+				return p.parseValue()
+			}
+		default:
+			p.pos++
+		}
+	}
+	return nil, ErrNoConfig
+}
+
+// I18n converts the configured locales to the content layer's locale layout.
+// Sites without a `locales` option are monolingual.
+func (c *Config) I18n() *content.I18n {
+	if len(c.Locales) == 0 {
+		return content.MonolingualI18n()
+	}
+
+	i18n := &content.I18n{DefaultLocale: c.DefaultLocale}
+	for _, l := range c.Locales {
+		i18n.Locales = append(i18n.Locales, content.Locale{Key: l.Key, Label: l.Label, Lang: l.Lang})
+	}
+	return i18n
+}
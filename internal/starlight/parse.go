@@ -0,0 +1,513 @@
+package starlight
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Kind is the type of a parsed JavaScript value.
+type Kind int
+
+const (
+	// KindDynamic is any expression that cannot be evaluated without running JS
+	// (identifiers, calls, spreads, template literals with substitutions, ...).
+	KindDynamic Kind = iota
+	KindString
+	KindNumber
+	KindBool
+	KindNull
+	KindObject
+	KindArray
+)
+
+// Node is a JavaScript literal value with its byte span in the source file.
+type Node struct {
+	Kind  Kind
+	Start int
+	End   int
+
+	Str   string
+	Num   float64
+	Bool  bool
+	Props []Property // KindObject, in source order
+	Elems []*Node    // KindArray
+}
+
+// Property is an object literal entry. Dynamic entries (spreads, computed
+// keys, methods) have an empty Key and a dynamic Value.
+type Property struct {
+	Key   string
+	Value *Node
+}
+
+// Get returns the value of an object property, or nil.
+func (n *Node) Get(key string) *Node {
+	if n == nil || n.Kind != KindObject {
+		return nil
+	}
+	for _, p := range n.Props {
+		if p.Key == key {
+			return p.Value
+		}
+	}
+	return nil
+}
+
+// String returns the value of a string property, or "".
+func (n *Node) String(key string) string {
+	if v := n.Get(key); v != nil && v.Kind == KindString {
+		return v.Str
+	}
+	return ""
+}
+
+// IsStatic reports whether the value and all of its children are literals.
+func (n *Node) IsStatic() bool {
+	switch n.Kind {
+	case KindDynamic:
+		return false
+	case KindObject:
+		for _, p := range n.Props {
+			if p.Key == "" || !p.Value.IsStatic() {
+				return false
+			}
+		}
+	case KindArray:
+		for _, e := range n.Elems {
+			if !e.IsStatic() {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// parser is a small recursive-descent parser for the literal subset of
+// JavaScript used by Astro config files. It never evaluates code; anything
+// outside object/array/primitive literals becomes a KindDynamic node.
+type parser struct {
+	src []byte
+	pos int
+}
+
+// parseValue parses one expression starting at the current position.
+func (p *parser) parseValue() (*Node, error) {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return nil, p.errorf("unexpected end of file")
+	}
+
+	start := p.pos
+	var node *Node
+	var err error
+
+	switch c := p.src[p.pos]; {
+	case c == '{':
+		node, err = p.parseObject()
+	case c == '[':
+		node, err = p.parseArray()
+	case c == '\'' || c == '"':
+		var s string
+		s, err = p.parseString()
+		node = &Node{Kind: KindString, Str: s}
+	case c == '`':
+		var s string
+		var static bool
+		s, static, err = p.parseTemplate()
+		node = &Node{Kind: KindString, Str: s}
+		if !static {
+			node = &Node{Kind: KindDynamic}
+		}
+	case c == '-' || c == '.' || (c >= '0' && c <= '9'):
+		node = p.parseNumber()
+	case isIdentStart(rune(c)):
+		ident := p.peekIdent()
+		switch ident {
+		case "true", "false":
+			p.pos += len(ident)
+			node = &Node{Kind: KindBool, Bool: ident == "true"}
+		case "null", "undefined":
+			p.pos += len(ident)
+			node = &Node{Kind: KindNull}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	end := p.pos
+
+	// Literals followed by operators, calls or member access are expressions
+	if node != nil {
+		p.skipSpace()
+		if p.pos < len(p.src) && !strings.ContainsRune(",}])", rune(p.src[p.pos])) {
+			node = nil
+		}
+	}
+	if node == nil {
+		p.pos = start
+		if end, err = p.skipExpression(); err != nil {
+			return nil, err
+		}
+		node = &Node{Kind: KindDynamic}
+	}
+
+	node.Start, node.End = start, end
+	return node, nil
+}
+
+func (p *parser) parseObject() (*Node, error) {
+	node := &Node{Kind: KindObject}
+	p.pos++ // {
+
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			return nil, p.errorf("unterminated object")
+		}
+		if p.src[p.pos] == '}' {
+			p.pos++
+			return node, nil
+		}
+
+		prop, err := p.parseProperty()
+		if err != nil {
+			return nil, err
+		}
+		node.Props = append(node.Props, prop)
+
+		p.skipSpace()
+		if p.pos < len(p.src) && p.src[p.pos] == ',' {
+			p.pos++
+			continue
+		}
+		p.skipSpace()
+		if p.pos >= len(p.src) || p.src[p.pos] != '}' {
+			return nil, p.errorf("expected , or } in object")
+		}
+	}
+}
+
+func (p *parser) parseProperty() (Property, error) {
+	start := p.pos
+	var key string
+
+	switch c := p.src[p.pos]; {
+	case c == '\'' || c == '"':
+		s, err := p.parseString()
+		if err != nil {
+			return Property{}, err
+		}
+		key = s
+	case isIdentStart(rune(c)):
+		key = p.peekIdent()
+		p.pos += len(key)
+	case c >= '0' && c <= '9':
+		p.parseNumber()
+		key = string(p.src[start:p.pos])
+	default:
+		// Spread, computed key, or something else we do not understand
+		end, err := p.skipExpression()
+		if err != nil {
+			return Property{}, err
+		}
+		return Property{Value: &Node{Kind: KindDynamic, Start: start, End: end}}, nil
+	}
+
+	p.skipSpace()
+	if p.pos < len(p.src) && p.src[p.pos] == ':' {
+		p.pos++
+		value, err := p.parseValue()
+		if err != nil {
+			return Property{}, err
+		}
+		return Property{Key: key, Value: value}, nil
+	}
+
+	// Shorthand property ({ sidebar }) or method; both reference code
+	p.pos = start
+	end, err := p.skipExpression()
+	if err != nil {
+		return Property{}, err
+	}
+	return Property{Key: key, Value: &Node{Kind: KindDynamic, Start: start, End: end}}, nil
+}
+
+func (p *parser) parseArray() (*Node, error) {
+	node := &Node{Kind: KindArray}
+	p.pos++ // [
+
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			return nil, p.errorf("unterminated array")
+		}
+		if p.src[p.pos] == ']' {
+			p.pos++
+			return node, nil
+		}
+
+		elem, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		node.Elems = append(node.Elems, elem)
+
+		p.skipSpace()
+		if p.pos < len(p.src) && p.src[p.pos] == ',' {
+			p.pos++
+			continue
+		}
+		if p.pos >= len(p.src) || p.src[p.pos] != ']' {
+			return nil, p.errorf("expected , or ] in array")
+		}
+	}
+}
+
+func (p *parser) parseString() (string, error) {
+	quote := p.src[p.pos]
+	p.pos++
+
+	var b strings.Builder
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == quote:
+			p.pos++
+			return b.String(), nil
+		case c == '\n':
+			return "", p.errorf("unterminated string")
+		case c == '\\':
+			if err := p.parseEscape(&b); err != nil {
+				return "", err
+			}
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+// parseTemplate parses a template literal. static is false when it has ${} substitutions.
+func (p *parser) parseTemplate() (s string, static bool, err error) {
+	p.pos++ // `
+	static = true
+
+	var b strings.Builder
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == '`':
+			p.pos++
+			return b.String(), static, nil
+		case c == '\\':
+			if err := p.parseEscape(&b); err != nil {
+				return "", false, err
+			}
+		case c == '$' && p.pos+1 < len(p.src) && p.src[p.pos+1] == '{':
+			static = false
+			p.pos++
+			if err := p.skipBalanced('{', '}'); err != nil {
+				return "", false, err
+			}
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+	return "", false, p.errorf("unterminated template literal")
+}
+
+func (p *parser) parseEscape(b *strings.Builder) error {
+	p.pos++ // backslash
+	if p.pos >= len(p.src) {
+		return p.errorf("unterminated escape")
+	}
+	c := p.src[p.pos]
+	p.pos++
+
+	switch c {
+	case 'n':
+		b.WriteByte('\n')
+	case 't':
+		b.WriteByte('\t')
+	case 'r':
+		b.WriteByte('\r')
+	case 'b':
+		b.WriteByte('\b')
+	case 'f':
+		b.WriteByte('\f')
+	case 'v':
+		b.WriteByte('\v')
+	case '0':
+		b.WriteByte(0)
+	case '\n':
+		// Line continuation
+	case 'u':
+		hex := ""
+		if p.pos < len(p.src) && p.src[p.pos] == '{' {
+			end := strings.IndexByte(string(p.src[p.pos:]), '}')
+			if end < 0 {
+				return p.errorf("invalid unicode escape")
+			}
+			hex = string(p.src[p.pos+1 : p.pos+end])
+			p.pos += end + 1
+		} else if p.pos+4 <= len(p.src) {
+			hex = string(p.src[p.pos : p.pos+4])
+			p.pos += 4
+		}
+		r, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil {
+			return p.errorf("invalid unicode escape")
+		}
+		b.WriteRune(rune(r))
+	case 'x':
+		if p.pos+2 > len(p.src) {
+			return p.errorf("invalid hex escape")
+		}
+		r, err := strconv.ParseUint(string(p.src[p.pos:p.pos+2]), 16, 8)
+		if err != nil {
+			return p.errorf("invalid hex escape")
+		}
+		p.pos += 2
+		b.WriteRune(rune(r))
+	default:
+		b.WriteByte(c)
+	}
+	return nil
+}
+
+func (p *parser) parseNumber() *Node {
+	start := p.pos
+	if p.src[p.pos] == '-' {
+		p.pos++
+	}
+	for p.pos < len(p.src) && (isIdentPart(rune(p.src[p.pos])) || p.src[p.pos] == '.') {
+		p.pos++
+	}
+	text := strings.ReplaceAll(string(p.src[start:p.pos]), "_", "")
+	n, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return nil
+	}
+	return &Node{Kind: KindNumber, Num: n}
+}
+
+// skipExpression advances past an arbitrary expression, stopping at a
+// top-level , } ] or ) without consuming it. It returns the offset just
+// after the last token of the expression.
+func (p *parser) skipExpression() (int, error) {
+	end := p.pos
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			return end, nil
+		}
+		var err error
+		switch c := p.src[p.pos]; c {
+		case ',', '}', ']', ')':
+			return end, nil
+		case '{':
+			err = p.skipBalanced('{', '}')
+		case '[':
+			err = p.skipBalanced('[', ']')
+		case '(':
+			err = p.skipBalanced('(', ')')
+		case '\'', '"':
+			_, err = p.parseString()
+		case '`':
+			_, _, err = p.parseTemplate()
+		default:
+			p.pos++
+		}
+		if err != nil {
+			return 0, err
+		}
+		end = p.pos
+	}
+}
+
+// skipBalanced advances past a bracketed region, honouring strings and comments.
+func (p *parser) skipBalanced(open, close byte) error {
+	depth := 0
+	for p.pos < len(p.src) {
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			break
+		}
+		switch c := p.src[p.pos]; c {
+		case open:
+			depth++
+			p.pos++
+		case close:
+			depth--
+			p.pos++
+			if depth == 0 {
+				return nil
+			}
+		case '\'', '"':
+			if _, err := p.parseString(); err != nil {
+				return err
+			}
+		case '`':
+			if _, _, err := p.parseTemplate(); err != nil {
+				return err
+			}
+		default:
+			p.pos++
+		}
+	}
+	return p.errorf("unbalanced %q", open)
+}
+
+// skipSpace skips whitespace and comments.
+func (p *parser) skipSpace() {
+	for p.pos < len(p.src) {
+		r, size := utf8.DecodeRune(p.src[p.pos:])
+		switch {
+		case unicode.IsSpace(r):
+			p.pos += size
+		case strings.HasPrefix(string(p.src[p.pos:min(p.pos+2, len(p.src))]), "//"):
+			for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+				p.pos++
+			}
+		case strings.HasPrefix(string(p.src[p.pos:min(p.pos+2, len(p.src))]), "/*"):
+			end := strings.Index(string(p.src[p.pos+2:]), "*/")
+			if end < 0 {
+				p.pos = len(p.src)
+				return
+			}
+			p.pos += end + 4
+		default:
+			return
+		}
+	}
+}
+
+func (p *parser) peekIdent() string {
+	end := p.pos
+	for end < len(p.src) {
+		r, size := utf8.DecodeRune(p.src[end:])
+		if !isIdentPart(r) {
+			break
+		}
+		end += size
+	}
+	return string(p.src[p.pos:end])
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	line := 1 + strings.Count(string(p.src[:min(p.pos, len(p.src))]), "\n")
+	return fmt.Errorf("line %d: %s", line, fmt.Sprintf(format, args...))
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || r == '$' || unicode.IsLetter(r)
+}
+
+func isIdentPart(r rune) bool {
+	return isIdentStart(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,57 @@
+package starlight
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gracchi-stdio/goaat/internal/content"
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+	"github.com/gracchi-stdio/goaat/internal/repository"
+)
+
+// Service defines access to a repository's Starlight configuration.
+type Service interface {
+	// Config parses the repository's Astro config and returns it with its file hash
+	Config(ctx context.Context, repo db.Repository) (*Config, string, error)
+
+	// SaveSidebar rewrites the sidebar order if the config is unchanged since baseHash
+	SaveSidebar(ctx context.Context, repo db.Repository, order []Order, baseHash string) (string, error)
+}
+
+type service struct {
+	reposDir string
+}
+
+// NewService creates a Starlight config service over the clones in reposDir.
+func NewService(reposDir string) Service {
+	return &service{reposDir: reposDir}
+}
+
+func (s *service) Config(ctx context.Context, repo db.Repository) (*Config, string, error) {
+	cfg, err := Load(repository.Dir(s.reposDir, repo.ID))
+	if err != nil {
+		return nil, "", err
+	}
+	return cfg, content.Hash(cfg.Source()), nil
+}
+
+func (s *service) SaveSidebar(ctx context.Context, repo db.Repository, order []Order, baseHash string) (string, error) {
+	cfg, hash, err := s.Config(ctx, repo)
+	if err != nil {
+		return "", err
+	}
+	if hash != baseHash {
+		return "", content.ErrConflict
+	}
+
+	updated, err := cfg.ReorderSidebar(order)
+	if err != nil {
+		return "", err
+	}
+
+	newHash, err := content.WriteFile(repository.Dir(s.reposDir, repo.ID), cfg.File, updated, baseHash)
+	if err != nil {
+		return "", fmt.Errorf("failed to save sidebar: %w", err)
+	}
+	return newHash, nil
+}
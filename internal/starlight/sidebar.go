@@ -0,0 +1,218 @@
+package starlight
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidOrder is returned when a new sidebar order does not contain
+// every existing item exactly once.
+var ErrInvalidOrder = errors.New("invalid sidebar order")
+
+// SidebarItem is a link, autogenerated section or group in the Starlight sidebar.
+type SidebarItem struct {
+	ID           string // Position in the original config, e.g. "2" or "2.0"
+	Label        string
+	Link         string
+	Slug         string
+	Autogenerate string // Directory of an autogenerated group
+	Collapsed    bool
+	IsGroup      bool
+	Items        []SidebarItem
+
+	node  *Node
+	items *Node // Group's items array
+}
+
+// Order is the desired position of a sidebar item, referenced by ID.
+// Items is only allowed for groups.
+type Order struct {
+	ID    string  `json:"id"`
+	Items []Order `json:"items,omitempty"`
+}
+
+// HasItems reports whether the item is a group with an `items` array that
+// other items can be moved into.
+func (i SidebarItem) HasItems() bool {
+	return i.items != nil
+}
+
+// Order returns the current sidebar order, the starting point for ReorderSidebar.
+func (c *Config) Order() []Order {
+	return orderOf(c.Sidebar)
+}
+
+func orderOf(items []SidebarItem) []Order {
+	order := make([]Order, 0, len(items))
+	for _, item := range items {
+		order = append(order, Order{ID: item.ID, Items: orderOf(item.Items)})
+	}
+	return order
+}
+
+func (c *Config) readSidebar(options *Node) {
+	sidebar := options.Get("sidebar")
+	if sidebar == nil {
+		c.ReadOnlyReason = "The config has no sidebar array; Starlight builds navigation from the content folders."
+		return
+	}
+	if sidebar.Kind != KindArray {
+		c.ReadOnlyReason = "The sidebar is built by code (a variable or function call) and cannot be edited safely."
+		return
+	}
+
+	c.sidebar = sidebar
+	c.Sidebar = readItems(sidebar, "")
+	c.SidebarEditable = sidebar.IsStatic()
+	if !c.SidebarEditable {
+		c.ReadOnlyReason = "Parts of the sidebar use variables, spreads or function calls and cannot be edited safely."
+	}
+}
+
+func readItems(array *Node, prefix string) []SidebarItem {
+	items := make([]SidebarItem, 0, len(array.Elems))
+	for i, elem := range array.Elems {
+		item := SidebarItem{ID: prefix + strconv.Itoa(i), node: elem}
+
+		switch elem.Kind {
+		case KindString:
+			// Shorthand for a slug: 'guides/getting-started'
+			item.Slug = elem.Str
+			item.Label = elem.Str
+		case KindObject:
+			item.Label = elem.String("label")
+			item.Link = elem.String("link")
+			item.Slug = elem.String("slug")
+			if c := elem.Get("collapsed"); c != nil && c.Kind == KindBool {
+				item.Collapsed = c.Bool
+			}
+			if auto := elem.Get("autogenerate"); auto != nil {
+				item.IsGroup = true
+				item.Autogenerate = auto.String("directory")
+			}
+			if sub := elem.Get("items"); sub != nil && sub.Kind == KindArray {
+				item.IsGroup = true
+				item.items = sub
+				item.Items = readItems(sub, item.ID+".")
+			}
+			if item.Label == "" {
+				item.Label = item.Slug
+			}
+		default:
+			item.Label = "(dynamic item)"
+		}
+
+		items = append(items, item)
+	}
+	return items
+}
+
+// ReorderSidebar returns the config file rewritten with the sidebar items in
+// the given order. Only the sidebar arrays are rewritten: each item keeps its
+// original source text, and separators and indentation are reused, so the
+// diff contains just the moved items.
+func (c *Config) ReorderSidebar(order []Order) ([]byte, error) {
+	if !c.SidebarEditable {
+		return nil, fmt.Errorf("sidebar is read-only: %s", c.ReadOnlyReason)
+	}
+
+	index := map[string]*SidebarItem{}
+	indexItems(c.Sidebar, index)
+
+	seen := map[string]bool{}
+	if err := validateOrder(order, index, seen); err != nil {
+		return nil, err
+	}
+	if len(seen) != len(index) {
+		return nil, fmt.Errorf("%w: %d of %d items placed", ErrInvalidOrder, len(seen), len(index))
+	}
+
+	var b strings.Builder
+	b.Write(c.src[:c.sidebar.Start])
+	b.WriteString(c.renderArray(c.sidebar, order, index))
+	b.Write(c.src[c.sidebar.End:])
+
+	return []byte(b.String()), nil
+}
+
+func indexItems(items []SidebarItem, index map[string]*SidebarItem) {
+	for i := range items {
+		index[items[i].ID] = &items[i]
+		indexItems(items[i].Items, index)
+	}
+}
+
+func validateOrder(order []Order, index map[string]*SidebarItem, seen map[string]bool) error {
+	for _, o := range order {
+		item, ok := index[o.ID]
+		if !ok {
+			return fmt.Errorf("%w: unknown item %q", ErrInvalidOrder, o.ID)
+		}
+		if seen[o.ID] {
+			return fmt.Errorf("%w: item %q placed twice", ErrInvalidOrder, o.ID)
+		}
+		seen[o.ID] = true
+
+		if len(o.Items) > 0 && item.items == nil {
+			return fmt.Errorf("%w: %q is not a group with items", ErrInvalidOrder, item.Label)
+		}
+		if err := validateOrder(o.Items, index, seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderArray re-emits an array literal with elements in the new order,
+// reusing the original leading whitespace, separators and trailing text.
+func (c *Config) renderArray(array *Node, order []Order, index map[string]*SidebarItem) string {
+	if len(order) == 0 {
+		return "[]"
+	}
+
+	lead, tail := "", ""
+	var seps []string
+	if n := len(array.Elems); n > 0 {
+		lead = string(c.src[array.Start+1 : array.Elems[0].Start])
+		tail = string(c.src[array.Elems[n-1].End : array.End-1])
+		for i := 0; i < n-1; i++ {
+			seps = append(seps, string(c.src[array.Elems[i].End:array.Elems[i+1].Start]))
+		}
+	}
+
+	var b strings.Builder
+	b.WriteByte('[')
+	b.WriteString(lead)
+	for i, o := range order {
+		if i > 0 {
+			switch {
+			case i-1 < len(seps):
+				b.WriteString(seps[i-1])
+			case len(seps) > 0:
+				b.WriteString(seps[len(seps)-1])
+			case strings.Contains(lead, "\n"):
+				b.WriteString("," + lead)
+			default:
+				b.WriteString(", ")
+			}
+		}
+		b.WriteString(c.renderItem(index[o.ID], o.Items, index))
+	}
+	b.WriteString(tail)
+	b.WriteByte(']')
+
+	return b.String()
+}
+
+// renderItem returns an item's source text, with a group's items array
+// replaced by its reordered version.
+func (c *Config) renderItem(item *SidebarItem, children []Order, index map[string]*SidebarItem) string {
+	if item.items == nil {
+		return string(c.src[item.node.Start:item.node.End])
+	}
+	return string(c.src[item.node.Start:item.items.Start]) +
+		c.renderArray(item.items, children, index) +
+		string(c.src[item.items.End:item.node.End])
+}
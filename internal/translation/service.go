@@ -0,0 +1,276 @@
+package translation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gracchi-stdio/goaat/internal/content"
+	"github.com/gracchi-stdio/goaat/internal/files"
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+	"github.com/gracchi-stdio/goaat/internal/repository"
+	"github.com/gracchi-stdio/goaat/internal/starlight"
+)
+
+// Status describes how a translation relates to its source page.
+type Status string
+
+const (
+	StatusMissing  Status = "missing"
+	StatusOutdated Status = "outdated"
+	StatusUpToDate Status = "up-to-date"
+)
+
+var (
+	// ErrUnknownLocale is returned for locales not configured in the repository.
+	ErrUnknownLocale = errors.New("unknown locale")
+
+	// ErrTranslationExists is returned when creating a stub over an existing file.
+	ErrTranslationExists = errors.New("translation already exists")
+)
+
+// Cell is the state of one page in one locale.
+type Cell struct {
+	Path   string
+	Status Status
+}
+
+// Row is a source page and its translations keyed by locale.
+type Row struct {
+	Slug       string
+	SourcePath string
+	Cells      map[string]Cell
+}
+
+// Coverage summarises a locale's column in the matrix.
+type Coverage struct {
+	UpToDate int
+	Outdated int
+	Missing  int
+}
+
+// Matrix is the pages × locales translation overview of a repository.
+type Matrix struct {
+	I18n     *content.I18n
+	Locales  []content.Locale // Translation locales (default excluded)
+	Rows     []Row
+	Coverage map[string]Coverage
+}
+
+// File is a content file with the hash used for optimistic saves.
+type File struct {
+	Path   string
+	Body   string
+	Hash   string
+	Exists bool
+}
+
+// Pair is a source page next to one of its translations.
+type Pair struct {
+	Locale      content.Locale
+	Source      File
+	Translation File
+	Status      Status
+}
+
+// Service defines translation tracking for Starlight multilingual content.
+type Service interface {
+	// Locales returns the locale configuration of a repository's content tree
+	Locales(ctx context.Context, repo db.Repository) (*content.I18n, error)
+
+	// Matrix computes the translation status of every source page
+	Matrix(ctx context.Context, repo db.Repository) (*Matrix, error)
+
+	// Pair loads a source page and its translation for side-by-side editing
+	Pair(ctx context.Context, repo db.Repository, sourcePath, locale string) (*Pair, error)
+
+	// CreateStub copies a source page into a locale and returns the new path
+	CreateStub(ctx context.Context, repo db.Repository, sourcePath, locale string) (string, error)
+}
+
+type service struct {
+	reposDir string
+	files    files.Service
+}
+
+// NewService creates a translation service over the clones in reposDir,
+// writing stubs through fileService.
+func NewService(reposDir string, fileService files.Service) Service {
+	return &service{reposDir: reposDir, files: fileService}
+}
+
+func (s *service) contentDir(repo db.Repository) string {
+	return filepath.Join(repository.Dir(s.reposDir, repo.ID), filepath.FromSlash(repo.ContentPath))
+}
+
+func (s *service) Locales(ctx context.Context, repo db.Repository) (*content.I18n, error) {
+	// Prefer the locales declared in astro.config.mjs; fall back to the folder layout
+	cfg, err := starlight.Load(repository.Dir(s.reposDir, repo.ID))
+	if err == nil && len(cfg.Locales) > 0 {
+		return cfg.I18n(), nil
+	}
+	return content.DetectLocales(s.contentDir(repo))
+}
+
+func (s *service) Matrix(ctx context.Context, repo db.Repository) (*Matrix, error) {
+	i18n, err := s.Locales(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	commits, err := s.commitTimes(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	// Group every content file by slug and locale
+	files := map[string]map[string]string{}
+	err = content.Walk(s.contentDir(repo), func(doc *content.Document) error {
+		locale, slug := i18n.Split(doc.Path)
+		if files[slug] == nil {
+			files[slug] = map[string]string{}
+		}
+		files[slug][locale] = doc.Path
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list content: %w", err)
+	}
+
+	m := &Matrix{
+		I18n:     i18n,
+		Locales:  i18n.Translations(),
+		Coverage: map[string]Coverage{},
+	}
+
+	for slug, byLocale := range files {
+		source, ok := byLocale[i18n.DefaultLocale]
+		if !ok {
+			// Pages that only exist in a translation have no source to track
+			continue
+		}
+
+		row := Row{Slug: slug, SourcePath: source, Cells: map[string]Cell{}}
+		for _, locale := range m.Locales {
+			cell := Cell{Path: i18n.Join(locale.Key, slug), Status: StatusMissing}
+			if _, exists := byLocale[locale.Key]; exists {
+				cell.Status = compare(commits[source], commits[cell.Path])
+			}
+			row.Cells[locale.Key] = cell
+
+			cov := m.Coverage[locale.Key]
+			switch cell.Status {
+			case StatusUpToDate:
+				cov.UpToDate++
+			case StatusOutdated:
+				cov.Outdated++
+			default:
+				cov.Missing++
+			}
+			m.Coverage[locale.Key] = cov
+		}
+		m.Rows = append(m.Rows, row)
+	}
+
+	sort.Slice(m.Rows, func(a, b int) bool { return m.Rows[a].Slug < m.Rows[b].Slug })
+	return m, nil
+}
+
+func (s *service) Pair(ctx context.Context, repo db.Repository, sourcePath, locale string) (*Pair, error) {
+	i18n, err := s.Locales(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	target, ok := i18n.Locale(locale)
+	if !ok || locale == i18n.DefaultLocale {
+		return nil, ErrUnknownLocale
+	}
+
+	_, slug := i18n.Split(sourcePath)
+	pair := &Pair{
+		Locale:      target,
+		Source:      File{Path: sourcePath},
+		Translation: File{Path: i18n.Join(locale, slug)},
+		Status:      StatusMissing,
+	}
+
+	if err := s.readInto(repo, &pair.Source); err != nil {
+		return nil, err
+	}
+	if !pair.Source.Exists {
+		return nil, os.ErrNotExist
+	}
+	if err := s.readInto(repo, &pair.Translation); err != nil {
+		return nil, err
+	}
+
+	if pair.Translation.Exists {
+		commits, err := s.commitTimes(ctx, repo)
+		if err != nil {
+			return nil, err
+		}
+		pair.Status = compare(commits[pair.Source.Path], commits[pair.Translation.Path])
+	}
+
+	return pair, nil
+}
+
+func (s *service) CreateStub(ctx context.Context, repo db.Repository, sourcePath, locale string) (string, error) {
+	pair, err := s.Pair(ctx, repo, sourcePath, locale)
+	if err != nil {
+		return "", err
+	}
+	if pair.Translation.Exists {
+		return "", ErrTranslationExists
+	}
+
+	if _, err := s.files.Write(ctx, repo, pair.Translation.Path, []byte(pair.Source.Body), ""); err != nil {
+		return "", err
+	}
+	return pair.Translation.Path, nil
+}
+
+// readInto fills f with the file's contents when it exists.
+func (s *service) readInto(repo db.Repository, f *File) error {
+	body, hash, err := content.ReadFile(s.contentDir(repo), f.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	f.Body, f.Hash, f.Exists = string(body), hash, true
+	return nil
+}
+
+// commitTimes returns last commit times keyed by content-relative path.
+func (s *service) commitTimes(ctx context.Context, repo db.Repository) (map[string]time.Time, error) {
+	times, err := repository.LastCommitTimes(ctx, repository.Dir(s.reposDir, repo.ID), repo.ContentPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit history: %w", err)
+	}
+
+	prefix := path.Clean(repo.ContentPath) + "/"
+	byContentPath := make(map[string]time.Time, len(times))
+	for p, t := range times {
+		if rel, ok := strings.CutPrefix(p, prefix); ok {
+			byContentPath[rel] = t
+		}
+	}
+	return byContentPath, nil
+}
+
+// compare decides a translation's status from the last commit times of the
+// source and translation. Uncommitted translations count as current work.
+func compare(source, translation time.Time) Status {
+	if translation.IsZero() || !translation.Before(source) {
+		return StatusUpToDate
+	}
+	return StatusOutdated
+}
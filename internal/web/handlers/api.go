@@ -0,0 +1,359 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gracchi-stdio/goaat/internal/apitoken"
+	"github.com/gracchi-stdio/goaat/internal/auth"
+	"github.com/gracchi-stdio/goaat/internal/content"
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+	"github.com/gracchi-stdio/goaat/internal/presence"
+	"github.com/gracchi-stdio/goaat/internal/repository"
+	"github.com/gracchi-stdio/goaat/internal/workflow"
+	"github.com/labstack/echo/v4"
+)
+
+// APIFile is a content file with the hash to send back when writing it.
+type APIFile struct {
+	Path string `json:"path"`
+	Body string `json:"body,omitempty"`
+	Hash string `json:"hash"`
+}
+
+// APIFileWrite replaces a content file. BaseHash is the hash the file was
+// read with, or empty to create it; the If-Match header may carry it
+// instead.
+type APIFileWrite struct {
+	Body     string `json:"body"`
+	BaseHash string `json:"base_hash,omitempty"`
+}
+
+// APIChangeSet is a change set as the API returns it.
+type APIChangeSet struct {
+	ID              int64         `json:"id"`
+	RepositoryID    int64         `json:"repository_id"`
+	Title           string        `json:"title"`
+	Description     string        `json:"description"`
+	Author          string        `json:"author"`
+	State           string        `json:"state"`
+	PublishedCommit string        `json:"published_commit,omitempty"`
+	UpdatedAt       time.Time     `json:"updated_at"`
+	Files           []string      `json:"files"`
+	Approvals       []APIApproval `json:"approvals"`
+}
+
+// APIApproval is a reviewer's approval of a change set.
+type APIApproval struct {
+	User string    `json:"user"`
+	Time time.Time `json:"time"`
+}
+
+// APIChangeSetCreate starts a draft change set from pending files.
+type APIChangeSetCreate struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description,omitempty"`
+	Files       []string `json:"files"`
+}
+
+// APIAction is a workflow action on a change set, with an optional note.
+type APIAction struct {
+	Note string `json:"note,omitempty"`
+}
+
+// APIListRepositories lists the repositories the token reaches
+func (h *Handler) APIListRepositories(c echo.Context) error {
+	if h.DB == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "database unavailable")
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	user := auth.GetUserFromContext(c.Request().Context())
+	repos, err := h.DB.ListRepositoriesForUser(ctx, user.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to list repositories: %w", err)
+	}
+
+	grant, _ := apitoken.GrantFromContext(c.Request().Context())
+	reachable := make([]db.Repository, 0, len(repos))
+	for _, repo := range repos {
+		if grant.Reaches(repo.ID) {
+			reachable = append(reachable, repo)
+		}
+	}
+	return c.JSON(http.StatusOK, reachable)
+}
+
+// APIGetRepository returns one repository
+func (h *Handler) APIGetRepository(c echo.Context) error {
+	repo, err := h.apiRepository(c, apitoken.PermissionRead)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, repo)
+}
+
+// APIGetFile returns a content file and its hash, also sent as the ETag
+func (h *Handler) APIGetFile(c echo.Context) error {
+	repo, err := h.apiRepository(c, apitoken.PermissionRead)
+	if err != nil {
+		return err
+	}
+
+	file, err := h.Files.Read(c.Request().Context(), repo, c.QueryParam("path"))
+	if errors.Is(err, content.ErrInvalidPath) || errors.Is(err, os.ErrNotExist) {
+		return echo.NewHTTPError(http.StatusNotFound, "file not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	c.Response().Header().Set("ETag", strconv.Quote(file.Hash))
+	return c.JSON(http.StatusOK, APIFile{Path: file.Path, Body: file.Body, Hash: file.Hash})
+}
+
+// APIPutFile writes a content file if it is unchanged since it was read
+func (h *Handler) APIPutFile(c echo.Context) error {
+	repo, err := h.apiRepository(c, apitoken.PermissionWrite)
+	if err != nil {
+		return err
+	}
+	if err := h.requireEditor(c, repo); err != nil {
+		return err
+	}
+
+	var write APIFileWrite
+	if err := c.Bind(&write); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid file")
+	}
+	if ifMatch := c.Request().Header.Get("If-Match"); ifMatch != "" {
+		write.BaseHash = strings.Trim(ifMatch, `"`)
+	}
+
+	if problem := h.authorReferenceProblem(c, repo, []byte(write.Body)); problem != "" {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, problem)
+	}
+
+	path := c.QueryParam("path")
+	hash, err := h.Files.Write(c.Request().Context(), repo, path, []byte(write.Body), write.BaseHash)
+	switch {
+	case errors.Is(err, content.ErrConflict):
+		return echo.NewHTTPError(http.StatusConflict, "the file changed since base_hash; read it again and reapply your change")
+	case errors.Is(err, content.ErrInvalidPath):
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, "path must be relative to the content directory")
+	case hash == "":
+		return fmt.Errorf("failed to write file: %w", err)
+	case err != nil:
+		c.Logger().Warnf("File saved with warning: %v", err)
+	}
+	h.publishFileEvent(c.Request().Context(), c, repo, presence.EventSaved, path)
+
+	c.Response().Header().Set("ETag", strconv.Quote(hash))
+	return c.JSON(http.StatusOK, APIFile{Path: path, Hash: hash})
+}
+
+// APIListChangeSets lists a repository's change sets, most recently updated first
+func (h *Handler) APIListChangeSets(c echo.Context) error {
+	repo, _, err := h.apiActor(c, apitoken.PermissionRead)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	changeSets, err := h.Workflow.ChangeSets(ctx, repo)
+	if err != nil {
+		return fmt.Errorf("failed to list change sets: %w", err)
+	}
+	list := make([]APIChangeSet, 0, len(changeSets))
+	for i := range changeSets {
+		list = append(list, apiChangeSet(&changeSets[i]))
+	}
+	return c.JSON(http.StatusOK, list)
+}
+
+// APICreateChangeSet starts a draft change set from pending files
+func (h *Handler) APICreateChangeSet(c echo.Context) error {
+	repo, actor, err := h.apiActor(c, apitoken.PermissionWrite)
+	if err != nil {
+		return err
+	}
+
+	var create APIChangeSetCreate
+	if err := c.Bind(&create); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid change set")
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	id, err := h.Workflow.Create(ctx, actor, repo, create.Title, create.Description, create.Files)
+	if err != nil {
+		return apiWorkflowError(err)
+	}
+	cs, err := h.Workflow.ChangeSet(ctx, repo, id)
+	if err != nil {
+		return apiWorkflowError(err)
+	}
+	return c.JSON(http.StatusCreated, apiChangeSet(cs))
+}
+
+// APIGetChangeSet returns a change set with its files and approvals
+func (h *Handler) APIGetChangeSet(c echo.Context) error {
+	repo, _, err := h.apiActor(c, apitoken.PermissionRead)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.ParseInt(c.Param("changeset"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "change set not found")
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	cs, err := h.Workflow.ChangeSet(ctx, repo, id)
+	if err != nil {
+		return apiWorkflowError(err)
+	}
+	return c.JSON(http.StatusOK, apiChangeSet(cs))
+}
+
+// APITransitionChangeSet takes a workflow action on a change set. Publishing
+// needs a token with the publish permission.
+func (h *Handler) APITransitionChangeSet(c echo.Context) error {
+	action, ok := workflow.ParseAction(c.Param("action"))
+	if !ok {
+		return echo.NewHTTPError(http.StatusNotFound, "unknown action")
+	}
+	required := apitoken.PermissionWrite
+	if action == workflow.ActionPublish {
+		required = apitoken.PermissionPublish
+	}
+
+	repo, actor, err := h.apiActor(c, required)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.ParseInt(c.Param("changeset"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "change set not found")
+	}
+
+	var body APIAction
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid action")
+	}
+
+	// Publishing runs git, so allow longer than a query
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 30*time.Second)
+	defer cancel()
+
+	cs, err := h.Workflow.Transition(ctx, actor, repo, id, action, body.Note)
+	if err != nil {
+		return apiWorkflowError(err)
+	}
+	h.emitPublished(ctx, c, repo, actor, cs)
+	return c.JSON(http.StatusOK, apiChangeSet(cs))
+}
+
+// apiRepository loads the repository named by the :id route param, checking
+// that the token reaches it and carries the required permission. The user's
+// own access is checked as for the web pages.
+func (h *Handler) apiRepository(c echo.Context, required apitoken.Permission) (db.Repository, error) {
+	repo, err := h.repositoryFromParam(c)
+	if err != nil {
+		return db.Repository{}, err
+	}
+
+	grant, _ := apitoken.GrantFromContext(c.Request().Context())
+	if !grant.Reaches(repo.ID) {
+		return db.Repository{}, echo.NewHTTPError(http.StatusNotFound, "repository not found")
+	}
+	if !grant.Permission.Allows(required) {
+		return db.Repository{}, echo.NewHTTPError(http.StatusForbidden, "the token does not have the "+string(required)+" permission")
+	}
+	return repo, nil
+}
+
+// apiActor resolves the repository and the token user's role in it.
+func (h *Handler) apiActor(c echo.Context, required apitoken.Permission) (db.Repository, workflow.Actor, error) {
+	if h.Workflow == nil {
+		return db.Repository{}, workflow.Actor{}, echo.NewHTTPError(http.StatusServiceUnavailable, "database unavailable")
+	}
+
+	repo, err := h.apiRepository(c, required)
+	if err != nil {
+		return db.Repository{}, workflow.Actor{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	user := auth.GetUserFromContext(c.Request().Context())
+	actor, err := h.Workflow.Actor(ctx, repo, user.UserID)
+	if err != nil {
+		return db.Repository{}, workflow.Actor{}, apiWorkflowError(err)
+	}
+	return repo, actor, nil
+}
+
+// apiChangeSet converts a change set to its API representation.
+func apiChangeSet(cs *workflow.ChangeSet) APIChangeSet {
+	approvals := make([]APIApproval, 0, len(cs.Approvals))
+	for _, a := range cs.Approvals {
+		approvals = append(approvals, APIApproval{User: a.UserName, Time: a.Time})
+	}
+	files := cs.Files
+	if files == nil {
+		files = []string{}
+	}
+	return APIChangeSet{
+		ID:              cs.ID,
+		RepositoryID:    cs.RepositoryID,
+		Title:           cs.Title,
+		Description:     cs.Description,
+		Author:          cs.AuthorName,
+		State:           string(cs.State),
+		PublishedCommit: cs.PublishedCommit,
+		UpdatedAt:       cs.UpdatedAt,
+		Files:           files,
+		Approvals:       approvals,
+	}
+}
+
+// apiWorkflowError maps workflow service errors to API status codes;
+// anything unexpected becomes a logged internal error.
+func apiWorkflowError(err error) error {
+	switch {
+	case errors.Is(err, workflow.ErrUnknownChangeSet):
+		return echo.NewHTTPError(http.StatusNotFound, "change set not found")
+	case errors.Is(err, workflow.ErrForbidden):
+		return echo.NewHTTPError(http.StatusForbidden, "your role does not allow this")
+	case errors.Is(err, workflow.ErrInvalidTransition):
+		return echo.NewHTTPError(http.StatusConflict, "this action is not available in the change set's current state")
+	case errors.Is(err, workflow.ErrStale):
+		return echo.NewHTTPError(http.StatusConflict, "someone else updated this change set")
+	case errors.Is(err, repository.ErrNothingToCommit):
+		return echo.NewHTTPError(http.StatusConflict, "the change set's files have no changes left to publish")
+	case errors.Is(err, workflow.ErrNoTitle):
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, "title is required")
+	case errors.Is(err, workflow.ErrNoFiles):
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, "files must list at least one changed file")
+	case errors.Is(err, workflow.ErrFileClaimed):
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, "a file is already part of another change set")
+	default:
+		return err
+	}
+}
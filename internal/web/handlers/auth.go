@@ -19,10 +19,12 @@ func (h *Handler) Auth(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "Provider not specified")
 	}
 
-	// Add provider to context for gothic
+	// Add provider to context for gothic, and the page to return to for
+	// the OAuth state
 	req := c.Request()
 	// Use gothic.ProviderParamKey to avoid SA1029 and ensure gothic finds it
 	ctx := context.WithValue(req.Context(), gothic.ProviderParamKey, provider)
+	ctx = auth.WithReturnTo(ctx, c.QueryParam(auth.ReturnToParam))
 	c.SetRequest(req.WithContext(ctx))
 
 	h.AuthService.BeginAuth(c.Response(), c.Request())
@@ -78,18 +80,13 @@ func (h *Handler) AuthCallback(c echo.Context) error {
 		s.AvatarURL = dbUser.AvatarUrl.String
 	}
 
-	// Check for return_to URL
-	returnTo := "/"
-	if s.ReturnTo != "" {
-		returnTo = s.ReturnTo
-		s.ReturnTo = "" // Clear it
-	}
-
 	if err := auth.SaveSession(c, s); err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to save session")
 	}
 
-	return c.Redirect(http.StatusTemporaryRedirect, returnTo)
+	// gothic has checked the state against the one it issued, so the page
+	// to return to in it is the one the sign-in started with
+	return c.Redirect(http.StatusSeeOther, auth.ReturnTo(gothic.GetState(c.Request())))
 }
 
 // Logout clears the session
@@ -1,19 +1,283 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
+	"github.com/gracchi-stdio/goaat/internal/auth"
+	"github.com/gracchi-stdio/goaat/internal/author"
+	"github.com/gracchi-stdio/goaat/internal/web/templates/components"
+	"github.com/gracchi-stdio/goaat/internal/web/templates/pages"
+	"github.com/gracchi-stdio/goaat/internal/workflow"
 	"github.com/labstack/echo/v4"
+	"github.com/starfederation/datastar-go/datastar"
 )
 
+// errAuthorInUse is returned for authors listed by the authors collection of
+// a repository the user cannot edit.
+var errAuthorInUse = errors.New("author is listed by a repository the user cannot edit")
+
+// authorSignals mirrors the Datastar signals of the authors page.
+type authorSignals struct {
+	AuthorSearch string `json:"authorSearch"`
+	AuthorName   string `json:"authorName"`
+	AuthorEmail  string `json:"authorEmail"`
+	AuthorUser   string `json:"authorUser"`
+}
+
+// ListAuthors returns a page of authors, filtered by ?q= and continued from
+// ?after=; the next page's URL is in the Link header
 func (h *Handler) ListAuthors(c echo.Context) error {
-	if h.DB == nil {
+	if h.Authors == nil {
 		return echo.NewHTTPError(http.StatusServiceUnavailable, "database unavailable")
 	}
 
-	authors, err := h.DB.ListAuthors(c.Request().Context())
+	limit := 0
+	if text := c.QueryParam("limit"); text != "" {
+		var err error
+		if limit, err = strconv.Atoi(text); err != nil || limit < 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, "limit must be a positive integer")
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	page, err := h.Authors.List(ctx, author.Query{Search: c.QueryParam("q"), After: c.QueryParam("after"), Limit: limit})
+	if errors.Is(err, author.ErrInvalidCursor) {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid after cursor")
+	}
 	if err != nil {
+		c.Logger().Errorf("Failed to list authors: %v", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to fetch authors")
 	}
-	return c.JSON(http.StatusOK, authors)
+
+	if page.Next != "" {
+		next := c.Request().URL.Query()
+		next.Set("after", page.Next)
+		c.Response().Header().Set("Link", "<"+(&url.URL{Path: c.Request().URL.Path, RawQuery: next.Encode()}).String()+`>; rel="next"`)
+	}
+	return c.JSON(http.StatusOK, page.Authors)
+}
+
+// AuthorListPage renders the authors admin with Datastar support
+func (h *Handler) AuthorListPage(c echo.Context) error {
+	if h.Authors == nil || h.DB == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "database unavailable")
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	view, err := h.authorsView(ctx, c.QueryParam("q"), c.QueryParam("after"))
+	if err != nil {
+		return authorPageError(c, err)
+	}
+	if c.Request().Header.Get("datastar-request") != "" {
+		return RenderWithDatastar(c, pages.AuthorListContent(view))
+	}
+	return Render(c, pages.AuthorList(view))
+}
+
+// AuthorResults patches in the authors matching the search box, from the
+// page after ?after=
+func (h *Handler) AuthorResults(c echo.Context) error {
+	if h.Authors == nil || h.DB == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "database unavailable")
+	}
+
+	var signals authorSignals
+	if err := datastar.ReadSignals(c.Request(), &signals); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid search request")
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	sse := datastar.NewSSE(c.Response().Writer, c.Request())
+	view, err := h.authorsView(ctx, signals.AuthorSearch, c.QueryParam("after"))
+	if err != nil {
+		return sse.PatchElementTempl(components.Toast(authorErrorMessage(c, err), "danger"))
+	}
+	return sse.PatchElementTempl(pages.AuthorResults(view))
+}
+
+// CreateAuthor adds an author from the form
+func (h *Handler) CreateAuthor(c echo.Context) error {
+	return h.saveAuthor(c, 0)
+}
+
+// UpdateAuthor saves the author being edited
+func (h *Handler) UpdateAuthor(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "author not found")
+	}
+	return h.saveAuthor(c, id)
+}
+
+// DeleteAuthor removes an author
+func (h *Handler) DeleteAuthor(c echo.Context) error {
+	if h.Authors == nil || h.DB == nil || h.Workflow == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "database unavailable")
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "author not found")
+	}
+
+	var signals authorSignals
+	if err := datastar.ReadSignals(c.Request(), &signals); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid author")
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	sse := datastar.NewSSE(c.Response().Writer, c.Request())
+	if err := h.authorWritable(ctx, c, id); err != nil {
+		return sse.PatchElementTempl(components.Toast(authorErrorMessage(c, err), "danger"))
+	}
+	if err := h.Authors.Delete(ctx, id); err != nil {
+		return sse.PatchElementTempl(components.Toast(authorErrorMessage(c, err), "danger"))
+	}
+	return h.patchAuthors(ctx, c, sse, signals.AuthorSearch, "Author deleted")
+}
+
+// saveAuthor creates an author, or updates it when id is set.
+func (h *Handler) saveAuthor(c echo.Context, id int64) error {
+	if h.Authors == nil || h.DB == nil || h.Workflow == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "database unavailable")
+	}
+
+	var signals authorSignals
+	if err := datastar.ReadSignals(c.Request(), &signals); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid author")
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	sse := datastar.NewSSE(c.Response().Writer, c.Request())
+
+	input := author.Input{Name: signals.AuthorName, Email: signals.AuthorEmail}
+	if signals.AuthorUser != "" {
+		userID, err := strconv.ParseInt(signals.AuthorUser, 10, 64)
+		if err != nil {
+			return sse.PatchElementTempl(components.Toast(authorErrorMessage(c, author.ErrUnknownUser), "danger"))
+		}
+		input.UserID = userID
+	}
+
+	var err error
+	message := "Author added"
+	if id == 0 {
+		_, err = h.Authors.Create(ctx, input)
+	} else if err = h.authorWritable(ctx, c, id); err == nil {
+		_, err = h.Authors.Update(ctx, id, input)
+		message = "Author saved"
+	}
+	if err != nil {
+		return sse.PatchElementTempl(components.Toast(authorErrorMessage(c, err), "danger"))
+	}
+
+	if err := sse.MarshalAndPatchSignals(map[string]any{"authorID": 0, "authorName": "", "authorEmail": "", "authorUser": ""}); err != nil {
+		return err
+	}
+	return h.patchAuthors(ctx, c, sse, signals.AuthorSearch, message)
+}
+
+// patchAuthors refreshes the first page of the list after a change.
+func (h *Handler) patchAuthors(ctx context.Context, c echo.Context, sse *datastar.ServerSentEventGenerator, search, message string) error {
+	view, err := h.authorsView(ctx, search, "")
+	if err != nil {
+		return sse.PatchElementTempl(components.Toast(authorErrorMessage(c, err), "danger"))
+	}
+	if err := sse.PatchElementTempl(pages.AuthorResults(view)); err != nil {
+		return err
+	}
+	return sse.PatchElementTempl(components.Toast(message, "success"))
+}
+
+// authorWritable checks the current user may change an author. A change
+// reaches every repository whose collection lists the author, so the user
+// must be able to edit the content of each.
+func (h *Handler) authorWritable(ctx context.Context, c echo.Context, id int64) error {
+	repos, err := h.Authors.Repositories(ctx, id)
+	if err != nil {
+		return err
+	}
+	user := auth.GetUserFromContext(c.Request().Context())
+	for _, repo := range repos {
+		actor, err := h.Workflow.Actor(ctx, repo, user.UserID)
+		if errors.Is(err, workflow.ErrForbidden) {
+			return errAuthorInUse
+		}
+		if err != nil {
+			return err
+		}
+		if !actor.CanEdit() {
+			return errAuthorInUse
+		}
+	}
+	return nil
+}
+
+// authorsView loads a page of authors and the users they can be linked to.
+func (h *Handler) authorsView(ctx context.Context, search, after string) (*pages.AuthorsView, error) {
+	page, err := h.Authors.List(ctx, author.Query{Search: search, After: after})
+	if err != nil {
+		return nil, err
+	}
+	users, err := h.DB.ListUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &pages.AuthorsView{
+		Search:  search,
+		Authors: page.Authors,
+		Next:    page.Next,
+		Paged:   after != "",
+		Users:   users,
+	}, nil
+}
+
+// authorPageError maps errors loading the authors page to HTTP errors.
+func authorPageError(c echo.Context, err error) error {
+	if errors.Is(err, author.ErrInvalidCursor) {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid after cursor")
+	}
+	c.Logger().Errorf("Failed to load authors: %v", err)
+	return echo.NewHTTPError(http.StatusInternalServerError, "failed to load authors")
+}
+
+// authorErrorMessage maps author service errors to user-facing text,
+// logging the ones that are not the user's to fix.
+func authorErrorMessage(c echo.Context, err error) string {
+	switch {
+	case errors.Is(err, author.ErrNoName):
+		return "Give the author a name."
+	case errors.Is(err, author.ErrInvalidEmail):
+		return "Enter a valid email address."
+	case errors.Is(err, author.ErrEmailTaken):
+		return "Another author already has this email address."
+	case errors.Is(err, author.ErrUserTaken):
+		return "This user is already linked to another author."
+	case errors.Is(err, author.ErrUnknownUser):
+		return "User not found."
+	case errors.Is(err, author.ErrUnknownAuthor):
+		return "Author not found."
+	case errors.Is(err, author.ErrInvalidCursor):
+		return "That page is no longer available."
+	case errors.Is(err, errAuthorInUse):
+		return "This author is in the authors collection of a repository you cannot edit."
+	default:
+		c.Logger().Errorf("Failed to update authors: %v", err)
+		return "Failed to update authors."
+	}
 }
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gracchi-stdio/goaat/internal/auth"
+	"github.com/gracchi-stdio/goaat/internal/author"
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+	"github.com/gracchi-stdio/goaat/internal/workflow"
+	"github.com/labstack/echo/v4"
+)
+
+// fakeAuthors has one author, listed by the collections of repos.
+type fakeAuthors struct {
+	author.Service
+	repos   []db.Repository
+	deleted bool
+}
+
+func (f *fakeAuthors) Repositories(ctx context.Context, id int64) ([]db.Repository, error) {
+	return f.repos, nil
+}
+
+func (f *fakeAuthors) List(ctx context.Context, q author.Query) (author.Page, error) {
+	return author.Page{}, nil
+}
+
+func (f *fakeAuthors) Delete(ctx context.Context, id int64) error {
+	f.deleted = true
+	return nil
+}
+
+func TestDeleteAuthorNeedsEveryRepository(t *testing.T) {
+	listed := []db.Repository{{ID: 1, OwnerID: 1}}
+	tests := []struct {
+		name   string
+		userID int64
+		role   string
+		repos  []db.Repository
+		want   bool
+	}{
+		{"in no collection", 2, "", nil, true},
+		{"owner", 1, "", listed, true},
+		{"editor", 2, string(workflow.RoleEditor), listed, true},
+		{"reviewer", 2, string(workflow.RoleReviewer), listed, false},
+		{"not a member", 2, "", listed, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queries := db.New(fakeDB{role: tt.role})
+			authors := &fakeAuthors{repos: tt.repos}
+			h := &Handler{DB: queries, Authors: authors, Workflow: workflow.NewService(queries, nil, t.TempDir())}
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodDelete, "/admin/authors/5", strings.NewReader(`{}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req = req.WithContext(context.WithValue(req.Context(), auth.UserContextKey, auth.UserSession{UserID: tt.userID}))
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues("5")
+
+			if err := h.DeleteAuthor(c); err != nil {
+				t.Fatal(err)
+			}
+			if authors.deleted != tt.want {
+				t.Fatalf("deleted = %v, want %v", authors.deleted, tt.want)
+			}
+			if !tt.want && !strings.Contains(rec.Body.String(), "a repository you cannot edit") {
+				t.Errorf("response does not explain the refusal:\n%s", rec.Body.String())
+			}
+		})
+	}
+}
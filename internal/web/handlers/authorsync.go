@@ -0,0 +1,325 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gracchi-stdio/goaat/internal/author"
+	"github.com/gracchi-stdio/goaat/internal/authorsync"
+	"github.com/gracchi-stdio/goaat/internal/content"
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+	"github.com/gracchi-stdio/goaat/internal/web/templates/components"
+	"github.com/gracchi-stdio/goaat/internal/web/templates/pages"
+	"github.com/gracchi-stdio/goaat/internal/webhook"
+	"github.com/gracchi-stdio/goaat/internal/workflow"
+	"github.com/labstack/echo/v4"
+	"github.com/starfederation/datastar-go/datastar"
+)
+
+// authorCollectionSignals mirrors the Datastar signals of the authors collection page.
+type authorCollectionSignals struct {
+	CollectionPath   string `json:"collectionPath"`
+	CollectionKey    string `json:"collectionKey"`
+	CollectionAuthor string `json:"collectionAuthor"`
+}
+
+// bylineSignals are the translation editor signals the byline picker uses.
+type bylineSignals struct {
+	TranslationBody string `json:"translationBody"`
+	BylineAuthor    string `json:"bylineAuthor"`
+}
+
+// AuthorCollectionPage renders the authors collection settings and what a sync would do
+func (h *Handler) AuthorCollectionPage(c echo.Context) error {
+	repo, actor, err := h.authorSyncActor(c)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	view, err := h.authorCollectionView(ctx, repo, actor)
+	if err != nil {
+		c.Logger().Errorf("Failed to load authors collection: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to load the authors collection")
+	}
+
+	if c.Request().Header.Get("datastar-request") != "" {
+		return RenderWithDatastar(c, pages.AuthorCollectionContent(repo, view))
+	}
+	return Render(c, pages.AuthorCollection(repo, view))
+}
+
+// ConfigureAuthorCollection turns syncing on, or changes where the collection is
+func (h *Handler) ConfigureAuthorCollection(c echo.Context) error {
+	repo, actor, err := h.authorSyncActor(c)
+	if err != nil {
+		return err
+	}
+
+	var signals authorCollectionSignals
+	if err := datastar.ReadSignals(c.Request(), &signals); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid settings")
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	sse := datastar.NewSSE(c.Response().Writer, c.Request())
+	if _, err := h.AuthorSync.Configure(ctx, actor, repo, signals.CollectionPath, signals.CollectionKey); err != nil {
+		return sse.PatchElementTempl(components.Toast(authorSyncErrorMessage(c, err), "danger"))
+	}
+	return h.patchAuthorCollection(ctx, c, sse, repo, actor, "Authors collection saved")
+}
+
+// DisableAuthorCollection stops syncing, leaving authors and files as they are
+func (h *Handler) DisableAuthorCollection(c echo.Context) error {
+	repo, actor, err := h.authorSyncActor(c)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	sse := datastar.NewSSE(c.Response().Writer, c.Request())
+	if err := h.AuthorSync.Disable(ctx, actor, repo); err != nil {
+		return sse.PatchElementTempl(components.Toast(authorSyncErrorMessage(c, err), "danger"))
+	}
+	return h.patchAuthorCollection(ctx, c, sse, repo, actor, "Authors are no longer synced")
+}
+
+// SyncAuthorCollection applies the changes of the sync plan, leaving conflicts
+func (h *Handler) SyncAuthorCollection(c echo.Context) error {
+	repo, actor, err := h.authorSyncActor(c)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	sse := datastar.NewSSE(c.Response().Writer, c.Request())
+	done, err := h.AuthorSync.Sync(ctx, actor, repo)
+	if err != nil {
+		return sse.PatchElementTempl(components.Toast(authorSyncErrorMessage(c, err), "danger"))
+	}
+	h.emitWebhook(ctx, c, repo, webhook.EventSyncCompleted, webhook.SyncCompleted{
+		Changes:   len(done.Changes),
+		Conflicts: len(done.Conflicts),
+		Sender:    webhookSender(c),
+	})
+
+	view, err := h.authorCollectionView(ctx, repo, actor)
+	if err != nil {
+		c.Logger().Errorf("Failed to load authors collection: %v", err)
+		return sse.PatchElementTempl(components.Toast("Failed to load the authors collection.", "danger"))
+	}
+	if view.Plan != nil {
+		// Changes that failed are pending again; keep the reasons they failed
+		view.Plan.Problems = done.Problems
+	}
+	if err := sse.PatchElementTempl(pages.AuthorCollectionBody(repo, view)); err != nil {
+		return err
+	}
+
+	message := "Synced " + strconv.Itoa(len(done.Changes)) + " change(s)"
+	if n := len(done.Conflicts); n > 0 {
+		message += "; " + strconv.Itoa(n) + " conflict(s) to resolve"
+	}
+	return sse.PatchElementTempl(components.Toast(message, "success"))
+}
+
+// AddCollectionAuthor lists an author in the collection, for the next sync to write their file
+func (h *Handler) AddCollectionAuthor(c echo.Context) error {
+	repo, actor, err := h.authorSyncActor(c)
+	if err != nil {
+		return err
+	}
+
+	var signals authorCollectionSignals
+	if err := datastar.ReadSignals(c.Request(), &signals); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid author")
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	sse := datastar.NewSSE(c.Response().Writer, c.Request())
+	authorID, err := strconv.ParseInt(signals.CollectionAuthor, 10, 64)
+	if err != nil {
+		return sse.PatchElementTempl(components.Toast(authorSyncErrorMessage(c, author.ErrUnknownAuthor), "danger"))
+	}
+	if err := h.AuthorSync.Add(ctx, actor, repo, authorID); err != nil {
+		return sse.PatchElementTempl(components.Toast(authorSyncErrorMessage(c, err), "danger"))
+	}
+	return h.patchAuthorCollection(ctx, c, sse, repo, actor, "Author added; sync to write their file")
+}
+
+// ResolveAuthorConflict keeps one side of a conflicting entry, ?keep=file or ?keep=author
+func (h *Handler) ResolveAuthorConflict(c echo.Context) error {
+	repo, actor, err := h.authorSyncActor(c)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	sse := datastar.NewSSE(c.Response().Writer, c.Request())
+	keep := authorsync.Side(c.QueryParam("keep"))
+	if err := h.AuthorSync.Resolve(ctx, actor, repo, c.Param("slug"), keep); err != nil {
+		return sse.PatchElementTempl(components.Toast(authorSyncErrorMessage(c, err), "danger"))
+	}
+	return h.patchAuthorCollection(ctx, c, sse, repo, actor, "Conflict resolved")
+}
+
+// AddByline credits an author from the collection in the edited page's frontmatter
+func (h *Handler) AddByline(c echo.Context) error {
+	repo, actor, err := h.authorSyncActor(c)
+	if err != nil {
+		return err
+	}
+	if !actor.CanEdit() {
+		return echo.NewHTTPError(http.StatusForbidden, "your role does not allow editing content")
+	}
+
+	var signals bylineSignals
+	if err := datastar.ReadSignals(c.Request(), &signals); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid byline")
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	sse := datastar.NewSSE(c.Response().Writer, c.Request())
+
+	collection, err := h.AuthorSync.Collection(ctx, repo)
+	if err != nil {
+		return sse.PatchElementTempl(components.Toast(authorSyncErrorMessage(c, err), "danger"))
+	}
+	entries, err := h.AuthorSync.Entries(ctx, repo)
+	if err != nil {
+		return sse.PatchElementTempl(components.Toast(authorSyncErrorMessage(c, err), "danger"))
+	}
+	if !slices.ContainsFunc(entries, func(e authorsync.Entry) bool { return e.Slug == signals.BylineAuthor }) {
+		return sse.PatchElementTempl(components.Toast("That author is not in the collection.", "danger"))
+	}
+
+	body, err := authorsync.AddReference([]byte(signals.TranslationBody), collection.FrontmatterKey, signals.BylineAuthor)
+	if err != nil {
+		return sse.PatchElementTempl(components.Toast("Fix the page's frontmatter before adding a byline.", "danger"))
+	}
+	return sse.MarshalAndPatchSignals(bylineSignals{TranslationBody: string(body)})
+}
+
+// authorSyncActor resolves the repository and the current user's role in it.
+func (h *Handler) authorSyncActor(c echo.Context) (db.Repository, workflow.Actor, error) {
+	if h.AuthorSync == nil {
+		return db.Repository{}, workflow.Actor{}, echo.NewHTTPError(http.StatusServiceUnavailable, "database unavailable")
+	}
+	return h.workflowActor(c)
+}
+
+// bylineAuthors lists the authors a page in the repository can credit; none
+// when the repository has no authors collection.
+func (h *Handler) bylineAuthors(c echo.Context, repo db.Repository) []authorsync.Entry {
+	if h.AuthorSync == nil {
+		return nil
+	}
+	entries, err := h.AuthorSync.Entries(c.Request().Context(), repo)
+	if err != nil && !errors.Is(err, authorsync.ErrNotConfigured) {
+		c.Logger().Errorf("Failed to list collection authors: %v", err)
+	}
+	return entries
+}
+
+// authorReferenceProblem checks the author references of a file about to be
+// saved, returning why it should not be, or "" if it may. A check that fails
+// is logged rather than blocking the save.
+func (h *Handler) authorReferenceProblem(c echo.Context, repo db.Repository, src []byte) string {
+	if h.AuthorSync == nil {
+		return ""
+	}
+	err := h.AuthorSync.CheckReferences(c.Request().Context(), repo, src)
+	var refErr *authorsync.ReferenceError
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &refErr):
+		return "The authors collection has no " + strings.Join(refErr.Unknown, ", ") + "."
+	default:
+		c.Logger().Errorf("Failed to check author references: %v", err)
+		return ""
+	}
+}
+
+// patchAuthorCollection re-renders the page after a change.
+func (h *Handler) patchAuthorCollection(ctx context.Context, c echo.Context, sse *datastar.ServerSentEventGenerator, repo db.Repository, actor workflow.Actor, message string) error {
+	view, err := h.authorCollectionView(ctx, repo, actor)
+	if err != nil {
+		c.Logger().Errorf("Failed to load authors collection: %v", err)
+		return sse.PatchElementTempl(components.Toast("Failed to load the authors collection.", "danger"))
+	}
+	if err := sse.PatchElementTempl(pages.AuthorCollectionBody(repo, view)); err != nil {
+		return err
+	}
+	return sse.PatchElementTempl(components.Toast(message, "success"))
+}
+
+// authorCollectionView loads the collection settings and, when syncing is
+// on, its sync plan.
+func (h *Handler) authorCollectionView(ctx context.Context, repo db.Repository, actor workflow.Actor) (*pages.AuthorCollectionView, error) {
+	view := &pages.AuthorCollectionView{Actor: actor}
+	collection, err := h.AuthorSync.Collection(ctx, repo)
+	if errors.Is(err, authorsync.ErrNotConfigured) {
+		return view, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	view.Collection = &collection
+
+	plan, err := h.AuthorSync.Plan(ctx, repo)
+	if errors.Is(err, content.ErrInvalidPath) {
+		return view, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	view.Plan = plan
+	return view, nil
+}
+
+// authorSyncErrorMessage maps author sync errors to user-facing text,
+// logging the ones that are not the user's to fix.
+func authorSyncErrorMessage(c echo.Context, err error) string {
+	switch {
+	case errors.Is(err, workflow.ErrForbidden):
+		return "Your role does not allow this."
+	case errors.Is(err, authorsync.ErrNotConfigured):
+		return "Authors are not synced in this repository."
+	case errors.Is(err, authorsync.ErrInvalidKey):
+		return "The frontmatter key must be a plain field name, like authors."
+	case errors.Is(err, content.ErrInvalidPath):
+		return "The collection path must be a directory inside the repository."
+	case errors.Is(err, authorsync.ErrNoConflict):
+		return "This entry is no longer in conflict. Reload to see the current plan."
+	case errors.Is(err, authorsync.ErrInvalidSide):
+		return "Choose whether to keep the file or the author."
+	case errors.Is(err, content.ErrConflict):
+		return "A collection file changed while resolving. Reload and try again."
+	case errors.Is(err, author.ErrNoName), errors.Is(err, author.ErrInvalidEmail),
+		errors.Is(err, author.ErrEmailTaken), errors.Is(err, author.ErrUnknownAuthor):
+		return authorErrorMessage(c, err)
+	default:
+		c.Logger().Errorf("Failed to sync authors: %v", err)
+		return "Failed to sync authors."
+	}
+}
@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gracchi-stdio/goaat/internal/auth"
+	"github.com/gracchi-stdio/goaat/internal/collab"
+	"github.com/gracchi-stdio/goaat/internal/content"
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+	"github.com/gracchi-stdio/goaat/internal/presence"
+	"github.com/gracchi-stdio/goaat/internal/web/templates/components"
+	"github.com/gracchi-stdio/goaat/internal/web/templates/pages"
+	"github.com/labstack/echo/v4"
+	"github.com/starfederation/datastar-go/datastar"
+)
+
+// collabPollInterval is how often a co-editing stream checks the op log
+// even without notifications, in case one was dropped.
+const collabPollInterval = 5 * time.Second
+
+// collabOpsRequest is the body posted by <collab-editor>.
+type collabOpsRequest struct {
+	Ops []collab.Op `json:"ops"`
+}
+
+// CollabEditorPage opens the live co-editing session on a content file
+func (h *Handler) CollabEditorPage(c echo.Context) error {
+	if h.Collab == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "database unavailable")
+	}
+
+	repo, err := h.repositoryFromParam(c)
+	if err != nil {
+		return err
+	}
+
+	session, err := h.Collab.Open(c.Request().Context(), repo, c.QueryParam("path"))
+	if err != nil {
+		return collabHTTPError(c, err)
+	}
+
+	if c.Request().Header.Get("datastar-request") != "" {
+		return RenderWithDatastar(c, pages.CollabEditorContent(repo, session))
+	}
+	return Render(c, pages.CollabEditor(repo, session))
+}
+
+// CollabStream sends the session's document and then every new operation
+// as scripts that feed <collab-editor>
+func (h *Handler) CollabStream(c echo.Context) error {
+	repo, session, err := h.collabSessionFromParam(c)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	events, unsubscribe := h.Presence.Subscribe(repo.ID)
+	defer unsubscribe()
+
+	if err := h.Collab.Join(ctx, repo, session.ID); err != nil {
+		return collabHTTPError(c, err)
+	}
+	defer h.Collab.Leave(session.ID)
+
+	snapshot, err := h.Collab.Snapshot(ctx, repo, session.ID)
+	if err != nil {
+		return collabHTTPError(c, err)
+	}
+
+	sse := datastar.NewSSE(c.Response().Writer, c.Request())
+	if err := sse.ExecuteScript(collabScript("load", snapshot)); err != nil {
+		return nil
+	}
+
+	seq := snapshot.Seq
+	ticker := time.NewTicker(collabPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if event.Path != session.Path {
+				continue
+			}
+		}
+
+		batch, err := h.Collab.Ops(ctx, repo, session.ID, seq)
+		if err != nil {
+			if ctx.Err() == nil {
+				c.Logger().Errorf("Failed to read op log: %v", err)
+			}
+			return nil
+		}
+		if len(batch.Ops) == 0 {
+			continue
+		}
+		if err := sse.ExecuteScript(collabScript("receive", batch)); err != nil {
+			return nil
+		}
+		seq = batch.Seq
+	}
+}
+
+// ApplyCollabOps appends a client's operations to the session's op log
+func (h *Handler) ApplyCollabOps(c echo.Context) error {
+	repo, session, err := h.collabSessionFromParam(c)
+	if err != nil {
+		return err
+	}
+	if err := h.requireEditor(c, repo); err != nil {
+		return err
+	}
+
+	var req collabOpsRequest
+	if err := json.NewDecoder(c.Request().Body).Decode(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid operations")
+	}
+
+	ctx := c.Request().Context()
+	user := auth.GetUserFromContext(ctx)
+
+	if err := h.Collab.Apply(ctx, user.UserID, repo, session.ID, req.Ops); err != nil {
+		if errors.Is(err, collab.ErrInvalidOp) {
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		}
+		return collabHTTPError(c, err)
+	}
+
+	h.publishFileEvent(ctx, c, repo, presence.EventEdited, session.Path)
+	return c.NoContent(http.StatusNoContent)
+}
+
+// SaveCollab writes the session's document to the content file
+func (h *Handler) SaveCollab(c echo.Context) error {
+	repo, session, err := h.collabSessionFromParam(c)
+	if err != nil {
+		return err
+	}
+	if err := h.requireEditor(c, repo); err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	sse := datastar.NewSSE(c.Response().Writer, c.Request())
+
+	hash, err := h.Collab.Save(ctx, repo, session.ID)
+	if hash == "" {
+		message := "Failed to save the document."
+		if errors.Is(err, content.ErrConflict) {
+			message = "The file was changed outside this session. Reopen it to continue from the latest version."
+		} else {
+			c.Logger().Errorf("Failed to save collaborative document: %v", err)
+		}
+		return sse.PatchElementTempl(components.Toast(message, "danger"))
+	}
+	if err != nil {
+		c.Logger().Warnf("Collaborative document saved with warning: %v", err)
+	}
+
+	h.publishFileEvent(ctx, c, repo, presence.EventSaved, session.Path)
+	return sse.PatchElementTempl(components.Toast("Document saved", "success"))
+}
+
+// collabSessionFromParam resolves the :session param within the repository.
+func (h *Handler) collabSessionFromParam(c echo.Context) (db.Repository, *collab.Session, error) {
+	if h.Collab == nil || h.Presence == nil {
+		return db.Repository{}, nil, echo.NewHTTPError(http.StatusServiceUnavailable, "database unavailable")
+	}
+
+	repo, err := h.repositoryFromParam(c)
+	if err != nil {
+		return db.Repository{}, nil, err
+	}
+
+	id, err := strconv.ParseInt(c.Param("session"), 10, 64)
+	if err != nil {
+		return db.Repository{}, nil, echo.NewHTTPError(http.StatusNotFound, "document not found")
+	}
+	session, err := h.Collab.Session(c.Request().Context(), repo, id)
+	if err != nil {
+		return db.Repository{}, nil, collabHTTPError(c, err)
+	}
+	return repo, session, nil
+}
+
+// collabScript calls a <collab-editor> method with a JSON argument.
+// encoding/json escapes <, > and & so the payload cannot close the script tag.
+func collabScript(method string, arg any) string {
+	payload, _ := json.Marshal(arg)
+	return fmt.Sprintf("document.getElementById('collab-editor')?.%s(%s)", method, payload)
+}
+
+// collabHTTPError turns a failed session lookup into 404 or 500.
+func collabHTTPError(c echo.Context, err error) error {
+	if errors.Is(err, collab.ErrUnknownDocument) || errors.Is(err, content.ErrInvalidPath) || errors.Is(err, os.ErrNotExist) {
+		return echo.NewHTTPError(http.StatusNotFound, "document not found")
+	}
+	c.Logger().Errorf("Collaborative editing failed: %v", err)
+	return echo.NewHTTPError(http.StatusInternalServerError, "collaborative editing failed")
+}
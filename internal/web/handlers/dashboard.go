@@ -1,16 +1,49 @@
 package handlers
 
 import (
+	"context"
+	"time"
+
+	"github.com/gracchi-stdio/goaat/internal/auth"
 	"github.com/gracchi-stdio/goaat/internal/web/templates/pages"
 	"github.com/labstack/echo/v4"
 )
 
 // DashboardPage renders the main dashboard page with Datastar support
 func (h *Handler) DashboardPage(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	user := auth.GetUserFromContext(c.Request().Context())
+	view, err := h.dashboardView(ctx, user.UserID)
+	if err != nil {
+		// The dashboard still works without its lists
+		c.Logger().Errorf("Failed to load dashboard: %v", err)
+		view = &pages.DashboardView{}
+	}
+
 	// Example: Show a welcome alert on Datastar navigation
 	// This will be picked up by the frontend JS
 	if c.Request().Header.Get("datastar-request") != "" {
-		return RenderWithDatastar(c, pages.DashboardContent())
+		return RenderWithDatastar(c, pages.DashboardContent(view))
+	}
+	return Render(c, pages.Dashboard(view))
+}
+
+// dashboardView loads the user's scheduled publishes and unread
+// notifications, leaving them empty without a database.
+func (h *Handler) dashboardView(ctx context.Context, userID int64) (*pages.DashboardView, error) {
+	view := &pages.DashboardView{}
+	var err error
+	if h.Workflow != nil {
+		if view.Schedules, err = h.Workflow.Upcoming(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+	if h.Notifications != nil {
+		if view.Notifications, err = h.Notifications.Unread(ctx, userID); err != nil {
+			return nil, err
+		}
 	}
-	return Render(c, pages.Dashboard())
+	return view, nil
 }
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gracchi-stdio/goaat/internal/apitoken"
+	"github.com/gracchi-stdio/goaat/internal/graph"
+	"github.com/labstack/echo/v4"
+)
+
+// GraphQL runs a read-only GraphQL query as the token's user
+func (h *Handler) GraphQL(c echo.Context) error {
+	if h.Graph == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "database unavailable")
+	}
+
+	var req graph.Request
+	if err := c.Bind(&req); err != nil || req.Query == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "the body must be a JSON object with a query")
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	grant, _ := apitoken.GrantFromContext(ctx)
+	resp := h.Graph.Execute(ctx, grant, req)
+	for _, err := range resp.Errors {
+		if err.ResolverError != nil {
+			c.Logger().Errorf("Failed to resolve %v: %v", err.Path, err.ResolverError)
+		}
+	}
+	return c.JSON(http.StatusOK, resp)
+}
@@ -2,9 +2,27 @@ package handlers
 
 import (
 	"github.com/a-h/templ"
+	"github.com/gracchi-stdio/goaat/internal/apitoken"
 	"github.com/gracchi-stdio/goaat/internal/auth"
+	"github.com/gracchi-stdio/goaat/internal/author"
+	"github.com/gracchi-stdio/goaat/internal/authorsync"
+	"github.com/gracchi-stdio/goaat/internal/collab"
+	"github.com/gracchi-stdio/goaat/internal/files"
+	"github.com/gracchi-stdio/goaat/internal/graph"
+	"github.com/gracchi-stdio/goaat/internal/health"
+	"github.com/gracchi-stdio/goaat/internal/notification"
+	"github.com/gracchi-stdio/goaat/internal/openapi"
 	"github.com/gracchi-stdio/goaat/internal/platform/db"
+	"github.com/gracchi-stdio/goaat/internal/presence"
+	"github.com/gracchi-stdio/goaat/internal/preview"
+	"github.com/gracchi-stdio/goaat/internal/review"
+	"github.com/gracchi-stdio/goaat/internal/revision"
+	"github.com/gracchi-stdio/goaat/internal/search"
+	"github.com/gracchi-stdio/goaat/internal/starlight"
+	"github.com/gracchi-stdio/goaat/internal/translation"
 	"github.com/gracchi-stdio/goaat/internal/web/templates/layouts"
+	"github.com/gracchi-stdio/goaat/internal/webhook"
+	"github.com/gracchi-stdio/goaat/internal/workflow"
 	"github.com/labstack/echo/v4"
 	"github.com/starfederation/datastar-go/datastar"
 )
@@ -12,16 +30,74 @@ import (
 // Handler holds dependencies for HTTP handlers.
 // All handlers should check for nil DB before database operations.
 type Handler struct {
-	DB          *db.Queries
-	AuthService auth.Service
+	DB            *db.Queries
+	AuthService   auth.Service
+	Authors       author.Service
+	AuthorSync    authorsync.Service
+	Search        search.Service
+	Files         files.Service
+	Translations  translation.Service
+	Starlight     starlight.Service
+	Revisions     revision.Service
+	Presence      presence.Service
+	Collab        collab.Service
+	Review        review.Service
+	Workflow      workflow.Service
+	Notifications notification.Service
+	Previews      preview.Service // nil unless preview builds are enabled
+	Tokens        apitoken.Service
+	Graph         graph.Service
+	Webhooks      webhook.Service
+	Readiness     *health.Checker
+	API           *openapi.Spec // set by the router, which describes the API
+}
+
+// Services groups the application services injected into handlers.
+// Services that depend on the database are nil when it is unavailable.
+type Services struct {
+	Auth          auth.Service
+	Authors       author.Service
+	AuthorSync    authorsync.Service
+	Search        search.Service
+	Files         files.Service
+	Translations  translation.Service
+	Starlight     starlight.Service
+	Revisions     revision.Service
+	Presence      presence.Service
+	Collab        collab.Service
+	Review        review.Service
+	Workflow      workflow.Service
+	Notifications notification.Service
+	Previews      preview.Service // nil unless preview builds are enabled
+	Tokens        apitoken.Service
+	Graph         graph.Service
+	Webhooks      webhook.Service
+	Readiness     *health.Checker
 }
 
 // New creates a new Handler with dependencies.
 // DB can be nil if database is unavailable.
-func New(db *db.Queries, authService auth.Service) *Handler {
+func New(db *db.Queries, services Services) *Handler {
 	return &Handler{
-		DB:          db,
-		AuthService: authService,
+		DB:            db,
+		AuthService:   services.Auth,
+		Authors:       services.Authors,
+		AuthorSync:    services.AuthorSync,
+		Search:        services.Search,
+		Files:         services.Files,
+		Translations:  services.Translations,
+		Starlight:     services.Starlight,
+		Revisions:     services.Revisions,
+		Presence:      services.Presence,
+		Collab:        services.Collab,
+		Review:        services.Review,
+		Workflow:      services.Workflow,
+		Notifications: services.Notifications,
+		Previews:      services.Previews,
+		Tokens:        services.Tokens,
+		Graph:         services.Graph,
+		Webhooks:      services.Webhooks,
+		Readiness:     services.Readiness,
 	}
 }
 
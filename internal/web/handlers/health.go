@@ -3,11 +3,27 @@ package handlers
 import (
 	"net/http"
 
+	"github.com/gracchi-stdio/goaat/internal/health"
 	"github.com/labstack/echo/v4"
 )
 
-func (h *Handler) Health(c echo.Context) error {
+// Livez reports that the process is up and serving requests.
+func (h *Handler) Livez(c echo.Context) error {
 	return c.JSON(http.StatusOK, map[string]string{
 		"status": "ok",
 	})
 }
+
+// Readyz runs the readiness checks, answering 503 with the failed checks
+// when this replica should not receive traffic.
+func (h *Handler) Readyz(c echo.Context) error {
+	if h.Readiness == nil {
+		return h.Livez(c)
+	}
+	report := h.Readiness.Run(c.Request().Context())
+	c.Response().Header().Set("Cache-Control", "no-store")
+	if report.Status != health.StatusOK {
+		return c.JSON(http.StatusServiceUnavailable, report)
+	}
+	return c.JSON(http.StatusOK, report)
+}
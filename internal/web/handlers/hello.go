@@ -13,13 +13,3 @@ func (h *Handler) HelloPage(c echo.Context) error {
 	}
 	return Render(c, pages.Hello(name))
 }
-
-// AuthorListPage renders the author list page with Datastar support
-func (h *Handler) AuthorListPage(c echo.Context) error {
-	// Mock data for now - will use real service later
-	authors := []string{"Alice", "Bob", "Charlie"}
-	if c.Request().Header.Get("datastar-request") != "" {
-		return RenderWithDatastar(c, pages.AuthorListContent(authors))
-	}
-	return Render(c, pages.AuthorList(authors))
-}
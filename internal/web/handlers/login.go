@@ -1,11 +1,13 @@
 package handlers
 
 import (
+	"github.com/gracchi-stdio/goaat/internal/auth"
 	"github.com/gracchi-stdio/goaat/internal/web/templates/pages"
 	"github.com/labstack/echo/v4"
 )
 
-// LoginPage renders the login page with OAuth options.
+// LoginPage renders the login page with OAuth options, which return to
+// the page in the return_to parameter if it is on this site.
 func (h *Handler) LoginPage(c echo.Context) error {
-	return Render(c, pages.Login())
+	return Render(c, pages.Login(auth.SafeReturnTo(c.QueryParam(auth.ReturnToParam))))
 }
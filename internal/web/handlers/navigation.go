@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gracchi-stdio/goaat/internal/content"
+	"github.com/gracchi-stdio/goaat/internal/starlight"
+	"github.com/gracchi-stdio/goaat/internal/web/templates/components"
+	"github.com/gracchi-stdio/goaat/internal/web/templates/pages"
+	"github.com/labstack/echo/v4"
+	"github.com/starfederation/datastar-go/datastar"
+)
+
+// navigationSignals mirrors the Datastar signals of the navigation editor.
+// NavOrder is the JSON-encoded []starlight.Order written by <nav-editor>.
+type navigationSignals struct {
+	NavOrder    string `json:"navOrder"`
+	NavBaseHash string `json:"navBaseHash"`
+}
+
+// NavigationPage renders the Starlight site settings and sidebar editor
+func (h *Handler) NavigationPage(c echo.Context) error {
+	repo, err := h.repositoryFromParam(c)
+	if err != nil {
+		return err
+	}
+
+	cfg, hash, err := h.Starlight.Config(c.Request().Context(), repo)
+	if err != nil && !errors.Is(err, starlight.ErrNoConfig) {
+		c.Logger().Errorf("Failed to load Starlight config for %s: %v", repo.FullName, err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to read the Astro config")
+	}
+
+	if c.Request().Header.Get("datastar-request") != "" {
+		return RenderWithDatastar(c, pages.NavigationContent(repo, cfg, hash))
+	}
+	return Render(c, pages.Navigation(repo, cfg, hash))
+}
+
+// SaveNavigation rewrites the sidebar order with an optimistic hash check
+func (h *Handler) SaveNavigation(c echo.Context) error {
+	repo, err := h.editorRepository(c)
+	if err != nil {
+		return err
+	}
+
+	var signals navigationSignals
+	if err := datastar.ReadSignals(c.Request(), &signals); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid navigation")
+	}
+
+	var order []starlight.Order
+	if err := json.Unmarshal([]byte(signals.NavOrder), &order); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid navigation order")
+	}
+
+	sse := datastar.NewSSE(c.Response().Writer, c.Request())
+
+	hash, err := h.Starlight.SaveSidebar(c.Request().Context(), repo, order, signals.NavBaseHash)
+	if err != nil {
+		return sse.PatchElementTempl(components.Toast(navigationErrorMessage(c, err), "danger"))
+	}
+
+	if err := sse.MarshalAndPatchSignals(navigationSignals{NavOrder: signals.NavOrder, NavBaseHash: hash}); err != nil {
+		return err
+	}
+	return sse.PatchElementTempl(components.Toast("Navigation saved", "success"))
+}
+
+// navigationErrorMessage maps sidebar save errors to user-facing text.
+func navigationErrorMessage(c echo.Context, err error) string {
+	switch {
+	case errors.Is(err, content.ErrConflict):
+		return "The Astro config was changed by someone else. Reload to get the latest version."
+	case errors.Is(err, starlight.ErrInvalidOrder):
+		return "The new order is incomplete. Reload and try again."
+	case errors.Is(err, starlight.ErrNoConfig):
+		return "No Starlight configuration found."
+	default:
+		c.Logger().Errorf("Failed to save navigation: %v", err)
+		return "Failed to save navigation."
+	}
+}
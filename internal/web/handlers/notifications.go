@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gracchi-stdio/goaat/internal/auth"
+	"github.com/gracchi-stdio/goaat/internal/web/templates/components"
+	"github.com/gracchi-stdio/goaat/internal/web/templates/layouts"
+	"github.com/gracchi-stdio/goaat/internal/web/templates/pages"
+	"github.com/labstack/echo/v4"
+	"github.com/starfederation/datastar-go/datastar"
+)
+
+// DismissNotification marks a notification as read and refreshes the dashboard
+func (h *Handler) DismissNotification(c echo.Context) error {
+	if h.Notifications == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "database unavailable")
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "notification not found")
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	sse := datastar.NewSSE(c.Response().Writer, c.Request())
+
+	user := auth.GetUserFromContext(c.Request().Context())
+	if err := h.Notifications.Dismiss(ctx, user.UserID, id); err != nil {
+		c.Logger().Errorf("Failed to dismiss notification: %v", err)
+		return sse.PatchElementTempl(components.Toast("Failed to dismiss the notification.", "danger"))
+	}
+
+	view, err := h.dashboardView(ctx, user.UserID)
+	if err != nil {
+		c.Logger().Errorf("Failed to load dashboard: %v", err)
+		return sse.PatchElementTempl(components.Toast("Failed to load the dashboard.", "danger"))
+	}
+	return sse.PatchElementTempl(layouts.PageContentWrapper(pages.DashboardContent(view)),
+		datastar.WithSelectorID("page-content"),
+	)
+}
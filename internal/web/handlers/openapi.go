@@ -0,0 +1,18 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gracchi-stdio/goaat/internal/web/templates/pages"
+	"github.com/labstack/echo/v4"
+)
+
+// OpenAPIDocument serves the API's OpenAPI document
+func (h *Handler) OpenAPIDocument(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.API.Document())
+}
+
+// APIDocsPage renders the API reference from the OpenAPI document
+func (h *Handler) APIDocsPage(c echo.Context) error {
+	return Render(c, pages.APIDocs(h.API.Document()))
+}
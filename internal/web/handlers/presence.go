@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gracchi-stdio/goaat/internal/auth"
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+	"github.com/gracchi-stdio/goaat/internal/presence"
+	"github.com/gracchi-stdio/goaat/internal/web/templates/components"
+	"github.com/gracchi-stdio/goaat/internal/web/templates/pages"
+	"github.com/gracchi-stdio/goaat/internal/webhook"
+	"github.com/labstack/echo/v4"
+	"github.com/starfederation/datastar-go/datastar"
+)
+
+// PresenceStream is a long-lived SSE stream of who has which file open.
+// With ?path= the user is present in that file (heartbeat for as long as the
+// stream is open) and gets the editor header patched; without it the
+// avatars of every open file are patched, e.g. into the translation matrix.
+func (h *Handler) PresenceStream(c echo.Context) error {
+	if h.Presence == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "database unavailable")
+	}
+
+	repo, err := h.repositoryFromParam(c)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	user := auth.GetUserFromContext(ctx)
+	path := c.QueryParam("path")
+	if path != "" {
+		if err := h.checkPresencePath(ctx, repo, path); err != nil {
+			return err
+		}
+	}
+
+	events, unsubscribe := h.Presence.Subscribe(repo.ID)
+	defer unsubscribe()
+
+	if path != "" {
+		if err := h.Presence.Heartbeat(ctx, user.UserID, repo, path); err != nil {
+			c.Logger().Errorf("Presence heartbeat failed: %v", err)
+		}
+		h.publishFileEvent(ctx, c, repo, presence.EventJoined, path)
+
+		defer func() {
+			// The request context is already cancelled when the client goes away
+			leaveCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := h.Presence.Leave(leaveCtx, user.UserID, repo, path); err != nil {
+				c.Logger().Errorf("Failed to remove presence: %v", err)
+			}
+			h.publishFileEvent(leaveCtx, c, repo, presence.EventLeft, path)
+		}()
+	}
+
+	sse := datastar.NewSSE(c.Response().Writer, c.Request())
+	shown := map[string]bool{}
+
+	render := func() error {
+		viewers, err := h.Presence.Viewers(ctx, repo)
+		if err != nil {
+			return err
+		}
+
+		if path != "" {
+			lock, err := h.Presence.CurrentLock(ctx, repo, path)
+			if err != nil {
+				return err
+			}
+			return sse.PatchElementTempl(pages.FilePresence(repo.ID, path, othersIn(viewers[path], user.UserID), lock, user.UserID))
+		}
+
+		// Patch files that gained viewers and clear the ones that lost them
+		for p := range shown {
+			if _, ok := viewers[p]; !ok {
+				if err := sse.PatchElementTempl(pages.PresenceAvatars(p, nil)); err != nil {
+					return err
+				}
+				delete(shown, p)
+			}
+		}
+		for p, v := range viewers {
+			if err := sse.PatchElementTempl(pages.PresenceAvatars(p, v)); err != nil {
+				return err
+			}
+			shown[p] = true
+		}
+		return nil
+	}
+
+	if err := render(); err != nil {
+		c.Logger().Errorf("Failed to render presence: %v", err)
+		return nil
+	}
+
+	ticker := time.NewTicker(presence.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case <-ticker.C:
+			if path != "" {
+				if err := h.Presence.Heartbeat(ctx, user.UserID, repo, path); err != nil {
+					c.Logger().Errorf("Presence heartbeat failed: %v", err)
+				}
+			}
+
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if event.Type == presence.EventSaved && event.Path == path && event.UserID != user.UserID {
+				toast := components.Toast(event.UserName+" saved this file. Reload to get their changes before saving.", "warning")
+				if err := sse.PatchElementTempl(toast); err != nil {
+					return nil
+				}
+			}
+		}
+
+		// Re-render on every tick too, so stale viewers drop off
+		if err := render(); err != nil {
+			if ctx.Err() == nil {
+				c.Logger().Errorf("Failed to render presence: %v", err)
+			}
+			return nil
+		}
+	}
+}
+
+// LockFile takes the advisory soft lock on a file
+func (h *Handler) LockFile(c echo.Context) error {
+	if h.Presence == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "database unavailable")
+	}
+
+	repo, err := h.repositoryFromParam(c)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	user := auth.GetUserFromContext(ctx)
+	path := c.QueryParam("path")
+	if err := h.checkPresencePath(ctx, repo, path); err != nil {
+		return err
+	}
+	sse := datastar.NewSSE(c.Response().Writer, c.Request())
+
+	if _, err := h.Presence.Lock(ctx, user.UserID, repo, path); err != nil {
+		message := "Failed to lock file."
+		if errors.Is(err, presence.ErrLocked) {
+			message = "Someone else has locked this file."
+		} else {
+			c.Logger().Errorf("Failed to lock file: %v", err)
+		}
+		return sse.PatchElementTempl(components.Toast(message, "danger"))
+	}
+
+	h.publishFileEvent(ctx, c, repo, presence.EventLocked, path)
+	return sse.PatchElementTempl(components.Toast("File locked for 30 minutes", "success"))
+}
+
+// UnlockFile releases the user's soft lock on a file
+func (h *Handler) UnlockFile(c echo.Context) error {
+	if h.Presence == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "database unavailable")
+	}
+
+	repo, err := h.repositoryFromParam(c)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	user := auth.GetUserFromContext(ctx)
+	path := c.QueryParam("path")
+	if err := h.checkPresencePath(ctx, repo, path); err != nil {
+		return err
+	}
+	sse := datastar.NewSSE(c.Response().Writer, c.Request())
+
+	if err := h.Presence.Unlock(ctx, user.UserID, repo, path); err != nil {
+		c.Logger().Errorf("Failed to unlock file: %v", err)
+		return sse.PatchElementTempl(components.Toast("Failed to unlock file.", "danger"))
+	}
+
+	h.publishFileEvent(ctx, c, repo, presence.EventUnlocked, path)
+	return sse.PatchElementTempl(components.Toast("File unlocked", "success"))
+}
+
+// checkPresencePath makes sure a path names a content file of the
+// repository, so presence rows, locks and events only carry real files.
+func (h *Handler) checkPresencePath(ctx context.Context, repo db.Repository, path string) error {
+	if h.Files == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "content unavailable")
+	}
+	if err := h.Files.Exists(ctx, repo, path); err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "file not found")
+	}
+	return nil
+}
+
+// publishFileEvent tells the repository's other editors, on every replica,
+// what the current user did to a file, and its webhooks of saves. Failures
+// are logged, not returned: notifications never block the action itself.
+func (h *Handler) publishFileEvent(ctx context.Context, c echo.Context, repo db.Repository, eventType presence.EventType, path string) {
+	if eventType == presence.EventSaved {
+		h.emitWebhook(ctx, c, repo, webhook.EventPageSaved, webhook.PageSaved{Path: path, Sender: webhookSender(c)})
+	}
+	if h.Presence == nil {
+		return
+	}
+
+	user := auth.GetUserFromContext(c.Request().Context())
+	err := h.Presence.Publish(ctx, presence.Event{
+		Type:         eventType,
+		RepositoryID: repo.ID,
+		Path:         path,
+		UserID:       user.UserID,
+		UserName:     user.Name,
+	})
+	if err != nil {
+		c.Logger().Errorf("Failed to publish %s event: %v", eventType, err)
+	}
+}
+
+// othersIn drops the current user from a file's viewers.
+func othersIn(viewers []presence.Viewer, userID int64) []presence.Viewer {
+	others := make([]presence.Viewer, 0, len(viewers))
+	for _, v := range viewers {
+		if v.UserID != userID {
+			others = append(others, v)
+		}
+	}
+	return others
+}
@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gracchi-stdio/goaat/internal/preview"
+	"github.com/gracchi-stdio/goaat/internal/web/templates/components"
+	"github.com/gracchi-stdio/goaat/internal/web/templates/layouts"
+	"github.com/gracchi-stdio/goaat/internal/web/templates/pages"
+	"github.com/gracchi-stdio/goaat/internal/workflow"
+	"github.com/labstack/echo/v4"
+	"github.com/starfederation/datastar-go/datastar"
+)
+
+// StartPreview builds the rendered site of a change set in the background
+func (h *Handler) StartPreview(c echo.Context) error {
+	if h.Previews == nil {
+		return echo.NewHTTPError(http.StatusNotFound, "previews are not enabled")
+	}
+	repo, actor, err := h.workflowActor(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.ParseInt(c.Param("changeset"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "change set not found")
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	sse := datastar.NewSSE(c.Response().Writer, c.Request())
+
+	cs, err := h.Workflow.ChangeSet(ctx, repo, id)
+	if err == nil && !actor.CanEdit() {
+		err = workflow.ErrForbidden
+	}
+	if err != nil {
+		return sse.PatchElementTempl(components.Toast(workflowErrorMessage(c, err), "danger"))
+	}
+
+	if err := h.Previews.Start(repo, cs.ID, cs.Files); err != nil {
+		if errors.Is(err, preview.ErrBuilding) {
+			return sse.PatchElementTempl(components.Toast("A preview of this change set is already building.", "warning"))
+		}
+		c.Logger().Errorf("Failed to start preview build: %v", err)
+		return sse.PatchElementTempl(components.Toast("Failed to start the preview build.", "danger"))
+	}
+
+	view, err := h.changeSetView(ctx, repo, actor, cs)
+	if err != nil {
+		return sse.PatchElementTempl(components.Toast(workflowErrorMessage(c, err), "danger"))
+	}
+	if err := sse.PatchElementTempl(layouts.PageContentWrapper(pages.ChangeSetContent(repo, view)),
+		datastar.WithSelectorID("page-content"),
+	); err != nil {
+		return err
+	}
+	return sse.PatchElementTempl(components.Toast("Preview build started", "success"))
+}
+
+// PreviewLogStream is a long-lived SSE stream of a running build's log. When
+// the build ends, the preview card is patched with its outcome.
+func (h *Handler) PreviewLogStream(c echo.Context) error {
+	if h.Previews == nil {
+		return echo.NewHTTPError(http.StatusNotFound, "previews are not enabled")
+	}
+	repo, actor, err := h.workflowActor(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.ParseInt(c.Param("changeset"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "change set not found")
+	}
+
+	ctx := c.Request().Context()
+	lookupCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if _, err := h.Workflow.ChangeSet(lookupCtx, repo, id); err != nil {
+		return workflowHTTPError(c, err)
+	}
+
+	lines, updates, stop := h.Previews.Follow(id)
+	defer stop()
+
+	sse := datastar.NewSSE(c.Response().Writer, c.Request())
+	if err := sse.PatchElementTempl(pages.PreviewLog(lines)); err != nil {
+		return nil
+	}
+
+	// Builds running on another replica are followed through their log file
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case <-ticker.C:
+			if updates != nil {
+				continue
+			}
+			view, err := h.previewView(actor, id)
+			if err != nil {
+				c.Logger().Errorf("Failed to load preview build: %v", err)
+				return nil
+			}
+			if view.Build != nil && view.Build.Status == preview.StatusBuilding {
+				lines, _, _ := h.Previews.Follow(id)
+				if err := sse.PatchElementTempl(pages.PreviewLog(lines)); err != nil {
+					return nil
+				}
+				continue
+			}
+			return sse.PatchElementTempl(pages.ChangeSetPreview(repo.ID, id, view))
+
+		case line, ok := <-updates:
+			if !ok {
+				// Finished here, or running elsewhere: the next tick tells
+				updates = nil
+				continue
+			}
+			if err := sse.PatchElementTempl(pages.PreviewLogLine(line),
+				datastar.WithSelectorID("preview-log"),
+				datastar.WithModeAppend(),
+			); err != nil {
+				return nil
+			}
+		}
+	}
+}
+
+// ServePreview serves a change set's built site to users with access to its repository
+func (h *Handler) ServePreview(c echo.Context) error {
+	c.Response().Header().Set("Content-Security-Policy", preview.ContentSecurityPolicy)
+	if h.Previews == nil || h.DB == nil {
+		return echo.NewHTTPError(http.StatusNotFound, "preview not found")
+	}
+
+	id, err := strconv.ParseInt(c.Param("changeset"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "preview not found")
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	repoID, err := h.DB.GetChangeSetRepositoryID(ctx, id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "preview not found")
+	}
+	if _, err := h.repositoryForUser(c, repoID); err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "preview not found")
+	}
+
+	root, ok := h.Previews.Root(id)
+	if !ok {
+		return echo.NewHTTPError(http.StatusNotFound, "preview not built yet")
+	}
+	if c.Param("*") == "" && c.Request().URL.Path != preview.URL(id) {
+		return c.Redirect(http.StatusMovedPermanently, preview.URL(id))
+	}
+
+	file, ok := siteFile(root, c.Param("*"))
+	if !ok {
+		return echo.NewHTTPError(http.StatusNotFound, "page not found")
+	}
+
+	// A rebuild replaces the files in place
+	c.Response().Header().Set("Cache-Control", "no-store")
+	return c.File(file)
+}
+
+// siteFile resolves a request path to a file of the built site. Cleaning a
+// rooted path keeps it inside the site, and resolving symlinks keeps a site
+// from linking to files outside it.
+func siteFile(root, rel string) (string, bool) {
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", false
+	}
+	file, err := filepath.EvalSymlinks(filepath.Join(realRoot, filepath.FromSlash(path.Clean("/"+rel))))
+	if err != nil {
+		return "", false
+	}
+	if info, err := os.Stat(file); err == nil && info.IsDir() {
+		if file, err = filepath.EvalSymlinks(filepath.Join(file, "index.html")); err != nil {
+			return "", false
+		}
+	}
+	if file != realRoot && !strings.HasPrefix(file, realRoot+string(filepath.Separator)) {
+		return "", false
+	}
+	return file, true
+}
+
+// previewView loads a change set's latest build, with its log once finished.
+func (h *Handler) previewView(actor workflow.Actor, changeSetID int64) (*pages.PreviewView, error) {
+	build, err := h.Previews.Build(changeSetID)
+	if err != nil {
+		return nil, err
+	}
+	view := &pages.PreviewView{Build: build, CanBuild: actor.CanEdit()}
+	_, view.Built = h.Previews.Root(changeSetID)
+	if build != nil && build.Status != preview.StatusBuilding {
+		var stop func()
+		view.Log, _, stop = h.Previews.Follow(changeSetID)
+		stop()
+	}
+	return view, nil
+}
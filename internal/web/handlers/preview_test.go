@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSiteFile(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	for name, body := range map[string]string{
+		"index.html":      "home",
+		"docs/index.html": "docs",
+		"docs/page.html":  "page",
+	} {
+		file := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(outside, "secret"), []byte("secret"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(outside, "secret"), filepath.Join(root, "leak.html")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "out")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(root, "docs", "page.html"), filepath.Join(root, "alias.html")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		rel  string
+		want string // body served, or "" for not found
+	}{
+		{"", "home"},
+		{"docs/", "docs"},
+		{"docs/page.html", "page"},
+		{"alias.html", "page"},
+		{"../../etc/passwd", ""},
+		{"docs/../../secret", ""},
+		{"missing.html", ""},
+		{"leak.html", ""},
+		{"out/secret", ""},
+	}
+	for _, tt := range tests {
+		file, ok := siteFile(root, tt.rel)
+		if tt.want == "" {
+			if ok {
+				t.Errorf("siteFile(%q) = %s, want not found", tt.rel, file)
+			}
+			continue
+		}
+		if !ok {
+			t.Errorf("siteFile(%q) not found, want %q", tt.rel, tt.want)
+			continue
+		}
+		if body, _ := os.ReadFile(file); string(body) != tt.want {
+			t.Errorf("siteFile(%q) serves %q, want %q", tt.rel, body, tt.want)
+		}
+	}
+}
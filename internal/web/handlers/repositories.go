@@ -1,14 +1,113 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gracchi-stdio/goaat/internal/auth"
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+	"github.com/gracchi-stdio/goaat/internal/platform/logger"
 	"github.com/gracchi-stdio/goaat/internal/web/templates/pages"
+	"github.com/gracchi-stdio/goaat/internal/workflow"
 	"github.com/labstack/echo/v4"
 )
 
 // RepositoriesPage renders the repositories page
 func (h *Handler) RepositoriesPage(c echo.Context) error {
+	var repos []db.Repository
+	if h.DB != nil {
+		ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+		defer cancel()
+
+		user := auth.GetUserFromContext(c.Request().Context())
+		var err error
+		repos, err = h.DB.ListRepositoriesForUser(ctx, user.UserID)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "failed to fetch repositories")
+		}
+	}
+
 	if c.Request().Header.Get("datastar-request") != "" {
-		return RenderWithDatastar(c, pages.RepositoriesContent())
+		return RenderWithDatastar(c, pages.RepositoriesContent(repos))
+	}
+	return Render(c, pages.Repositories(repos))
+}
+
+// repositoryFromParam loads the repository named by the :id route param and
+// checks that the current user owns it or is a member. Unknown and foreign
+// repositories both return 404 so ids cannot be probed.
+func (h *Handler) repositoryFromParam(c echo.Context) (db.Repository, error) {
+	if h.DB == nil {
+		return db.Repository{}, echo.NewHTTPError(http.StatusServiceUnavailable, "database unavailable")
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return db.Repository{}, echo.NewHTTPError(http.StatusNotFound, "repository not found")
+	}
+	return h.repositoryForUser(c, id)
+}
+
+// repositoryForUser loads a repository the current user owns or is a member of.
+func (h *Handler) repositoryForUser(c echo.Context, id int64) (db.Repository, error) {
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	repo, err := h.DB.GetRepository(ctx, id)
+	if err != nil {
+		return db.Repository{}, echo.NewHTTPError(http.StatusNotFound, "repository not found")
+	}
+
+	user := auth.GetUserFromContext(c.Request().Context())
+	if repo.OwnerID != user.UserID {
+		// Members can read everything; editorRepository limits writes by role
+		_, err := h.DB.GetRepositoryMemberRole(ctx, db.GetRepositoryMemberRoleParams{RepositoryID: repo.ID, UserID: user.UserID})
+		if err != nil {
+			return db.Repository{}, echo.NewHTTPError(http.StatusNotFound, "repository not found")
+		}
+	}
+
+	c.SetRequest(c.Request().WithContext(logger.WithRepositoryID(c.Request().Context(), repo.ID)))
+	return repo, nil
+}
+
+// editorRepository loads the repository from the route for a handler that
+// changes its content.
+func (h *Handler) editorRepository(c echo.Context) (db.Repository, error) {
+	repo, err := h.repositoryFromParam(c)
+	if err != nil {
+		return db.Repository{}, err
+	}
+	if err := h.requireEditor(c, repo); err != nil {
+		return db.Repository{}, err
+	}
+	return repo, nil
+}
+
+// requireEditor refuses users whose role in the repository does not let
+// them edit its content, such as reviewers.
+func (h *Handler) requireEditor(c echo.Context, repo db.Repository) error {
+	if h.Workflow == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "database unavailable")
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	user := auth.GetUserFromContext(c.Request().Context())
+	actor, err := h.Workflow.Actor(ctx, repo, user.UserID)
+	if errors.Is(err, workflow.ErrForbidden) {
+		return echo.NewHTTPError(http.StatusNotFound, "repository not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load role: %w", err)
+	}
+	if !actor.CanEdit() {
+		return echo.NewHTTPError(http.StatusForbidden, "your role does not allow editing content")
 	}
-	return Render(c, pages.Repositories())
+	return nil
 }
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gracchi-stdio/goaat/internal/apitoken"
+	"github.com/gracchi-stdio/goaat/internal/auth"
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+	"github.com/gracchi-stdio/goaat/internal/workflow"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/labstack/echo/v4"
+)
+
+// fakeDB answers the queries that load a repository and the user's role in
+// it: repository 1 is owned by user 1, and every other user has role.
+type fakeDB struct {
+	role string // "" when the user is not a member
+}
+
+func (f fakeDB) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, errors.New("unexpected exec")
+}
+
+func (f fakeDB) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return nil, errors.New("unexpected query")
+}
+
+func (f fakeDB) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	switch {
+	case strings.HasPrefix(sql, "-- name: GetRepository :one"):
+		return fakeRow{values: []any{int64(1), int64(1)}}
+	case strings.HasPrefix(sql, "-- name: GetUser :one"):
+		return fakeRow{values: []any{args[0]}}
+	case strings.HasPrefix(sql, "-- name: GetRepositoryMemberRole :one") && f.role != "":
+		return fakeRow{values: []any{f.role}}
+	}
+	return fakeRow{err: pgx.ErrNoRows}
+}
+
+// fakeRow scans its values into the leading columns of a row.
+type fakeRow struct {
+	values []any
+	err    error
+}
+
+func (r fakeRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	for i, v := range r.values {
+		reflect.ValueOf(dest[i]).Elem().Set(reflect.ValueOf(v))
+	}
+	return nil
+}
+
+func TestEditorRepository(t *testing.T) {
+	tests := []struct {
+		name   string
+		userID int64
+		role   string
+		want   int // 0 when the user may edit
+	}{
+		{"owner", 1, "", 0},
+		{"editor", 2, string(workflow.RoleEditor), 0},
+		{"reviewer", 2, string(workflow.RoleReviewer), http.StatusForbidden},
+		{"not a member", 2, "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queries := db.New(fakeDB{role: tt.role})
+			h := &Handler{DB: queries, Workflow: workflow.NewService(queries, nil, t.TempDir())}
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/admin/repositories/1/navigation", nil)
+			req = req.WithContext(context.WithValue(req.Context(), auth.UserContextKey, auth.UserSession{UserID: tt.userID}))
+			c := e.NewContext(req, httptest.NewRecorder())
+			c.SetParamNames("id")
+			c.SetParamValues("1")
+
+			_, err := h.editorRepository(c)
+			if got := statusOf(err); got != tt.want {
+				t.Fatalf("editorRepository = %v, want status %d", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestReviewerTokenCannotPutFile(t *testing.T) {
+	queries := db.New(fakeDB{role: string(workflow.RoleReviewer)})
+	h := &Handler{DB: queries, Workflow: workflow.NewService(queries, nil, t.TempDir())}
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/repositories/1/files?path=intro.md", strings.NewReader(`{"body":"# Hi"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	ctx := context.WithValue(req.Context(), auth.UserContextKey, auth.UserSession{UserID: 2})
+	ctx = apitoken.WithGrant(ctx, apitoken.Grant{UserID: 2, Permission: apitoken.PermissionWrite})
+	c := e.NewContext(req.WithContext(ctx), httptest.NewRecorder())
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+
+	// Files is nil, so getting past the role check would panic
+	if err := h.APIPutFile(c); statusOf(err) != http.StatusForbidden {
+		t.Fatalf("APIPutFile as a reviewer = %v, want 403", err)
+	}
+}
+
+// statusOf returns the HTTP status of a handler error, or 0 for none.
+func statusOf(err error) int {
+	var he *echo.HTTPError
+	if errors.As(err, &he) {
+		return he.Code
+	}
+	if err != nil {
+		return http.StatusInternalServerError
+	}
+	return 0
+}
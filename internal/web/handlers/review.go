@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gracchi-stdio/goaat/internal/auth"
+	"github.com/gracchi-stdio/goaat/internal/content"
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+	"github.com/gracchi-stdio/goaat/internal/review"
+	"github.com/gracchi-stdio/goaat/internal/web/templates/components"
+	"github.com/gracchi-stdio/goaat/internal/web/templates/layouts"
+	"github.com/gracchi-stdio/goaat/internal/web/templates/pages"
+	"github.com/labstack/echo/v4"
+	"github.com/starfederation/datastar-go/datastar"
+)
+
+// reviewSignals mirrors the Datastar signals of the review page.
+type reviewSignals struct {
+	CommentLineStart int               `json:"commentLineStart"`
+	CommentLineEnd   int               `json:"commentLineEnd"`
+	CommentField     string            `json:"commentField"`
+	CommentBody      string            `json:"commentBody"`
+	Replies          map[string]string `json:"replies"`
+}
+
+// ReviewPage shows a content file with its inline comment threads
+func (h *Handler) ReviewPage(c echo.Context) error {
+	if h.Review == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "database unavailable")
+	}
+
+	repo, err := h.repositoryFromParam(c)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	file, err := h.Review.File(ctx, repo, c.QueryParam("path"))
+	if err != nil {
+		return reviewHTTPError(c, err)
+	}
+
+	if c.Request().Header.Get("datastar-request") != "" {
+		return RenderWithDatastar(c, pages.ReviewContent(repo, file))
+	}
+	return Render(c, pages.Review(repo, file))
+}
+
+// CreateReviewThread starts a comment thread on the selected lines or field
+func (h *Handler) CreateReviewThread(c echo.Context) error {
+	if h.Review == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "database unavailable")
+	}
+
+	repo, err := h.repositoryFromParam(c)
+	if err != nil {
+		return err
+	}
+
+	var signals reviewSignals
+	if err := datastar.ReadSignals(c.Request(), &signals); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid comment")
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	user := auth.GetUserFromContext(c.Request().Context())
+	path := c.QueryParam("path")
+	sse := datastar.NewSSE(c.Response().Writer, c.Request())
+
+	_, err = h.Review.CreateThread(ctx, user.UserID, repo, path, review.NewThread{
+		LineStart: signals.CommentLineStart,
+		LineEnd:   signals.CommentLineEnd,
+		Field:     signals.CommentField,
+		Body:      signals.CommentBody,
+	})
+	if err != nil {
+		return sse.PatchElementTempl(components.Toast(reviewErrorMessage(c, err), "danger"))
+	}
+
+	if err := sse.MarshalAndPatchSignals(map[string]any{
+		"commentLineStart": 0,
+		"commentLineEnd":   0,
+		"commentField":     "",
+		"commentBody":      "",
+	}); err != nil {
+		return err
+	}
+	return h.patchReview(ctx, c, sse, repo, path, "Comment added")
+}
+
+// ReplyReviewThread adds the reply typed under a thread
+func (h *Handler) ReplyReviewThread(c echo.Context) error {
+	repo, threadID, err := h.reviewThreadFromParam(c)
+	if err != nil {
+		return err
+	}
+
+	var signals reviewSignals
+	if err := datastar.ReadSignals(c.Request(), &signals); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid reply")
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	user := auth.GetUserFromContext(c.Request().Context())
+	key := pages.ReplyKey(threadID)
+	sse := datastar.NewSSE(c.Response().Writer, c.Request())
+
+	if err := h.Review.Reply(ctx, user.UserID, repo, threadID, signals.Replies[key]); err != nil {
+		return sse.PatchElementTempl(components.Toast(reviewErrorMessage(c, err), "danger"))
+	}
+
+	if err := sse.MarshalAndPatchSignals(map[string]any{"replies": map[string]string{key: ""}}); err != nil {
+		return err
+	}
+	return h.patchReview(ctx, c, sse, repo, c.QueryParam("path"), "Reply added")
+}
+
+// ResolveReviewThread marks a thread as resolved
+func (h *Handler) ResolveReviewThread(c echo.Context) error {
+	repo, threadID, err := h.reviewThreadFromParam(c)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	user := auth.GetUserFromContext(c.Request().Context())
+	sse := datastar.NewSSE(c.Response().Writer, c.Request())
+
+	if err := h.Review.Resolve(ctx, user.UserID, repo, threadID); err != nil {
+		return sse.PatchElementTempl(components.Toast(reviewErrorMessage(c, err), "danger"))
+	}
+	return h.patchReview(ctx, c, sse, repo, c.QueryParam("path"), "Thread resolved")
+}
+
+// ReopenReviewThread clears a thread's resolution
+func (h *Handler) ReopenReviewThread(c echo.Context) error {
+	repo, threadID, err := h.reviewThreadFromParam(c)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	sse := datastar.NewSSE(c.Response().Writer, c.Request())
+
+	if err := h.Review.Reopen(ctx, repo, threadID); err != nil {
+		return sse.PatchElementTempl(components.Toast(reviewErrorMessage(c, err), "danger"))
+	}
+	return h.patchReview(ctx, c, sse, repo, c.QueryParam("path"), "Thread reopened")
+}
+
+// patchReview re-renders the review page after a change and confirms it with a toast.
+func (h *Handler) patchReview(ctx context.Context, c echo.Context, sse *datastar.ServerSentEventGenerator, repo db.Repository, path, message string) error {
+	file, err := h.Review.File(ctx, repo, path)
+	if err != nil {
+		return sse.PatchElementTempl(components.Toast(reviewErrorMessage(c, err), "danger"))
+	}
+	if err := sse.PatchElementTempl(layouts.PageContentWrapper(pages.ReviewContent(repo, file)),
+		datastar.WithSelectorID("page-content"),
+	); err != nil {
+		return err
+	}
+	return sse.PatchElementTempl(components.Toast(message, "success"))
+}
+
+// reviewThreadFromParam resolves the repository and the :thread param.
+func (h *Handler) reviewThreadFromParam(c echo.Context) (db.Repository, int64, error) {
+	if h.Review == nil {
+		return db.Repository{}, 0, echo.NewHTTPError(http.StatusServiceUnavailable, "database unavailable")
+	}
+
+	repo, err := h.repositoryFromParam(c)
+	if err != nil {
+		return db.Repository{}, 0, err
+	}
+
+	id, err := strconv.ParseInt(c.Param("thread"), 10, 64)
+	if err != nil {
+		return db.Repository{}, 0, echo.NewHTTPError(http.StatusNotFound, "comment thread not found")
+	}
+	return repo, id, nil
+}
+
+// reviewErrorMessage maps review service errors to user-facing text,
+// logging the ones that are not the user's to fix.
+func reviewErrorMessage(c echo.Context, err error) string {
+	switch {
+	case errors.Is(err, review.ErrEmptyComment):
+		return "Write a comment first."
+	case errors.Is(err, review.ErrInvalidAnchor):
+		return "Select the lines or field to comment on."
+	case errors.Is(err, review.ErrAmbiguousMention):
+		return "More than one participant goes by that @handle; mention them another way."
+	case errors.Is(err, review.ErrUnknownThread):
+		return "Comment thread not found."
+	case errors.Is(err, content.ErrInvalidPath), errors.Is(err, os.ErrNotExist):
+		return "File not found."
+	default:
+		c.Logger().Errorf("Failed to update review comments: %v", err)
+		return "Failed to save comment."
+	}
+}
+
+// reviewHTTPError turns a failed review lookup into 404 or 500.
+func reviewHTTPError(c echo.Context, err error) error {
+	if errors.Is(err, content.ErrInvalidPath) || errors.Is(err, os.ErrNotExist) {
+		return echo.NewHTTPError(http.StatusNotFound, "file not found")
+	}
+	c.Logger().Errorf("Failed to load review comments: %v", err)
+	return echo.NewHTTPError(http.StatusInternalServerError, "failed to load review comments")
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"os"
+
+	"github.com/gracchi-stdio/goaat/internal/content"
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+	"github.com/gracchi-stdio/goaat/internal/presence"
+	"github.com/gracchi-stdio/goaat/internal/revision"
+	"github.com/gracchi-stdio/goaat/internal/web/templates/components"
+	"github.com/gracchi-stdio/goaat/internal/web/templates/layouts"
+	"github.com/gracchi-stdio/goaat/internal/web/templates/pages"
+	"github.com/labstack/echo/v4"
+	"github.com/starfederation/datastar-go/datastar"
+)
+
+// historySignals mirrors the Datastar signals of the revision history page.
+type historySignals struct {
+	DiffFrom string `json:"diffFrom"`
+	DiffTo   string `json:"diffTo"`
+	BaseHash string `json:"baseHash"`
+}
+
+// HistoryPage lists a file's commits and drafts and diffs two of them.
+// The compare form sends its selection as signals; links use ?from=&to=.
+func (h *Handler) HistoryPage(c echo.Context) error {
+	if h.Revisions == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "database unavailable")
+	}
+
+	repo, err := h.repositoryFromParam(c)
+	if err != nil {
+		return err
+	}
+
+	from, to := c.QueryParam("from"), c.QueryParam("to")
+	if c.Request().Header.Get("datastar-request") != "" {
+		var signals historySignals
+		if err := datastar.ReadSignals(c.Request(), &signals); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid comparison")
+		}
+		from, to = signals.DiffFrom, signals.DiffTo
+	}
+
+	view, err := h.historyView(c, repo, c.QueryParam("path"), from, to)
+	if err != nil {
+		return err
+	}
+
+	if c.Request().Header.Get("datastar-request") != "" {
+		return RenderWithDatastar(c, pages.HistoryContent(repo, view))
+	}
+	return Render(c, pages.History(repo, view))
+}
+
+// RestoreRevision writes a revision over the file with an optimistic hash check
+func (h *Handler) RestoreRevision(c echo.Context) error {
+	if h.Revisions == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "database unavailable")
+	}
+
+	repo, err := h.editorRepository(c)
+	if err != nil {
+		return err
+	}
+
+	var signals historySignals
+	if err := datastar.ReadSignals(c.Request(), &signals); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid restore request")
+	}
+
+	path, rev := c.QueryParam("path"), c.QueryParam("rev")
+	sse := datastar.NewSSE(c.Response().Writer, c.Request())
+
+	hash, err := h.Revisions.Restore(c.Request().Context(), repo, path, rev, signals.BaseHash)
+	if hash == "" {
+		return sse.PatchElementTempl(components.Toast(revisionErrorMessage(err), "danger"))
+	}
+	if err != nil {
+		c.Logger().Warnf("Revision restored with warning: %v", err)
+	}
+	h.publishFileEvent(c.Request().Context(), c, repo, presence.EventSaved, path)
+
+	// Show the restored file against the revision it came from
+	view, err := h.historyView(c, repo, path, rev, revision.CurrentID)
+	if err != nil {
+		return err
+	}
+	if err := sse.PatchElementTempl(layouts.PageContentWrapper(pages.HistoryContent(repo, view)),
+		datastar.WithSelectorID("page-content"),
+	); err != nil {
+		return err
+	}
+	return sse.PatchElementTempl(components.Toast("Revision restored", "success"))
+}
+
+// historyView loads a file's revisions and the comparison between from and
+// to, defaulting to the latest earlier revision against the current file.
+func (h *Handler) historyView(c echo.Context, repo db.Repository, path, from, to string) (*pages.HistoryView, error) {
+	ctx := c.Request().Context()
+
+	revisions, err := h.Revisions.History(ctx, repo, path)
+	if err != nil {
+		return nil, revisionHTTPError(c, err)
+	}
+
+	current, err := h.Revisions.Content(ctx, repo, path, revision.CurrentID)
+	if err != nil {
+		return nil, revisionHTTPError(c, err)
+	}
+
+	if to == "" {
+		to = revision.CurrentID
+	}
+	if from == "" {
+		from = to
+		if len(revisions) > 1 {
+			from = revisions[1].ID
+		}
+	}
+
+	comparison, err := h.Revisions.Compare(ctx, repo, path, from, to)
+	if err != nil {
+		return nil, revisionHTTPError(c, err)
+	}
+
+	return &pages.HistoryView{
+		Path:       path,
+		BaseHash:   content.Hash([]byte(current)),
+		Revisions:  revisions,
+		Comparison: comparison,
+	}, nil
+}
+
+// revisionErrorMessage maps revision service errors to user-facing text.
+func revisionErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, content.ErrConflict):
+		return "This file was changed since the history was loaded. Reload to get the latest version."
+	case errors.Is(err, revision.ErrUnknownRevision):
+		return "Revision not found."
+	case errors.Is(err, content.ErrInvalidPath), errors.Is(err, os.ErrNotExist):
+		return "File not found."
+	default:
+		return "Failed to restore revision."
+	}
+}
+
+// revisionHTTPError turns a failed history lookup into 404 or 500.
+func revisionHTTPError(c echo.Context, err error) error {
+	if errors.Is(err, revision.ErrUnknownRevision) || errors.Is(err, content.ErrInvalidPath) || errors.Is(err, os.ErrNotExist) {
+		return echo.NewHTTPError(http.StatusNotFound, revisionErrorMessage(err))
+	}
+	c.Logger().Errorf("Failed to load revision history: %v", err)
+	return echo.NewHTTPError(http.StatusInternalServerError, "failed to load revision history")
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gracchi-stdio/goaat/internal/auth"
+	"github.com/gracchi-stdio/goaat/internal/web/templates/components"
+	"github.com/gracchi-stdio/goaat/internal/web/templates/pages"
+	"github.com/labstack/echo/v4"
+	"github.com/starfederation/datastar-go/datastar"
+)
+
+// searchSignals mirrors the Datastar signals sent by the header search box.
+type searchSignals struct {
+	SearchQuery string `json:"searchQuery"`
+}
+
+// SearchPage runs a full-text query across the user's repositories.
+// Datastar requests from the header search box get the results dropdown
+// patched in place; regular requests (?q=) render a full results page.
+func (h *Handler) SearchPage(c echo.Context) error {
+	if h.DB == nil || h.Search == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "database unavailable")
+	}
+
+	isDatastar := c.Request().Header.Get("datastar-request") != ""
+
+	query := c.QueryParam("q")
+	if isDatastar {
+		var signals searchSignals
+		if err := datastar.ReadSignals(c.Request(), &signals); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid search request")
+		}
+		query = signals.SearchQuery
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	user := auth.GetUserFromContext(c.Request().Context())
+	results, err := h.Search.Search(ctx, user.UserID, query, 0)
+	if err != nil {
+		c.Logger().Errorf("Search failed: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "search failed")
+	}
+
+	if isDatastar {
+		sse := datastar.NewSSE(c.Response().Writer, c.Request())
+		return sse.PatchElementTempl(components.SearchResults(query, results))
+	}
+	return Render(c, pages.Search(query, results))
+}
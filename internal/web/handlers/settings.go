@@ -1,14 +1,175 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gracchi-stdio/goaat/internal/apitoken"
+	"github.com/gracchi-stdio/goaat/internal/auth"
+	"github.com/gracchi-stdio/goaat/internal/web/templates/components"
+	"github.com/gracchi-stdio/goaat/internal/web/templates/layouts"
 	"github.com/gracchi-stdio/goaat/internal/web/templates/pages"
 	"github.com/labstack/echo/v4"
+	"github.com/starfederation/datastar-go/datastar"
 )
 
+// tokenSignals mirrors the Datastar signals of the token form.
+type tokenSignals struct {
+	TokenName       string `json:"tokenName"`
+	TokenRepository string `json:"tokenRepository"`
+	TokenPermission string `json:"tokenPermission"`
+	TokenExpiry     string `json:"tokenExpiry"`
+}
+
 // SettingsPage renders the settings page
 func (h *Handler) SettingsPage(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	view, err := h.settingsView(ctx, c)
+	if err != nil {
+		c.Logger().Errorf("Failed to load settings: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to load settings")
+	}
+
 	if c.Request().Header.Get("datastar-request") != "" {
-		return RenderWithDatastar(c, pages.SettingsContent())
+		return RenderWithDatastar(c, pages.SettingsContent(view))
+	}
+	return Render(c, pages.Settings(view))
+}
+
+// CreateToken issues a personal access token and shows its secret once
+func (h *Handler) CreateToken(c echo.Context) error {
+	if h.Tokens == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "database unavailable")
+	}
+
+	var signals tokenSignals
+	if err := datastar.ReadSignals(c.Request(), &signals); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid token")
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	sse := datastar.NewSSE(c.Response().Writer, c.Request())
+
+	// A token scoped to a repository needs the user's access to it
+	var repositoryID int64
+	if signals.TokenRepository != "" {
+		id, err := strconv.ParseInt(signals.TokenRepository, 10, 64)
+		if err != nil {
+			return sse.PatchElementTempl(components.Toast("Repository not found.", "danger"))
+		}
+		repo, err := h.repositoryForUser(c, id)
+		if err != nil {
+			return sse.PatchElementTempl(components.Toast("Repository not found.", "danger"))
+		}
+		repositoryID = repo.ID
+	}
+
+	var expiresAt time.Time
+	if signals.TokenExpiry != "" {
+		day, err := time.Parse(time.DateOnly, signals.TokenExpiry)
+		if err != nil {
+			return sse.PatchElementTempl(components.Toast("Choose a valid expiry date.", "danger"))
+		}
+		// Tokens last through the chosen day
+		expiresAt = day.AddDate(0, 0, 1)
+	}
+
+	user := auth.GetUserFromContext(c.Request().Context())
+	secret, err := h.Tokens.Create(ctx, user.UserID, signals.TokenName, repositoryID, apitoken.Permission(signals.TokenPermission), expiresAt)
+	if err != nil {
+		return sse.PatchElementTempl(components.Toast(tokenErrorMessage(c, err), "danger"))
+	}
+
+	view, err := h.settingsView(ctx, c)
+	if err != nil {
+		return sse.PatchElementTempl(components.Toast(tokenErrorMessage(c, err), "danger"))
+	}
+	view.NewToken = secret
+	if err := sse.MarshalAndPatchSignals(map[string]string{"tokenName": "", "tokenExpiry": ""}); err != nil {
+		return err
+	}
+	if err := sse.PatchElementTempl(layouts.PageContentWrapper(pages.SettingsContent(view)),
+		datastar.WithSelectorID("page-content"),
+	); err != nil {
+		return err
+	}
+	return sse.PatchElementTempl(components.Toast("Token created. Copy it now; it will not be shown again.", "success"))
+}
+
+// RevokeToken stops one of the user's tokens from working
+func (h *Handler) RevokeToken(c echo.Context) error {
+	if h.Tokens == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "database unavailable")
+	}
+
+	id, err := strconv.ParseInt(c.Param("token"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "token not found")
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	sse := datastar.NewSSE(c.Response().Writer, c.Request())
+
+	user := auth.GetUserFromContext(c.Request().Context())
+	if err := h.Tokens.Revoke(ctx, user.UserID, id); err != nil {
+		return sse.PatchElementTempl(components.Toast(tokenErrorMessage(c, err), "danger"))
+	}
+
+	view, err := h.settingsView(ctx, c)
+	if err != nil {
+		return sse.PatchElementTempl(components.Toast(tokenErrorMessage(c, err), "danger"))
+	}
+	if err := sse.PatchElementTempl(layouts.PageContentWrapper(pages.SettingsContent(view)),
+		datastar.WithSelectorID("page-content"),
+	); err != nil {
+		return err
+	}
+	return sse.PatchElementTempl(components.Toast("Token revoked", "success"))
+}
+
+// settingsView loads the user's tokens and the repositories they can scope
+// new ones to.
+func (h *Handler) settingsView(ctx context.Context, c echo.Context) (*pages.SettingsView, error) {
+	view := &pages.SettingsView{}
+	if h.Tokens == nil || h.DB == nil {
+		return view, nil
+	}
+
+	user := auth.GetUserFromContext(c.Request().Context())
+	var err error
+	if view.Tokens, err = h.Tokens.List(ctx, user.UserID); err != nil {
+		return nil, err
+	}
+	if view.Repositories, err = h.DB.ListRepositoriesForUser(ctx, user.UserID); err != nil {
+		return nil, err
+	}
+	view.TokensEnabled = true
+	return view, nil
+}
+
+// tokenErrorMessage maps token service errors to user-facing text, logging
+// the ones that are not the user's to fix.
+func tokenErrorMessage(c echo.Context, err error) string {
+	switch {
+	case errors.Is(err, apitoken.ErrNoName):
+		return "Give the token a name."
+	case errors.Is(err, apitoken.ErrInvalidPermission):
+		return "Choose a permission."
+	case errors.Is(err, apitoken.ErrPastExpiry):
+		return "Choose an expiry date in the future."
+	case errors.Is(err, apitoken.ErrUnknownToken):
+		return "Token not found."
+	default:
+		c.Logger().Errorf("Failed to update tokens: %v", err)
+		return "Failed to update tokens."
 	}
-	return Render(c, pages.Settings())
 }
@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gracchi-stdio/goaat/internal/auth"
+	"github.com/gracchi-stdio/goaat/internal/content"
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+	"github.com/gracchi-stdio/goaat/internal/presence"
+	"github.com/gracchi-stdio/goaat/internal/translation"
+	"github.com/gracchi-stdio/goaat/internal/web/templates/components"
+	"github.com/gracchi-stdio/goaat/internal/web/templates/pages"
+	"github.com/labstack/echo/v4"
+	"github.com/starfederation/datastar-go/datastar"
+)
+
+// translationSignals mirrors the Datastar signals of the translation editor.
+type translationSignals struct {
+	TranslationBody string `json:"translationBody"`
+	BaseHash        string `json:"baseHash"`
+}
+
+// draftStatusSignals reports autosave progress in the translation editor.
+type draftStatusSignals struct {
+	DraftStatus string `json:"draftStatus"`
+}
+
+// TranslationsPage renders the pages × locales translation matrix
+func (h *Handler) TranslationsPage(c echo.Context) error {
+	repo, err := h.repositoryFromParam(c)
+	if err != nil {
+		return err
+	}
+
+	matrix, err := h.Translations.Matrix(c.Request().Context(), repo)
+	if err != nil {
+		c.Logger().Errorf("Failed to build translation matrix for %s: %v", repo.FullName, err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to load translations")
+	}
+
+	if c.Request().Header.Get("datastar-request") != "" {
+		return RenderWithDatastar(c, pages.TranslationsContent(repo, matrix))
+	}
+	return Render(c, pages.Translations(repo, matrix))
+}
+
+// CreateTranslationStub copies a source page into a locale and opens the editor
+func (h *Handler) CreateTranslationStub(c echo.Context) error {
+	repo, err := h.editorRepository(c)
+	if err != nil {
+		return err
+	}
+
+	sourcePath, locale := c.QueryParam("path"), c.QueryParam("locale")
+	sse := datastar.NewSSE(c.Response().Writer, c.Request())
+
+	if _, err := h.Translations.CreateStub(c.Request().Context(), repo, sourcePath, locale); err != nil {
+		return sse.PatchElementTempl(components.Toast(translationErrorMessage(err), "danger"))
+	}
+
+	return sse.Redirect(pages.TranslationEditorURL(repo.ID, sourcePath, locale))
+}
+
+// TranslationEditorPage renders the side-by-side source/translation editor
+func (h *Handler) TranslationEditorPage(c echo.Context) error {
+	repo, err := h.repositoryFromParam(c)
+	if err != nil {
+		return err
+	}
+
+	pair, err := h.Translations.Pair(c.Request().Context(), repo, c.QueryParam("path"), c.QueryParam("locale"))
+	if err != nil {
+		return translationHTTPError(c, err)
+	}
+
+	// Reopening a file restores the user's unsaved draft of this version
+	var draft *db.Draft
+	if h.Revisions != nil && pair.Translation.Exists {
+		user := auth.GetUserFromContext(c.Request().Context())
+		draft, err = h.Revisions.Draft(c.Request().Context(), user.UserID, repo, pair.Translation.Path, pair.Translation.Hash)
+		if err != nil {
+			c.Logger().Errorf("Failed to load draft: %v", err)
+		}
+		if draft != nil && draft.Body == pair.Translation.Body {
+			draft = nil
+		}
+	}
+
+	authors := h.bylineAuthors(c, repo)
+	if c.Request().Header.Get("datastar-request") != "" {
+		return RenderWithDatastar(c, pages.TranslationEditorContent(repo, pair, draft, authors))
+	}
+	return Render(c, pages.TranslationEditor(repo, pair, draft, authors))
+}
+
+// SaveTranslation writes the edited translation with an optimistic hash check
+func (h *Handler) SaveTranslation(c echo.Context) error {
+	repo, err := h.editorRepository(c)
+	if err != nil {
+		return err
+	}
+
+	var signals translationSignals
+	if err := datastar.ReadSignals(c.Request(), &signals); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid translation")
+	}
+
+	pair, err := h.Translations.Pair(c.Request().Context(), repo, c.QueryParam("path"), c.QueryParam("locale"))
+	if err != nil {
+		return translationHTTPError(c, err)
+	}
+
+	sse := datastar.NewSSE(c.Response().Writer, c.Request())
+
+	if problem := h.authorReferenceProblem(c, repo, []byte(signals.TranslationBody)); problem != "" {
+		return sse.PatchElementTempl(components.Toast(problem, "danger"))
+	}
+
+	hash, err := h.Files.Write(c.Request().Context(), repo, pair.Translation.Path, []byte(signals.TranslationBody), signals.BaseHash)
+	if hash == "" {
+		return sse.PatchElementTempl(components.Toast(translationErrorMessage(err), "danger"))
+	}
+	if err != nil {
+		c.Logger().Warnf("Translation saved with warning: %v", err)
+	}
+	h.publishFileEvent(c.Request().Context(), c, repo, presence.EventSaved, pair.Translation.Path)
+
+	if err := sse.MarshalAndPatchSignals(translationSignals{TranslationBody: signals.TranslationBody, BaseHash: hash}); err != nil {
+		return err
+	}
+	return sse.PatchElementTempl(components.Toast("Translation saved", "success"))
+}
+
+// AutosaveTranslation stores the in-progress translation as a draft
+func (h *Handler) AutosaveTranslation(c echo.Context) error {
+	if h.Revisions == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "database unavailable")
+	}
+
+	repo, err := h.editorRepository(c)
+	if err != nil {
+		return err
+	}
+
+	var signals translationSignals
+	if err := datastar.ReadSignals(c.Request(), &signals); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid translation")
+	}
+
+	pair, err := h.Translations.Pair(c.Request().Context(), repo, c.QueryParam("path"), c.QueryParam("locale"))
+	if err != nil {
+		return translationHTTPError(c, err)
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	user := auth.GetUserFromContext(c.Request().Context())
+	status := "Draft saved"
+	draft, err := h.Revisions.SaveDraft(ctx, user.UserID, repo, pair.Translation.Path, signals.BaseHash, signals.TranslationBody)
+	if err != nil {
+		c.Logger().Errorf("Failed to autosave draft: %v", err)
+		status = "Autosave failed"
+	} else {
+		status += " at " + draft.UpdatedAt.Time.Format("15:04:05")
+	}
+
+	sse := datastar.NewSSE(c.Response().Writer, c.Request())
+	return sse.MarshalAndPatchSignals(draftStatusSignals{DraftStatus: status})
+}
+
+// DiscardTranslationDraft deletes the user's draft and reopens the saved translation
+func (h *Handler) DiscardTranslationDraft(c echo.Context) error {
+	if h.Revisions == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "database unavailable")
+	}
+
+	repo, err := h.editorRepository(c)
+	if err != nil {
+		return err
+	}
+
+	sourcePath, locale := c.QueryParam("path"), c.QueryParam("locale")
+	pair, err := h.Translations.Pair(c.Request().Context(), repo, sourcePath, locale)
+	if err != nil {
+		return translationHTTPError(c, err)
+	}
+
+	user := auth.GetUserFromContext(c.Request().Context())
+	sse := datastar.NewSSE(c.Response().Writer, c.Request())
+
+	if err := h.Revisions.DiscardDraft(c.Request().Context(), user.UserID, repo, pair.Translation.Path, pair.Translation.Hash); err != nil {
+		c.Logger().Errorf("Failed to discard draft: %v", err)
+		return sse.PatchElementTempl(components.Toast("Failed to discard draft.", "danger"))
+	}
+
+	return sse.Redirect(pages.TranslationEditorURL(repo.ID, sourcePath, locale))
+}
+
+// translationErrorMessage maps translation service errors to user-facing text.
+func translationErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, content.ErrConflict):
+		return "This translation was changed by someone else. Reload to get the latest version."
+	case errors.Is(err, translation.ErrTranslationExists):
+		return "A translation already exists for this page."
+	case errors.Is(err, translation.ErrUnknownLocale):
+		return "This locale is not configured for the repository."
+	case errors.Is(err, content.ErrInvalidPath), errors.Is(err, os.ErrNotExist):
+		return "Source page not found."
+	default:
+		return "Failed to save translation."
+	}
+}
+
+// translationHTTPError turns a failed page lookup into 404 or 500.
+func translationHTTPError(c echo.Context, err error) error {
+	if errors.Is(err, translation.ErrUnknownLocale) || errors.Is(err, content.ErrInvalidPath) || errors.Is(err, os.ErrNotExist) {
+		return echo.NewHTTPError(http.StatusNotFound, translationErrorMessage(err))
+	}
+	c.Logger().Errorf("Failed to load translation: %v", err)
+	return echo.NewHTTPError(http.StatusInternalServerError, "failed to load translation")
+}
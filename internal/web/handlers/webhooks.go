@@ -0,0 +1,259 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gracchi-stdio/goaat/internal/auth"
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+	"github.com/gracchi-stdio/goaat/internal/web/templates/components"
+	"github.com/gracchi-stdio/goaat/internal/web/templates/layouts"
+	"github.com/gracchi-stdio/goaat/internal/web/templates/pages"
+	"github.com/gracchi-stdio/goaat/internal/webhook"
+	"github.com/gracchi-stdio/goaat/internal/workflow"
+	"github.com/labstack/echo/v4"
+	"github.com/starfederation/datastar-go/datastar"
+)
+
+// webhookSignals mirrors the Datastar signals of the webhook form.
+type webhookSignals struct {
+	WebhookURL    string   `json:"webhookUrl"`
+	WebhookSecret string   `json:"webhookSecret"`
+	WebhookEvents []string `json:"webhookEvents"`
+}
+
+// WebhooksPage lists a repository's webhooks with a form to add one
+func (h *Handler) WebhooksPage(c echo.Context) error {
+	repo, actor, err := h.webhookActor(c)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	endpoints, err := h.Webhooks.Endpoints(ctx, actor, repo)
+	if err != nil {
+		return webhookHTTPError(c, err)
+	}
+
+	view := &pages.WebhooksView{Endpoints: endpoints}
+	if c.Request().Header.Get("datastar-request") != "" {
+		return RenderWithDatastar(c, pages.WebhooksContent(repo, view))
+	}
+	return Render(c, pages.Webhooks(repo, view))
+}
+
+// CreateWebhook registers a webhook and shows its secret once
+func (h *Handler) CreateWebhook(c echo.Context) error {
+	repo, actor, err := h.webhookActor(c)
+	if err != nil {
+		return err
+	}
+
+	var signals webhookSignals
+	if err := datastar.ReadSignals(c.Request(), &signals); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid webhook")
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	sse := datastar.NewSSE(c.Response().Writer, c.Request())
+
+	events := make([]webhook.Event, 0, len(signals.WebhookEvents))
+	for _, e := range signals.WebhookEvents {
+		events = append(events, webhook.Event(e))
+	}
+	endpoint, err := h.Webhooks.Create(ctx, actor, repo, signals.WebhookURL, signals.WebhookSecret, events)
+	if err != nil {
+		return sse.PatchElementTempl(components.Toast(webhookErrorMessage(c, err), "danger"))
+	}
+
+	if err := sse.MarshalAndPatchSignals(map[string]any{"webhookUrl": "", "webhookSecret": "", "webhookEvents": []string{}}); err != nil {
+		return err
+	}
+	return h.patchWebhooks(ctx, c, sse, repo, actor, endpoint.Secret, "Webhook added")
+}
+
+// DeleteWebhook removes a webhook with its delivery log
+func (h *Handler) DeleteWebhook(c echo.Context) error {
+	repo, actor, err := h.webhookActor(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.ParseInt(c.Param("webhook"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "webhook not found")
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	sse := datastar.NewSSE(c.Response().Writer, c.Request())
+
+	if err := h.Webhooks.Delete(ctx, actor, repo, id); err != nil {
+		return sse.PatchElementTempl(components.Toast(webhookErrorMessage(c, err), "danger"))
+	}
+	return h.patchWebhooks(ctx, c, sse, repo, actor, "", "Webhook deleted")
+}
+
+// WebhookPage shows a webhook's recent deliveries
+func (h *Handler) WebhookPage(c echo.Context) error {
+	repo, actor, err := h.webhookActor(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.ParseInt(c.Param("webhook"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "webhook not found")
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	view, err := h.webhookView(ctx, repo, actor, id)
+	if err != nil {
+		return webhookHTTPError(c, err)
+	}
+
+	if c.Request().Header.Get("datastar-request") != "" {
+		return RenderWithDatastar(c, pages.WebhookContent(repo, view))
+	}
+	return Render(c, pages.Webhook(repo, view))
+}
+
+// RedeliverWebhook queues a delivery's payload to be sent again
+func (h *Handler) RedeliverWebhook(c echo.Context) error {
+	repo, actor, err := h.webhookActor(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.ParseInt(c.Param("delivery"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "delivery not found")
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	sse := datastar.NewSSE(c.Response().Writer, c.Request())
+
+	delivery, err := h.Webhooks.Redeliver(ctx, actor, repo, id)
+	if err != nil {
+		return sse.PatchElementTempl(components.Toast(webhookErrorMessage(c, err), "danger"))
+	}
+
+	view, err := h.webhookView(ctx, repo, actor, delivery.EndpointID)
+	if err != nil {
+		return sse.PatchElementTempl(components.Toast(webhookErrorMessage(c, err), "danger"))
+	}
+	if err := sse.PatchElementTempl(layouts.PageContentWrapper(pages.WebhookContent(repo, view)),
+		datastar.WithSelectorID("page-content"),
+	); err != nil {
+		return err
+	}
+	return sse.PatchElementTempl(components.Toast("Redelivery queued", "success"))
+}
+
+// webhookActor resolves the repository and the current user's role in it.
+func (h *Handler) webhookActor(c echo.Context) (db.Repository, workflow.Actor, error) {
+	if h.Webhooks == nil {
+		return db.Repository{}, workflow.Actor{}, echo.NewHTTPError(http.StatusServiceUnavailable, "database unavailable")
+	}
+	return h.workflowActor(c)
+}
+
+// webhookView loads a webhook with its delivery log.
+func (h *Handler) webhookView(ctx context.Context, repo db.Repository, actor workflow.Actor, id int64) (*pages.WebhookView, error) {
+	endpoint, err := h.Webhooks.Endpoint(ctx, actor, repo, id)
+	if err != nil {
+		return nil, err
+	}
+	deliveries, err := h.Webhooks.Deliveries(ctx, actor, repo, id)
+	if err != nil {
+		return nil, err
+	}
+	return &pages.WebhookView{Endpoint: endpoint, Deliveries: deliveries}, nil
+}
+
+// patchWebhooks re-renders the webhooks page after a change, showing a new
+// webhook's secret if there is one, and confirms it with a toast.
+func (h *Handler) patchWebhooks(ctx context.Context, c echo.Context, sse *datastar.ServerSentEventGenerator, repo db.Repository, actor workflow.Actor, secret, message string) error {
+	endpoints, err := h.Webhooks.Endpoints(ctx, actor, repo)
+	if err != nil {
+		return sse.PatchElementTempl(components.Toast(webhookErrorMessage(c, err), "danger"))
+	}
+	view := &pages.WebhooksView{Endpoints: endpoints, NewSecret: secret}
+	if err := sse.PatchElementTempl(layouts.PageContentWrapper(pages.WebhooksContent(repo, view)),
+		datastar.WithSelectorID("page-content"),
+	); err != nil {
+		return err
+	}
+	return sse.PatchElementTempl(components.Toast(message, "success"))
+}
+
+// emitWebhook queues an event for the repository's webhooks. Failures are
+// logged, not returned: webhooks never block the action itself.
+func (h *Handler) emitWebhook(ctx context.Context, c echo.Context, repo db.Repository, event webhook.Event, data any) {
+	if h.Webhooks == nil {
+		return
+	}
+	if err := h.Webhooks.Emit(ctx, repo, event, data); err != nil {
+		c.Logger().Errorf("Failed to queue %s webhooks: %v", event, err)
+	}
+}
+
+// emitPublished queues changeset.published when an action published a change set.
+func (h *Handler) emitPublished(ctx context.Context, c echo.Context, repo db.Repository, actor workflow.Actor, cs *workflow.ChangeSet) {
+	if h.Webhooks == nil || cs.State != workflow.StatePublished {
+		return
+	}
+	if err := h.Webhooks.Published(ctx, actor, repo, cs); err != nil {
+		c.Logger().Errorf("Failed to queue %s webhooks: %v", webhook.EventChangeSetPublished, err)
+	}
+}
+
+// webhookSender is the current user, as named in webhook payloads.
+func webhookSender(c echo.Context) webhook.Sender {
+	user := auth.GetUserFromContext(c.Request().Context())
+	return webhook.Sender{ID: user.UserID, Name: user.Name}
+}
+
+// webhookErrorMessage maps webhook service errors to user-facing text,
+// logging the ones that are not the user's to fix.
+func webhookErrorMessage(c echo.Context, err error) string {
+	switch {
+	case errors.Is(err, workflow.ErrForbidden):
+		return "Only the repository owner manages webhooks."
+	case errors.Is(err, webhook.ErrInvalidURL):
+		return "Enter an http:// or https:// payload URL."
+	case errors.Is(err, webhook.ErrNoEvents):
+		return "Select at least one event."
+	case errors.Is(err, webhook.ErrUnknownEvent):
+		return "Select only events from the list."
+	case errors.Is(err, webhook.ErrUnknownEndpoint):
+		return "Webhook not found."
+	case errors.Is(err, webhook.ErrUnknownDelivery):
+		return "Delivery not found."
+	default:
+		c.Logger().Errorf("Failed to update webhooks: %v", err)
+		return "Failed to update webhooks."
+	}
+}
+
+// webhookHTTPError turns a failed webhook lookup into 404 or 500; the
+// webhooks of repositories the user does not own are not found.
+func webhookHTTPError(c echo.Context, err error) error {
+	if errors.Is(err, webhook.ErrUnknownEndpoint) || errors.Is(err, workflow.ErrForbidden) {
+		return echo.NewHTTPError(http.StatusNotFound, "webhook not found")
+	}
+	c.Logger().Errorf("Failed to load webhooks: %v", err)
+	return echo.NewHTTPError(http.StatusInternalServerError, "failed to load webhooks")
+}
@@ -0,0 +1,473 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/gracchi-stdio/goaat/internal/auth"
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+	"github.com/gracchi-stdio/goaat/internal/repository"
+	"github.com/gracchi-stdio/goaat/internal/web/templates/components"
+	"github.com/gracchi-stdio/goaat/internal/web/templates/layouts"
+	"github.com/gracchi-stdio/goaat/internal/web/templates/pages"
+	"github.com/gracchi-stdio/goaat/internal/webhook"
+	"github.com/gracchi-stdio/goaat/internal/workflow"
+	"github.com/labstack/echo/v4"
+	"github.com/starfederation/datastar-go/datastar"
+)
+
+// workflowSignals mirrors the Datastar signals of the workflow pages.
+type workflowSignals struct {
+	RequiredApprovals    string   `json:"requiredApprovals"`
+	ApproverRole         string   `json:"approverRole"`
+	MemberEmail          string   `json:"memberEmail"`
+	MemberRole           string   `json:"memberRole"`
+	ChangeSetTitle       string   `json:"changeSetTitle"`
+	ChangeSetDescription string   `json:"changeSetDescription"`
+	ChangeSetFiles       []string `json:"changeSetFiles"`
+	TransitionNote       string   `json:"transitionNote"`
+	ScheduleAt           string   `json:"scheduleAt"`
+	ScheduleTimezone     string   `json:"scheduleTimezone"`
+}
+
+// WorkflowPage shows a repository's pending files, change sets, members and approval settings
+func (h *Handler) WorkflowPage(c echo.Context) error {
+	repo, actor, err := h.workflowActor(c)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	view, err := h.workflowView(ctx, repo, actor)
+	if err != nil {
+		c.Logger().Errorf("Failed to load workflow: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to load workflow")
+	}
+
+	if c.Request().Header.Get("datastar-request") != "" {
+		return RenderWithDatastar(c, pages.WorkflowContent(repo, view))
+	}
+	return Render(c, pages.Workflow(repo, view))
+}
+
+// UpdateWorkflowSettings changes the number and role of required approvers
+func (h *Handler) UpdateWorkflowSettings(c echo.Context) error {
+	repo, actor, err := h.workflowActor(c)
+	if err != nil {
+		return err
+	}
+
+	var signals workflowSignals
+	if err := datastar.ReadSignals(c.Request(), &signals); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid settings")
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	sse := datastar.NewSSE(c.Response().Writer, c.Request())
+
+	required, err := strconv.Atoi(signals.RequiredApprovals)
+	if err != nil {
+		return sse.PatchElementTempl(components.Toast(workflowErrorMessage(c, workflow.ErrInvalidSettings), "danger"))
+	}
+	settings := workflow.Settings{RequiredApprovals: required, ApproverRole: workflow.Role(signals.ApproverRole)}
+	if err := h.Workflow.UpdateSettings(ctx, actor, repo, settings); err != nil {
+		return sse.PatchElementTempl(components.Toast(workflowErrorMessage(c, err), "danger"))
+	}
+	return h.patchWorkflow(ctx, c, sse, repo, actor, "Workflow settings saved")
+}
+
+// AddRepositoryMember gives a signed-up user a role in the repository
+func (h *Handler) AddRepositoryMember(c echo.Context) error {
+	repo, actor, err := h.workflowActor(c)
+	if err != nil {
+		return err
+	}
+
+	var signals workflowSignals
+	if err := datastar.ReadSignals(c.Request(), &signals); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid member")
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	sse := datastar.NewSSE(c.Response().Writer, c.Request())
+
+	if err := h.Workflow.AddMember(ctx, actor, repo, signals.MemberEmail, workflow.Role(signals.MemberRole)); err != nil {
+		return sse.PatchElementTempl(components.Toast(workflowErrorMessage(c, err), "danger"))
+	}
+	h.emitWebhook(ctx, c, repo, webhook.EventMemberAdded, webhook.MemberAdded{
+		Email:  strings.TrimSpace(signals.MemberEmail),
+		Role:   signals.MemberRole,
+		Sender: webhookSender(c),
+	})
+	if err := sse.MarshalAndPatchSignals(map[string]string{"memberEmail": ""}); err != nil {
+		return err
+	}
+	return h.patchWorkflow(ctx, c, sse, repo, actor, "Member added")
+}
+
+// RemoveRepositoryMember takes away a member's role
+func (h *Handler) RemoveRepositoryMember(c echo.Context) error {
+	repo, actor, err := h.workflowActor(c)
+	if err != nil {
+		return err
+	}
+
+	userID, err := strconv.ParseInt(c.Param("user"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "member not found")
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	sse := datastar.NewSSE(c.Response().Writer, c.Request())
+
+	if err := h.Workflow.RemoveMember(ctx, actor, repo, userID); err != nil {
+		return sse.PatchElementTempl(components.Toast(workflowErrorMessage(c, err), "danger"))
+	}
+	return h.patchWorkflow(ctx, c, sse, repo, actor, "Member removed")
+}
+
+// CreateChangeSet groups the selected pending files into a draft change set
+func (h *Handler) CreateChangeSet(c echo.Context) error {
+	repo, actor, err := h.workflowActor(c)
+	if err != nil {
+		return err
+	}
+
+	var signals workflowSignals
+	if err := datastar.ReadSignals(c.Request(), &signals); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid change set")
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	sse := datastar.NewSSE(c.Response().Writer, c.Request())
+
+	id, err := h.Workflow.Create(ctx, actor, repo, signals.ChangeSetTitle, signals.ChangeSetDescription, signals.ChangeSetFiles)
+	if err != nil {
+		return sse.PatchElementTempl(components.Toast(workflowErrorMessage(c, err), "danger"))
+	}
+	return sse.Redirect(pages.ChangeSetURL(repo.ID, id))
+}
+
+// ChangeSetPage shows a change set with its files, approvals and history
+func (h *Handler) ChangeSetPage(c echo.Context) error {
+	repo, actor, err := h.workflowActor(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.ParseInt(c.Param("changeset"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "change set not found")
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	cs, err := h.Workflow.ChangeSet(ctx, repo, id)
+	if err != nil {
+		return workflowHTTPError(c, err)
+	}
+	view, err := h.changeSetView(ctx, repo, actor, cs)
+	if err != nil {
+		return workflowHTTPError(c, err)
+	}
+
+	if c.Request().Header.Get("datastar-request") != "" {
+		return RenderWithDatastar(c, pages.ChangeSetContent(repo, view))
+	}
+	return Render(c, pages.ChangeSet(repo, view))
+}
+
+// TransitionChangeSet takes a workflow action (submit, approve, publish, ...) on a change set
+func (h *Handler) TransitionChangeSet(c echo.Context) error {
+	repo, actor, err := h.workflowActor(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.ParseInt(c.Param("changeset"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "change set not found")
+	}
+	action, ok := workflow.ParseAction(c.Param("action"))
+	if !ok {
+		return echo.NewHTTPError(http.StatusNotFound, "unknown action")
+	}
+
+	var signals workflowSignals
+	if err := datastar.ReadSignals(c.Request(), &signals); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid action")
+	}
+
+	// Publishing runs git, so allow longer than a query
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 30*time.Second)
+	defer cancel()
+
+	sse := datastar.NewSSE(c.Response().Writer, c.Request())
+
+	cs, err := h.Workflow.Transition(ctx, actor, repo, id, action, signals.TransitionNote)
+	if err != nil {
+		return sse.PatchElementTempl(components.Toast(workflowErrorMessage(c, err), "danger"))
+	}
+	h.emitPublished(ctx, c, repo, actor, cs)
+	view, err := h.changeSetView(ctx, repo, actor, cs)
+	if err != nil {
+		return sse.PatchElementTempl(components.Toast(workflowErrorMessage(c, err), "danger"))
+	}
+
+	if err := sse.MarshalAndPatchSignals(map[string]string{"transitionNote": ""}); err != nil {
+		return err
+	}
+	if err := sse.PatchElementTempl(layouts.PageContentWrapper(pages.ChangeSetContent(repo, view)),
+		datastar.WithSelectorID("page-content"),
+	); err != nil {
+		return err
+	}
+	return sse.PatchElementTempl(components.Toast("Change set is now "+cs.State.Label(), "success"))
+}
+
+// ScheduleChangeSet sets a change set to publish at a future time, or moves
+// its existing schedule
+func (h *Handler) ScheduleChangeSet(c echo.Context) error {
+	repo, actor, err := h.workflowActor(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.ParseInt(c.Param("changeset"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "change set not found")
+	}
+
+	var signals workflowSignals
+	if err := datastar.ReadSignals(c.Request(), &signals); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid schedule")
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	sse := datastar.NewSSE(c.Response().Writer, c.Request())
+
+	at, err := workflow.ParseScheduleTime(signals.ScheduleAt, signals.ScheduleTimezone)
+	if err == nil {
+		err = h.Workflow.Schedule(ctx, actor, repo, id, at)
+	}
+	if err != nil {
+		return sse.PatchElementTempl(components.Toast(workflowErrorMessage(c, err), "danger"))
+	}
+	return h.patchSchedule(ctx, c, sse, repo, actor, id, "Publish scheduled for "+at.Format(pages.ScheduleTimeFormat))
+}
+
+// UnscheduleChangeSet cancels a change set's scheduled publish
+func (h *Handler) UnscheduleChangeSet(c echo.Context) error {
+	repo, actor, err := h.workflowActor(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.ParseInt(c.Param("changeset"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "change set not found")
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	sse := datastar.NewSSE(c.Response().Writer, c.Request())
+
+	if err := h.Workflow.Unschedule(ctx, actor, repo, id); err != nil {
+		return sse.PatchElementTempl(components.Toast(workflowErrorMessage(c, err), "danger"))
+	}
+	return h.patchSchedule(ctx, c, sse, repo, actor, id, "Scheduled publish cancelled")
+}
+
+// ReviewQueuePage lists the change sets waiting for the current user's approval
+func (h *Handler) ReviewQueuePage(c echo.Context) error {
+	if h.Workflow == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "database unavailable")
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	user := auth.GetUserFromContext(c.Request().Context())
+	queue, err := h.Workflow.Queue(ctx, user.UserID)
+	if err != nil {
+		c.Logger().Errorf("Failed to load review queue: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to load review queue")
+	}
+
+	if c.Request().Header.Get("datastar-request") != "" {
+		return RenderWithDatastar(c, pages.ReviewQueueContent(queue))
+	}
+	return Render(c, pages.ReviewQueue(queue))
+}
+
+// workflowActor resolves the repository and the current user's role in it.
+func (h *Handler) workflowActor(c echo.Context) (db.Repository, workflow.Actor, error) {
+	if h.Workflow == nil {
+		return db.Repository{}, workflow.Actor{}, echo.NewHTTPError(http.StatusServiceUnavailable, "database unavailable")
+	}
+
+	repo, err := h.repositoryFromParam(c)
+	if err != nil {
+		return db.Repository{}, workflow.Actor{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	defer cancel()
+
+	user := auth.GetUserFromContext(c.Request().Context())
+	actor, err := h.Workflow.Actor(ctx, repo, user.UserID)
+	if err != nil {
+		return db.Repository{}, workflow.Actor{}, workflowHTTPError(c, err)
+	}
+	return repo, actor, nil
+}
+
+// workflowView loads everything the workflow page shows.
+func (h *Handler) workflowView(ctx context.Context, repo db.Repository, actor workflow.Actor) (*pages.WorkflowView, error) {
+	view := &pages.WorkflowView{Actor: actor}
+	var err error
+	if view.Settings, err = h.Workflow.Settings(ctx, repo); err != nil {
+		return nil, err
+	}
+	if view.Members, err = h.Workflow.Members(ctx, repo); err != nil {
+		return nil, err
+	}
+	if view.Pending, err = h.Workflow.PendingFiles(ctx, repo); err != nil {
+		return nil, err
+	}
+	if view.ChangeSets, err = h.Workflow.ChangeSets(ctx, repo); err != nil {
+		return nil, err
+	}
+	return view, nil
+}
+
+// changeSetView pairs a change set with the actions the actor may take on it.
+func (h *Handler) changeSetView(ctx context.Context, repo db.Repository, actor workflow.Actor, cs *workflow.ChangeSet) (*pages.ChangeSetView, error) {
+	settings, err := h.Workflow.Settings(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	schedule, err := h.Workflow.ScheduledPublish(ctx, repo, cs.ID)
+	if err != nil {
+		return nil, err
+	}
+	view := &pages.ChangeSetView{
+		ChangeSet:   cs,
+		Settings:    settings,
+		Actions:     workflow.Allowed(actor, cs, settings),
+		Schedule:    schedule,
+		CanSchedule: workflow.AuthorizeSchedule(actor, cs) == nil,
+	}
+	if h.Previews != nil {
+		if view.Preview, err = h.previewView(actor, cs.ID); err != nil {
+			return nil, err
+		}
+	}
+	return view, nil
+}
+
+// patchWorkflow re-renders the workflow page after a change and confirms it with a toast.
+func (h *Handler) patchWorkflow(ctx context.Context, c echo.Context, sse *datastar.ServerSentEventGenerator, repo db.Repository, actor workflow.Actor, message string) error {
+	view, err := h.workflowView(ctx, repo, actor)
+	if err != nil {
+		return sse.PatchElementTempl(components.Toast(workflowErrorMessage(c, err), "danger"))
+	}
+	if err := sse.PatchElementTempl(layouts.PageContentWrapper(pages.WorkflowContent(repo, view)),
+		datastar.WithSelectorID("page-content"),
+	); err != nil {
+		return err
+	}
+	return sse.PatchElementTempl(components.Toast(message, "success"))
+}
+
+// patchSchedule re-renders the page a schedule was changed from, either the
+// dashboard or the change set, and confirms the change with a toast.
+func (h *Handler) patchSchedule(ctx context.Context, c echo.Context, sse *datastar.ServerSentEventGenerator, repo db.Repository, actor workflow.Actor, id int64, message string) error {
+	var content templ.Component
+	if c.QueryParam("view") == "dashboard" {
+		view, err := h.dashboardView(ctx, actor.UserID)
+		if err != nil {
+			return sse.PatchElementTempl(components.Toast(workflowErrorMessage(c, err), "danger"))
+		}
+		content = pages.DashboardContent(view)
+	} else {
+		cs, err := h.Workflow.ChangeSet(ctx, repo, id)
+		if err != nil {
+			return sse.PatchElementTempl(components.Toast(workflowErrorMessage(c, err), "danger"))
+		}
+		view, err := h.changeSetView(ctx, repo, actor, cs)
+		if err != nil {
+			return sse.PatchElementTempl(components.Toast(workflowErrorMessage(c, err), "danger"))
+		}
+		content = pages.ChangeSetContent(repo, view)
+	}
+
+	if err := sse.PatchElementTempl(layouts.PageContentWrapper(content),
+		datastar.WithSelectorID("page-content"),
+	); err != nil {
+		return err
+	}
+	return sse.PatchElementTempl(components.Toast(message, "success"))
+}
+
+// workflowErrorMessage maps workflow service errors to user-facing text,
+// logging the ones that are not the user's to fix.
+func workflowErrorMessage(c echo.Context, err error) string {
+	switch {
+	case errors.Is(err, workflow.ErrForbidden):
+		return "Your role does not allow this."
+	case errors.Is(err, workflow.ErrInvalidTransition):
+		return "This action is not available in the change set's current state."
+	case errors.Is(err, workflow.ErrStale):
+		return "Someone else updated this change set. Reload to see its current state."
+	case errors.Is(err, workflow.ErrUnknownChangeSet):
+		return "Change set not found."
+	case errors.Is(err, workflow.ErrUnknownUser):
+		return "No user with that email has signed in yet."
+	case errors.Is(err, workflow.ErrInvalidSettings):
+		return "Choose a role and a number of approvals of 0 or more."
+	case errors.Is(err, workflow.ErrNoTitle):
+		return "Give the change set a title."
+	case errors.Is(err, workflow.ErrNoFiles):
+		return "Select at least one changed file."
+	case errors.Is(err, workflow.ErrFileClaimed):
+		return "A selected file is already part of another change set."
+	case errors.Is(err, workflow.ErrPastSchedule):
+		return "Choose a publish time in the future."
+	case errors.Is(err, workflow.ErrInvalidSchedule):
+		return "Choose a publish date, time and timezone."
+	case errors.Is(err, repository.ErrNothingToCommit):
+		return "The change set's files have no changes left to publish."
+	default:
+		c.Logger().Errorf("Failed to update workflow: %v", err)
+		return "Failed to update the workflow."
+	}
+}
+
+// workflowHTTPError turns a failed workflow lookup into 404 or 500.
+func workflowHTTPError(c echo.Context, err error) error {
+	if errors.Is(err, workflow.ErrUnknownChangeSet) || errors.Is(err, workflow.ErrForbidden) {
+		return echo.NewHTTPError(http.StatusNotFound, "change set not found")
+	}
+	c.Logger().Errorf("Failed to load workflow: %v", err)
+	return echo.NewHTTPError(http.StatusInternalServerError, "failed to load workflow")
+}
@@ -0,0 +1,137 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/gracchi-stdio/goaat/internal/apitoken"
+	"github.com/gracchi-stdio/goaat/internal/middleware"
+	"github.com/gracchi-stdio/goaat/internal/openapi"
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+	"github.com/gracchi-stdio/goaat/internal/web/handlers"
+	"github.com/gracchi-stdio/goaat/internal/workflow"
+)
+
+// apiPrefix is where the routes the specification must describe live.
+const apiPrefix = "/api/"
+
+// APISpec describes every route under /api. RegisterRoutes refuses to
+// register an API route it does not describe, so adding a route means
+// adding its description here.
+func APISpec() *openapi.Spec {
+	repositoryID := openapi.Param{Name: "id", In: "path", Type: "integer", Description: "Repository id"}
+	changeSetID := openapi.Param{Name: "changeset", In: "path", Type: "integer", Description: "Change set id"}
+	filePath := openapi.Param{Name: "path", In: "query", Required: true, Description: "File path, relative to the repository's content directory"}
+
+	var actions []string
+	for _, a := range workflow.Actions() {
+		actions = append(actions, string(a))
+	}
+
+	return openapi.New(openapi.Options{
+		Info: openapi.Info{
+			Title:       "Goaat API",
+			Version:     "1",
+			Description: "Read and write a repository's content files and take change sets through review. Requests to /api/v1 and /api/authors authenticate with a personal access token, sent as a bearer token; errors are problem details (RFC 9457).",
+		},
+		Problem: middleware.Problem{},
+	},
+		openapi.Route{
+			Method: http.MethodGet, Path: "/api/openapi.json", ID: "getOpenAPI", Tag: "meta",
+			Summary: "This OpenAPI document",
+		},
+		openapi.Route{
+			Method: http.MethodGet, Path: "/api/docs", ID: "getAPIDocs", Tag: "meta",
+			Summary: "API reference, as an HTML page",
+		},
+		openapi.Route{
+			Method: http.MethodGet, Path: "/api/authors", ID: "listAuthors", Tag: "authors",
+			Summary:     "List authors",
+			Description: "Lists a page of authors in name order. The Link header carries the URL of the next page, if there is one.",
+			Params: []openapi.Param{
+				{Name: "q", In: "query", Description: "Part of the name to search for"},
+				{Name: "after", In: "query", Description: "Cursor from the previous page's Link header"},
+				{Name: "limit", In: "query", Type: "integer", Description: "Authors per page, at most 100; 50 if omitted"},
+			},
+			Response: []db.Author{},
+			Headers:  []string{"Link"},
+			Token:    true,
+			Errors:   []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusTooManyRequests, http.StatusServiceUnavailable},
+		},
+		tokenRoute(openapi.Route{
+			Method: http.MethodGet, Path: "/api/v1/repositories", ID: "listRepositories", Tag: "repositories",
+			Summary:     "List repositories",
+			Description: "Lists the repositories the token reaches: its one repository, or every repository its user can access.",
+			Response:    []db.Repository{},
+		}),
+		tokenRoute(openapi.Route{
+			Method: http.MethodGet, Path: "/api/v1/repositories/:id", ID: "getRepository", Tag: "repositories",
+			Summary:  "Get a repository",
+			Params:   []openapi.Param{repositoryID},
+			Response: db.Repository{},
+			Errors:   []int{http.StatusForbidden, http.StatusNotFound},
+		}),
+		tokenRoute(openapi.Route{
+			Method: http.MethodGet, Path: "/api/v1/repositories/:id/files", ID: "getFile", Tag: "files",
+			Summary:     "Read a content file",
+			Description: "Returns the file with its hash, also sent as the ETag. Send the hash back when writing the file.",
+			Params:      []openapi.Param{repositoryID, filePath},
+			Response:    handlers.APIFile{},
+			Headers:     []string{"ETag"},
+			Errors:      []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound},
+		}),
+		tokenRoute(openapi.Route{
+			Method: http.MethodPut, Path: "/api/v1/repositories/:id/files", ID: "putFile", Tag: "files",
+			Summary:     "Write a content file",
+			Description: "Replaces the file if it is unchanged since it was read with base_hash, or the hash in If-Match. Without either the file must not exist yet. When the repository syncs an authors collection, authors the frontmatter names must be in it. Needs the write permission.",
+			Params: []openapi.Param{repositoryID, filePath,
+				{Name: "If-Match", In: "header", Description: "Hash the file was read with, instead of base_hash"}},
+			Request:  handlers.APIFileWrite{},
+			Response: handlers.APIFile{},
+			Headers:  []string{"ETag"},
+			Errors:   []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity},
+		}),
+		tokenRoute(openapi.Route{
+			Method: http.MethodGet, Path: "/api/v1/repositories/:id/change-sets", ID: "listChangeSets", Tag: "change sets",
+			Summary:  "List change sets",
+			Params:   []openapi.Param{repositoryID},
+			Response: []handlers.APIChangeSet{},
+			Errors:   []int{http.StatusForbidden, http.StatusNotFound},
+		}),
+		tokenRoute(openapi.Route{
+			Method: http.MethodPost, Path: "/api/v1/repositories/:id/change-sets", ID: "createChangeSet", Tag: "change sets",
+			Summary:     "Start a change set",
+			Description: "Starts a draft change set from files with unpublished changes. Needs the write permission.",
+			Params:      []openapi.Param{repositoryID},
+			Request:     handlers.APIChangeSetCreate{},
+			Status:      http.StatusCreated,
+			Response:    handlers.APIChangeSet{},
+			Errors:      []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusUnprocessableEntity},
+		}),
+		tokenRoute(openapi.Route{
+			Method: http.MethodGet, Path: "/api/v1/repositories/:id/change-sets/:changeset", ID: "getChangeSet", Tag: "change sets",
+			Summary:  "Get a change set",
+			Params:   []openapi.Param{repositoryID, changeSetID},
+			Response: handlers.APIChangeSet{},
+			Errors:   []int{http.StatusForbidden, http.StatusNotFound},
+		}),
+		tokenRoute(openapi.Route{
+			Method: http.MethodPost, Path: "/api/v1/repositories/:id/change-sets/:changeset/actions/:action", ID: "transitionChangeSet", Tag: "change sets",
+			Summary:     "Take a workflow action",
+			Description: "Moves the change set through review. Publishing needs the " + string(apitoken.PermissionPublish) + " permission, the other actions " + string(apitoken.PermissionWrite) + ".",
+			Params: []openapi.Param{repositoryID, changeSetID,
+				{Name: "action", In: "path", Enum: actions}},
+			Request:  handlers.APIAction{},
+			Response: handlers.APIChangeSet{},
+			Errors:   []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusConflict},
+		}),
+	)
+}
+
+// tokenRoute marks a route as part of the token-authenticated API, adding the
+// errors every such route can return.
+func tokenRoute(route openapi.Route) openapi.Route {
+	route.Token = true
+	route.Problems = true
+	route.Errors = append([]int{http.StatusUnauthorized, http.StatusTooManyRequests, http.StatusServiceUnavailable}, route.Errors...)
+	return route
+}
@@ -1,17 +1,28 @@
 package web
 
 import (
-	"github.com/gracchi-stdio/goaat/internal/auth"
 	"github.com/gracchi-stdio/goaat/internal/middleware"
 	"github.com/gracchi-stdio/goaat/internal/platform/db"
+	"github.com/gracchi-stdio/goaat/internal/ratelimit"
 	"github.com/gracchi-stdio/goaat/internal/web/handlers"
 	"github.com/labstack/echo/v4"
 )
 
-// RegisterRoutes sets up all application routes
-func RegisterRoutes(e *echo.Echo, queries *db.Queries, authService auth.Service) {
+// RegisterRoutes sets up all application routes. It fails if a route under
+// /api is not described by APISpec, or a description has no route.
+func RegisterRoutes(e *echo.Echo, queries *db.Queries, services handlers.Services, limits ratelimit.Store) error {
 	// Initialize handlers with dependencies
-	h := handlers.New(queries, authService)
+	h := handlers.New(queries, services)
+	h.API = APISpec()
+
+	// Rate limit budgets. Sign-in and token checks are charged to the
+	// client's address, as there is no user yet, so guessing tokens is
+	// throttled before any lookup; expensive actions get budgets of their own.
+	authLimit := middleware.RateLimit(limits, "auth", ratelimit.PerMinute(10), middleware.ByIP)
+	tokenLimit := middleware.RateLimit(limits, "token", ratelimit.PerMinute(300), middleware.ByIP)
+	apiLimit := middleware.RateLimit(limits, "api", ratelimit.PerMinute(120), middleware.ByUser)
+	commitLimit := middleware.RateLimit(limits, "commit", ratelimit.PerMinute(30), middleware.ByUser)
+	previewLimit := middleware.RateLimit(limits, "preview", ratelimit.PerHour(20), middleware.ByUser)
 
 	// Public pages with user context
 	publicPages := e.Group("")
@@ -21,11 +32,13 @@ func RegisterRoutes(e *echo.Echo, queries *db.Queries, authService auth.Service)
 	publicPages.GET("/login", h.LoginPage)
 
 	// Health checks (no user needed)
-	e.GET("/health", h.Health)
+	e.GET("/livez", h.Livez)
+	e.GET("/readyz", h.Readyz)
+	e.GET("/health", h.Readyz)
 
 	// Auth routes
-	e.GET("/auth/:provider", h.Auth)
-	e.GET("/auth/:provider/callback", h.AuthCallback)
+	e.GET("/auth/:provider", h.Auth, authLimit)
+	e.GET("/auth/:provider/callback", h.AuthCallback, authLimit)
 	e.POST("/logout/:provider", h.Logout)
 	e.POST("/logout", h.Logout)
 
@@ -34,12 +47,93 @@ func RegisterRoutes(e *echo.Echo, queries *db.Queries, authService auth.Service)
 	authGroup.Use(middleware.RequireAuth)
 	authGroup.GET("/dashboard", h.DashboardPage)
 	authGroup.GET("/authors", h.AuthorListPage)
+	authGroup.GET("/authors/results", h.AuthorResults)
+	authGroup.POST("/authors", h.CreateAuthor)
+	authGroup.PUT("/authors/:id", h.UpdateAuthor)
+	authGroup.DELETE("/authors/:id", h.DeleteAuthor)
 	authGroup.GET("/profile", h.ProfilePage)
 	authGroup.POST("/profile/update", h.UpdateProfile)
 	authGroup.GET("/repositories", h.RepositoriesPage)
+	authGroup.GET("/repositories/:id/translations", h.TranslationsPage)
+	authGroup.POST("/repositories/:id/translations/stub", h.CreateTranslationStub, commitLimit)
+	authGroup.GET("/repositories/:id/translations/edit", h.TranslationEditorPage)
+	authGroup.POST("/repositories/:id/translations/edit", h.SaveTranslation, commitLimit)
+	authGroup.POST("/repositories/:id/translations/draft", h.AutosaveTranslation)
+	authGroup.DELETE("/repositories/:id/translations/draft", h.DiscardTranslationDraft)
+	authGroup.GET("/repositories/:id/history", h.HistoryPage)
+	authGroup.POST("/repositories/:id/history/restore", h.RestoreRevision, commitLimit)
+	authGroup.GET("/repositories/:id/presence", h.PresenceStream)
+	authGroup.POST("/repositories/:id/presence/lock", h.LockFile)
+	authGroup.DELETE("/repositories/:id/presence/lock", h.UnlockFile)
+	authGroup.GET("/repositories/:id/collab", h.CollabEditorPage)
+	authGroup.GET("/repositories/:id/collab/:session/stream", h.CollabStream)
+	authGroup.POST("/repositories/:id/collab/:session/ops", h.ApplyCollabOps)
+	authGroup.POST("/repositories/:id/collab/:session/save", h.SaveCollab, commitLimit)
+	authGroup.GET("/repositories/:id/review", h.ReviewPage)
+	authGroup.POST("/repositories/:id/review/threads", h.CreateReviewThread)
+	authGroup.POST("/repositories/:id/review/threads/:thread/replies", h.ReplyReviewThread)
+	authGroup.POST("/repositories/:id/review/threads/:thread/resolve", h.ResolveReviewThread)
+	authGroup.POST("/repositories/:id/review/threads/:thread/reopen", h.ReopenReviewThread)
+	authGroup.GET("/repositories/:id/workflow", h.WorkflowPage)
+	authGroup.POST("/repositories/:id/workflow/settings", h.UpdateWorkflowSettings)
+	authGroup.POST("/repositories/:id/workflow/members", h.AddRepositoryMember)
+	authGroup.DELETE("/repositories/:id/workflow/members/:user", h.RemoveRepositoryMember)
+	authGroup.GET("/repositories/:id/authors", h.AuthorCollectionPage)
+	authGroup.POST("/repositories/:id/authors/settings", h.ConfigureAuthorCollection)
+	authGroup.DELETE("/repositories/:id/authors/settings", h.DisableAuthorCollection)
+	authGroup.POST("/repositories/:id/authors/members", h.AddCollectionAuthor)
+	authGroup.POST("/repositories/:id/authors/sync", h.SyncAuthorCollection, commitLimit)
+	authGroup.POST("/repositories/:id/authors/conflicts/:slug", h.ResolveAuthorConflict, commitLimit)
+	authGroup.POST("/repositories/:id/authors/byline", h.AddByline)
+	authGroup.GET("/repositories/:id/webhooks", h.WebhooksPage)
+	authGroup.POST("/repositories/:id/webhooks", h.CreateWebhook)
+	authGroup.GET("/repositories/:id/webhooks/:webhook", h.WebhookPage)
+	authGroup.DELETE("/repositories/:id/webhooks/:webhook", h.DeleteWebhook)
+	authGroup.POST("/repositories/:id/webhooks/:webhook/deliveries/:delivery/redeliver", h.RedeliverWebhook)
+	authGroup.POST("/repositories/:id/changesets", h.CreateChangeSet)
+	authGroup.GET("/repositories/:id/changesets/:changeset", h.ChangeSetPage)
+	authGroup.POST("/repositories/:id/changesets/:changeset/schedule", h.ScheduleChangeSet)
+	authGroup.DELETE("/repositories/:id/changesets/:changeset/schedule", h.UnscheduleChangeSet)
+	authGroup.POST("/repositories/:id/changesets/:changeset/preview", h.StartPreview, previewLimit)
+	authGroup.GET("/repositories/:id/changesets/:changeset/preview/log", h.PreviewLogStream)
+	authGroup.POST("/repositories/:id/changesets/:changeset/:action", h.TransitionChangeSet)
+	authGroup.GET("/review-queue", h.ReviewQueuePage)
+	authGroup.POST("/notifications/:id/dismiss", h.DismissNotification)
+	authGroup.GET("/repositories/:id/navigation", h.NavigationPage)
+	authGroup.POST("/repositories/:id/navigation", h.SaveNavigation, commitLimit)
 	authGroup.GET("/settings", h.SettingsPage)
+	authGroup.POST("/settings/tokens", h.CreateToken)
+	authGroup.DELETE("/settings/tokens/:token", h.RevokeToken)
+	authGroup.GET("/search", h.SearchPage)
+
+	// Built change set previews, served at the base path they were built with
+	previews := e.Group("/preview")
+	previews.Use(middleware.RequireAuth)
+	previews.GET("/:changeset", h.ServePreview)
+	previews.GET("/:changeset/*", h.ServePreview)
+
+	// API; listing authors shows their emails, so it needs a token too
+	api := e.Group("/api", apiLimit)
+	api.GET("/authors", h.ListAuthors, tokenLimit, middleware.RequireToken(services.Tokens))
+	api.GET("/openapi.json", h.OpenAPIDocument)
+	api.GET("/docs", h.APIDocsPage)
+
+	// Versioned API for scripts, authenticated by personal access tokens;
+	// errors are application/problem+json, and requests that do not match
+	// the specification are rejected before reaching the handlers
+	v1 := e.Group("/api/v1", middleware.Problems, tokenLimit, middleware.RequireToken(services.Tokens), apiLimit, middleware.ValidateRequest(h.API))
+	v1.GET("/repositories", h.APIListRepositories)
+	v1.GET("/repositories/:id", h.APIGetRepository)
+	v1.GET("/repositories/:id/files", h.APIGetFile)
+	v1.PUT("/repositories/:id/files", h.APIPutFile, commitLimit)
+	v1.GET("/repositories/:id/change-sets", h.APIListChangeSets)
+	v1.POST("/repositories/:id/change-sets", h.APICreateChangeSet)
+	v1.GET("/repositories/:id/change-sets/:changeset", h.APIGetChangeSet)
+	v1.POST("/repositories/:id/change-sets/:changeset/actions/:action", h.APITransitionChangeSet)
 
-	// API
-	e.GET("/api/authors", h.ListAuthors)
+	// GraphQL, a read-only view across repositories for the same tokens;
+	// it lives outside /api as the OpenAPI document does not describe it
+	e.POST("/graphql", h.GraphQL, tokenLimit, middleware.RequireToken(services.Tokens), apiLimit)
 
+	return h.API.Check(e.Routes(), apiPrefix)
 }
@@ -0,0 +1,83 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gracchi-stdio/goaat/internal/apitoken"
+	"github.com/gracchi-stdio/goaat/internal/graph"
+	"github.com/gracchi-stdio/goaat/internal/middleware"
+	"github.com/gracchi-stdio/goaat/internal/ratelimit"
+	"github.com/gracchi-stdio/goaat/internal/web/handlers"
+	"github.com/labstack/echo/v4"
+)
+
+// Registering the routes checks them against the API specification, so a
+// route added without a description, or the reverse, fails here.
+func TestRegisterRoutes(t *testing.T) {
+	if err := RegisterRoutes(echo.New(), nil, handlers.Services{}, ratelimit.NewMemoryStore()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// fakeTokens accepts a single secret.
+type fakeTokens struct {
+	apitoken.Service
+}
+
+func (fakeTokens) Authenticate(ctx context.Context, secret string) (apitoken.Grant, error) {
+	if secret != apitoken.Prefix+"test" {
+		return apitoken.Grant{}, apitoken.ErrInvalidToken
+	}
+	return apitoken.Grant{TokenID: 1, UserID: 1, Permission: apitoken.PermissionRead}, nil
+}
+
+// Token requests carry no session or CSRF cookie, so they must get past the
+// CSRF middleware the pages are protected by.
+func TestGraphQLWithToken(t *testing.T) {
+	e := echo.New()
+	e.Use(middleware.Security(middleware.SecurityConfig{})...)
+	services := handlers.Services{Tokens: fakeTokens{}, Graph: graph.NewService(nil, t.TempDir())}
+	if err := RegisterRoutes(e, nil, services, ratelimit.NewMemoryStore()); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		want  int
+	}{
+		{"token", apitoken.Prefix + "test", http.StatusOK},
+		{"invalid token", apitoken.Prefix + "wrong", http.StatusUnauthorized},
+		{"no token", "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query":"{ __typename }"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if tt.token != "" {
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+tt.token)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		if rec.Code != tt.want {
+			t.Errorf("%s: status %d, want %d: %s", tt.name, rec.Code, tt.want, rec.Body)
+			continue
+		}
+		if tt.want != http.StatusOK {
+			continue
+		}
+		var resp struct {
+			Data struct {
+				Typename string `json:"__typename"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || resp.Data.Typename != "Query" {
+			t.Errorf("%s: body %s, want the query's result", tt.name, rec.Body)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package layouts
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gracchi-stdio/goaat/internal/middleware"
+	"github.com/labstack/echo/v4"
+)
+
+func TestLayoutLoadsAssetsFromDevServer(t *testing.T) {
+	tests := []struct {
+		devServer string
+		want      string
+	}{
+		{"", `src="/js/main.js"`},
+		{"http://localhost:5173", `src="http://localhost:5173/assets/js/main.js"`},
+	}
+	for _, tt := range tests {
+		e := echo.New()
+		e.Use(middleware.Security(middleware.SecurityConfig{DevServer: tt.devServer})...)
+		e.GET("/", func(c echo.Context) error {
+			return Layout("Test", "").Render(c.Request().Context(), c.Response())
+		})
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		if !strings.Contains(rec.Body.String(), tt.want) {
+			t.Errorf("dev server %q: page does not contain %s", tt.devServer, tt.want)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package pages
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+)
+
+// TranslationsURL is the translation matrix of a repository.
+func TranslationsURL(repoID int64) string {
+	return fmt.Sprintf("/admin/repositories/%d/translations", repoID)
+}
+
+// TranslationEditorURL is the side-by-side editor for a source page in a locale.
+func TranslationEditorURL(repoID int64, sourcePath, locale string) string {
+	return fmt.Sprintf("/admin/repositories/%d/translations/edit?path=%s&locale=%s",
+		repoID, url.QueryEscape(sourcePath), url.QueryEscape(locale))
+}
+
+// TranslationStubURL creates a translation stub for a source page in a locale.
+func TranslationStubURL(repoID int64, sourcePath, locale string) string {
+	return fmt.Sprintf("/admin/repositories/%d/translations/stub?path=%s&locale=%s",
+		repoID, url.QueryEscape(sourcePath), url.QueryEscape(locale))
+}
+
+// NavigationURL is the sidebar editor of a repository.
+func NavigationURL(repoID int64) string {
+	return fmt.Sprintf("/admin/repositories/%d/navigation", repoID)
+}
+
+// TranslationDraftURL autosaves or discards the draft of a translation.
+func TranslationDraftURL(repoID int64, sourcePath, locale string) string {
+	return fmt.Sprintf("/admin/repositories/%d/translations/draft?path=%s&locale=%s",
+		repoID, url.QueryEscape(sourcePath), url.QueryEscape(locale))
+}
+
+// HistoryURL is the revision history of a content file.
+func HistoryURL(repoID int64, path string) string {
+	return fmt.Sprintf("/admin/repositories/%d/history?path=%s", repoID, url.QueryEscape(path))
+}
+
+// RestoreRevisionURL restores a revision of a content file.
+func RestoreRevisionURL(repoID int64, path, revisionID string) string {
+	return fmt.Sprintf("/admin/repositories/%d/history/restore?path=%s&rev=%s",
+		repoID, url.QueryEscape(path), url.QueryEscape(revisionID))
+}
+
+// PresenceURL streams presence for a repository, joining path when set.
+func PresenceURL(repoID int64, path string) string {
+	if path == "" {
+		return fmt.Sprintf("/admin/repositories/%d/presence", repoID)
+	}
+	return fmt.Sprintf("/admin/repositories/%d/presence?path=%s", repoID, url.QueryEscape(path))
+}
+
+// FileLockURL takes (POST) or releases (DELETE) the soft lock on a file.
+func FileLockURL(repoID int64, path string) string {
+	return fmt.Sprintf("/admin/repositories/%d/presence/lock?path=%s", repoID, url.QueryEscape(path))
+}
+
+// PresenceID is the element ID of a file's presence avatars.
+func PresenceID(path string) string {
+	sum := sha1.Sum([]byte(path))
+	return "presence-" + hex.EncodeToString(sum[:8])
+}
+
+// CollabEditorURL opens the live co-editing session on a content file.
+func CollabEditorURL(repoID int64, path string) string {
+	return fmt.Sprintf("/admin/repositories/%d/collab?path=%s", repoID, url.QueryEscape(path))
+}
+
+// CollabSessionURL is the base URL of a co-editing session's stream, ops and save endpoints.
+func CollabSessionURL(repoID, sessionID int64) string {
+	return fmt.Sprintf("/admin/repositories/%d/collab/%d", repoID, sessionID)
+}
+
+// ReviewURL shows a content file with its inline comment threads.
+func ReviewURL(repoID int64, path string) string {
+	return fmt.Sprintf("/admin/repositories/%d/review?path=%s", repoID, url.QueryEscape(path))
+}
+
+// ReviewThreadsURL starts a comment thread on a content file.
+func ReviewThreadsURL(repoID int64, path string) string {
+	return fmt.Sprintf("/admin/repositories/%d/review/threads?path=%s", repoID, url.QueryEscape(path))
+}
+
+// ReviewThreadURL runs an action (replies, resolve, reopen) on a comment thread.
+func ReviewThreadURL(repoID, threadID int64, action, path string) string {
+	return fmt.Sprintf("/admin/repositories/%d/review/threads/%d/%s?path=%s",
+		repoID, threadID, action, url.QueryEscape(path))
+}
+
+// WorkflowURL is the editorial workflow page of a repository.
+func WorkflowURL(repoID int64) string {
+	return fmt.Sprintf("/admin/repositories/%d/workflow", repoID)
+}
+
+// AuthorCollectionURL is the authors collection sync page of a repository.
+func AuthorCollectionURL(repoID int64) string {
+	return fmt.Sprintf("/admin/repositories/%d/authors", repoID)
+}
+
+// AuthorConflictURL resolves a conflicting collection entry by keeping one side.
+func AuthorConflictURL(repoID int64, slug, keep string) string {
+	return fmt.Sprintf("/admin/repositories/%d/authors/conflicts/%s?keep=%s",
+		repoID, url.PathEscape(slug), url.QueryEscape(keep))
+}
+
+// BylineURL credits a collection author in the frontmatter of the edited page.
+func BylineURL(repoID int64) string {
+	return fmt.Sprintf("/admin/repositories/%d/authors/byline", repoID)
+}
+
+// ChangeSetsURL creates change sets in a repository.
+func ChangeSetsURL(repoID int64) string {
+	return fmt.Sprintf("/admin/repositories/%d/changesets", repoID)
+}
+
+// ChangeSetURL is a change set's page.
+func ChangeSetURL(repoID, changeSetID int64) string {
+	return fmt.Sprintf("/admin/repositories/%d/changesets/%d", repoID, changeSetID)
+}
+
+// ChangeSetActionURL takes a workflow action on a change set.
+func ChangeSetActionURL(repoID, changeSetID int64, action string) string {
+	return fmt.Sprintf("/admin/repositories/%d/changesets/%d/%s", repoID, changeSetID, action)
+}
+
+// ChangeSetScheduleURL schedules or cancels a change set's publish; from
+// the dashboard, the dashboard is re-rendered instead of the change set.
+func ChangeSetScheduleURL(repoID, changeSetID int64, fromDashboard bool) string {
+	u := fmt.Sprintf("/admin/repositories/%d/changesets/%d/schedule", repoID, changeSetID)
+	if fromDashboard {
+		u += "?view=dashboard"
+	}
+	return u
+}
+
+// ChangeSetPreviewURL starts a preview build of a change set; its log
+// streams from ChangeSetPreviewURL + "/log".
+func ChangeSetPreviewURL(repoID, changeSetID int64) string {
+	return fmt.Sprintf("/admin/repositories/%d/changesets/%d/preview", repoID, changeSetID)
+}
+
+// NotificationDismissURL marks a notification as read.
+func NotificationDismissURL(id int64) string {
+	return fmt.Sprintf("/admin/notifications/%d/dismiss", id)
+}
+
+// ReviewQueueURL lists the change sets awaiting the current user's approval.
+const ReviewQueueURL = "/admin/review-queue"
+
+// WebhooksURL lists (GET) or registers (POST) a repository's webhooks.
+func WebhooksURL(repoID int64) string {
+	return fmt.Sprintf("/admin/repositories/%d/webhooks", repoID)
+}
+
+// WebhookURL shows (GET) or deletes (DELETE) a webhook with its delivery log.
+func WebhookURL(repoID, webhookID int64) string {
+	return fmt.Sprintf("/admin/repositories/%d/webhooks/%d", repoID, webhookID)
+}
+
+// RedeliverURL sends a webhook delivery's payload again.
+func RedeliverURL(repoID, webhookID, deliveryID int64) string {
+	return fmt.Sprintf("/admin/repositories/%d/webhooks/%d/deliveries/%d/redeliver", repoID, webhookID, deliveryID)
+}
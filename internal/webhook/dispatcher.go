@@ -0,0 +1,256 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+	"github.com/gracchi-stdio/goaat/internal/platform/metrics"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// dispatcherLockKey is the advisory lock held by the one replica that
+	// sends due deliveries.
+	dispatcherLockKey int64 = 0x676f61617402
+
+	dispatcherInterval = 10 * time.Second
+	dispatcherBatch    = 20
+
+	// maxDeliveryAttempts is how often a delivery is tried before it is
+	// given up on; the last retry comes about an hour after the first try.
+	maxDeliveryAttempts = 8
+
+	deliveryTimeout = 10 * time.Second
+
+	// dispatcherStall is how long a tick may run before the dispatcher is
+	// reported unhealthy: a full batch of deliveries at their timeout.
+	dispatcherStall = dispatcherInterval + dispatcherBatch*deliveryTimeout
+
+	// SignatureHeader carries the hex HMAC-SHA256 of the body, keyed with
+	// the endpoint's secret and prefixed "sha256=".
+	SignatureHeader = "X-Goaat-Signature-256"
+
+	// EventHeader and DeliveryHeader name the event and the delivery.
+	EventHeader    = "X-Goaat-Event"
+	DeliveryHeader = "X-Goaat-Delivery"
+)
+
+// Dispatcher sends queued deliveries to their endpoints.
+type Dispatcher struct {
+	pool   *pgxpool.Pool
+	client *http.Client
+	logger echo.Logger
+
+	// heartbeat is when the last tick started, in Unix nanoseconds; zero
+	// while the dispatcher is not running
+	heartbeat atomic.Int64
+}
+
+// ErrInternalAddress is returned for deliveries to an address on a
+// loopback, private, link-local or otherwise internal network.
+var ErrInternalAddress = errors.New("webhook URL resolves to an internal address")
+
+// NewDispatcher creates a dispatcher sending the deliveries queued in pool.
+func NewDispatcher(pool *pgxpool.Pool, logger echo.Logger) *Dispatcher {
+	return &Dispatcher{pool: pool, client: newClient(publicOnly), logger: logger}
+}
+
+// newClient creates the client deliveries are sent with. control vets every
+// address dialled, after DNS resolution, so a name cannot be pointed at an
+// internal address once the URL is saved. Redirects are not followed, as
+// they would send the payload somewhere the endpoint's owner did not set.
+func newClient(control func(network, address string, conn syscall.RawConn) error) *http.Client {
+	dialer := &net.Dialer{Timeout: deliveryTimeout, Control: control}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil // A proxy would be dialled instead of the receiver
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Transport: transport,
+		Timeout:   deliveryTimeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// publicOnly refuses connections to this host and the networks around it,
+// so webhooks cannot be used to reach internal services.
+func publicOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	ip = ip.Unmap()
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() || ip.IsMulticast() {
+		return fmt.Errorf("%w: %s", ErrInternalAddress, ip)
+	}
+	return nil
+}
+
+// Run sends due deliveries until ctx is cancelled. Every replica runs a
+// dispatcher; an advisory lock lets only one of them work at a time.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(dispatcherInterval)
+	defer ticker.Stop()
+	defer d.heartbeat.Store(0)
+
+	for {
+		d.heartbeat.Store(time.Now().UnixNano())
+		if err := d.tick(ctx); err != nil && ctx.Err() == nil {
+			d.logger.Warnf("Webhook delivery failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Healthy reports whether the dispatcher is running and not stuck in a tick.
+func (d *Dispatcher) Healthy() error {
+	beat := d.heartbeat.Load()
+	if beat == 0 {
+		return errors.New("webhook dispatcher is not running")
+	}
+	if since := time.Since(time.Unix(0, beat)); since > dispatcherStall {
+		return fmt.Errorf("webhook dispatcher has not ticked for %s", since.Round(time.Second))
+	}
+	return nil
+}
+
+func (d *Dispatcher) tick(ctx context.Context) error {
+	conn, err := d.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	// The lock belongs to this connection's session, so it is taken and
+	// released on it, and dies with it if the replica does
+	queries := db.New(conn)
+	locked, err := queries.TryAdvisoryLock(ctx, dispatcherLockKey)
+	if err != nil || !locked {
+		return err
+	}
+	defer func() {
+		if err := queries.AdvisoryUnlock(context.Background(), dispatcherLockKey); err != nil {
+			d.logger.Warnf("Failed to release webhook dispatcher lock: %v", err)
+			conn.Conn().Close(context.Background())
+		}
+	}()
+
+	backlog, err := queries.CountDueWebhookDeliveries(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to count due deliveries: %w", err)
+	}
+	metrics.SetQueueDepth(metrics.JobWebhookDelivery, int(backlog))
+
+	due, err := queries.ListDueWebhookDeliveries(ctx, dispatcherBatch)
+	if err != nil {
+		return fmt.Errorf("failed to list due deliveries: %w", err)
+	}
+	for _, delivery := range due {
+		if ctx.Err() != nil {
+			return nil
+		}
+		d.deliver(ctx, queries, delivery)
+		backlog--
+		metrics.SetQueueDepth(metrics.JobWebhookDelivery, int(max(backlog, 0)))
+	}
+	return nil
+}
+
+// deliver sends one delivery and records the attempt, scheduling a retry
+// if it failed.
+func (d *Dispatcher) deliver(ctx context.Context, queries db.Querier, delivery db.ListDueWebhookDeliveriesRow) {
+	start := time.Now()
+	status, sendErr := d.send(ctx, delivery)
+	metrics.ObserveJob(metrics.JobWebhookDelivery, time.Since(start), sendErr)
+
+	attempts := delivery.Attempts + 1
+	params := db.UpdateWebhookDeliveryAttemptParams{
+		ID:             delivery.ID,
+		Status:         "delivered",
+		Attempts:       attempts,
+		NextAttemptAt:  pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		ResponseStatus: int32(status),
+	}
+	if sendErr != nil {
+		params.Status = "pending"
+		if attempts >= maxDeliveryAttempts {
+			params.Status = "failed"
+		}
+		params.NextAttemptAt.Time = time.Now().Add(retryDelay(attempts))
+		params.LastError = sendErr.Error()
+	}
+	// Record the outcome even if the dispatcher is stopping meanwhile, so
+	// a delivery that was received is not sent again
+	if err := queries.UpdateWebhookDeliveryAttempt(context.WithoutCancel(ctx), params); err != nil {
+		d.logger.Errorf("Failed to record webhook delivery %d: %v", delivery.ID, err)
+	}
+}
+
+// send posts a delivery's payload, returning the response status, or 0 if
+// there was none. Any status outside 2xx is a failure.
+func (d *Dispatcher) send(ctx context.Context, delivery db.ListDueWebhookDeliveriesRow) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, deliveryTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("invalid request: %w", err)
+	}
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set("User-Agent", "goaat-webhooks")
+	req.Header.Set(EventHeader, delivery.Event)
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(SignatureHeader, Sign(delivery.Secret, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// The body is not kept: it would let whoever sets the URL read responses
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, errors.New("receiver responded " + resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// Sign returns the signature header value of a payload; receivers compute
+// it from the body they got and compare it in constant time.
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// retryDelay backs off exponentially from thirty seconds.
+func retryDelay(attempts int32) time.Duration {
+	return 30 * time.Second << (attempts - 1)
+}
@@ -0,0 +1,225 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+	"github.com/gracchi-stdio/goaat/internal/workflow"
+	"github.com/labstack/echo/v4"
+)
+
+func TestSign(t *testing.T) {
+	tests := []struct {
+		secret  string
+		payload string
+		want    string
+	}{
+		// RFC 4231 test case 2
+		{"Jefe", "what do ya want for nothing?", "sha256=5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"},
+		{"secret", "", "sha256=f9e66e179b6747ae54108f82f8ade8b3c25d76fd30afde6c395822c530196169"},
+	}
+	for _, tt := range tests {
+		if got := Sign(tt.secret, []byte(tt.payload)); got != tt.want {
+			t.Errorf("Sign(%q, %q) = %s, want %s", tt.secret, tt.payload, got, tt.want)
+		}
+	}
+	if Sign("a", []byte("body")) == Sign("b", []byte("body")) {
+		t.Error("different secrets give the same signature")
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempts int32
+		want     string
+	}{
+		{1, "30s"},
+		{2, "1m0s"},
+		{4, "4m0s"},
+		{maxDeliveryAttempts - 1, "32m0s"},
+	}
+	for _, tt := range tests {
+		if got := retryDelay(tt.attempts).String(); got != tt.want {
+			t.Errorf("retryDelay(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+// fakeQueries keeps deliveries in memory the way the webhook queries do.
+type fakeQueries struct {
+	db.Querier
+	url        string
+	secret     string
+	deliveries []db.WebhookDelivery
+}
+
+func (f *fakeQueries) due() []db.ListDueWebhookDeliveriesRow {
+	var rows []db.ListDueWebhookDeliveriesRow
+	for _, d := range f.deliveries {
+		if d.Status == "pending" {
+			rows = append(rows, db.ListDueWebhookDeliveriesRow{
+				ID: d.ID, Event: d.Event, Payload: d.Payload, Attempts: d.Attempts, Url: f.url, Secret: f.secret,
+			})
+		}
+	}
+	return rows
+}
+
+func (f *fakeQueries) UpdateWebhookDeliveryAttempt(ctx context.Context, arg db.UpdateWebhookDeliveryAttemptParams) error {
+	for i := range f.deliveries {
+		if d := &f.deliveries[i]; d.ID == arg.ID {
+			d.Status, d.Attempts, d.ResponseStatus, d.LastError = arg.Status, arg.Attempts, arg.ResponseStatus, arg.LastError
+		}
+	}
+	return nil
+}
+
+func (f *fakeQueries) RedeliverWebhookDelivery(ctx context.Context, arg db.RedeliverWebhookDeliveryParams) (db.WebhookDelivery, error) {
+	for _, d := range f.deliveries {
+		if d.ID == arg.ID {
+			again := db.WebhookDelivery{ID: int64(len(f.deliveries) + 1), Event: d.Event, Payload: d.Payload, Status: "pending"}
+			again.RedeliveryOf.Int64, again.RedeliveryOf.Valid = d.ID, true
+			f.deliveries = append(f.deliveries, again)
+			return again, nil
+		}
+	}
+	return db.WebhookDelivery{}, errors.New("no such delivery")
+}
+
+// received is a request the test receiver got.
+type received struct {
+	delivery  string
+	event     string
+	signature string
+	body      string
+}
+
+func TestDeliveries(t *testing.T) {
+	var got []received
+	failures := 1
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got = append(got, received{r.Header.Get(DeliveryHeader), r.Header.Get(EventHeader), r.Header.Get(SignatureHeader), string(body)})
+		if failures > 0 {
+			failures--
+			http.Error(w, "internal details the sender must not see", http.StatusInternalServerError)
+		}
+	}))
+	defer receiver.Close()
+
+	payload := `{"event":"change_set.published"}`
+	queries := &fakeQueries{
+		url:        receiver.URL,
+		secret:     "s3cret",
+		deliveries: []db.WebhookDelivery{{ID: 1, Event: string(EventChangeSetPublished), Payload: []byte(payload), Status: "pending"}},
+	}
+	// The receiver listens on loopback, which the real client refuses
+	d := &Dispatcher{client: newClient(nil), logger: echo.New().Logger}
+	ctx := context.Background()
+	sendDue := func() {
+		for _, row := range queries.due() {
+			d.deliver(ctx, queries, row)
+		}
+	}
+
+	// The first attempt fails and is retried
+	sendDue()
+	first := queries.deliveries[0]
+	if first.Status != "pending" || first.Attempts != 1 || first.ResponseStatus != http.StatusInternalServerError {
+		t.Fatalf("after a 500: %+v, want pending after 1 attempt with status 500", first)
+	}
+	if first.LastError != "receiver responded 500 Internal Server Error" {
+		t.Fatalf("last error = %q, want only the status", first.LastError)
+	}
+
+	sendDue()
+	if first := queries.deliveries[0]; first.Status != "delivered" || first.Attempts != 2 {
+		t.Fatalf("after the retry: %+v, want delivered after 2 attempts", first)
+	}
+
+	// A redelivery is a new delivery of the same payload
+	service := NewService(queries)
+	owner := workflow.Actor{UserID: 1, Role: workflow.RoleOwner}
+	if _, err := service.Redeliver(ctx, owner, db.Repository{ID: 1}, 1); err != nil {
+		t.Fatal(err)
+	}
+	sendDue()
+	if again := queries.deliveries[1]; again.Status != "delivered" {
+		t.Fatalf("redelivery: %+v, want delivered", again)
+	}
+
+	wantDeliveries := []string{"1", "1", "2"}
+	if len(got) != len(wantDeliveries) {
+		t.Fatalf("receiver got %d requests, want %d", len(got), len(wantDeliveries))
+	}
+	for i, r := range got {
+		if r.delivery != wantDeliveries[i] || r.event != string(EventChangeSetPublished) || r.body != payload {
+			t.Errorf("request %d = %+v", i, r)
+		}
+		if r.signature != Sign("s3cret", []byte(payload)) {
+			t.Errorf("request %d signature %q does not match the body", i, r.signature)
+		}
+	}
+}
+
+func TestInternalAddressesAreRefused(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("a delivery reached a loopback receiver")
+	}))
+	defer receiver.Close()
+
+	d := &Dispatcher{client: newClient(publicOnly), logger: echo.New().Logger}
+	_, err := d.send(context.Background(), db.ListDueWebhookDeliveriesRow{ID: 1, Url: receiver.URL})
+	if !errors.Is(err, ErrInternalAddress) {
+		t.Fatalf("send to %s = %v, want ErrInternalAddress", receiver.URL, err)
+	}
+
+	tests := []struct {
+		address string
+		ok      bool
+	}{
+		{"93.184.215.14:443", true},
+		{"[2606:2800:21f:cb07:6820:80da:af6b:8b2c]:443", true},
+		{"127.0.0.1:80", false},
+		{"[::1]:80", false},
+		{"10.1.2.3:80", false},
+		{"172.16.0.1:80", false},
+		{"192.168.1.1:80", false},
+		{"169.254.169.254:80", false},
+		{"[fe80::1]:80", false},
+		{"[fc00::1]:80", false},
+		{"0.0.0.0:80", false},
+		{"[::ffff:127.0.0.1]:80", false},
+		{"224.0.0.1:80", false},
+	}
+	for _, tt := range tests {
+		err := publicOnly("tcp", tt.address, nil)
+		if (err == nil) != tt.ok {
+			t.Errorf("publicOnly(%s) = %v, want ok=%v", tt.address, err, tt.ok)
+		}
+	}
+}
+
+func TestRedirectsAreNotFollowed(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("a delivery followed a redirect")
+	}))
+	defer target.Close()
+	receiver := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusTemporaryRedirect))
+	defer receiver.Close()
+
+	d := &Dispatcher{client: newClient(nil), logger: echo.New().Logger}
+	status, err := d.send(context.Background(), db.ListDueWebhookDeliveriesRow{ID: 1, Url: receiver.URL})
+	if status != http.StatusTemporaryRedirect || err == nil {
+		t.Fatalf("send = %d, %v; want a failed %d", status, err, http.StatusTemporaryRedirect)
+	}
+	if want := "receiver responded " + strconv.Itoa(status) + " Temporary Redirect"; err.Error() != want {
+		t.Fatalf("error = %q, want %q", err, want)
+	}
+}
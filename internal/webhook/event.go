@@ -0,0 +1,94 @@
+package webhook
+
+import (
+	"time"
+
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+)
+
+// Event is a type of event endpoints subscribe to.
+type Event string
+
+const (
+	EventPageSaved          Event = "page.saved"
+	EventChangeSetPublished Event = "changeset.published"
+	EventSyncCompleted      Event = "sync.completed"
+	EventMemberAdded        Event = "member.added"
+)
+
+// Events lists every event type, in the order the settings page shows them.
+var Events = []Event{EventPageSaved, EventChangeSetPublished, EventSyncCompleted, EventMemberAdded}
+
+// Description says when an event is sent.
+func (e Event) Description() string {
+	switch e {
+	case EventPageSaved:
+		return "A content file is saved"
+	case EventChangeSetPublished:
+		return "A change set is published"
+	case EventSyncCompleted:
+		return "Authors are synced with the authors collection"
+	case EventMemberAdded:
+		return "A member is added to the repository"
+	}
+	return string(e)
+}
+
+// Payload is the JSON body of a delivery.
+type Payload struct {
+	Event      Event      `json:"event"`
+	Repository Repository `json:"repository"`
+	Time       time.Time  `json:"time"`
+	Data       any        `json:"data"`
+}
+
+// Repository identifies the repository an event happened in.
+type Repository struct {
+	ID       int64  `json:"id"`
+	FullName string `json:"full_name"`
+}
+
+// Sender is the user whose action caused an event.
+type Sender struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// PageSaved is the data of a page.saved event.
+type PageSaved struct {
+	Path   string `json:"path"` // relative to the content directory
+	Sender Sender `json:"sender"`
+}
+
+// ChangeSetPublished is the data of a changeset.published event.
+type ChangeSetPublished struct {
+	ID              int64    `json:"id"`
+	Title           string   `json:"title"`
+	PublishedCommit string   `json:"published_commit"`
+	Files           []string `json:"files"`
+	Sender          Sender   `json:"sender"`
+}
+
+// SyncCompleted is the data of a sync.completed event.
+type SyncCompleted struct {
+	Changes   int    `json:"changes"`
+	Conflicts int    `json:"conflicts"`
+	Sender    Sender `json:"sender"`
+}
+
+// MemberAdded is the data of a member.added event.
+type MemberAdded struct {
+	Email  string `json:"email"`
+	Role   string `json:"role"`
+	Sender Sender `json:"sender"`
+}
+
+// newPayload wraps an event's data for delivery.
+func newPayload(repo db.Repository, event Event, data any) Payload {
+	return Payload{
+		Event:      event,
+		Repository: Repository{ID: repo.ID, FullName: repo.FullName},
+		Time:       time.Now().UTC(),
+		Data:       data,
+	}
+}
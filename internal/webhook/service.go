@@ -0,0 +1,215 @@
+// Package webhook sends a repository's events to the HTTP endpoints its
+// owner registers, signed with each endpoint's secret and retried until
+// they are received.
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+	"github.com/gracchi-stdio/goaat/internal/workflow"
+	"github.com/jackc/pgx/v5"
+)
+
+// logSize is how many recent deliveries an endpoint's log shows.
+const logSize = 50
+
+var (
+	// ErrInvalidURL is returned for endpoint URLs that are not absolute http(s) URLs.
+	ErrInvalidURL = errors.New("invalid webhook URL")
+
+	// ErrNoEvents is returned for endpoints subscribed to no event.
+	ErrNoEvents = errors.New("webhook subscribes to no events")
+
+	// ErrUnknownEvent is returned for event types that do not exist.
+	ErrUnknownEvent = errors.New("unknown webhook event")
+
+	// ErrUnknownEndpoint is returned for endpoint IDs that do not belong to the repository.
+	ErrUnknownEndpoint = errors.New("unknown webhook")
+
+	// ErrUnknownDelivery is returned for delivery IDs that do not belong to the repository.
+	ErrUnknownDelivery = errors.New("unknown webhook delivery")
+)
+
+// Service manages a repository's webhooks and queues their deliveries,
+// which a Dispatcher sends. Only the repository owner manages webhooks.
+type Service interface {
+	// Endpoints lists the repository's webhooks, oldest first
+	Endpoints(ctx context.Context, actor workflow.Actor, repo db.Repository) ([]db.WebhookEndpoint, error)
+
+	// Endpoint returns one webhook; ErrUnknownEndpoint if the repository has no such webhook
+	Endpoint(ctx context.Context, actor workflow.Actor, repo db.Repository, id int64) (db.WebhookEndpoint, error)
+
+	// Create registers a webhook; a random secret is generated when secret is empty
+	Create(ctx context.Context, actor workflow.Actor, repo db.Repository, rawURL, secret string, events []Event) (db.WebhookEndpoint, error)
+
+	// Delete removes a webhook with its delivery log
+	Delete(ctx context.Context, actor workflow.Actor, repo db.Repository, id int64) error
+
+	// Deliveries returns a webhook's most recent deliveries, newest first
+	Deliveries(ctx context.Context, actor workflow.Actor, repo db.Repository, endpointID int64) ([]db.WebhookDelivery, error)
+
+	// Redeliver queues a new delivery of a delivery's payload
+	Redeliver(ctx context.Context, actor workflow.Actor, repo db.Repository, deliveryID int64) (db.WebhookDelivery, error)
+
+	// Emit queues an event for every webhook of the repository subscribed to it
+	Emit(ctx context.Context, repo db.Repository, event Event, data any) error
+
+	// Published emits changeset.published for a change set the actor published
+	Published(ctx context.Context, actor workflow.Actor, repo db.Repository, cs *workflow.ChangeSet) error
+}
+
+type service struct {
+	queries db.Querier
+}
+
+// NewService creates a webhook service.
+func NewService(queries db.Querier) Service {
+	return &service{queries: queries}
+}
+
+func (s *service) Endpoints(ctx context.Context, actor workflow.Actor, repo db.Repository) ([]db.WebhookEndpoint, error) {
+	if !actor.CanManage() {
+		return nil, workflow.ErrForbidden
+	}
+	endpoints, err := s.queries.ListWebhookEndpoints(ctx, repo.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	return endpoints, nil
+}
+
+func (s *service) Endpoint(ctx context.Context, actor workflow.Actor, repo db.Repository, id int64) (db.WebhookEndpoint, error) {
+	if !actor.CanManage() {
+		return db.WebhookEndpoint{}, workflow.ErrForbidden
+	}
+	endpoint, err := s.queries.GetWebhookEndpoint(ctx, db.GetWebhookEndpointParams{ID: id, RepositoryID: repo.ID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return db.WebhookEndpoint{}, ErrUnknownEndpoint
+	}
+	if err != nil {
+		return db.WebhookEndpoint{}, fmt.Errorf("failed to load webhook: %w", err)
+	}
+	return endpoint, nil
+}
+
+func (s *service) Create(ctx context.Context, actor workflow.Actor, repo db.Repository, rawURL, secret string, events []Event) (db.WebhookEndpoint, error) {
+	if !actor.CanManage() {
+		return db.WebhookEndpoint{}, workflow.ErrForbidden
+	}
+
+	rawURL = strings.TrimSpace(rawURL)
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.User != nil {
+		return db.WebhookEndpoint{}, ErrInvalidURL
+	}
+	if len(events) == 0 {
+		return db.WebhookEndpoint{}, ErrNoEvents
+	}
+	names := make([]string, 0, len(events))
+	for _, e := range events {
+		if !slices.Contains(Events, e) {
+			return db.WebhookEndpoint{}, ErrUnknownEvent
+		}
+		if !slices.Contains(names, string(e)) {
+			names = append(names, string(e))
+		}
+	}
+
+	secret = strings.TrimSpace(secret)
+	if secret == "" {
+		random := make([]byte, 32)
+		if _, err := rand.Read(random); err != nil {
+			return db.WebhookEndpoint{}, fmt.Errorf("failed to generate secret: %w", err)
+		}
+		secret = hex.EncodeToString(random)
+	}
+
+	endpoint, err := s.queries.CreateWebhookEndpoint(ctx, db.CreateWebhookEndpointParams{
+		RepositoryID: repo.ID,
+		Url:          rawURL,
+		Secret:       secret,
+		Events:       names,
+	})
+	if err != nil {
+		return db.WebhookEndpoint{}, fmt.Errorf("failed to create webhook: %w", err)
+	}
+	return endpoint, nil
+}
+
+func (s *service) Delete(ctx context.Context, actor workflow.Actor, repo db.Repository, id int64) error {
+	if !actor.CanManage() {
+		return workflow.ErrForbidden
+	}
+	n, err := s.queries.DeleteWebhookEndpoint(ctx, db.DeleteWebhookEndpointParams{ID: id, RepositoryID: repo.ID})
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	if n == 0 {
+		return ErrUnknownEndpoint
+	}
+	return nil
+}
+
+func (s *service) Deliveries(ctx context.Context, actor workflow.Actor, repo db.Repository, endpointID int64) ([]db.WebhookDelivery, error) {
+	if _, err := s.Endpoint(ctx, actor, repo, endpointID); err != nil {
+		return nil, err
+	}
+	deliveries, err := s.queries.ListWebhookDeliveries(ctx, db.ListWebhookDeliveriesParams{EndpointID: endpointID, Limit: logSize})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+func (s *service) Redeliver(ctx context.Context, actor workflow.Actor, repo db.Repository, deliveryID int64) (db.WebhookDelivery, error) {
+	if !actor.CanManage() {
+		return db.WebhookDelivery{}, workflow.ErrForbidden
+	}
+	delivery, err := s.queries.RedeliverWebhookDelivery(ctx, db.RedeliverWebhookDeliveryParams{ID: deliveryID, RepositoryID: repo.ID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return db.WebhookDelivery{}, ErrUnknownDelivery
+	}
+	if err != nil {
+		return db.WebhookDelivery{}, fmt.Errorf("failed to queue redelivery: %w", err)
+	}
+	return delivery, nil
+}
+
+func (s *service) Emit(ctx context.Context, repo db.Repository, event Event, data any) error {
+	payload, err := json.Marshal(newPayload(repo, event, data))
+	if err != nil {
+		return fmt.Errorf("failed to encode %s payload: %w", event, err)
+	}
+	_, err = s.queries.EnqueueWebhookDeliveries(ctx, db.EnqueueWebhookDeliveriesParams{
+		Event:        string(event),
+		Payload:      payload,
+		RepositoryID: repo.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to queue %s deliveries: %w", event, err)
+	}
+	return nil
+}
+
+func (s *service) Published(ctx context.Context, actor workflow.Actor, repo db.Repository, cs *workflow.ChangeSet) error {
+	files := cs.Files
+	if files == nil {
+		files = []string{}
+	}
+	return s.Emit(ctx, repo, EventChangeSetPublished, ChangeSetPublished{
+		ID:              cs.ID,
+		Title:           cs.Title,
+		PublishedCommit: cs.PublishedCommit,
+		Files:           files,
+		Sender:          Sender{ID: actor.UserID, Name: actor.Name},
+	})
+}
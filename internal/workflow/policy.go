@@ -0,0 +1,99 @@
+package workflow
+
+import (
+	"errors"
+	"slices"
+)
+
+var (
+	// ErrForbidden is returned when the user's role does not allow an action.
+	ErrForbidden = errors.New("not allowed")
+
+	// ErrInvalidTransition is returned for actions not available in the change set's state.
+	ErrInvalidTransition = errors.New("action not available in this state")
+)
+
+// Actor is a user acting on a repository, with their role in it.
+type Actor struct {
+	UserID int64
+	Name   string
+	Email  string
+	Role   Role
+}
+
+// Has reports whether the actor holds a role; the owner holds every role.
+func (a Actor) Has(role Role) bool {
+	return a.Role == RoleOwner || a.Role == role
+}
+
+// CanEdit reports whether the actor may create and publish change sets.
+func (a Actor) CanEdit() bool {
+	return a.Has(RoleEditor)
+}
+
+// CanManage reports whether the actor may change members and settings.
+func (a Actor) CanManage() bool {
+	return a.Role == RoleOwner
+}
+
+// from lists the states each action can be taken in.
+var from = map[Action][]State{
+	ActionSubmit:         {StateDraft},
+	ActionApprove:        {StateInReview},
+	ActionRequestChanges: {StateInReview, StateApproved},
+	ActionWithdraw:       {StateInReview, StateApproved},
+	ActionPublish:        {StateApproved},
+}
+
+// Authorize decides whether the actor may take an action on a change set.
+// Every workflow transition is checked here and nowhere else.
+func Authorize(actor Actor, cs *ChangeSet, settings Settings, action Action) error {
+	if !slices.Contains(from[action], cs.State) {
+		return ErrInvalidTransition
+	}
+
+	author := actor.UserID == cs.AuthorID
+	allowed := false
+	switch action {
+	case ActionSubmit, ActionWithdraw:
+		allowed = actor.CanEdit() && (author || actor.Role == RoleOwner)
+	case ActionApprove:
+		// Authors cannot approve their own changes
+		allowed = actor.Has(settings.ApproverRole) && !author && !cs.ApprovedBy(actor.UserID)
+	case ActionRequestChanges:
+		allowed = actor.Has(settings.ApproverRole) && !author
+	case ActionPublish:
+		allowed = actor.CanEdit()
+	}
+	if !allowed {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// Allowed lists the actions the actor may take on a change set.
+func Allowed(actor Actor, cs *ChangeSet, settings Settings) []Action {
+	var allowed []Action
+	for _, a := range actions {
+		if Authorize(actor, cs, settings, a) == nil {
+			allowed = append(allowed, a)
+		}
+	}
+	return allowed
+}
+
+// next returns the state a change set moves to after an action, given the
+// number of approvals it has once the action is applied.
+func next(action Action, approvals int, settings Settings) State {
+	switch action {
+	case ActionSubmit, ActionApprove:
+		if approvals >= settings.RequiredApprovals {
+			return StateApproved
+		}
+		return StateInReview
+	case ActionPublish:
+		return StatePublished
+	default:
+		return StateDraft
+	}
+}
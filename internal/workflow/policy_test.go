@@ -0,0 +1,119 @@
+package workflow
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestAuthorize(t *testing.T) {
+	const (
+		ownerID    = 1
+		authorID   = 2
+		editorID   = 3
+		reviewerID = 4
+	)
+	owner := Actor{UserID: ownerID, Role: RoleOwner}
+	author := Actor{UserID: authorID, Role: RoleEditor}
+	editor := Actor{UserID: editorID, Role: RoleEditor}
+	reviewer := Actor{UserID: reviewerID, Role: RoleReviewer}
+	ownAuthor := Actor{UserID: ownerID, Role: RoleOwner} // owner who wrote the change set
+
+	settings := DefaultSettings
+	editorsApprove := Settings{RequiredApprovals: 1, ApproverRole: RoleEditor}
+
+	changeSet := func(state State, approvers ...int64) *ChangeSet {
+		cs := &ChangeSet{AuthorID: authorID, State: state}
+		for _, id := range approvers {
+			cs.Approvals = append(cs.Approvals, Approval{UserID: id})
+		}
+		return cs
+	}
+
+	tests := []struct {
+		name     string
+		actor    Actor
+		cs       *ChangeSet
+		settings Settings
+		action   Action
+		want     error
+	}{
+		{"author submits draft", author, changeSet(StateDraft), settings, ActionSubmit, nil},
+		{"owner submits someone's draft", owner, changeSet(StateDraft), settings, ActionSubmit, nil},
+		{"other editor cannot submit", editor, changeSet(StateDraft), settings, ActionSubmit, ErrForbidden},
+		{"reviewer cannot submit", reviewer, changeSet(StateDraft), settings, ActionSubmit, ErrForbidden},
+		{"submit twice", author, changeSet(StateInReview), settings, ActionSubmit, ErrInvalidTransition},
+
+		{"reviewer approves", reviewer, changeSet(StateInReview), settings, ActionApprove, nil},
+		{"owner approves as any role", owner, changeSet(StateInReview), settings, ActionApprove, nil},
+		{"author cannot approve own", author, changeSet(StateInReview), editorsApprove, ActionApprove, ErrForbidden},
+		{"owner cannot approve own", ownAuthor, &ChangeSet{AuthorID: ownerID, State: StateInReview}, settings, ActionApprove, ErrForbidden},
+		{"editor is not the approver role", editor, changeSet(StateInReview), settings, ActionApprove, ErrForbidden},
+		{"editor approves when editors approve", editor, changeSet(StateInReview), editorsApprove, ActionApprove, nil},
+		{"approve twice", reviewer, changeSet(StateInReview, reviewerID), settings, ActionApprove, ErrForbidden},
+		{"approve a draft", reviewer, changeSet(StateDraft), settings, ActionApprove, ErrInvalidTransition},
+
+		{"reviewer requests changes", reviewer, changeSet(StateApproved), settings, ActionRequestChanges, nil},
+		{"author cannot request changes", author, changeSet(StateInReview), editorsApprove, ActionRequestChanges, ErrForbidden},
+		{"request changes on published", reviewer, changeSet(StatePublished), settings, ActionRequestChanges, ErrInvalidTransition},
+
+		{"author withdraws", author, changeSet(StateApproved), settings, ActionWithdraw, nil},
+		{"other editor cannot withdraw", editor, changeSet(StateInReview), settings, ActionWithdraw, ErrForbidden},
+		{"withdraw a draft", author, changeSet(StateDraft), settings, ActionWithdraw, ErrInvalidTransition},
+
+		{"editor publishes approved", editor, changeSet(StateApproved), settings, ActionPublish, nil},
+		{"reviewer cannot publish", reviewer, changeSet(StateApproved), settings, ActionPublish, ErrForbidden},
+		{"publish in review", owner, changeSet(StateInReview), settings, ActionPublish, ErrInvalidTransition},
+		{"publish twice", owner, changeSet(StatePublished), settings, ActionPublish, ErrInvalidTransition},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Authorize(tt.actor, tt.cs, tt.settings, tt.action)
+			if !errors.Is(err, tt.want) || (tt.want == nil && err != nil) {
+				t.Fatalf("Authorize = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllowed(t *testing.T) {
+	cs := &ChangeSet{AuthorID: 2, State: StateInReview}
+	got := Allowed(Actor{UserID: 1, Role: RoleOwner}, cs, DefaultSettings)
+	want := []Action{ActionApprove, ActionRequestChanges, ActionWithdraw}
+	for _, a := range want {
+		if !slices.Contains(got, a) {
+			t.Errorf("Allowed = %v, missing %s", got, a)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("Allowed = %v, want %v", got, want)
+	}
+}
+
+func TestNext(t *testing.T) {
+	two := Settings{RequiredApprovals: 2, ApproverRole: RoleReviewer}
+	none := Settings{RequiredApprovals: 0, ApproverRole: RoleReviewer}
+
+	tests := []struct {
+		action    Action
+		approvals int
+		settings  Settings
+		want      State
+	}{
+		{ActionSubmit, 0, two, StateInReview},
+		{ActionSubmit, 0, none, StateApproved},
+		{ActionSubmit, 2, two, StateApproved},
+		{ActionApprove, 1, two, StateInReview},
+		{ActionApprove, 2, two, StateApproved},
+		{ActionApprove, 3, two, StateApproved},
+		{ActionRequestChanges, 1, two, StateDraft},
+		{ActionWithdraw, 0, two, StateDraft},
+		{ActionPublish, 2, two, StatePublished},
+	}
+	for _, tt := range tests {
+		if got := next(tt.action, tt.approvals, tt.settings); got != tt.want {
+			t.Errorf("next(%s, %d approvals of %d) = %s, want %s",
+				tt.action, tt.approvals, tt.settings.RequiredApprovals, got, tt.want)
+		}
+	}
+}
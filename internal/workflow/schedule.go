@@ -0,0 +1,166 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	_ "time/tzdata" // Timezones entered by users must resolve on hosts without zoneinfo
+
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	// ErrPastSchedule is returned for publish times that are not in the future.
+	ErrPastSchedule = errors.New("publish time is in the past")
+
+	// ErrInvalidSchedule is returned for unreadable times and unknown timezones.
+	ErrInvalidSchedule = errors.New("invalid publish time")
+)
+
+// ScheduleLayout is the format of publish times entered in a
+// datetime-local input.
+const ScheduleLayout = "2006-01-02T15:04"
+
+// ScheduleStatus is where a scheduled publish stands.
+type ScheduleStatus string
+
+const (
+	SchedulePending ScheduleStatus = "pending"
+	ScheduleFailed  ScheduleStatus = "failed" // Given up on until rescheduled
+)
+
+// Schedule is a change set set to publish at a future time.
+type Schedule struct {
+	ChangeSetID     int64
+	RepositoryID    int64
+	RepositoryName  string
+	Title           string
+	ScheduledByName string
+	// PublishAt is in the timezone it was entered in
+	PublishAt time.Time
+	Status    ScheduleStatus
+	Attempts  int
+	LastError string
+}
+
+// ParseScheduleTime reads a wall-clock time in an IANA timezone.
+func ParseScheduleTime(value, timezone string) (time.Time, error) {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil || timezone == "" {
+		return time.Time{}, ErrInvalidSchedule
+	}
+	at, err := time.ParseInLocation(ScheduleLayout, value, loc)
+	if err != nil {
+		return time.Time{}, ErrInvalidSchedule
+	}
+	return at, nil
+}
+
+// AuthorizeSchedule decides whether the actor may schedule or cancel a
+// change set's publish. Whether it can actually be published is checked
+// by Authorize when the time comes.
+func AuthorizeSchedule(actor Actor, cs *ChangeSet) error {
+	if cs.State == StatePublished {
+		return ErrInvalidTransition
+	}
+	if !actor.CanEdit() {
+		return ErrForbidden
+	}
+	return nil
+}
+
+func (s *service) Schedule(ctx context.Context, actor Actor, repo db.Repository, id int64, at time.Time) error {
+	cs, err := s.ChangeSet(ctx, repo, id)
+	if err != nil {
+		return err
+	}
+	if err := AuthorizeSchedule(actor, cs); err != nil {
+		return err
+	}
+	if !at.After(time.Now()) {
+		return ErrPastSchedule
+	}
+
+	_, err = s.queries.UpsertScheduledPublish(ctx, db.UpsertScheduledPublishParams{
+		ChangeSetID:  id,
+		RepositoryID: repo.ID,
+		ScheduledBy:  actor.UserID,
+		PublishAt:    pgtype.Timestamptz{Time: at, Valid: true},
+		Timezone:     at.Location().String(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to schedule publish: %w", err)
+	}
+	return nil
+}
+
+func (s *service) Unschedule(ctx context.Context, actor Actor, repo db.Repository, id int64) error {
+	cs, err := s.ChangeSet(ctx, repo, id)
+	if err != nil {
+		return err
+	}
+	if err := AuthorizeSchedule(actor, cs); err != nil {
+		return err
+	}
+	if err := s.queries.DeleteScheduledPublish(ctx, id); err != nil {
+		return fmt.Errorf("failed to cancel scheduled publish: %w", err)
+	}
+	return nil
+}
+
+func (s *service) ScheduledPublish(ctx context.Context, repo db.Repository, id int64) (*Schedule, error) {
+	row, err := s.queries.GetScheduledPublish(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && row.RepositoryID != repo.ID) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load scheduled publish: %w", err)
+	}
+	user, err := s.queries.GetUser(ctx, row.ScheduledBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load user: %w", err)
+	}
+	return &Schedule{
+		ChangeSetID:     row.ChangeSetID,
+		RepositoryID:    row.RepositoryID,
+		RepositoryName:  repo.FullName,
+		ScheduledByName: user.Name,
+		PublishAt:       inZone(row.PublishAt.Time, row.Timezone),
+		Status:          ScheduleStatus(row.Status),
+		Attempts:        int(row.Attempts),
+		LastError:       row.LastError,
+	}, nil
+}
+
+func (s *service) Upcoming(ctx context.Context, userID int64) ([]Schedule, error) {
+	rows, err := s.queries.ListUpcomingScheduledPublishes(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scheduled publishes: %w", err)
+	}
+	schedules := make([]Schedule, 0, len(rows))
+	for _, r := range rows {
+		schedules = append(schedules, Schedule{
+			ChangeSetID:     r.ChangeSetID,
+			RepositoryID:    r.RepositoryID,
+			RepositoryName:  r.RepositoryName,
+			Title:           r.Title,
+			ScheduledByName: r.ScheduledByName,
+			PublishAt:       inZone(r.PublishAt.Time, r.Timezone),
+			Status:          ScheduleStatus(r.Status),
+			Attempts:        int(r.Attempts),
+			LastError:       r.LastError,
+		})
+	}
+	return schedules, nil
+}
+
+// inZone shows a stored time in the timezone it was entered in.
+func inZone(t time.Time, timezone string) time.Time {
+	if loc, err := time.LoadLocation(timezone); err == nil {
+		return t.In(loc)
+	}
+	return t
+}
@@ -0,0 +1,215 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/gracchi-stdio/goaat/internal/notification"
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+	"github.com/gracchi-stdio/goaat/internal/platform/metrics"
+	"github.com/gracchi-stdio/goaat/internal/platform/tracing"
+	"github.com/gracchi-stdio/goaat/internal/repository"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// schedulerLockKey is the advisory lock held by the one replica that
+	// publishes due change sets.
+	schedulerLockKey int64 = 0x676f61617401
+
+	schedulerInterval = 30 * time.Second
+	schedulerBatch    = 10
+
+	// maxPublishAttempts is how often a transient failure is retried
+	// before the schedule is given up on.
+	maxPublishAttempts = 5
+
+	// schedulerStall is how long a tick may run before the scheduler is
+	// reported unhealthy: a full batch of publishes at their timeout.
+	schedulerStall = schedulerInterval + schedulerBatch*publishTimeout
+
+	publishTimeout = 30 * time.Second
+)
+
+// PublishHook is told about each change set the scheduler publishes.
+type PublishHook func(ctx context.Context, actor Actor, repo db.Repository, cs *ChangeSet) error
+
+// Scheduler publishes change sets when their scheduled time comes.
+type Scheduler struct {
+	pool          *pgxpool.Pool
+	service       Service
+	notifications notification.Service
+	link          func(repoID, changeSetID int64) string
+	published     PublishHook // nil if nothing needs telling
+	logger        echo.Logger
+
+	// heartbeat is when the last tick started, in Unix nanoseconds; zero
+	// while the scheduler is not running
+	heartbeat atomic.Int64
+}
+
+// NewScheduler creates a scheduler that publishes through service and
+// notifies whoever scheduled each publish, linking to the change set.
+// published, if set, is called after each publish.
+func NewScheduler(pool *pgxpool.Pool, service Service, notifications notification.Service, link func(repoID, changeSetID int64) string, published PublishHook, logger echo.Logger) *Scheduler {
+	return &Scheduler{pool: pool, service: service, notifications: notifications, link: link, published: published, logger: logger}
+}
+
+// Run publishes due change sets until ctx is cancelled. Every replica runs
+// a scheduler; an advisory lock lets only one of them work at a time.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(schedulerInterval)
+	defer ticker.Stop()
+	defer s.heartbeat.Store(0)
+
+	for {
+		s.heartbeat.Store(time.Now().UnixNano())
+		if err := s.tick(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Warnf("Scheduled publishing failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Healthy reports whether the scheduler is running and not stuck in a tick.
+func (s *Scheduler) Healthy() error {
+	beat := s.heartbeat.Load()
+	if beat == 0 {
+		return errors.New("scheduler is not running")
+	}
+	if since := time.Since(time.Unix(0, beat)); since > schedulerStall {
+		return fmt.Errorf("scheduler has not ticked for %s", since.Round(time.Second))
+	}
+	return nil
+}
+
+func (s *Scheduler) tick(ctx context.Context) error {
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	// The lock belongs to this connection's session, so it is taken and
+	// released on it, and dies with it if the replica does
+	queries := db.New(conn)
+	locked, err := queries.TryAdvisoryLock(ctx, schedulerLockKey)
+	if err != nil || !locked {
+		return err
+	}
+	defer func() {
+		if err := queries.AdvisoryUnlock(context.Background(), schedulerLockKey); err != nil {
+			s.logger.Warnf("Failed to release scheduler lock: %v", err)
+			conn.Conn().Close(context.Background())
+		}
+	}()
+
+	backlog, err := queries.CountDueScheduledPublishes(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to count due publishes: %w", err)
+	}
+	metrics.SetQueueDepth(metrics.JobScheduledPublish, int(backlog))
+
+	due, err := queries.ListDueScheduledPublishes(ctx, schedulerBatch)
+	if err != nil {
+		return fmt.Errorf("failed to list due publishes: %w", err)
+	}
+	for _, p := range due {
+		if ctx.Err() != nil {
+			return nil
+		}
+		s.publish(ctx, queries, p)
+		backlog--
+		metrics.SetQueueDepth(metrics.JobScheduledPublish, int(max(backlog, 0)))
+	}
+	return nil
+}
+
+// publish runs one scheduled publish, recording a failed attempt for retry.
+func (s *Scheduler) publish(ctx context.Context, queries db.Querier, p db.ScheduledPublish) {
+	// Publishing runs git, so allow longer than a query
+	ctx, cancel := context.WithTimeout(ctx, publishTimeout)
+	defer cancel()
+
+	link := s.link(p.RepositoryID, p.ChangeSetID)
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "scheduled publish", attribute.Int64("change_set.id", p.ChangeSetID))
+	cs, publishErr := s.transition(ctx, queries, p)
+	tracing.End(span, publishErr)
+	metrics.ObserveJob(metrics.JobScheduledPublish, time.Since(start), publishErr)
+	if publishErr == nil {
+		s.notify(ctx, p.ScheduledBy, notification.LevelSuccess, fmt.Sprintf("%q was published as scheduled.", cs.Title), link)
+		return
+	}
+
+	attempts := p.Attempts + 1
+	status := SchedulePending
+	if permanent(publishErr) || attempts >= maxPublishAttempts {
+		status = ScheduleFailed
+	}
+	err := queries.UpdateScheduledPublishAttempt(ctx, db.UpdateScheduledPublishAttemptParams{
+		ID:            p.ID,
+		Status:        string(status),
+		Attempts:      attempts,
+		NextAttemptAt: pgtype.Timestamptz{Time: time.Now().Add(retryDelay(attempts)), Valid: true},
+		LastError:     publishErr.Error(),
+	})
+	if err != nil {
+		s.logger.Errorf("Failed to record scheduled publish attempt: %v", err)
+	}
+	if status == ScheduleFailed {
+		s.notify(ctx, p.ScheduledBy, notification.LevelDanger, "A scheduled publish failed and needs rescheduling: "+publishErr.Error(), link)
+	}
+}
+
+// transition publishes the change set as the user who scheduled it, so
+// their role still has to allow it.
+func (s *Scheduler) transition(ctx context.Context, queries db.Querier, p db.ScheduledPublish) (*ChangeSet, error) {
+	repo, err := queries.GetRepository(ctx, p.RepositoryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load repository: %w", err)
+	}
+	actor, err := s.service.Actor(ctx, repo, p.ScheduledBy)
+	if err != nil {
+		return nil, err
+	}
+	cs, err := s.service.Transition(ctx, actor, repo, p.ChangeSetID, ActionPublish, "Scheduled publish")
+	if err == nil && s.published != nil {
+		if err := s.published(ctx, actor, repo, cs); err != nil {
+			s.logger.Errorf("Failed to report scheduled publish: %v", err)
+		}
+	}
+	return cs, err
+}
+
+func (s *Scheduler) notify(ctx context.Context, userID int64, level notification.Level, message, link string) {
+	if err := s.notifications.Notify(ctx, userID, level, message, link); err != nil {
+		s.logger.Errorf("Failed to notify user %d: %v", userID, err)
+	}
+}
+
+// permanent reports whether retrying a failed publish cannot help.
+func permanent(err error) bool {
+	return errors.Is(err, ErrForbidden) ||
+		errors.Is(err, ErrInvalidTransition) ||
+		errors.Is(err, ErrUnknownChangeSet) ||
+		errors.Is(err, repository.ErrNothingToCommit) ||
+		errors.Is(err, pgx.ErrNoRows)
+}
+
+// retryDelay backs off exponentially from a minute.
+func retryDelay(attempts int32) time.Duration {
+	return time.Minute << (attempts - 1)
+}
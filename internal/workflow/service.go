@@ -0,0 +1,519 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gracchi-stdio/goaat/internal/platform/db"
+	"github.com/gracchi-stdio/goaat/internal/repository"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	// ErrUnknownChangeSet is returned for change set IDs that do not belong to the repository.
+	ErrUnknownChangeSet = errors.New("unknown change set")
+
+	// ErrUnknownUser is returned when adding a member who has never signed in.
+	ErrUnknownUser = errors.New("unknown user")
+
+	// ErrStale is returned when a change set was moved on by someone else first.
+	ErrStale = errors.New("change set was updated by someone else")
+
+	// ErrNoTitle is returned for change sets without a title.
+	ErrNoTitle = errors.New("change set needs a title")
+
+	// ErrNoFiles is returned for change sets without changed files.
+	ErrNoFiles = errors.New("change set has no changed files")
+
+	// ErrInvalidSettings is returned for unknown roles and negative approval counts.
+	ErrInvalidSettings = errors.New("invalid workflow settings")
+
+	// ErrFileClaimed is returned when a file is already part of an unpublished change set.
+	ErrFileClaimed = errors.New("file is already in another change set")
+)
+
+// Service defines the editorial workflow of change sets.
+type Service interface {
+	// Actor returns the user with their role in the repository; ErrForbidden if they have none
+	Actor(ctx context.Context, repo db.Repository, userID int64) (Actor, error)
+
+	// Settings returns the repository's approval settings
+	Settings(ctx context.Context, repo db.Repository) (Settings, error)
+
+	// UpdateSettings changes the repository's approval settings
+	UpdateSettings(ctx context.Context, actor Actor, repo db.Repository, settings Settings) error
+
+	// Members lists the users with a role in the repository, besides the owner
+	Members(ctx context.Context, repo db.Repository) ([]Member, error)
+
+	// AddMember gives the user with an email address a role in the repository
+	AddMember(ctx context.Context, actor Actor, repo db.Repository, email string, role Role) error
+
+	// RemoveMember takes away a user's role in the repository
+	RemoveMember(ctx context.Context, actor Actor, repo db.Repository, userID int64) error
+
+	// PendingFiles lists the content files with unpublished changes
+	PendingFiles(ctx context.Context, repo db.Repository) ([]PendingFile, error)
+
+	// Create starts a draft change set from pending files
+	Create(ctx context.Context, actor Actor, repo db.Repository, title, description string, files []string) (int64, error)
+
+	// ChangeSets lists the repository's change sets, most recently updated first
+	ChangeSets(ctx context.Context, repo db.Repository) ([]ChangeSet, error)
+
+	// ChangeSet returns a change set with its files, approvals and transitions
+	ChangeSet(ctx context.Context, repo db.Repository, id int64) (*ChangeSet, error)
+
+	// Transition takes an action on a change set and records it
+	Transition(ctx context.Context, actor Actor, repo db.Repository, id int64, action Action, note string) (*ChangeSet, error)
+
+	// Queue lists the change sets the user can approve, oldest first
+	Queue(ctx context.Context, userID int64) ([]ChangeSet, error)
+
+	// Schedule sets a change set to publish at a future time, replacing any earlier schedule
+	Schedule(ctx context.Context, actor Actor, repo db.Repository, id int64, at time.Time) error
+
+	// Unschedule cancels a change set's scheduled publish
+	Unschedule(ctx context.Context, actor Actor, repo db.Repository, id int64) error
+
+	// ScheduledPublish returns a change set's schedule, or nil if it has none
+	ScheduledPublish(ctx context.Context, repo db.Repository, id int64) (*Schedule, error)
+
+	// Upcoming lists the pending scheduled publishes in the user's repositories, soonest first
+	Upcoming(ctx context.Context, userID int64) ([]Schedule, error)
+}
+
+type service struct {
+	queries  db.Querier
+	pool     *pgxpool.Pool // transactions for transitions
+	reposDir string
+}
+
+// NewService creates a workflow service over the clones in reposDir.
+func NewService(queries db.Querier, pool *pgxpool.Pool, reposDir string) Service {
+	return &service{queries: queries, pool: pool, reposDir: reposDir}
+}
+
+func (s *service) Actor(ctx context.Context, repo db.Repository, userID int64) (Actor, error) {
+	user, err := s.queries.GetUser(ctx, userID)
+	if err != nil {
+		return Actor{}, fmt.Errorf("failed to load user: %w", err)
+	}
+	actor := Actor{UserID: user.ID, Name: user.Name, Email: user.Email, Role: RoleOwner}
+	if repo.OwnerID == userID {
+		return actor, nil
+	}
+
+	role, err := s.queries.GetRepositoryMemberRole(ctx, db.GetRepositoryMemberRoleParams{RepositoryID: repo.ID, UserID: userID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Actor{}, ErrForbidden
+	}
+	if err != nil {
+		return Actor{}, fmt.Errorf("failed to load member role: %w", err)
+	}
+	actor.Role = Role(role)
+	return actor, nil
+}
+
+func (s *service) Settings(ctx context.Context, repo db.Repository) (Settings, error) {
+	row, err := s.queries.GetWorkflowSettings(ctx, repo.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return DefaultSettings, nil
+	}
+	if err != nil {
+		return Settings{}, fmt.Errorf("failed to load workflow settings: %w", err)
+	}
+	return Settings{RequiredApprovals: int(row.RequiredApprovals), ApproverRole: Role(row.ApproverRole)}, nil
+}
+
+func (s *service) UpdateSettings(ctx context.Context, actor Actor, repo db.Repository, settings Settings) error {
+	if !actor.CanManage() {
+		return ErrForbidden
+	}
+	if settings.RequiredApprovals < 0 || !slices.Contains(MemberRoles, settings.ApproverRole) {
+		return ErrInvalidSettings
+	}
+
+	err := s.queries.UpsertWorkflowSettings(ctx, db.UpsertWorkflowSettingsParams{
+		RepositoryID:      repo.ID,
+		RequiredApprovals: int32(settings.RequiredApprovals),
+		ApproverRole:      string(settings.ApproverRole),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save workflow settings: %w", err)
+	}
+	return nil
+}
+
+func (s *service) Members(ctx context.Context, repo db.Repository) ([]Member, error) {
+	rows, err := s.queries.ListRepositoryMembers(ctx, repo.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list members: %w", err)
+	}
+	members := make([]Member, 0, len(rows))
+	for _, r := range rows {
+		members = append(members, Member{
+			UserID:    r.UserID,
+			Name:      r.Name,
+			Email:     r.Email,
+			AvatarURL: r.AvatarUrl.String,
+			Role:      Role(r.Role),
+		})
+	}
+	return members, nil
+}
+
+func (s *service) AddMember(ctx context.Context, actor Actor, repo db.Repository, email string, role Role) error {
+	if !actor.CanManage() {
+		return ErrForbidden
+	}
+	if !slices.Contains(MemberRoles, role) {
+		return ErrInvalidSettings
+	}
+
+	user, err := s.queries.GetUserByEmail(ctx, strings.TrimSpace(email))
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && user.ID == repo.OwnerID) {
+		return ErrUnknownUser
+	}
+	if err != nil {
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+
+	err = s.queries.UpsertRepositoryMember(ctx, db.UpsertRepositoryMemberParams{RepositoryID: repo.ID, UserID: user.ID, Role: string(role)})
+	if err != nil {
+		return fmt.Errorf("failed to add member: %w", err)
+	}
+	return nil
+}
+
+func (s *service) RemoveMember(ctx context.Context, actor Actor, repo db.Repository, userID int64) error {
+	if !actor.CanManage() {
+		return ErrForbidden
+	}
+	if err := s.queries.DeleteRepositoryMember(ctx, db.DeleteRepositoryMemberParams{RepositoryID: repo.ID, UserID: userID}); err != nil {
+		return fmt.Errorf("failed to remove member: %w", err)
+	}
+	return nil
+}
+
+func (s *service) PendingFiles(ctx context.Context, repo db.Repository) ([]PendingFile, error) {
+	changed, err := repository.ChangedFiles(ctx, repository.Dir(s.reposDir, repo.ID), repo.ContentPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list changed files: %w", err)
+	}
+	claimed, err := s.queries.ListUnpublishedChangeSetFiles(ctx, repo.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list change set files: %w", err)
+	}
+	owners := make(map[string]int64, len(claimed))
+	for _, c := range claimed {
+		owners[c.Path] = c.ChangeSetID
+	}
+
+	prefix := repo.ContentPath + "/"
+	files := make([]PendingFile, 0, len(changed))
+	for _, file := range changed {
+		rel, ok := strings.CutPrefix(file, prefix)
+		if !ok {
+			continue
+		}
+		files = append(files, PendingFile{Path: rel, ChangeSetID: owners[rel]})
+	}
+	return files, nil
+}
+
+func (s *service) Create(ctx context.Context, actor Actor, repo db.Repository, title, description string, files []string) (int64, error) {
+	if !actor.CanEdit() {
+		return 0, ErrForbidden
+	}
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return 0, ErrNoTitle
+	}
+	if len(files) == 0 {
+		return 0, ErrNoFiles
+	}
+
+	pending, err := s.PendingFiles(ctx, repo)
+	if err != nil {
+		return 0, err
+	}
+	for _, file := range files {
+		i := slices.IndexFunc(pending, func(p PendingFile) bool { return p.Path == file })
+		if i < 0 {
+			return 0, ErrNoFiles
+		}
+		if pending[i].ChangeSetID != 0 {
+			return 0, ErrFileClaimed
+		}
+	}
+
+	cs, err := s.queries.CreateChangeSet(ctx, db.CreateChangeSetParams{
+		RepositoryID: repo.ID,
+		Title:        title,
+		Description:  strings.TrimSpace(description),
+		AuthorID:     actor.UserID,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create change set: %w", err)
+	}
+	for _, file := range files {
+		if err := s.queries.AddChangeSetFile(ctx, db.AddChangeSetFileParams{ChangeSetID: cs.ID, Path: file}); err != nil {
+			return 0, fmt.Errorf("failed to add change set file: %w", err)
+		}
+	}
+	return cs.ID, nil
+}
+
+func (s *service) ChangeSets(ctx context.Context, repo db.Repository) ([]ChangeSet, error) {
+	rows, err := s.queries.ListChangeSets(ctx, repo.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list change sets: %w", err)
+	}
+	sets := make([]ChangeSet, 0, len(rows))
+	for _, r := range rows {
+		sets = append(sets, ChangeSet{
+			ID:              r.ID,
+			RepositoryID:    r.RepositoryID,
+			RepositoryName:  repo.FullName,
+			Title:           r.Title,
+			Description:     r.Description,
+			AuthorID:        r.AuthorID,
+			AuthorName:      r.AuthorName,
+			State:           State(r.State),
+			PublishedCommit: r.PublishedCommit,
+			UpdatedAt:       r.UpdatedAt.Time,
+		})
+	}
+	return sets, nil
+}
+
+func (s *service) ChangeSet(ctx context.Context, repo db.Repository, id int64) (*ChangeSet, error) {
+	row, err := s.queries.GetChangeSet(ctx, db.GetChangeSetParams{ID: id, RepositoryID: repo.ID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrUnknownChangeSet
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load change set: %w", err)
+	}
+	cs := &ChangeSet{
+		ID:              row.ID,
+		RepositoryID:    row.RepositoryID,
+		RepositoryName:  repo.FullName,
+		Title:           row.Title,
+		Description:     row.Description,
+		AuthorID:        row.AuthorID,
+		AuthorName:      row.AuthorName,
+		State:           State(row.State),
+		PublishedCommit: row.PublishedCommit,
+		UpdatedAt:       row.UpdatedAt.Time,
+	}
+
+	if cs.Files, err = s.queries.ListChangeSetFiles(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to list change set files: %w", err)
+	}
+	if cs.Approvals, err = s.approvals(ctx, id); err != nil {
+		return nil, err
+	}
+
+	transitions, err := s.queries.ListChangeSetTransitions(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list change set history: %w", err)
+	}
+	for _, t := range transitions {
+		cs.Transitions = append(cs.Transitions, Transition{
+			Action:   Action(t.Action),
+			From:     State(t.FromState),
+			To:       State(t.ToState),
+			Note:     t.Note,
+			UserName: t.UserName,
+			Time:     t.CreatedAt.Time,
+		})
+	}
+	return cs, nil
+}
+
+func (s *service) approvals(ctx context.Context, id int64) ([]Approval, error) {
+	rows, err := s.queries.ListChangeSetApprovals(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list approvals: %w", err)
+	}
+	approvals := make([]Approval, 0, len(rows))
+	for _, r := range rows {
+		approvals = append(approvals, Approval{UserID: r.UserID, UserName: r.UserName, Time: r.CreatedAt.Time})
+	}
+	return approvals, nil
+}
+
+func (s *service) Transition(ctx context.Context, actor Actor, repo db.Repository, id int64, action Action, note string) (*ChangeSet, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transition: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Everything up to the commit reads and writes under the change set's
+	// row lock, so concurrent actions on it wait for this one
+	locked := &service{queries: db.New(tx), reposDir: s.reposDir}
+	_, err = locked.queries.LockChangeSet(ctx, db.LockChangeSetParams{ID: id, RepositoryID: repo.ID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrUnknownChangeSet
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock change set: %w", err)
+	}
+	cs, err := locked.ChangeSet(ctx, repo, id)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := locked.Settings(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	if err := Authorize(actor, cs, settings, action); err != nil {
+		return nil, err
+	}
+
+	approvals := len(cs.Approvals)
+	switch action {
+	case ActionApprove:
+		if err := locked.queries.CreateChangeSetApproval(ctx, db.CreateChangeSetApprovalParams{ChangeSetID: id, UserID: actor.UserID}); err != nil {
+			return nil, fmt.Errorf("failed to record approval: %w", err)
+		}
+		approvals++
+	case ActionRequestChanges, ActionWithdraw:
+		// Changes made after going back to draft need fresh approvals
+		if err := locked.queries.DeleteChangeSetApprovals(ctx, id); err != nil {
+			return nil, fmt.Errorf("failed to clear approvals: %w", err)
+		}
+	}
+
+	to := next(action, approvals, settings)
+	n, err := locked.queries.UpdateChangeSetState(ctx, db.UpdateChangeSetStateParams{
+		ToState:         string(to),
+		PublishedCommit: cs.PublishedCommit,
+		ID:              id,
+		FromState:       string(cs.State),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update change set: %w", err)
+	}
+	if n == 0 {
+		return nil, ErrStale
+	}
+
+	err = locked.queries.CreateChangeSetTransition(ctx, db.CreateChangeSetTransitionParams{
+		ChangeSetID: id,
+		UserID:      actor.UserID,
+		Action:      string(action),
+		FromState:   string(cs.State),
+		ToState:     string(to),
+		Note:        strings.TrimSpace(note),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to record transition: %w", err)
+	}
+	if action == ActionPublish {
+		// Published by hand or by the scheduler, nothing is left to schedule
+		if err := locked.queries.DeleteScheduledPublish(ctx, id); err != nil {
+			return nil, fmt.Errorf("failed to clear scheduled publish: %w", err)
+		}
+		// Only now that the transition is secured does git change; if the
+		// commit fails, the transaction is rolled back
+		commit, err := s.publish(ctx, actor, repo, cs)
+		if err != nil {
+			return nil, err
+		}
+		if err := locked.queries.SetChangeSetPublishedCommit(ctx, db.SetChangeSetPublishedCommitParams{PublishedCommit: commit, ID: id}); err != nil {
+			return nil, fmt.Errorf("failed to record published commit: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transition: %w", err)
+	}
+	return s.ChangeSet(ctx, repo, id)
+}
+
+// publish commits the change set's files to the working copy's current branch.
+func (s *service) publish(ctx context.Context, actor Actor, repo db.Repository, cs *ChangeSet) (string, error) {
+	files := make([]string, 0, len(cs.Files))
+	for _, f := range cs.Files {
+		files = append(files, path.Join(repo.ContentPath, f))
+	}
+
+	message := cs.Title
+	if cs.Description != "" {
+		message += "\n\n" + cs.Description
+	}
+
+	hash, err := repository.CommitFiles(ctx, repository.Dir(s.reposDir, repo.ID), files, message,
+		repository.Signature{Name: actor.Name, Email: actor.Email})
+	if err != nil {
+		return "", fmt.Errorf("failed to publish change set: %w", err)
+	}
+	return hash, nil
+}
+
+func (s *service) Queue(ctx context.Context, userID int64) ([]ChangeSet, error) {
+	rows, err := s.queries.ListChangeSetsInReview(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list change sets in review: %w", err)
+	}
+
+	type access struct {
+		actor    Actor
+		settings Settings
+		err      error
+	}
+	repos := make(map[int64]*access)
+
+	var queue []ChangeSet
+	for _, r := range rows {
+		a, ok := repos[r.RepositoryID]
+		if !ok {
+			a = &access{}
+			repo, err := s.queries.GetRepository(ctx, r.RepositoryID)
+			if err == nil {
+				a.actor, a.err = s.Actor(ctx, repo, userID)
+			} else {
+				a.err = fmt.Errorf("failed to load repository: %w", err)
+			}
+			if a.err == nil {
+				a.settings, a.err = s.Settings(ctx, repo)
+			}
+			repos[r.RepositoryID] = a
+		}
+		if a.err != nil {
+			if errors.Is(a.err, ErrForbidden) {
+				continue
+			}
+			return nil, a.err
+		}
+
+		cs := ChangeSet{
+			ID:             r.ID,
+			RepositoryID:   r.RepositoryID,
+			RepositoryName: r.RepositoryName,
+			Title:          r.Title,
+			Description:    r.Description,
+			AuthorID:       r.AuthorID,
+			AuthorName:     r.AuthorName,
+			State:          State(r.State),
+			UpdatedAt:      r.UpdatedAt.Time,
+		}
+		if cs.Approvals, err = s.approvals(ctx, r.ID); err != nil {
+			return nil, err
+		}
+		if Authorize(a.actor, &cs, a.settings, ActionApprove) == nil {
+			queue = append(queue, cs)
+		}
+	}
+	return queue, nil
+}
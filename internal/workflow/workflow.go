@@ -0,0 +1,159 @@
+package workflow
+
+import (
+	"slices"
+	"time"
+)
+
+// State is a change set's position in the editorial workflow.
+type State string
+
+const (
+	StateDraft     State = "draft"
+	StateInReview  State = "in_review"
+	StateApproved  State = "approved"
+	StatePublished State = "published"
+)
+
+// Label returns the state's display name.
+func (s State) Label() string {
+	switch s {
+	case StateInReview:
+		return "In review"
+	case StateApproved:
+		return "Approved"
+	case StatePublished:
+		return "Published"
+	default:
+		return "Draft"
+	}
+}
+
+// Action moves a change set between states.
+type Action string
+
+const (
+	ActionSubmit         Action = "submit"
+	ActionApprove        Action = "approve"
+	ActionRequestChanges Action = "request_changes"
+	ActionWithdraw       Action = "withdraw"
+	ActionPublish        Action = "publish"
+)
+
+// actions is every action, in the order they are offered.
+var actions = []Action{ActionSubmit, ActionApprove, ActionRequestChanges, ActionWithdraw, ActionPublish}
+
+// Actions returns every action, in the order they are offered.
+func Actions() []Action {
+	return slices.Clone(actions)
+}
+
+// ParseAction returns the named action, or false if there is none.
+func ParseAction(name string) (Action, bool) {
+	for _, a := range actions {
+		if string(a) == name {
+			return a, true
+		}
+	}
+	return "", false
+}
+
+// Label returns the action's button text.
+func (a Action) Label() string {
+	switch a {
+	case ActionSubmit:
+		return "Submit for review"
+	case ActionApprove:
+		return "Approve"
+	case ActionRequestChanges:
+		return "Request changes"
+	case ActionWithdraw:
+		return "Back to draft"
+	case ActionPublish:
+		return "Publish"
+	default:
+		return string(a)
+	}
+}
+
+// Role is a user's role in a repository.
+type Role string
+
+const (
+	// RoleOwner is the repository owner, who holds every role
+	RoleOwner    Role = "owner"
+	RoleEditor   Role = "editor"
+	RoleReviewer Role = "reviewer"
+)
+
+// MemberRoles are the roles that can be given to members.
+var MemberRoles = []Role{RoleEditor, RoleReviewer}
+
+// Settings configures approvals for a repository.
+type Settings struct {
+	RequiredApprovals int
+	ApproverRole      Role
+}
+
+// DefaultSettings apply to repositories that have not configured the workflow.
+var DefaultSettings = Settings{RequiredApprovals: 1, ApproverRole: RoleReviewer}
+
+// Member is a user with a role in a repository.
+type Member struct {
+	UserID    int64
+	Name      string
+	Email     string
+	AvatarURL string
+	Role      Role
+}
+
+// ChangeSet is a group of changed content files moving through the workflow.
+type ChangeSet struct {
+	ID              int64
+	RepositoryID    int64
+	RepositoryName  string
+	Title           string
+	Description     string
+	AuthorID        int64
+	AuthorName      string
+	State           State
+	PublishedCommit string
+	UpdatedAt       time.Time
+	Files           []string
+	Approvals       []Approval
+	Transitions     []Transition
+}
+
+// ApprovedBy reports whether the user has approved the change set.
+func (cs *ChangeSet) ApprovedBy(userID int64) bool {
+	for _, a := range cs.Approvals {
+		if a.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// Approval is a reviewer's approval of a change set.
+type Approval struct {
+	UserID   int64
+	UserName string
+	Time     time.Time
+}
+
+// Transition is a recorded action on a change set.
+type Transition struct {
+	Action   Action
+	From     State
+	To       State
+	Note     string
+	UserName string
+	Time     time.Time
+}
+
+// PendingFile is a changed content file; ChangeSetID is set when an
+// unpublished change set already includes it.
+type PendingFile struct {
+	Path        string
+	ChangeSetID int64
+}